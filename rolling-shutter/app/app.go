@@ -24,11 +24,10 @@ import (
 
 var (
 	// PersistMinDuration is the minimum duration between two calls to persistToDisk
-	// TODO we should probably increase the default here and we should have a way to collect
-	// garbage to keep the persisted state small enough.
+	// TODO we should probably increase the default here. Once the state pruning fork is active,
+	// pruneState keeps the persisted state small enough.
 	// The variable is declared here, because we do not want to persist it as part of the
-	// application. The same could be said about the Gobpath field though, which we persist as
-	// part of the application.
+	// application.
 	// If we set this to zero, the state will get saved on every call to Commit.
 	PersistMinDuration time.Duration = 30 * time.Second
 
@@ -70,15 +69,28 @@ func (app *ShutterApp) CheckTx(req abcitypes.RequestCheckTx) abcitypes.ResponseC
 	if !app.NonceTracker.Check(signer, msg.RandomNonce) {
 		return abcitypes.ResponseCheckTx{Code: 1, Log: "nonce already used"}
 	}
+	if !app.checkNonceHeight(msg.RandomNonce) {
+		return abcitypes.ResponseCheckTx{Code: 1, Log: "nonce expired"}
+	}
 	if !app.CheckTxState.AddTx(signer, msg) {
 		return abcitypes.ResponseCheckTx{Code: 1, Log: "not a keyper set member"}
 	}
 	return abcitypes.ResponseCheckTx{Code: 0, GasWanted: 1}
 }
 
+// Config holds the configuration of a ShutterApp that is not part of the app state.
+type Config struct {
+	// ForkHeightOverrides maps chain ids to the fork activations that take precedence over the
+	// ones stored in the app state and the ones of the known chains built into the app, see
+	// ReadForkHeightOverrides. They are part of the consensus rules, so all nodes of a chain have
+	// to use the same overrides.
+	ForkHeightOverrides map[string]ForkHeightOverrides
+}
+
 // NewShutterApp creates a new ShutterApp.
-func NewShutterApp() *ShutterApp {
+func NewShutterApp(config Config) *ShutterApp {
 	return &ShutterApp{
+		config:       config,
 		Configs:      []*BatchConfig{{}},
 		DKGMap:       make(map[uint64]*DKGInstance),
 		ConfigVoting: NewConfigVoting(),
@@ -91,37 +103,40 @@ func NewShutterApp() *ShutterApp {
 	}
 }
 
-// LoadShutterAppFromFile loads a shutter app from a file.
-func LoadShutterAppFromFile(gobpath string) (ShutterApp, error) {
+// LoadShutterAppFromFile loads a shutter app from a file written by PersistToDisk. Files written
+// in an older format version, including the gob encoded files of earlier releases, are upgraded
+// to the current version (see DecodeAppState).
+func LoadShutterAppFromFile(statePath string, config Config) (ShutterApp, error) {
 	var shapp ShutterApp
-	gobfile, err := os.Open(gobpath)
+	data, err := os.ReadFile(statePath)
 	if os.IsNotExist(err) {
-		shapp = *NewShutterApp()
+		shapp = *NewShutterApp(config)
 	} else if err != nil {
 		return shapp, err
 	} else {
-		defer gobfile.Close()
-		dec := gob.NewDecoder(gobfile)
-		err = dec.Decode(&shapp)
+		decoded, err := DecodeAppState(data)
 		if err != nil {
 			return shapp, err
 		}
-		shapp.ForkHeights = migrateForkHeights(shapp.ForkHeights)
+		shapp = *decoded
 		log.Info().
-			Str("file", gobpath).
-			Time("last-saved", shapp.LastSaved).
+			Str("file", statePath).
+			Uint32("format-version", AppStateFileVersion(data)).
 			Int64("last-block-height", shapp.LastBlockHeight).
 			Bool("devmode", shapp.DevMode).
 			Msg("Loaded shutter app from file")
 	}
 
-	shapp.Gobpath = gobpath
+	shapp.config = config
+	shapp.StatePath = statePath
 	shapp.LastSaved = time.Now() // Do not persist immediately after starting
+	shapp.updateCommittedState()
 	return shapp, nil
 }
 
 // migrateForkHeights applies backwards-compatibility migrations for fork
-// height state loaded from persisted app state or genesis.
+// height state loaded from genesis. Persisted app state is migrated by
+// upgradeStateV0.
 func migrateForkHeights(forkHeights *ForkHeights) *ForkHeights {
 	if forkHeights == nil {
 		return NewForkHeightsAllDisabled()
@@ -174,6 +189,7 @@ func (app *ShutterApp) addConfig(cfg BatchConfig) error {
 	log.Info().Uint64("config-index", cfg.KeyperConfigIndex).
 		Msg("adding keyper config")
 	app.Configs = append(app.Configs, &cfg)
+	app.changes.configs = true
 	app.updateCheckTxMembers()
 	return nil
 }
@@ -190,39 +206,16 @@ func (app *ShutterApp) updateCheckTxMembers() {
 	app.CheckTxState.SetMembers(members)
 }
 
-func (app *ShutterApp) Query(_ abcitypes.RequestQuery) abcitypes.ResponseQuery {
-	return abcitypes.ResponseQuery{
-		Code: 1,
-		Log:  "query not implemented",
-	}
-}
-
 // Info should return the latest committed state of the app. On startup, tendermint calls the Info
 // method and will replay blocks that are not yet committed.
 // See https://github.com/tendermint/spec/blob/master/spec/abci/apps.md#crash-recovery
 func (app *ShutterApp) Info(_ abcitypes.RequestInfo) abcitypes.ResponseInfo {
 	return abcitypes.ResponseInfo{
 		LastBlockHeight:  app.LastBlockHeight,
-		LastBlockAppHash: []byte(""),
+		LastBlockAppHash: app.AppHash(),
 	}
 }
 
-func (ShutterApp) ListSnapshots(abcitypes.RequestListSnapshots) abcitypes.ResponseListSnapshots {
-	return abcitypes.ResponseListSnapshots{}
-}
-
-func (ShutterApp) LoadSnapshotChunk(abcitypes.RequestLoadSnapshotChunk) abcitypes.ResponseLoadSnapshotChunk {
-	return abcitypes.ResponseLoadSnapshotChunk{}
-}
-
-func (ShutterApp) ApplySnapshotChunk(abcitypes.RequestApplySnapshotChunk) abcitypes.ResponseApplySnapshotChunk {
-	return abcitypes.ResponseApplySnapshotChunk{}
-}
-
-func (ShutterApp) OfferSnapshot(abcitypes.RequestOfferSnapshot) abcitypes.ResponseOfferSnapshot {
-	return abcitypes.ResponseOfferSnapshot{}
-}
-
 /*
 	BlockExecution
 
@@ -289,6 +282,8 @@ func (app *ShutterApp) InitChain(req abcitypes.RequestInitChain) abcitypes.Respo
 	}
 
 	app.ChainID = req.ChainId
+	app.committed = nil // the state has been replaced by the genesis state
+	app.updateCommittedState()
 
 	return abcitypes.ResponseInitChain{}
 }
@@ -354,7 +349,11 @@ func (app *ShutterApp) DeliverTx(req abcitypes.RequestDeliverTx) abcitypes.Respo
 		msg := fmt.Sprintf("Nonce %d of %s already used", msg.RandomNonce, signer.Hex())
 		return makeErrorResponse(msg)
 	}
-	app.NonceTracker.Add(signer, msg.RandomNonce)
+	if !app.checkNonceHeight(msg.RandomNonce) {
+		msg := fmt.Sprintf("Nonce %d of %s expired", msg.RandomNonce, signer.Hex())
+		return makeErrorResponse(msg)
+	}
+	app.addNonce(signer, msg.RandomNonce)
 	return app.deliverMessage(msg.Msg, signer)
 }
 
@@ -477,6 +476,7 @@ func (app *ShutterApp) deliverCheckIn(msg *shmsg.CheckIn, sender common.Address)
 	encryptionPublicKey := ecies.ImportECDSAPublic(encryptionPublicKeyECDSA)
 
 	app.Identities[sender] = validatorPublicKey
+	app.changes.identities = true
 	return abcitypes.ResponseDeliverTx{
 		Code: 0,
 		Events: []abcitypes.Event{
@@ -494,6 +494,7 @@ func (app *ShutterApp) deliverBlockSeen(
 ) abcitypes.ResponseDeliverTx {
 	if msg.BlockNumber > app.BlocksSeen[sender] {
 		app.BlocksSeen[sender] = msg.BlockNumber
+		app.changes.blocksSeen = true
 	}
 	return abcitypes.ResponseDeliverTx{
 		Code:   0,
@@ -504,6 +505,9 @@ func (app *ShutterApp) deliverBlockSeen(
 func (app *ShutterApp) deliverDKGResult(msg *shmsg.DKGResult, sender common.Address) abcitypes.ResponseDeliverTx {
 	dkginstance, ok := app.DKGMap[msg.Eon]
 	if !ok {
+		if app.isPrunedEon(msg.Eon) {
+			return makeAlreadySeenResponse(fmt.Sprintf("dkg of eon %d already finalized", msg.Eon))
+		}
 		return makeErrorResponse(fmt.Sprintf(
 			"cannot handle DKGResult message for eon %d", msg.Eon),
 		)
@@ -517,6 +521,7 @@ func (app *ShutterApp) deliverDKGResult(msg *shmsg.DKGResult, sender common.Addr
 	if err != nil {
 		return makeAlreadySeenResponse("already voted on dkg result")
 	}
+	app.changes.eon(msg.Eon)
 
 	dkg, started := app.maybeStartEon(msg.Eon)
 	if !started {
@@ -563,6 +568,9 @@ func (app *ShutterApp) handlePolyEvalMsg(msg *shmsg.PolyEval, sender common.Addr
 
 	dkg := app.DKGMap[appMsg.Eon]
 	if dkg == nil {
+		if app.isPrunedEon(appMsg.Eon) {
+			return makeAlreadySeenResponse(fmt.Sprintf("dkg of eon %d already finalized", appMsg.Eon))
+		}
 		msg := "Error: Received PolyEval message while DKG is not active"
 		log.Print(msg)
 		return makeErrorResponse(msg)
@@ -578,6 +586,7 @@ func (app *ShutterApp) handlePolyEvalMsg(msg *shmsg.PolyEval, sender common.Addr
 		return makeErrorResponse(msg)
 	}
 
+	app.changes.eon(appMsg.Eon)
 	event := appMsg.MakeABCIEvent()
 	return abcitypes.ResponseDeliverTx{
 		Code:   0,
@@ -595,6 +604,9 @@ func (app *ShutterApp) handlePolyCommitmentMsg(msg *shmsg.PolyCommitment, sender
 
 	dkg := app.DKGMap[appMsg.Eon]
 	if dkg == nil {
+		if app.isPrunedEon(appMsg.Eon) {
+			return makeAlreadySeenResponse(fmt.Sprintf("dkg of eon %d already finalized", appMsg.Eon))
+		}
 		msg := "Error: Received PolyCommitment message while DKG is not active"
 		log.Print(msg)
 		return makeErrorResponse(msg)
@@ -610,6 +622,7 @@ func (app *ShutterApp) handlePolyCommitmentMsg(msg *shmsg.PolyCommitment, sender
 		return makeErrorResponse(msg)
 	}
 
+	app.changes.eon(appMsg.Eon)
 	event := appMsg.MakeABCIEvent()
 	return abcitypes.ResponseDeliverTx{
 		Code:   0,
@@ -627,6 +640,9 @@ func (app *ShutterApp) handleAccusationMsg(msg *shmsg.Accusation, sender common.
 
 	dkg := app.DKGMap[appMsg.Eon]
 	if dkg == nil {
+		if app.isPrunedEon(appMsg.Eon) {
+			return makeAlreadySeenResponse(fmt.Sprintf("dkg of eon %d already finalized", appMsg.Eon))
+		}
 		msg := "Error: Received Accusation message while DKG is not active"
 		log.Print(msg)
 		return makeErrorResponse(msg)
@@ -642,6 +658,7 @@ func (app *ShutterApp) handleAccusationMsg(msg *shmsg.Accusation, sender common.
 		return makeErrorResponse(msg)
 	}
 
+	app.changes.eon(appMsg.Eon)
 	event := appMsg.MakeABCIEvent()
 	return abcitypes.ResponseDeliverTx{
 		Code:   0,
@@ -659,6 +676,9 @@ func (app *ShutterApp) handleApologyMsg(msg *shmsg.Apology, sender common.Addres
 
 	dkg := app.DKGMap[appMsg.Eon]
 	if dkg == nil {
+		if app.isPrunedEon(appMsg.Eon) {
+			return makeAlreadySeenResponse(fmt.Sprintf("dkg of eon %d already finalized", appMsg.Eon))
+		}
 		msg := "Error: Received Apology message while DKG is not active"
 		log.Print(msg)
 		return makeErrorResponse(msg)
@@ -674,6 +694,7 @@ func (app *ShutterApp) handleApologyMsg(msg *shmsg.Apology, sender common.Addres
 		return makeErrorResponse(msg)
 	}
 
+	app.changes.eon(appMsg.Eon)
 	event := appMsg.MakeABCIEvent()
 	return abcitypes.ResponseDeliverTx{
 		Code:   0,
@@ -715,6 +736,7 @@ func (app *ShutterApp) StartDKG(config BatchConfig) *DKGInstance {
 	app.EONCounter++
 	dkg := NewDKGInstance(config, app.EONCounter)
 	app.DKGMap[dkg.Eon] = &dkg
+	app.changes.eon(dkg.Eon)
 	return &dkg
 }
 
@@ -784,6 +806,7 @@ func (app *ShutterApp) EndBlock(req abcitypes.RequestEndBlock) abcitypes.Respons
 			if numVotes >= numRequiredVotes {
 				log.Info().Uint64("config-index", config.KeyperConfigIndex).Msg("starting keyper config")
 				config.Started = true
+				app.changes.configs = true
 				events = append(events, shutterevents.BatchConfigStarted{
 					KeyperConfigIndex: config.KeyperConfigIndex,
 				}.MakeABCIEvent())
@@ -791,13 +814,19 @@ func (app *ShutterApp) EndBlock(req abcitypes.RequestEndBlock) abcitypes.Respons
 		}
 		if config.Started && !config.ValidatorsUpdated && app.countCheckedInKeypers(config.Keypers) >= numRequiredTransitionValidators(config) {
 			config.ValidatorsUpdated = true
+			app.changes.configs = true
 		}
 	}
 
+	app.pruneState()
+
 	newValidators := app.CurrentValidators()
 	validatorUpdates := DiffPowermaps(app.Validators, newValidators).ValidatorUpdates()
 	app.Validators = newValidators
 	app.LastBlockHeight = req.Height
+	if len(validatorUpdates) > 0 {
+		app.changes.validators = true
+	}
 	if app.DevMode {
 		if len(validatorUpdates) > 0 {
 			log.Info().Int("count", len(validatorUpdates)).Msg("ignoring validator updates in dev mode")
@@ -836,7 +865,7 @@ func numRequiredTransitionValidators(config *BatchConfig) uint64 {
 // renames the file later. Most probably this will not work on windows!
 func (app *ShutterApp) PersistToDisk() error {
 	log.Info().Int64("height", app.LastBlockHeight).Msg("persisting state to disk")
-	tmppath := app.Gobpath + ".tmp"
+	tmppath := app.StatePath + ".tmp"
 	file, err := os.Create(tmppath)
 	if err != nil {
 		return err
@@ -850,8 +879,11 @@ func (app *ShutterApp) PersistToDisk() error {
 	}()
 
 	app.LastSaved = time.Now()
-	enc := gob.NewEncoder(file)
-	err = enc.Encode(app)
+	data, err := EncodeAppState(app)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
 	if err != nil {
 		return err
 	}
@@ -859,12 +891,20 @@ func (app *ShutterApp) PersistToDisk() error {
 	if err != nil {
 		return err
 	}
-	err = os.Rename(tmppath, app.Gobpath)
-	return err
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmppath, app.StatePath)
+	if err != nil {
+		return err
+	}
+	metricsPersistedStateSize.Set(float64(info.Size()))
+	return nil
 }
 
 func (app *ShutterApp) maybePersistToDisk() error {
-	if app.Gobpath == "" {
+	if app.StatePath == "" {
 		return nil
 	}
 	if time.Since(app.LastSaved) <= PersistMinDuration {
@@ -880,8 +920,13 @@ func (app *ShutterApp) Commit() abcitypes.ResponseCommit { //nolint:unparam
 	if err != nil {
 		log.Error().Err(err).Msg("cannot persist state to disk")
 	}
+	err = app.maybeCreateSnapshot()
+	if err != nil {
+		log.Error().Err(err).Msg("cannot create state sync snapshot")
+	}
+	app.updateCommittedState()
 
-	return abcitypes.ResponseCommit{}
+	return abcitypes.ResponseCommit{Data: app.AppHash()}
 }
 
 // CurrentBlockHeight returns the height of the block being processed between
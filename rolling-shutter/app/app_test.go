@@ -17,13 +17,13 @@ func init() {
 }
 
 func TestNewShutterApp(t *testing.T) {
-	app := NewShutterApp()
+	app := NewShutterApp(Config{})
 	assert.Equal(t, len(app.Configs), 1, "Configs should contain exactly one guard element")
 	assert.Assert(t, is.DeepEqual(app.Configs[0], &BatchConfig{}), "Bad guard element")
 }
 
 func TestAddConfig(t *testing.T) {
-	app := NewShutterApp()
+	app := NewShutterApp(Config{})
 
 	err := app.addConfig(BatchConfig{
 		KeyperConfigIndex:     1,
@@ -0,0 +1,279 @@
+package app
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"maps"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The app hash version is written as the first element of the hashed state. It has to be
+// increased whenever the canonical encoding below changes, and the new encoding must only be used
+// once all nodes of a chain run a binary that knows it, i.e., it has to be gated behind a fork.
+const (
+	// appHashVersionInitial is the encoding used since the app hash fork.
+	appHashVersionInitial uint64 = 1
+	// appHashVersionStatePruning additionally includes the state pruning fork height and the
+	// expiring nonces. It is used once the state pruning fork is active.
+	appHashVersionStatePruning uint64 = 2
+	// appHashVersionForkEons additionally includes the activation eons of the forks. It is used
+	// if the activation of any fork is configured by eon. This requires a binary that knows
+	// about activation eons anyway, so the hashes of chains not using them are not affected.
+	appHashVersionForkEons uint64 = 3
+)
+
+// AppHash returns the hash of the application state after the last committed block. It returns
+// nil before the first block has been processed or if the app hash fork is not active yet.
+func (app *ShutterApp) AppHash() []byte {
+	if app.LastBlockHeight == 0 || !app.IsAppHashForkActive() {
+		return nil
+	}
+	return app.computeStateHash()
+}
+
+// IsAppHashForkActive checks if the state hash has to be returned from Commit and Info at the
+// last committed block height.
+func (app *ShutterApp) IsAppHashForkActive() bool {
+	return app.isForkActiveAt(ForkAppHash, app.LastBlockHeight)
+}
+
+// appHashVersion returns the version of the encoding used to hash the state at the last
+// committed block height.
+func (app *ShutterApp) appHashVersion() uint64 {
+	if app.ForkHeights.hasActivationEon() {
+		return appHashVersionForkEons
+	}
+	if app.isForkActiveAt(ForkStatePruning, app.LastBlockHeight) {
+		return appHashVersionStatePruning
+	}
+	return appHashVersionInitial
+}
+
+// computeStateHash computes a hash over all parts of the state that are changed by executing
+// blocks. Map entries are hashed in sorted key order, so the result does not depend on Go's map
+// iteration order. Fields that are local to a node (e.g. StatePath, LastSaved, DevMode and
+// CheckTxState) are not included.
+func (app *ShutterApp) computeStateHash() []byte {
+	s := newStateHasher(app.appHashVersion())
+	s.uint64(s.version)
+	s.string(app.ChainID)
+	s.int64(app.LastBlockHeight)
+	s.uint64(app.EONCounter)
+	s.forkHeights(app.ForkHeights)
+
+	s.uint64(uint64(len(app.Configs)))
+	for _, cfg := range app.Configs {
+		s.batchConfig(cfg)
+	}
+	hashVoting(s, app.ConfigVoting, func(cfg BatchConfig) { s.batchConfig(&cfg) })
+
+	eons := slices.Sorted(maps.Keys(app.DKGMap))
+	s.uint64(uint64(len(eons)))
+	for _, eon := range eons {
+		s.uint64(eon)
+		s.dkgInstance(app.DKGMap[eon])
+	}
+
+	identities := sortedAddresses(app.Identities)
+	s.uint64(uint64(len(identities)))
+	for _, a := range identities {
+		s.address(a)
+		s.string(app.Identities[a].Ed25519pubkey)
+	}
+
+	blocksSeen := sortedAddresses(app.BlocksSeen)
+	s.uint64(uint64(len(blocksSeen)))
+	for _, a := range blocksSeen {
+		s.address(a)
+		s.uint64(app.BlocksSeen[a])
+	}
+
+	s.powermap(app.Validators)
+	s.nonceTracker(app.NonceTracker)
+	return s.sum()
+}
+
+// stateHasher writes a canonical binary encoding of the app state into a hash function. Integers
+// are written as fixed size big endian values, byte strings are prefixed with their length.
+type stateHasher struct {
+	h       hash.Hash
+	buf     [8]byte
+	version uint64
+}
+
+func newStateHasher(version uint64) *stateHasher {
+	return &stateHasher{h: sha256.New(), version: version}
+}
+
+func (s *stateHasher) sum() []byte {
+	return s.h.Sum(nil)
+}
+
+func (s *stateHasher) uint64(v uint64) {
+	binary.BigEndian.PutUint64(s.buf[:], v)
+	s.h.Write(s.buf[:])
+}
+
+func (s *stateHasher) int64(v int64) {
+	s.uint64(uint64(v)) //nolint:gosec // G115, two's complement representation is intended
+}
+
+func (s *stateHasher) bool(v bool) {
+	if v {
+		s.h.Write([]byte{1})
+	} else {
+		s.h.Write([]byte{0})
+	}
+}
+
+func (s *stateHasher) bytes(v []byte) {
+	s.uint64(uint64(len(v)))
+	s.h.Write(v)
+}
+
+func (s *stateHasher) string(v string) {
+	s.bytes([]byte(v))
+}
+
+func (s *stateHasher) address(a common.Address) {
+	s.h.Write(a.Bytes())
+}
+
+func (s *stateHasher) addressSet(m map[common.Address]struct{}) {
+	addresses := sortedAddresses(m)
+	s.uint64(uint64(len(addresses)))
+	for _, a := range addresses {
+		s.address(a)
+	}
+}
+
+func (s *stateHasher) forkHeight(fh ForkHeight) {
+	s.bool(fh.Enabled)
+	s.int64(fh.Height)
+	if s.version < appHashVersionForkEons {
+		return
+	}
+	s.bool(fh.Eon != nil)
+	if fh.Eon != nil {
+		s.uint64(*fh.Eon)
+	}
+}
+
+func (s *stateHasher) forkHeights(fh *ForkHeights) {
+	s.bool(fh != nil)
+	if fh == nil {
+		return
+	}
+	s.bool(fh.CheckInUpdate != nil)
+	if fh.CheckInUpdate != nil {
+		s.int64(*fh.CheckInUpdate)
+	}
+	for _, name := range hashedForks(s.version) {
+		s.forkHeight(fh.Get(name))
+	}
+}
+
+// hashedForks returns the forks whose activation is part of the hashed state in the given
+// version. Forks added to the registry later on are only hashed in the versions introduced with
+// them, so that registering a fork does not change the hash of existing chains.
+func hashedForks(version uint64) []ForkName {
+	forks := []ForkName{ForkCheckInUpdate, ForkAppHash}
+	if version >= appHashVersionStatePruning {
+		forks = append(forks, ForkStatePruning)
+	}
+	return forks
+}
+
+func (s *stateHasher) batchConfig(cfg *BatchConfig) {
+	s.int64(cfg.Height)
+	s.uint64(uint64(len(cfg.Keypers)))
+	for _, k := range cfg.Keypers {
+		s.address(k)
+	}
+	s.uint64(cfg.ActivationBlockNumber)
+	s.uint64(cfg.Threshold)
+	s.uint64(cfg.KeyperConfigIndex)
+	s.bool(cfg.Started)
+	s.bool(cfg.ValidatorsUpdated)
+}
+
+func (s *stateHasher) dkgInstance(dkg *DKGInstance) {
+	s.batchConfig(&dkg.Config)
+	s.uint64(dkg.Eon)
+	hashVoting(s, dkg.SuccessVoting, s.bool)
+
+	pairs := slices.SortedFunc(maps.Keys(dkg.PolyEvalsSeen), func(a, b SenderReceiverPair) int {
+		if c := a.Sender.Cmp(b.Sender); c != 0 {
+			return c
+		}
+		return a.Receiver.Cmp(b.Receiver)
+	})
+	s.uint64(uint64(len(pairs)))
+	for _, p := range pairs {
+		s.address(p.Sender)
+		s.address(p.Receiver)
+	}
+	s.addressSet(dkg.PolyCommitmentsSeen)
+	s.addressSet(dkg.AccusationsSeen)
+	s.addressSet(dkg.ApologiesSeen)
+}
+
+func (s *stateHasher) powermap(pm Powermap) {
+	pubkeys := slices.SortedFunc(maps.Keys(pm), func(a, b ValidatorPubkey) int {
+		return cmp.Compare(a.Ed25519pubkey, b.Ed25519pubkey)
+	})
+	s.uint64(uint64(len(pubkeys)))
+	for _, k := range pubkeys {
+		s.string(k.Ed25519pubkey)
+		s.int64(pm[k])
+	}
+}
+
+func (s *stateHasher) nonceTracker(t *NonceTracker) {
+	s.bool(t != nil)
+	if t == nil {
+		return
+	}
+	s.nonces(t.RandomNonces)
+	if s.version >= appHashVersionStatePruning {
+		s.nonces(t.ExpiringNonces)
+	}
+}
+
+func (s *stateHasher) nonces(m map[common.Address]map[uint64]bool) {
+	senders := sortedAddresses(m)
+	s.uint64(uint64(len(senders)))
+	for _, sender := range senders {
+		s.address(sender)
+		nonces := slices.Sorted(maps.Keys(m[sender]))
+		s.uint64(uint64(len(nonces)))
+		for _, n := range nonces {
+			s.uint64(n)
+			s.bool(m[sender][n])
+		}
+	}
+}
+
+// hashVoting writes the votes sorted by voter address, followed by the candidates in the order
+// they were added.
+func hashVoting[T any, E Equals[T]](s *stateHasher, v Voting[T, E], hashCandidate func(T)) {
+	voters := sortedAddresses(v.Votes)
+	s.uint64(uint64(len(voters)))
+	for _, a := range voters {
+		s.address(a)
+		s.int64(int64(v.Votes[a]))
+	}
+	s.uint64(uint64(len(v.Candidates)))
+	for _, c := range v.Candidates {
+		hashCandidate(c)
+	}
+}
+
+// sortedAddresses returns the keys of the given map in ascending byte order.
+func sortedAddresses[V any](m map[common.Address]V) []common.Address {
+	return slices.SortedFunc(maps.Keys(m), common.Address.Cmp)
+}
@@ -0,0 +1,294 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/tendermint/go-amino"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
+)
+
+const testChainID = "shutter-test-chain"
+
+type testKeyper struct {
+	key          *ecdsa.PrivateKey
+	validatorKey ed25519.PublicKey
+	nonce        uint64
+}
+
+func newTestKeypers(t *testing.T, n int) []*testKeyper {
+	t.Helper()
+	keypers := []*testKeyper{}
+	for i := 0; i < n; i++ {
+		key, err := crypto.GenerateKey()
+		assert.NilError(t, err)
+		validatorKey, _, err := ed25519.GenerateKey(nil)
+		assert.NilError(t, err)
+		keypers = append(keypers, &testKeyper{key: key, validatorKey: validatorKey})
+	}
+	return keypers
+}
+
+func (k *testKeyper) address() common.Address {
+	return crypto.PubkeyToAddress(k.key.PublicKey)
+}
+
+// tx creates a signed transaction. Nonces are increased for each transaction, so calling tx twice
+// with the same message creates two distinct transactions.
+func (k *testKeyper) tx(t *testing.T, msg *shmsg.Message) []byte {
+	t.Helper()
+	k.nonce++
+	return k.txWithNonce(t, msg, k.nonce)
+}
+
+func (k *testKeyper) txWithNonce(t *testing.T, msg *shmsg.Message, nonce uint64) []byte {
+	t.Helper()
+	signedMessage, err := shmsg.SignMessage(&shmsg.MessageWithNonce{
+		Msg:         msg,
+		ChainId:     []byte(testChainID),
+		RandomNonce: nonce,
+	}, k.key)
+	assert.NilError(t, err)
+	return []byte(base64.RawURLEncoding.EncodeToString(signedMessage))
+}
+
+func (k *testKeyper) checkInTx(t *testing.T) []byte {
+	t.Helper()
+	return k.tx(t, shmsg.NewCheckIn(k.validatorKey, ecies.ImportECDSAPublic(&k.key.PublicKey)))
+}
+
+func newTestChainApp(t *testing.T, keypers []*testKeyper, forkHeights *ForkHeights) *ShutterApp {
+	t.Helper()
+	addresses := []common.Address{}
+	for _, k := range keypers {
+		addresses = append(addresses, k.address())
+	}
+	appState := NewGenesisAppState(addresses, 2, 0, forkHeights)
+	appStateBytes, err := amino.NewCodec().MarshalJSON(appState)
+	assert.NilError(t, err)
+
+	app := NewShutterApp(Config{})
+	app.InitChain(abcitypes.RequestInitChain{
+		ChainId:       testChainID,
+		AppStateBytes: appStateBytes,
+	})
+	return app
+}
+
+// runBlock executes a block containing the given transactions and returns the app hash returned
+// from Commit.
+func runBlock(t *testing.T, app *ShutterApp, txs [][]byte) []byte {
+	t.Helper()
+	height := app.LastBlockHeight + 1
+	app.BeginBlock(abcitypes.RequestBeginBlock{})
+	for _, tx := range txs {
+		res := app.DeliverTx(abcitypes.RequestDeliverTx{Tx: tx})
+		assert.Equal(t, res.Code, uint32(0), res.Log)
+	}
+	app.EndBlock(abcitypes.RequestEndBlock{Height: height})
+	return app.Commit().Data
+}
+
+func TestAppHashReplay(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app1 := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	app2 := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+
+	var blocks [][][]byte
+	blocks = append(blocks, [][]byte{
+		keypers[0].checkInTx(t),
+		keypers[1].checkInTx(t),
+		keypers[2].checkInTx(t),
+	})
+	blocks = append(blocks, [][]byte{
+		keypers[0].tx(t, shmsg.NewBlockSeen(10)),
+		keypers[1].tx(t, shmsg.NewBlockSeen(12)),
+	})
+	newKeypers := []common.Address{keypers[0].address(), keypers[1].address()}
+	blocks = append(blocks, [][]byte{
+		keypers[0].tx(t, shmsg.NewBatchConfig(20, newKeypers, 2, 1)),
+		keypers[1].tx(t, shmsg.NewBatchConfig(20, newKeypers, 2, 1)),
+	})
+	blocks = append(blocks, [][]byte{
+		keypers[0].tx(t, shmsg.NewPolyEval(1, []common.Address{keypers[1].address()}, [][]byte{{1}})),
+		keypers[1].tx(t, shmsg.NewAccusation(1, []common.Address{keypers[0].address()})),
+		keypers[0].tx(t, shmsg.NewDKGResult(1, true)),
+	})
+
+	var previousHash []byte
+	for _, txs := range blocks {
+		hash1 := runBlock(t, app1, txs)
+		hash2 := runBlock(t, app2, txs)
+		assert.Equal(t, len(hash1), 32)
+		assert.DeepEqual(t, hash1, hash2)
+		assert.Assert(t, string(hash1) != string(previousHash), "state change did not change app hash")
+		previousHash = hash1
+	}
+
+	info := app1.Info(abcitypes.RequestInfo{})
+	assert.Equal(t, info.LastBlockHeight, int64(len(blocks)))
+	assert.DeepEqual(t, info.LastBlockAppHash, previousHash)
+}
+
+func TestAppHashIndependentOfTxOrder(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app1 := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	app2 := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+
+	checkIns := [][]byte{keypers[0].checkInTx(t), keypers[1].checkInTx(t), keypers[2].checkInTx(t)}
+	blocksSeen := [][]byte{
+		keypers[0].tx(t, shmsg.NewBlockSeen(5)),
+		keypers[1].tx(t, shmsg.NewBlockSeen(6)),
+		keypers[2].tx(t, shmsg.NewBlockSeen(7)),
+	}
+
+	runBlock(t, app1, checkIns)
+	runBlock(t, app2, [][]byte{checkIns[2], checkIns[0], checkIns[1]})
+	hash1 := runBlock(t, app1, blocksSeen)
+	hash2 := runBlock(t, app2, [][]byte{blocksSeen[1], blocksSeen[2], blocksSeen[0]})
+	assert.DeepEqual(t, hash1, hash2)
+}
+
+func TestAppHashDetectsDivergence(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app1 := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	app2 := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+
+	checkIn := keypers[0].checkInTx(t)
+	hash1 := runBlock(t, app1, [][]byte{checkIn})
+	hash2 := runBlock(t, app2, [][]byte{checkIn})
+	assert.DeepEqual(t, hash1, hash2)
+
+	app2.BlocksSeen[keypers[1].address()] = 1
+	assert.Assert(t, string(app1.AppHash()) != string(app2.AppHash()))
+}
+
+func TestAppHashForkInactive(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	forkHeights := NewForkHeightsAllEnabled()
+	forkHeights.AppHash = ForkHeight{Enabled: true, Height: 2}
+	app := newTestChainApp(t, keypers, forkHeights)
+
+	hash := runBlock(t, app, [][]byte{keypers[0].checkInTx(t)})
+	assert.Assert(t, hash == nil)
+	assert.Assert(t, app.Info(abcitypes.RequestInfo{}).LastBlockAppHash == nil)
+
+	hash = runBlock(t, app, [][]byte{keypers[1].checkInTx(t)})
+	assert.Equal(t, len(hash), 32)
+
+	disabledApp := newTestChainApp(t, keypers, NewForkHeightsAllDisabled())
+	hash = runBlock(t, disabledApp, [][]byte{keypers[0].checkInTx(t)})
+	assert.Assert(t, hash == nil)
+}
+
+func TestAppHashVersionStatePruning(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	forkHeights := NewForkHeightsAllEnabled()
+	forkHeights.StatePruning = ForkHeight{Enabled: true, Height: 2}
+	app := newTestChainApp(t, keypers, forkHeights)
+
+	hash := runBlock(t, app, [][]byte{keypers[0].checkInTx(t)})
+	assert.Equal(t, app.appHashVersion(), appHashVersionInitial)
+
+	// expiring nonces are not part of the initial encoding
+	app.NonceTracker.AddExpiring(keypers[0].address(), shmsg.NewNonce(1, 1))
+	assert.DeepEqual(t, app.AppHash(), hash)
+
+	runBlock(t, app, [][]byte{keypers[1].checkInTx(t)})
+	assert.Equal(t, app.appHashVersion(), appHashVersionStatePruning)
+	hash = app.AppHash()
+	app.NonceTracker.AddExpiring(keypers[0].address(), shmsg.NewNonce(2, 1))
+	assert.Assert(t, string(app.AppHash()) != string(hash))
+}
+
+// newFixedStateApp returns an app with a fixed state covering all hashed fields.
+func newFixedStateApp(forkHeights *ForkHeights) *ShutterApp {
+	keypers := []common.Address{
+		common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		common.HexToAddress("0x2222222222222222222222222222222222222222"),
+		common.HexToAddress("0x3333333333333333333333333333333333333333"),
+	}
+	app := NewShutterApp(Config{})
+	app.ChainID = testChainID
+	app.LastBlockHeight = 100
+	app.ForkHeights = forkHeights
+	config := BatchConfig{
+		Height:                1,
+		Keypers:               keypers,
+		ActivationBlockNumber: 20,
+		Threshold:             2,
+		KeyperConfigIndex:     1,
+		Started:               true,
+	}
+	app.Configs = append(app.Configs, &config)
+	app.ConfigVoting.SetVote(keypers[0], config)
+	dkg := app.StartDKG(config)
+	dkg.SuccessVoting.SetVote(keypers[1], true)
+	dkg.PolyEvalsSeen[SenderReceiverPair{Sender: keypers[0], Receiver: keypers[1]}] = struct{}{}
+	dkg.PolyCommitmentsSeen[keypers[0]] = struct{}{}
+	dkg.AccusationsSeen[keypers[1]] = struct{}{}
+	dkg.ApologiesSeen[keypers[0]] = struct{}{}
+	for i, k := range keypers {
+		app.Identities[k] = ValidatorPubkey{Ed25519pubkey: strings.Repeat(string(rune('a'+i)), ed25519.PublicKeySize)}
+		app.BlocksSeen[k] = uint64(10 + i)
+	}
+	app.Validators = app.makePowermap(keypers)
+	app.NonceTracker.Add(keypers[0], 42)
+	app.NonceTracker.AddExpiring(keypers[1], shmsg.NewNonce(99, 7))
+	return app
+}
+
+// TestAppHashPinned makes sure that the encodings of all app hash versions stay the same. If this
+// test fails, the hash of existing chains would change.
+func TestAppHashPinned(t *testing.T) {
+	statePruningAt := func(height int64) *ForkHeights {
+		forkHeights := NewForkHeightsAllEnabled()
+		forkHeights.StatePruning = ForkHeight{Enabled: true, Height: height}
+		return forkHeights
+	}
+	eonActivation := NewForkHeightsAllEnabled()
+	eonActivation.CheckInUpdateNew = ForkHeight{Enabled: true, Eon: uint64Ptr(3)}
+
+	testCases := []struct {
+		name        string
+		forkHeights *ForkHeights
+		version     uint64
+		hash        string
+	}{
+		{
+			"initial",
+			statePruningAt(1000),
+			appHashVersionInitial,
+			"ecf4538d5c3780842345bbc2b0245985df326d23b5c9fad430b3cc2d2ac31be7",
+		},
+		{
+			"state pruning",
+			statePruningAt(0),
+			appHashVersionStatePruning,
+			"47a173e14e9acbfe8f3a37f21d7169723ddb7714df75011c863152cb6944280d",
+		},
+		{
+			"fork eons",
+			eonActivation,
+			appHashVersionForkEons,
+			"abe6791696a6d57614961268b32cb09d67ab6116a82315c60e8bf3cb1b49c8da",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newFixedStateApp(tc.forkHeights)
+			assert.Equal(t, app.appHashVersion(), tc.version)
+			assert.Equal(t, hex.EncodeToString(app.AppHash()), tc.hash)
+		})
+	}
+}
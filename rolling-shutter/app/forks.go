@@ -1,23 +1,162 @@
 package app
 
-import "github.com/rs/zerolog/log"
-
-var forkHeightOverrides = map[string]ForkHeightOverrides{
-	"shutter-gnosis-1000": {CheckInUpdate: &ForkHeightOverride{
-		Eon: uint64Ptr(9),
-	}},
-	"shutter-chiado-102000": {CheckInUpdate: &ForkHeightOverride{
-		Eon: uint64Ptr(13),
-	}},
-	"shutter-api-gnosis-1001": {CheckInUpdate: &ForkHeightOverride{
-		Eon: uint64Ptr(13),
-	}},
-	"shutter-service-chiado-1000": {CheckInUpdate: &ForkHeightOverride{
-		Eon: uint64Ptr(9),
-	}},
-	"shutter-api-gnosis-1002": {CheckInUpdate: &ForkHeightOverride{
-		Eon: uint64Ptr(0),
-	}},
+import (
+	_ "embed"
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ForkHeightOverridesFileName is the name of the file holding the fork height overrides of the
+// known chains in this package, and of the file read from next to the genesis file at runtime.
+const ForkHeightOverridesFileName = "fork-overrides.json"
+
+// defaultForkHeightOverridesJSON holds the fork activations of the known chains that have been
+// decided on after the chains have been started. All nodes of these chains need them.
+//
+//go:embed fork-overrides.json
+var defaultForkHeightOverridesJSON []byte
+
+var defaultForkHeightOverrides = mustParseForkHeightOverrides(defaultForkHeightOverridesJSON)
+
+// ForkName identifies a fork in the fork registry.
+type ForkName string
+
+const (
+	ForkCheckInUpdate ForkName = "checkInUpdate"
+	ForkAppHash       ForkName = "appHash"
+	ForkStatePruning  ForkName = "statePruning"
+)
+
+// ForkSpec describes a fork known to the app.
+type ForkSpec struct {
+	Name ForkName
+	// FlagName is used for the `--forks.<FlagName>.*` flags of `chain init`.
+	FlagName    string
+	Description string
+	// forkHeight returns the field of ForkHeights in which the activation of the fork is stored.
+	forkHeight func(fh *ForkHeights) *ForkHeight
+}
+
+// Forks is the registry of all forks in the order in which they have been introduced. To add a
+// new fork, add a field to ForkHeights and register it here.
+var Forks = []ForkSpec{
+	{
+		Name:        ForkCheckInUpdate,
+		FlagName:    "check-in-update",
+		Description: "allow keypers to update their check-in",
+		forkHeight:  func(fh *ForkHeights) *ForkHeight { return &fh.CheckInUpdateNew },
+	},
+	{
+		Name:        ForkAppHash,
+		FlagName:    "app-hash",
+		Description: "commit to the app state hash",
+		forkHeight:  func(fh *ForkHeights) *ForkHeight { return &fh.AppHash },
+	},
+	{
+		Name:        ForkStatePruning,
+		FlagName:    "state-pruning",
+		Description: "prune finalized DKGs and expired nonces",
+		forkHeight:  func(fh *ForkHeights) *ForkHeight { return &fh.StatePruning },
+	},
+}
+
+// LookupFork returns the registry entry of the fork with the given name.
+func LookupFork(name ForkName) (ForkSpec, error) {
+	for _, spec := range Forks {
+		if spec.Name == name {
+			return spec, nil
+		}
+	}
+	return ForkSpec{}, errors.Errorf("unknown fork %s", name)
+}
+
+func mustLookupFork(name ForkName) ForkSpec {
+	spec, err := LookupFork(name)
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
+
+// Get returns the activation of the fork with the given name.
+func (fh *ForkHeights) Get(name ForkName) ForkHeight {
+	return *mustLookupFork(name).forkHeight(fh)
+}
+
+// hasActivationEon checks if the activation of any fork is configured by eon.
+func (fh *ForkHeights) hasActivationEon() bool {
+	if fh == nil {
+		return false
+	}
+	for _, spec := range Forks {
+		if fh.Get(spec.Name).Eon != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Set sets the activation of the fork with the given name.
+func (fh *ForkHeights) Set(name ForkName, forkHeight ForkHeight) error {
+	spec, err := LookupFork(name)
+	if err != nil {
+		return err
+	}
+	*spec.forkHeight(fh) = forkHeight
+	return nil
+}
+
+// ParseForkHeightOverrides parses fork height overrides given as JSON object mapping chain ids to
+// fork names to an override height or eon, e.g. {"my-chain": {"appHash": {"eon": 3}}}.
+func ParseForkHeightOverrides(data []byte) (map[string]ForkHeightOverrides, error) {
+	overrides := make(map[string]ForkHeightOverrides)
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, errors.Wrap(err, "failed to parse fork height overrides")
+	}
+	for chainID, chainOverrides := range overrides {
+		for name, override := range chainOverrides {
+			if _, err := LookupFork(name); err != nil {
+				return nil, errors.Wrapf(err, "invalid fork height override for chain %s", chainID)
+			}
+			if override == nil || (override.Height == nil && override.Eon == nil) {
+				return nil, errors.Errorf(
+					"fork height override for fork %s of chain %s sets neither height nor eon", name, chainID,
+				)
+			}
+		}
+	}
+	return overrides, nil
+}
+
+func mustParseForkHeightOverrides(data []byte) map[string]ForkHeightOverrides {
+	overrides, err := ParseForkHeightOverrides(data)
+	if err != nil {
+		panic(err)
+	}
+	return overrides
+}
+
+// ReadForkHeightOverrides reads fork height overrides from a JSON file, see
+// ParseForkHeightOverrides.
+func ReadForkHeightOverrides(path string) (map[string]ForkHeightOverrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read fork height overrides")
+	}
+	return ParseForkHeightOverrides(data)
+}
+
+// forkHeightOverride returns the override for the given fork on the app's chain or nil if there
+// is none. Overrides from the app config take precedence over the ones of the known chains
+// embedded from fork-overrides.json.
+func (app *ShutterApp) forkHeightOverride(name ForkName) *ForkHeightOverride {
+	if override, ok := app.config.ForkHeightOverrides[app.ChainID][name]; ok {
+		return override
+	}
+	return defaultForkHeightOverrides[app.ChainID][name]
 }
 
 func int64Ptr(value int64) *int64 {
@@ -31,36 +170,42 @@ func uint64Ptr(value uint64) *uint64 {
 // NewForkHeightsAllEnabled creates a ForkHeights struct, activating all forks
 // at genesis.
 func NewForkHeightsAllEnabled() *ForkHeights {
-	zero := int64(0)
-	return &ForkHeights{
-		CheckInUpdateNew: ForkHeight{
-			Enabled: true,
-			Height:  zero,
-		},
+	forkHeights := &ForkHeights{}
+	for _, spec := range Forks {
+		*spec.forkHeight(forkHeights) = ForkHeight{Enabled: true, Height: 0}
 	}
+	return forkHeights
 }
 
 // NewForkHeightsAllDisabled creates a ForkHeights struct in which all forks
 // are set to disabled.
 func NewForkHeightsAllDisabled() *ForkHeights {
-	return &ForkHeights{
-		CheckInUpdateNew: ForkHeight{
-			Enabled: false,
-			Height:  0,
-		},
+	forkHeights := &ForkHeights{}
+	for _, spec := range Forks {
+		*spec.forkHeight(forkHeights) = ForkHeight{Enabled: false, Height: 0}
 	}
+	return forkHeights
 }
 
-func (app *ShutterApp) IsCheckInUpdateForkActive() bool {
-	var override *ForkHeightOverride
-	if overrides, ok := forkHeightOverrides[app.ChainID]; ok {
-		override = overrides.CheckInUpdate
+// IsForkActive checks if the named fork is active in the block currently being processed.
+func (app *ShutterApp) IsForkActive(name ForkName) bool {
+	return app.isForkActiveAt(name, app.CurrentBlockHeight())
+}
+
+func (app *ShutterApp) isForkActiveAt(name ForkName, height int64) bool {
+	if app.ForkHeights == nil {
+		return false
 	}
+	override := app.forkHeightOverride(name)
+	return app.ForkHeights.Get(name).IsForkActive(override, height, app.EONCounter)
+}
+
+func (app *ShutterApp) IsCheckInUpdateForkActive() bool {
 	if app.ForkHeights == nil {
 		log.Warn().Msg("ForkHeights is nil, assuming all forks disabled")
 		return false
 	}
-	return app.ForkHeights.CheckInUpdateNew.IsForkActive(override, app.CurrentBlockHeight(), app.EONCounter)
+	return app.IsForkActive(ForkCheckInUpdate)
 }
 
 // IsForkActive checks whether a fork is active.
@@ -68,12 +213,14 @@ func (app *ShutterApp) IsCheckInUpdateForkActive() bool {
 // A fork is active in either of the following cases:
 //   - an override is set and the override condition is met
 //   - no override is set, the fork height enabled flag is set, and the current
-//     block height is greater than or equal to the fork height
+//     block height is greater than or equal to the fork height, or, if an
+//     activation eon is set, the current eon is greater than or equal to it
 //
 // Otherwise, the fork is not active, i.e., in any of the following cases:
 //   - an override is set but the override condition is not met
 //   - no override is set, the fork height flag is enabled, but the current
-//     block height is less than the fork height
+//     block height is less than the fork height (or the current eon is less
+//     than the activation eon)
 //   - no override is set and the fork height enabled flag is not set
 //
 // An override condition is met if
@@ -98,5 +245,8 @@ func (fh ForkHeight) IsForkActive(override *ForkHeightOverride, currentBlockHeig
 	if !fh.Enabled {
 		return false
 	}
+	if fh.Eon != nil {
+		return currentEon >= *fh.Eon
+	}
 	return currentBlockHeight >= fh.Height
 }
@@ -1,6 +1,11 @@
 package app
 
-import "testing"
+import (
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
 
 func TestIsForkActive(t *testing.T) {
 	testcases := []struct {
@@ -79,6 +84,26 @@ func TestIsForkActive(t *testing.T) {
 			currentBlockHeight: 100,
 			want:               false,
 		},
+		{
+			name:               "activation eon met",
+			forkHeight:         ForkHeight{Enabled: true, Height: 100, Eon: uint64Ptr(3)},
+			currentBlockHeight: 5,
+			currentEon:         3,
+			want:               true,
+		},
+		{
+			name:               "activation eon not met",
+			forkHeight:         ForkHeight{Enabled: true, Height: 0, Eon: uint64Ptr(3)},
+			currentBlockHeight: 100,
+			currentEon:         2,
+			want:               false,
+		},
+		{
+			name:       "disabled fork with activation eon stays inactive",
+			forkHeight: ForkHeight{Enabled: false, Eon: uint64Ptr(0)},
+			currentEon: 5,
+			want:       false,
+		},
 		{
 			name:               "nil override uses fork height",
 			forkHeight:         ForkHeight{Enabled: false, Height: 0},
@@ -97,3 +122,101 @@ func TestIsForkActive(t *testing.T) {
 		})
 	}
 }
+
+func TestForkRegistry(t *testing.T) {
+	forkHeights := NewForkHeightsAllDisabled()
+	for _, spec := range Forks {
+		assert.Equal(t, forkHeights.Get(spec.Name).Enabled, false)
+
+		forkHeight := ForkHeight{Enabled: true, Height: 5, Eon: uint64Ptr(2)}
+		assert.NilError(t, forkHeights.Set(spec.Name, forkHeight))
+		assert.DeepEqual(t, forkHeights.Get(spec.Name), forkHeight)
+	}
+	assert.DeepEqual(t, forkHeights.AppHash, ForkHeight{Enabled: true, Height: 5, Eon: uint64Ptr(2)})
+
+	assert.ErrorContains(t, forkHeights.Set("unknown", ForkHeight{}), "unknown fork")
+	_, err := LookupFork("unknown")
+	assert.ErrorContains(t, err, "unknown fork")
+}
+
+func TestForkHeightOverridesFile(t *testing.T) {
+	overrides, err := ReadForkHeightOverrides(ForkHeightOverridesFileName)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, overrides["shutter-gnosis-1000"], ForkHeightOverrides{
+		ForkCheckInUpdate: &ForkHeightOverride{Eon: uint64Ptr(9)},
+	})
+
+	_, err = ReadForkHeightOverrides(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read")
+}
+
+func TestParseForkHeightOverrides(t *testing.T) {
+	overrides, err := ParseForkHeightOverrides([]byte(`{"chain": {"appHash": {"height": 5}}}`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, overrides, map[string]ForkHeightOverrides{
+		"chain": {ForkAppHash: &ForkHeightOverride{Height: int64Ptr(5)}},
+	})
+
+	_, err = ParseForkHeightOverrides([]byte(`{"chain": {"unknown": {"height": 5}}}`))
+	assert.ErrorContains(t, err, "unknown fork")
+	_, err = ParseForkHeightOverrides([]byte(`{"chain": {"appHash": {}}}`))
+	assert.ErrorContains(t, err, "neither height nor eon")
+	_, err = ParseForkHeightOverrides([]byte(`[]`))
+	assert.ErrorContains(t, err, "failed to parse")
+}
+
+func TestForkHeightOverridesConfig(t *testing.T) {
+	overrides, err := ParseForkHeightOverrides([]byte(`{
+		"my-chain": {"statePruning": {"eon": 4}, "appHash": {"height": 10}}
+	}`))
+	assert.NilError(t, err)
+
+	app := NewShutterApp(Config{ForkHeightOverrides: overrides})
+	app.ChainID = "my-chain"
+	app.ForkHeights = NewForkHeightsAllEnabled()
+	assert.DeepEqual(t, app.forkHeightOverride(ForkAppHash), &ForkHeightOverride{Height: int64Ptr(10)})
+	assert.Assert(t, app.forkHeightOverride(ForkCheckInUpdate) == nil)
+	assert.Assert(t, !app.IsForkActive(ForkStatePruning))
+	app.EONCounter = 4
+	assert.Assert(t, app.IsForkActive(ForkStatePruning))
+	assert.Assert(t, !app.IsForkActive(ForkAppHash))
+	assert.Assert(t, app.IsForkActive(ForkCheckInUpdate))
+
+	// overrides don't affect other app instances
+	other := NewShutterApp(Config{})
+	other.ChainID = "my-chain"
+	other.ForkHeights = NewForkHeightsAllEnabled()
+	assert.Assert(t, other.forkHeightOverride(ForkAppHash) == nil)
+	assert.Assert(t, other.IsForkActive(ForkAppHash))
+}
+
+func TestDefaultForkHeightOverrides(t *testing.T) {
+	activationEons := map[string]uint64{
+		"shutter-gnosis-1000":         9,
+		"shutter-chiado-102000":       13,
+		"shutter-api-gnosis-1001":     13,
+		"shutter-service-chiado-1000": 9,
+		"shutter-api-gnosis-1002":     0,
+	}
+	for chainID, eon := range activationEons {
+		app := NewShutterApp(Config{})
+		app.ChainID = chainID
+		app.ForkHeights = NewForkHeightsAllDisabled()
+		app.EONCounter = eon
+		assert.Assert(t, app.IsCheckInUpdateForkActive(), chainID)
+		if eon > 0 {
+			app.EONCounter = eon - 1
+			assert.Assert(t, !app.IsCheckInUpdateForkActive(), chainID)
+		}
+	}
+
+	// overrides from the config take precedence
+	overrides, err := ParseForkHeightOverrides([]byte(`{"shutter-gnosis-1000": {"checkInUpdate": {"eon": 20}}}`))
+	assert.NilError(t, err)
+	app := NewShutterApp(Config{ForkHeightOverrides: overrides})
+	app.ChainID = "shutter-gnosis-1000"
+	app.ForkHeights = NewForkHeightsAllDisabled()
+	app.EONCounter = 9
+	assert.Assert(t, !app.IsCheckInUpdateForkActive())
+	assert.Assert(t, !app.IsForkActive(ForkAppHash))
+}
@@ -0,0 +1,59 @@
+package app
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// The metrics are registered with the default registry, which is served by tendermint if
+// instrumentation.prometheus is enabled in its config.
+
+var metricsPersistedStateSize = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "shuttermint",
+		Name:      "persisted_state_size_bytes",
+		Help:      "Size of the app state file written by the last call to PersistToDisk",
+	},
+)
+
+var metricsDKGInstances = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "shuttermint",
+		Name:      "dkg_instances",
+		Help:      "Number of DKG instances kept in the app state",
+	},
+)
+
+var metricsNonces = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "shuttermint",
+		Name:      "nonces",
+		Help:      "Number of message nonces kept in the app state",
+	},
+)
+
+var metricsPrunedDKGInstances = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "shuttermint",
+		Name:      "pruned_dkg_instances_total",
+		Help:      "Number of finalized DKG instances removed from the app state",
+	},
+)
+
+var metricsPrunedNonces = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "shuttermint",
+		Name:      "pruned_nonces_total",
+		Help:      "Number of expired nonces removed from the app state",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(metricsPersistedStateSize)
+	prometheus.MustRegister(metricsDKGInstances)
+	prometheus.MustRegister(metricsNonces)
+	prometheus.MustRegister(metricsPrunedDKGInstances)
+	prometheus.MustRegister(metricsPrunedNonces)
+}
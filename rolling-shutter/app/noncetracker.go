@@ -1,29 +1,78 @@
 package app
 
-import "github.com/ethereum/go-ethereum/common"
+import (
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
+)
 
 // NewNonceTracker creates a new NonceTracker.
 func NewNonceTracker() *NonceTracker {
 	return &NonceTracker{
-		RandomNonces: make(map[common.Address]map[uint64]bool),
+		RandomNonces:   make(map[common.Address]map[uint64]bool),
+		ExpiringNonces: make(map[common.Address]map[uint64]bool),
 	}
 }
 
 // Check returns true if the given nonce is free and false if it has been added already.
 func (t *NonceTracker) Check(sender common.Address, randomNonce uint64) bool {
-	m, ok := t.RandomNonces[sender]
-	if !ok {
-		return true
-	}
-	return !m[randomNonce]
+	return !t.RandomNonces[sender][randomNonce] && !t.ExpiringNonces[sender][randomNonce]
 }
 
 // Add adds the given nonce if it hasn't already.
 func (t *NonceTracker) Add(sender common.Address, randomNonce uint64) {
-	m, ok := t.RandomNonces[sender]
+	addNonce(t.RandomNonces, sender, randomNonce)
+}
+
+// AddExpiring adds the given nonce so that it can be removed by Prune later on.
+func (t *NonceTracker) AddExpiring(sender common.Address, nonce uint64) {
+	if t.ExpiringNonces == nil {
+		// app state persisted before ExpiringNonces was introduced
+		t.ExpiringNonces = make(map[common.Address]map[uint64]bool)
+	}
+	addNonce(t.ExpiringNonces, sender, nonce)
+}
+
+// Prune removes all nonces with a block height lower than minHeight and returns the number of
+// removed nonces. This includes random nonces that happen to encode such a height: they can't be
+// used again either, as messages with a nonce height that old are rejected.
+func (t *NonceTracker) Prune(minHeight int64) int {
+	return pruneNonces(t.RandomNonces, minHeight) + pruneNonces(t.ExpiringNonces, minHeight)
+}
+
+// Len returns the number of tracked nonces.
+func (t *NonceTracker) Len() int {
+	n := 0
+	for _, nonces := range t.RandomNonces {
+		n += len(nonces)
+	}
+	for _, nonces := range t.ExpiringNonces {
+		n += len(nonces)
+	}
+	return n
+}
+
+func addNonce(m map[common.Address]map[uint64]bool, sender common.Address, nonce uint64) {
+	nonces, ok := m[sender]
 	if !ok {
-		m = make(map[uint64]bool)
-		t.RandomNonces[sender] = m
+		nonces = make(map[uint64]bool)
+		m[sender] = nonces
+	}
+	nonces[nonce] = true
+}
+
+func pruneNonces(m map[common.Address]map[uint64]bool, minHeight int64) int {
+	numPruned := 0
+	for sender, nonces := range m {
+		for nonce := range nonces {
+			if shmsg.NonceHeight(nonce) < minHeight {
+				delete(nonces, nonce)
+				numPruned++
+			}
+		}
+		if len(nonces) == 0 {
+			delete(m, sender)
+		}
 	}
-	m[randomNonce] = true
+	return numPruned
 }
@@ -6,6 +6,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
 )
 
 func TestNonceTracker(t *testing.T) {
@@ -21,3 +23,32 @@ func TestNonceTracker(t *testing.T) {
 	assert.Assert(t, tracker.Check(a1, r2))
 	assert.Assert(t, tracker.Check(a2, r1))
 }
+
+func TestNonceTrackerPrune(t *testing.T) {
+	tracker := NewNonceTracker()
+	a1 := common.BigToAddress(big.NewInt(0))
+	a2 := common.BigToAddress(big.NewInt(1))
+	legacy := uint64(1) << 63
+	legacyOld := uint64(5)<<32 | 7
+	old := shmsg.NewNonce(10, 1)
+	recent := shmsg.NewNonce(20, 1)
+
+	tracker.Add(a1, legacy)
+	tracker.Add(a2, legacyOld)
+	tracker.AddExpiring(a1, old)
+	tracker.AddExpiring(a2, old)
+	tracker.AddExpiring(a2, recent)
+	assert.Equal(t, tracker.Len(), 5)
+	assert.Assert(t, !tracker.Check(a1, old))
+
+	assert.Equal(t, tracker.Prune(20), 3)
+	assert.Equal(t, tracker.Len(), 2)
+	assert.Assert(t, !tracker.Check(a1, legacy))
+	assert.Assert(t, tracker.Check(a2, legacyOld))
+	assert.Assert(t, tracker.Check(a1, old))
+	assert.Assert(t, !tracker.Check(a2, recent))
+	_, ok := tracker.ExpiringNonces[a1]
+	assert.Assert(t, !ok)
+	_, ok = tracker.RandomNonces[a2]
+	assert.Assert(t, !ok)
+}
@@ -0,0 +1,459 @@
+package app
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
+)
+
+// The app state is persisted as a header followed by a payload. The header consists of
+// stateMagic and the big endian encoded version of the payload format. Payloads of older versions
+// are converted to the current version by the functions in stateUpgrades, one version at a time,
+// so that state written by any earlier release can be loaded. Version 0 is the gob encoded
+// ShutterApp struct written before the header has been introduced. It is recognized by the
+// missing header.
+//
+// To change the format, bump AppStateVersion and add an upgrade function for the previous
+// version. Fields of shmsg.AppState must never be renamed or reused, only added.
+
+// AppStateVersion is the version of the format written by EncodeAppState.
+const AppStateVersion uint32 = 1
+
+var stateMagic = []byte("shmstate")
+
+const stateHeaderSize = 12
+
+// stateUpgrades maps a format version to the function that converts a payload of that version to
+// a payload of the next version.
+var stateUpgrades = map[uint32]func(payload []byte) ([]byte, error){
+	0: upgradeStateV0,
+}
+
+// AppStateFileVersion returns the format version of the given persisted app state.
+func AppStateFileVersion(data []byte) uint32 {
+	if len(data) < stateHeaderSize || !bytes.Equal(data[:len(stateMagic)], stateMagic) {
+		return 0
+	}
+	return binary.BigEndian.Uint32(data[len(stateMagic):stateHeaderSize])
+}
+
+// EncodeAppState encodes the state of the app in the current format version.
+func EncodeAppState(app *ShutterApp) ([]byte, error) {
+	payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(app.toProto())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode app state")
+	}
+	data := make([]byte, stateHeaderSize, stateHeaderSize+len(payload))
+	copy(data, stateMagic)
+	binary.BigEndian.PutUint32(data[len(stateMagic):], AppStateVersion)
+	return append(data, payload...), nil
+}
+
+// DecodeAppState decodes app state persisted in the current or any earlier format version.
+func DecodeAppState(data []byte) (*ShutterApp, error) {
+	version := AppStateFileVersion(data)
+	if version > AppStateVersion {
+		return nil, errors.Errorf(
+			"app state format version %d is newer than the supported version %d", version, AppStateVersion,
+		)
+	}
+	payload := data
+	if version > 0 {
+		payload = data[stateHeaderSize:]
+	}
+	for ; version < AppStateVersion; version++ {
+		var err error
+		payload, err = stateUpgrades[version](payload)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to upgrade app state from format version %d", version)
+		}
+	}
+
+	state := &shmsg.AppState{}
+	if err := proto.Unmarshal(payload, state); err != nil {
+		return nil, errors.Wrap(err, "failed to decode app state")
+	}
+	app, err := appFromProto(state)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid app state")
+	}
+	app.updateCheckTxMembers()
+	return app, nil
+}
+
+// MigrateStateFile reads the app state from src and writes it in the current format version to
+// dst. It returns the format version of src.
+func MigrateStateFile(src, dst string) (uint32, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return 0, err
+	}
+	version := AppStateFileVersion(data)
+	app, err := DecodeAppState(data)
+	if err != nil {
+		return version, err
+	}
+	app.StatePath = dst
+	return version, app.PersistToDisk()
+}
+
+func encodeAddresses(addresses []common.Address) [][]byte {
+	res := make([][]byte, len(addresses))
+	for i, a := range addresses {
+		res[i] = a.Bytes()
+	}
+	return res
+}
+
+func decodeAddress(b []byte) (common.Address, error) {
+	if len(b) != common.AddressLength {
+		return common.Address{}, errors.Errorf("invalid address length %d", len(b))
+	}
+	return common.BytesToAddress(b), nil
+}
+
+func decodeAddresses(bs [][]byte) ([]common.Address, error) {
+	res := make([]common.Address, len(bs))
+	for i, b := range bs {
+		a, err := decodeAddress(b)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = a
+	}
+	return res, nil
+}
+
+func decodeAddressSet(bs [][]byte) (map[common.Address]struct{}, error) {
+	addresses, err := decodeAddresses(bs)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[common.Address]struct{})
+	for _, a := range addresses {
+		res[a] = struct{}{}
+	}
+	return res, nil
+}
+
+func encodeVotes(votes map[common.Address]int) []*shmsg.AppStateVote {
+	res := []*shmsg.AppStateVote{}
+	for _, sender := range sortedAddresses(votes) {
+		res = append(res, &shmsg.AppStateVote{
+			Sender:    sender.Bytes(),
+			Candidate: uint64(votes[sender]), //nolint:gosec // G115
+		})
+	}
+	return res
+}
+
+func decodeVotes(votes []*shmsg.AppStateVote, numCandidates int) (map[common.Address]int, error) {
+	res := make(map[common.Address]int)
+	for _, v := range votes {
+		sender, err := decodeAddress(v.Sender)
+		if err != nil {
+			return nil, err
+		}
+		if v.Candidate >= uint64(numCandidates) { //nolint:gosec // G115
+			return nil, errors.Errorf("vote for unknown candidate %d", v.Candidate)
+		}
+		res[sender] = int(v.Candidate) //nolint:gosec // G115
+	}
+	return res, nil
+}
+
+func encodeNonces(nonces map[common.Address]map[uint64]bool) []*shmsg.AppStateNonces {
+	res := []*shmsg.AppStateNonces{}
+	for _, sender := range sortedAddresses(nonces) {
+		used := []uint64{}
+		for nonce, ok := range nonces[sender] {
+			if ok {
+				used = append(used, nonce)
+			}
+		}
+		slices.Sort(used)
+		res = append(res, &shmsg.AppStateNonces{Sender: sender.Bytes(), Nonces: used})
+	}
+	return res
+}
+
+func decodeNonces(nonces []*shmsg.AppStateNonces) (map[common.Address]map[uint64]bool, error) {
+	res := make(map[common.Address]map[uint64]bool)
+	for _, n := range nonces {
+		sender, err := decodeAddress(n.Sender)
+		if err != nil {
+			return nil, err
+		}
+		res[sender] = make(map[uint64]bool)
+		for _, nonce := range n.Nonces {
+			res[sender][nonce] = true
+		}
+	}
+	return res, nil
+}
+
+func encodeBatchConfig(cfg *BatchConfig) *shmsg.AppStateBatchConfig {
+	return &shmsg.AppStateBatchConfig{
+		Height:                cfg.Height,
+		Keypers:               encodeAddresses(cfg.Keypers),
+		ActivationBlockNumber: cfg.ActivationBlockNumber,
+		Threshold:             cfg.Threshold,
+		KeyperConfigIndex:     cfg.KeyperConfigIndex,
+		Started:               cfg.Started,
+		ValidatorsUpdated:     cfg.ValidatorsUpdated,
+	}
+}
+
+func decodeBatchConfig(cfg *shmsg.AppStateBatchConfig) (*BatchConfig, error) {
+	if cfg == nil {
+		return nil, errors.New("missing batch config")
+	}
+	keypers, err := decodeAddresses(cfg.Keypers)
+	if err != nil {
+		return nil, err
+	}
+	return &BatchConfig{
+		Height:                cfg.Height,
+		Keypers:               keypers,
+		ActivationBlockNumber: cfg.ActivationBlockNumber,
+		Threshold:             cfg.Threshold,
+		KeyperConfigIndex:     cfg.KeyperConfigIndex,
+		Started:               cfg.Started,
+		ValidatorsUpdated:     cfg.ValidatorsUpdated,
+	}, nil
+}
+
+func encodeDKGInstance(dkg *DKGInstance) *shmsg.AppStateDKGInstance {
+	res := &shmsg.AppStateDKGInstance{
+		Eon:    dkg.Eon,
+		Config: encodeBatchConfig(&dkg.Config),
+		SuccessVoting: &shmsg.AppStateSuccessVoting{
+			Candidates: slices.Clone(dkg.SuccessVoting.Candidates),
+			Votes:      encodeVotes(dkg.SuccessVoting.Votes),
+		},
+		PolyEvalsSeen:       []*shmsg.AppStateSenderReceiver{},
+		PolyCommitmentsSeen: encodeAddresses(sortedAddresses(dkg.PolyCommitmentsSeen)),
+		AccusationsSeen:     encodeAddresses(sortedAddresses(dkg.AccusationsSeen)),
+		ApologiesSeen:       encodeAddresses(sortedAddresses(dkg.ApologiesSeen)),
+	}
+	pairs := slices.SortedFunc(maps.Keys(dkg.PolyEvalsSeen), func(a, b SenderReceiverPair) int {
+		return cmp.Or(a.Sender.Cmp(b.Sender), a.Receiver.Cmp(b.Receiver))
+	})
+	for _, pair := range pairs {
+		res.PolyEvalsSeen = append(res.PolyEvalsSeen, &shmsg.AppStateSenderReceiver{
+			Sender:   pair.Sender.Bytes(),
+			Receiver: pair.Receiver.Bytes(),
+		})
+	}
+	return res
+}
+
+func decodeDKGInstance(dkg *shmsg.AppStateDKGInstance) (*DKGInstance, error) {
+	config, err := decodeBatchConfig(dkg.Config)
+	if err != nil {
+		return nil, err
+	}
+	res := &DKGInstance{
+		Config:        *config,
+		Eon:           dkg.Eon,
+		SuccessVoting: NewVoting[bool, ComparableEquals[bool]](),
+		PolyEvalsSeen: make(map[SenderReceiverPair]struct{}),
+	}
+	if dkg.SuccessVoting != nil {
+		res.SuccessVoting.Candidates = slices.Clone(dkg.SuccessVoting.Candidates)
+		res.SuccessVoting.Votes, err = decodeVotes(dkg.SuccessVoting.Votes, len(dkg.SuccessVoting.Candidates))
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, pair := range dkg.PolyEvalsSeen {
+		sender, err := decodeAddress(pair.Sender)
+		if err != nil {
+			return nil, err
+		}
+		receiver, err := decodeAddress(pair.Receiver)
+		if err != nil {
+			return nil, err
+		}
+		res.PolyEvalsSeen[SenderReceiverPair{Sender: sender, Receiver: receiver}] = struct{}{}
+	}
+	if res.PolyCommitmentsSeen, err = decodeAddressSet(dkg.PolyCommitmentsSeen); err != nil {
+		return nil, err
+	}
+	if res.AccusationsSeen, err = decodeAddressSet(dkg.AccusationsSeen); err != nil {
+		return nil, err
+	}
+	if res.ApologiesSeen, err = decodeAddressSet(dkg.ApologiesSeen); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func encodeForks(fh *ForkHeights) []*shmsg.AppStateFork {
+	res := []*shmsg.AppStateFork{}
+	if fh == nil {
+		return res
+	}
+	for _, spec := range Forks {
+		forkHeight := fh.Get(spec.Name)
+		res = append(res, &shmsg.AppStateFork{
+			Name:    string(spec.Name),
+			Enabled: forkHeight.Enabled,
+			Height:  forkHeight.Height,
+			Eon:     forkHeight.Eon,
+		})
+	}
+	return res
+}
+
+// decodeForks decodes the fork activations. Forks missing from the persisted state have been
+// added after the state was written and are disabled.
+func decodeForks(forks []*shmsg.AppStateFork) (*ForkHeights, error) {
+	res := NewForkHeightsAllDisabled()
+	for _, fork := range forks {
+		forkHeight := ForkHeight{Enabled: fork.Enabled, Height: fork.Height}
+		if fork.Eon != nil {
+			forkHeight.Eon = uint64Ptr(*fork.Eon)
+		}
+		if err := res.Set(ForkName(fork.Name), forkHeight); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (app *ShutterApp) toProto() *shmsg.AppState {
+	state := &shmsg.AppState{
+		ChainId:         app.ChainID,
+		LastBlockHeight: app.LastBlockHeight,
+		EonCounter:      app.EONCounter,
+		DevMode:         app.DevMode,
+		Configs:         []*shmsg.AppStateBatchConfig{},
+		ConfigVoting: &shmsg.AppStateConfigVoting{
+			Candidates: []*shmsg.AppStateBatchConfig{},
+			Votes:      encodeVotes(app.ConfigVoting.Votes),
+		},
+		DkgInstances: []*shmsg.AppStateDKGInstance{},
+		Identities:   []*shmsg.AppStateIdentity{},
+		BlocksSeen:   []*shmsg.AppStateBlockSeen{},
+		Validators:   []*shmsg.AppStateValidator{},
+		Forks:        encodeForks(app.ForkHeights),
+	}
+	for _, cfg := range app.Configs {
+		state.Configs = append(state.Configs, encodeBatchConfig(cfg))
+	}
+	for i := range app.ConfigVoting.Candidates {
+		state.ConfigVoting.Candidates = append(state.ConfigVoting.Candidates, encodeBatchConfig(&app.ConfigVoting.Candidates[i]))
+	}
+	for _, eon := range slices.Sorted(maps.Keys(app.DKGMap)) {
+		state.DkgInstances = append(state.DkgInstances, encodeDKGInstance(app.DKGMap[eon]))
+	}
+	for _, keyper := range sortedAddresses(app.Identities) {
+		state.Identities = append(state.Identities, &shmsg.AppStateIdentity{
+			Keyper:             keyper.Bytes(),
+			ValidatorPublicKey: []byte(app.Identities[keyper].Ed25519pubkey),
+		})
+	}
+	for _, keyper := range sortedAddresses(app.BlocksSeen) {
+		state.BlocksSeen = append(state.BlocksSeen, &shmsg.AppStateBlockSeen{
+			Keyper:      keyper.Bytes(),
+			BlockNumber: app.BlocksSeen[keyper],
+		})
+	}
+	validators := slices.SortedFunc(maps.Keys(app.Validators), func(a, b ValidatorPubkey) int {
+		return cmp.Compare(a.Ed25519pubkey, b.Ed25519pubkey)
+	})
+	for _, pubkey := range validators {
+		state.Validators = append(state.Validators, &shmsg.AppStateValidator{
+			PublicKey: []byte(pubkey.Ed25519pubkey),
+			Power:     app.Validators[pubkey],
+		})
+	}
+	if app.NonceTracker != nil {
+		state.RandomNonces = encodeNonces(app.NonceTracker.RandomNonces)
+		state.ExpiringNonces = encodeNonces(app.NonceTracker.ExpiringNonces)
+	}
+	return state
+}
+
+func appFromProto(state *shmsg.AppState) (*ShutterApp, error) {
+	app := NewShutterApp(Config{})
+	app.ChainID = state.ChainId
+	app.LastBlockHeight = state.LastBlockHeight
+	app.EONCounter = state.EonCounter
+	app.DevMode = state.DevMode
+	app.Validators = make(Powermap)
+
+	var err error
+	app.Configs = []*BatchConfig{}
+	for _, cfg := range state.Configs {
+		config, err := decodeBatchConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		app.Configs = append(app.Configs, config)
+	}
+	if state.ConfigVoting != nil {
+		for _, cfg := range state.ConfigVoting.Candidates {
+			config, err := decodeBatchConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			app.ConfigVoting.Candidates = append(app.ConfigVoting.Candidates, *config)
+		}
+		app.ConfigVoting.Votes, err = decodeVotes(state.ConfigVoting.Votes, len(app.ConfigVoting.Candidates))
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, dkg := range state.DkgInstances {
+		instance, err := decodeDKGInstance(dkg)
+		if err != nil {
+			return nil, err
+		}
+		app.DKGMap[instance.Eon] = instance
+	}
+	for _, identity := range state.Identities {
+		keyper, err := decodeAddress(identity.Keyper)
+		if err != nil {
+			return nil, err
+		}
+		app.Identities[keyper], err = NewValidatorPubkey(identity.ValidatorPublicKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, blockSeen := range state.BlocksSeen {
+		keyper, err := decodeAddress(blockSeen.Keyper)
+		if err != nil {
+			return nil, err
+		}
+		app.BlocksSeen[keyper] = blockSeen.BlockNumber
+	}
+	for _, validator := range state.Validators {
+		pubkey, err := NewValidatorPubkey(validator.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		app.Validators[pubkey] = validator.Power
+	}
+	if app.NonceTracker.RandomNonces, err = decodeNonces(state.RandomNonces); err != nil {
+		return nil, err
+	}
+	if app.NonceTracker.ExpiringNonces, err = decodeNonces(state.ExpiringNonces); err != nil {
+		return nil, err
+	}
+	if app.ForkHeights, err = decodeForks(state.Forks); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
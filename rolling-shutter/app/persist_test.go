@@ -0,0 +1,129 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
+)
+
+// The files in testdata have been written by earlier releases from the same sequence of blocks.
+// appstate-v0-baseline.gob predates the fork registry and stores the check-in update fork in the
+// legacy field, appstate-v0.gob has all forks enabled and appstate-v1.state is the result of
+// migrating appstate-v0.gob.
+const fixtureStateHash = "80428348e83e57b589b934bce614cffb5755f83c236260829afae105207cefc9"
+
+func loadFixture(t *testing.T, name string) (*ShutterApp, []byte) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	assert.NilError(t, err)
+	app, err := DecodeAppState(data)
+	assert.NilError(t, err)
+	return app, data
+}
+
+func assertFixtureState(t *testing.T, app *ShutterApp) {
+	t.Helper()
+	assert.Equal(t, app.ChainID, "shutter-fixture-chain")
+	assert.Equal(t, app.LastBlockHeight, int64(4))
+	assert.Equal(t, app.EONCounter, uint64(1))
+	assert.Equal(t, len(app.Configs), 2)
+	assert.Equal(t, len(app.Identities), 3)
+	assert.Equal(t, len(app.BlocksSeen), 2)
+	assert.Equal(t, len(app.Validators), 3)
+	assert.Equal(t, len(app.DKGMap), 1)
+	dkg := app.DKGMap[1]
+	assert.Assert(t, dkg != nil)
+	assert.Equal(t, len(dkg.PolyEvalsSeen), 1)
+	assert.Equal(t, len(dkg.AccusationsSeen), 1)
+	assert.DeepEqual(t, dkg.SuccessVoting.Candidates, []bool{true})
+	assert.Assert(t, len(app.CheckTxState.Members) > 0)
+}
+
+func TestDecodeAppStateV0(t *testing.T) {
+	app, data := loadFixture(t, "appstate-v0.gob")
+	assert.Equal(t, AppStateFileVersion(data), uint32(0))
+	assertFixtureState(t, app)
+	for _, fork := range Forks {
+		assert.DeepEqual(t, app.ForkHeights.Get(fork.Name), ForkHeight{Enabled: true})
+	}
+	assert.Equal(t, hex.EncodeToString(app.computeStateHash()), fixtureStateHash)
+}
+
+func TestDecodeAppStateV0Baseline(t *testing.T) {
+	app, _ := loadFixture(t, "appstate-v0-baseline.gob")
+	assertFixtureState(t, app)
+	assert.DeepEqual(t, app.ForkHeights.Get(ForkCheckInUpdate), ForkHeight{Enabled: true, Height: 5})
+	assert.DeepEqual(t, app.ForkHeights.Get(ForkAppHash), ForkHeight{})
+	assert.DeepEqual(t, app.ForkHeights.Get(ForkStatePruning), ForkHeight{})
+}
+
+func TestDecodeAppStateV1(t *testing.T) {
+	app, data := loadFixture(t, "appstate-v1.state")
+	assert.Equal(t, AppStateFileVersion(data), AppStateVersion)
+	assertFixtureState(t, app)
+	assert.Equal(t, hex.EncodeToString(app.computeStateHash()), fixtureStateHash)
+
+	// encoding is deterministic and stable across releases
+	encoded, err := EncodeAppState(app)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, encoded, data)
+}
+
+func TestEncodeAppStateRoundTrip(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	runBlock(t, app, [][]byte{keypers[0].checkInTx(t), keypers[1].checkInTx(t)})
+	runBlock(t, app, [][]byte{keypers[0].tx(t, shmsg.NewBlockSeen(7))})
+
+	encoded, err := EncodeAppState(app)
+	assert.NilError(t, err)
+	decoded, err := DecodeAppState(encoded)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, decoded.computeStateHash(), app.computeStateHash())
+	assert.DeepEqual(t, decoded.NonceTracker, app.NonceTracker)
+	assert.DeepEqual(t, decoded.CheckTxState.Members, app.CheckTxState.Members)
+
+	reencoded, err := EncodeAppState(decoded)
+	assert.NilError(t, err)
+	assert.Assert(t, bytes.Equal(reencoded, encoded))
+}
+
+func TestDecodeAppStateRejectsNewerVersion(t *testing.T) {
+	app, _ := loadFixture(t, "appstate-v1.state")
+	encoded, err := EncodeAppState(app)
+	assert.NilError(t, err)
+	binary.BigEndian.PutUint32(encoded[len(stateMagic):], AppStateVersion+1)
+	_, err = DecodeAppState(encoded)
+	assert.ErrorContains(t, err, "newer than the supported version")
+}
+
+func TestDecodeAppStateRejectsInvalidData(t *testing.T) {
+	_, err := DecodeAppState([]byte("not a gob"))
+	assert.ErrorContains(t, err, "format version 0")
+
+	app, _ := loadFixture(t, "appstate-v1.state")
+	encoded, err := EncodeAppState(app)
+	assert.NilError(t, err)
+	_, err = DecodeAppState(encoded[:len(encoded)-3])
+	assert.Assert(t, err != nil)
+}
+
+func TestMigrateStateFile(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "shutter.state")
+	version, err := MigrateStateFile(filepath.Join("testdata", "appstate-v0.gob"), dst)
+	assert.NilError(t, err)
+	assert.Equal(t, version, uint32(0))
+
+	migrated, err := os.ReadFile(dst)
+	assert.NilError(t, err)
+	expected, err := os.ReadFile(filepath.Join("testdata", "appstate-v1.state"))
+	assert.NilError(t, err)
+	assert.Assert(t, bytes.Equal(migrated, expected))
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
+)
+
+// NonceLifetime is the number of blocks during which a message is accepted after the
+// block height encoded in its nonce (see shmsg.NewNonce). Once the state pruning fork is active,
+// messages with a nonce height outside of [height - NonceLifetime, height + NonceLifetime] are
+// rejected, so nonces older than that can safely be forgotten. This value is part of the
+// consensus rules and must not be changed without a fork.
+const NonceLifetime int64 = 1000
+
+// IsStatePruningForkActive checks if finalized DKG instances and expired nonces are removed
+// from the state in the block currently being processed.
+func (app *ShutterApp) IsStatePruningForkActive() bool {
+	return app.IsForkActive(ForkStatePruning)
+}
+
+// checkNonceHeight checks if the block height encoded in the given nonce is close enough to the
+// current block height. Before the state pruning fork, nonces are not required to encode the
+// block height.
+func (app *ShutterApp) checkNonceHeight(nonce uint64) bool {
+	if !app.IsStatePruningForkActive() {
+		return true
+	}
+	nonceHeight := shmsg.NonceHeight(nonce)
+	height := app.CurrentBlockHeight()
+	return nonceHeight >= height-NonceLifetime && nonceHeight <= height+NonceLifetime
+}
+
+// addNonce marks the nonce as used by the sender. After the state pruning fork, nonces are
+// tracked only until they expire.
+func (app *ShutterApp) addNonce(sender common.Address, nonce uint64) {
+	if app.IsStatePruningForkActive() {
+		app.NonceTracker.AddExpiring(sender, nonce)
+	} else {
+		app.NonceTracker.Add(sender, nonce)
+	}
+}
+
+// isPrunedEon checks if the DKG instance of the given eon has been removed from the state by
+// pruneState.
+func (app *ShutterApp) isPrunedEon(eon uint64) bool {
+	_, ok := app.DKGMap[eon]
+	return !ok && eon <= app.EONCounter && app.IsStatePruningForkActive()
+}
+
+// pruneState removes DKG instances for which the result vote has reached an outcome and nonces
+// that have expired. A DKG instance does not change the state anymore once an outcome has been
+// reached: either the eon has been started successfully or a new DKG instance has been started
+// for the next eon.
+func (app *ShutterApp) pruneState() {
+	if !app.IsStatePruningForkActive() {
+		return
+	}
+
+	numPrunedDKGs := 0
+	for eon, dkg := range app.DKGMap {
+		if _, ok := dkg.SuccessVoting.Outcome(int(dkg.Config.Threshold)); ok {
+			delete(app.DKGMap, eon)
+			app.changes.eon(eon)
+			numPrunedDKGs++
+		}
+	}
+	numPrunedNonces := app.NonceTracker.Prune(app.CurrentBlockHeight() - NonceLifetime)
+
+	if numPrunedDKGs > 0 || numPrunedNonces > 0 {
+		log.Debug().
+			Int64("height", app.CurrentBlockHeight()).
+			Int("dkg-instances", numPrunedDKGs).
+			Int("nonces", numPrunedNonces).
+			Msg("pruned state")
+	}
+	metricsPrunedDKGInstances.Add(float64(numPrunedDKGs))
+	metricsPrunedNonces.Add(float64(numPrunedNonces))
+	metricsDKGInstances.Set(float64(len(app.DKGMap)))
+	metricsNonces.Set(float64(app.NonceTracker.Len()))
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/shutterevents/shtxresp"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
+)
+
+func TestPruneFinalizedDKG(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	addresses := []common.Address{keypers[0].address(), keypers[1].address(), keypers[2].address()}
+
+	runBlock(t, app, [][]byte{
+		keypers[0].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+		keypers[1].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+	})
+	assert.Equal(t, app.EONCounter, uint64(1))
+	assert.Assert(t, app.DKGMap[1] != nil)
+
+	runBlock(t, app, [][]byte{keypers[0].tx(t, shmsg.NewDKGResult(1, true))})
+	assert.Assert(t, app.DKGMap[1] != nil, "dkg pruned before outcome was reached")
+
+	runBlock(t, app, [][]byte{keypers[1].tx(t, shmsg.NewDKGResult(1, true))})
+	assert.Equal(t, len(app.DKGMap), 0)
+
+	// late messages for the pruned eon are treated as already seen
+	late := [][]byte{
+		keypers[2].tx(t, shmsg.NewDKGResult(1, true)),
+		keypers[2].tx(t, shmsg.NewAccusation(1, []common.Address{keypers[0].address()})),
+	}
+	for _, tx := range late {
+		res := app.DeliverTx(abcitypes.RequestDeliverTx{Tx: tx})
+		assert.Equal(t, res.Code, shtxresp.Seen, res.Log)
+	}
+
+	// messages for unknown eons are still errors
+	res := app.DeliverTx(abcitypes.RequestDeliverTx{Tx: keypers[2].tx(t, shmsg.NewDKGResult(2, true))})
+	assert.Equal(t, res.Code, shtxresp.Error)
+}
+
+func TestPruneFailedDKG(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	addresses := []common.Address{keypers[0].address(), keypers[1].address(), keypers[2].address()}
+
+	runBlock(t, app, [][]byte{
+		keypers[0].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+		keypers[1].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+	})
+	runBlock(t, app, [][]byte{
+		keypers[0].tx(t, shmsg.NewDKGResult(1, false)),
+		keypers[1].tx(t, shmsg.NewDKGResult(1, false)),
+	})
+	assert.Equal(t, app.EONCounter, uint64(2))
+	assert.Assert(t, app.DKGMap[1] == nil)
+	assert.Assert(t, app.DKGMap[2] != nil)
+}
+
+func TestNoPruningBeforeFork(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app := newTestChainApp(t, keypers, NewForkHeightsAllDisabled())
+	addresses := []common.Address{keypers[0].address(), keypers[1].address(), keypers[2].address()}
+
+	runBlock(t, app, [][]byte{
+		keypers[0].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+		keypers[1].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+	})
+	runBlock(t, app, [][]byte{
+		keypers[0].tx(t, shmsg.NewDKGResult(1, true)),
+		keypers[1].tx(t, shmsg.NewDKGResult(1, true)),
+	})
+	assert.Assert(t, app.DKGMap[1] != nil)
+
+	// nonces are not required to encode the block height
+	runBlock(t, app, [][]byte{keypers[0].txWithNonce(t, shmsg.NewBlockSeen(1), shmsg.NewNonce(1e6, 1))})
+	assert.Equal(t, len(app.NonceTracker.ExpiringNonces), 0)
+}
+
+func TestNonceExpiry(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	app.LastBlockHeight = 2 * NonceLifetime
+	height := app.CurrentBlockHeight()
+
+	tooOld := keypers[0].txWithNonce(t, shmsg.NewBlockSeen(1), shmsg.NewNonce(height-NonceLifetime-1, 1))
+	tooNew := keypers[0].txWithNonce(t, shmsg.NewBlockSeen(1), shmsg.NewNonce(height+NonceLifetime+1, 1))
+	for _, tx := range [][]byte{tooOld, tooNew} {
+		assert.Equal(t, app.CheckTx(abcitypes.RequestCheckTx{Tx: tx}).Code, uint32(1))
+		assert.Equal(t, app.DeliverTx(abcitypes.RequestDeliverTx{Tx: tx}).Code, shtxresp.Error)
+	}
+
+	tx := keypers[0].txWithNonce(t, shmsg.NewBlockSeen(1), shmsg.NewNonce(height-NonceLifetime, 1))
+	runBlock(t, app, [][]byte{tx})
+	assert.Equal(t, app.NonceTracker.Len(), 1)
+	assert.Equal(t, app.DeliverTx(abcitypes.RequestDeliverTx{Tx: tx}).Code, shtxresp.Error)
+
+	// in the next block, the nonce is expired and therefore pruned
+	runBlock(t, app, nil)
+	assert.Equal(t, app.NonceTracker.Len(), 0)
+	assert.Equal(t, app.CheckTx(abcitypes.RequestCheckTx{Tx: tx}).Code, uint32(1))
+}
+
+func TestPruneRandomNonces(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	forkHeights := NewForkHeightsAllEnabled()
+	forkHeights.StatePruning = ForkHeight{Enabled: true, Height: 2}
+	app := newTestChainApp(t, keypers, forkHeights)
+
+	// before the fork, nonces are tracked forever
+	tx := keypers[0].checkInTx(t)
+	runBlock(t, app, [][]byte{tx})
+	assert.Equal(t, len(app.NonceTracker.RandomNonces), 1)
+
+	// after the fork, random nonces are pruned once the height they encode has expired
+	runBlock(t, app, nil)
+	assert.Equal(t, len(app.NonceTracker.RandomNonces), 1)
+	app.LastBlockHeight = 2 * NonceLifetime
+	runBlock(t, app, nil)
+	assert.Equal(t, len(app.NonceTracker.RandomNonces), 0)
+	assert.Equal(t, app.DeliverTx(abcitypes.RequestDeliverTx{Tx: tx}).Code, shtxresp.Error)
+}
@@ -0,0 +1,355 @@
+package app
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// Query paths supported by ShutterApp.Query. Responses are JSON encoded.
+const (
+	QueryPathConfigs    = "/configs"
+	QueryPathDKG        = "/dkg"
+	QueryPathIdentities = "/identities"
+	QueryPathValidators = "/validators"
+	QueryPathBlocksSeen = "/blocks-seen"
+	QueryPathForks      = "/forks"
+)
+
+// QueryPaths lists the query paths supported by ShutterApp.Query. Paths followed by a parameter
+// in braces expect an additional path element.
+var QueryPaths = []string{
+	QueryPathConfigs,
+	QueryPathConfigs + "/{index}",
+	QueryPathDKG + "/{eon}",
+	QueryPathIdentities,
+	QueryPathValidators,
+	QueryPathBlocksSeen,
+	QueryPathForks,
+}
+
+// QueryConfig is the query response type for a batch config.
+type QueryConfig struct {
+	KeyperConfigIndex     uint64           `json:"keyperConfigIndex"`
+	ActivationBlockNumber uint64           `json:"activationBlockNumber"`
+	Threshold             uint64           `json:"threshold"`
+	Keypers               []common.Address `json:"keypers"`
+	Started               bool             `json:"started"`
+	ValidatorsUpdated     bool             `json:"validatorsUpdated"`
+}
+
+// QuerySenderReceiver is the query response type for a polynomial evaluation sent from one keyper
+// to another.
+type QuerySenderReceiver struct {
+	Sender   common.Address `json:"sender"`
+	Receiver common.Address `json:"receiver"`
+}
+
+// QueryDKG is the query response type for the DKG instance of an eon.
+type QueryDKG struct {
+	Eon             uint64                  `json:"eon"`
+	Config          QueryConfig             `json:"config"`
+	PolyCommitments []common.Address        `json:"polyCommitments"`
+	PolyEvals       []QuerySenderReceiver   `json:"polyEvals"`
+	Accusations     []common.Address        `json:"accusations"`
+	Apologies       []common.Address        `json:"apologies"`
+	ResultVotes     map[common.Address]bool `json:"resultVotes"`
+	// Outcome is "success" or "failure" once enough result votes have been received and
+	// "pending" otherwise.
+	Outcome string `json:"outcome"`
+}
+
+// QueryValidator is the query response type for a validator.
+type QueryValidator struct {
+	PublicKey string `json:"publicKey"`
+	Power     int64  `json:"power"`
+}
+
+// QueryFork is the query response type for a fork of the fork registry.
+type QueryFork struct {
+	Name        ForkName            `json:"name"`
+	Description string              `json:"description"`
+	Enabled     bool                `json:"enabled"`
+	Height      int64               `json:"height"`
+	Eon         *uint64             `json:"eon,omitempty"`
+	Override    *ForkHeightOverride `json:"override,omitempty"`
+	// Active tells if the fork is active in the block following the committed one.
+	Active bool `json:"active"`
+}
+
+// committedState holds the responses to all queries. It is created at the end of Commit, so
+// queries always see the state of the last committed block, even if they are answered while the
+// next block is being processed.
+type committedState struct {
+	height     int64
+	configs    []QueryConfig
+	dkgs       map[uint64]QueryDKG
+	prunedEons uint64 // eons lower than or equal to this one without a DKG instance have been pruned
+	identities map[common.Address]string
+	validators []QueryValidator
+	blocksSeen map[common.Address]uint64
+	forks      []QueryFork
+}
+
+func makeQueryConfig(cfg *BatchConfig) QueryConfig {
+	return QueryConfig{
+		KeyperConfigIndex:     cfg.KeyperConfigIndex,
+		ActivationBlockNumber: cfg.ActivationBlockNumber,
+		Threshold:             cfg.Threshold,
+		Keypers:               slices.Clone(cfg.Keypers),
+		Started:               cfg.Started,
+		ValidatorsUpdated:     cfg.ValidatorsUpdated,
+	}
+}
+
+func makeQueryDKG(dkg *DKGInstance) QueryDKG {
+	res := QueryDKG{
+		Eon:             dkg.Eon,
+		Config:          makeQueryConfig(&dkg.Config),
+		PolyCommitments: sortedAddresses(dkg.PolyCommitmentsSeen),
+		PolyEvals:       []QuerySenderReceiver{},
+		Accusations:     sortedAddresses(dkg.AccusationsSeen),
+		Apologies:       sortedAddresses(dkg.ApologiesSeen),
+		ResultVotes:     make(map[common.Address]bool),
+		Outcome:         "pending",
+	}
+	for pair := range dkg.PolyEvalsSeen {
+		res.PolyEvals = append(res.PolyEvals, QuerySenderReceiver{Sender: pair.Sender, Receiver: pair.Receiver})
+	}
+	slices.SortFunc(res.PolyEvals, func(a, b QuerySenderReceiver) int {
+		if c := a.Sender.Cmp(b.Sender); c != 0 {
+			return c
+		}
+		return a.Receiver.Cmp(b.Receiver)
+	})
+	for sender, candidate := range dkg.SuccessVoting.Votes {
+		res.ResultVotes[sender] = dkg.SuccessVoting.Candidates[candidate]
+	}
+	success, ok := dkg.SuccessVoting.Outcome(int(dkg.Config.Threshold))
+	if ok && success {
+		res.Outcome = "success"
+	} else if ok {
+		res.Outcome = "failure"
+	}
+	return res
+}
+
+// stateChanges records which parts of the state queries read from have changed since the last
+// call to updateCommittedState.
+type stateChanges struct {
+	configs    bool
+	eons       map[uint64]struct{}
+	identities bool
+	validators bool
+	blocksSeen bool
+}
+
+func (c *stateChanges) eon(eon uint64) {
+	if c.eons == nil {
+		c.eons = make(map[uint64]struct{})
+	}
+	c.eons[eon] = struct{}{}
+}
+
+// updateCommittedState stores the query responses for the current state. It must only be called
+// when no block is being processed.
+//
+// The responses of the previously committed state are never modified, so the parts of the state
+// that have not changed since then are shared with it instead of being rebuilt. If there are no
+// previous responses, e.g., after the state has been loaded or restored, all of them are built.
+func (app *ShutterApp) updateCommittedState() {
+	prev, changes := app.committed, app.changes
+	if prev == nil {
+		prev = &committedState{dkgs: make(map[uint64]QueryDKG)}
+		changes = stateChanges{configs: true, identities: true, validators: true, blocksSeen: true}
+		for eon := range app.DKGMap {
+			changes.eon(eon)
+		}
+	}
+	cs := &committedState{
+		height:     app.LastBlockHeight,
+		configs:    prev.configs,
+		dkgs:       prev.dkgs,
+		identities: prev.identities,
+		validators: prev.validators,
+		blocksSeen: prev.blocksSeen,
+	}
+	if changes.configs {
+		cs.configs = app.queryConfigs()
+	}
+	if len(changes.eons) > 0 {
+		cs.dkgs = maps.Clone(prev.dkgs)
+		for eon := range changes.eons {
+			if dkg, ok := app.DKGMap[eon]; ok {
+				cs.dkgs[eon] = makeQueryDKG(dkg)
+			} else {
+				delete(cs.dkgs, eon)
+			}
+		}
+	}
+	if app.IsStatePruningForkActive() {
+		cs.prunedEons = app.EONCounter
+	}
+	if changes.identities {
+		cs.identities = make(map[common.Address]string)
+		for address, pubkey := range app.Identities {
+			cs.identities[address] = hex.EncodeToString([]byte(pubkey.Ed25519pubkey))
+		}
+	}
+	if changes.validators {
+		cs.validators = app.queryValidators()
+	}
+	if changes.blocksSeen {
+		cs.blocksSeen = maps.Clone(app.BlocksSeen)
+		if cs.blocksSeen == nil {
+			cs.blocksSeen = make(map[common.Address]uint64)
+		}
+	}
+	cs.forks = app.queryForks()
+	app.committed = cs
+	app.changes = stateChanges{}
+}
+
+func (app *ShutterApp) queryConfigs() []QueryConfig {
+	configs := []QueryConfig{}
+	// Skip the guard element added by NewShutterApp
+	for _, cfg := range app.Configs {
+		if len(cfg.Keypers) == 0 {
+			continue
+		}
+		configs = append(configs, makeQueryConfig(cfg))
+	}
+	return configs
+}
+
+func (app *ShutterApp) queryValidators() []QueryValidator {
+	validators := []QueryValidator{}
+	for _, update := range app.Validators.ValidatorUpdates() {
+		validators = append(validators, QueryValidator{
+			PublicKey: hex.EncodeToString(update.PubKey.GetEd25519()),
+			Power:     update.Power,
+		})
+	}
+	slices.SortFunc(validators, func(a, b QueryValidator) int {
+		return strings.Compare(a.PublicKey, b.PublicKey)
+	})
+	return validators
+}
+
+// queryForks returns the fork responses. They are rebuilt for every block, as whether a fork is
+// active depends on the block height.
+func (app *ShutterApp) queryForks() []QueryFork {
+	forks := []QueryFork{}
+	for _, spec := range Forks {
+		fork := QueryFork{
+			Name:        spec.Name,
+			Description: spec.Description,
+			Override:    app.forkHeightOverride(spec.Name),
+			Active:      app.IsForkActive(spec.Name),
+		}
+		if app.ForkHeights != nil {
+			forkHeight := app.ForkHeights.Get(spec.Name)
+			fork.Enabled, fork.Height = forkHeight.Enabled, forkHeight.Height
+			if forkHeight.Eon != nil {
+				fork.Eon = uint64Ptr(*forkHeight.Eon)
+			}
+		}
+		forks = append(forks, fork)
+	}
+	return forks
+}
+
+// Query answers queries about the state at the last committed block height. See QueryPaths for
+// the supported paths.
+func (app *ShutterApp) Query(req abcitypes.RequestQuery) abcitypes.ResponseQuery {
+	cs := app.committed
+	if cs == nil {
+		return abcitypes.ResponseQuery{Code: 1, Log: "no committed state available yet"}
+	}
+	if req.Height != 0 && req.Height != cs.height {
+		return abcitypes.ResponseQuery{
+			Code:   1,
+			Log:    fmt.Sprintf("only the latest committed height %d can be queried", cs.height),
+			Height: cs.height,
+		}
+	}
+
+	res, err := cs.query(req.Path)
+	if err != nil {
+		return abcitypes.ResponseQuery{Code: 1, Log: err.Error(), Height: cs.height}
+	}
+	value, err := json.Marshal(res)
+	if err != nil {
+		return abcitypes.ResponseQuery{Code: 1, Log: err.Error(), Height: cs.height}
+	}
+	return abcitypes.ResponseQuery{
+		Code:   0,
+		Key:    []byte(req.Path),
+		Value:  value,
+		Height: cs.height,
+	}
+}
+
+func (cs *committedState) query(path string) (any, error) {
+	elements := strings.Split(strings.Trim(path, "/"), "/")
+	switch "/" + elements[0] {
+	case QueryPathConfigs:
+		if len(elements) == 1 {
+			return cs.configs, nil
+		}
+		if len(elements) != 2 {
+			break
+		}
+		index, err := strconv.ParseUint(elements[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid config index")
+		}
+		for _, cfg := range cs.configs {
+			if cfg.KeyperConfigIndex == index {
+				return cfg, nil
+			}
+		}
+		return nil, errors.Errorf("config with index %d not found", index)
+	case QueryPathDKG:
+		if len(elements) != 2 {
+			break
+		}
+		eon, err := strconv.ParseUint(elements[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid eon")
+		}
+		dkg, ok := cs.dkgs[eon]
+		if ok {
+			return dkg, nil
+		}
+		if eon <= cs.prunedEons {
+			return nil, errors.Errorf("dkg of eon %d has been finalized and pruned", eon)
+		}
+		return nil, errors.Errorf("dkg of eon %d not found", eon)
+	case QueryPathIdentities:
+		if len(elements) == 1 {
+			return cs.identities, nil
+		}
+	case QueryPathValidators:
+		if len(elements) == 1 {
+			return cs.validators, nil
+		}
+	case QueryPathBlocksSeen:
+		if len(elements) == 1 {
+			return cs.blocksSeen, nil
+		}
+	case QueryPathForks:
+		if len(elements) == 1 {
+			return cs.forks, nil
+		}
+	}
+	return nil, errors.Errorf("unknown query path %s", path)
+}
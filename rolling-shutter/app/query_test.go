@@ -0,0 +1,184 @@
+package app
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
+)
+
+func query(t *testing.T, app *ShutterApp, path string, res any) {
+	t.Helper()
+	resp := app.Query(abcitypes.RequestQuery{Path: path})
+	assert.Equal(t, resp.Code, uint32(0), resp.Log)
+	assert.Equal(t, resp.Height, app.LastBlockHeight)
+	assert.NilError(t, json.Unmarshal(resp.Value, res))
+}
+
+func TestQuery(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	addresses := []common.Address{keypers[0].address(), keypers[1].address(), keypers[2].address()}
+
+	runBlock(t, app, [][]byte{
+		keypers[0].checkInTx(t),
+		keypers[1].checkInTx(t),
+		keypers[0].tx(t, shmsg.NewBlockSeen(10)),
+	})
+	runBlock(t, app, [][]byte{
+		keypers[0].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+		keypers[1].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+	})
+	runBlock(t, app, [][]byte{
+		keypers[0].tx(t, shmsg.NewPolyEval(1, []common.Address{keypers[1].address()}, [][]byte{{1}})),
+		keypers[1].tx(t, shmsg.NewAccusation(1, []common.Address{keypers[0].address()})),
+		keypers[0].tx(t, shmsg.NewDKGResult(1, true)),
+	})
+
+	var configs []QueryConfig
+	query(t, app, "/configs", &configs)
+	assert.Equal(t, len(configs), 2)
+	assert.Equal(t, configs[1].KeyperConfigIndex, uint64(1))
+	assert.Equal(t, configs[1].ActivationBlockNumber, uint64(20))
+	assert.DeepEqual(t, configs[1].Keypers, addresses)
+
+	var config QueryConfig
+	query(t, app, "/configs/1", &config)
+	assert.DeepEqual(t, config, configs[1])
+
+	var dkg QueryDKG
+	query(t, app, "/dkg/1", &dkg)
+	assert.Equal(t, dkg.Eon, uint64(1))
+	assert.DeepEqual(t, dkg.PolyEvals, []QuerySenderReceiver{
+		{Sender: keypers[0].address(), Receiver: keypers[1].address()},
+	})
+	assert.DeepEqual(t, dkg.Accusations, []common.Address{keypers[1].address()})
+	assert.DeepEqual(t, dkg.ResultVotes, map[common.Address]bool{keypers[0].address(): true})
+	assert.Equal(t, dkg.Outcome, "pending")
+
+	var identities map[common.Address]string
+	query(t, app, "/identities", &identities)
+	assert.Equal(t, len(identities), 2)
+	assert.Equal(t, identities[keypers[0].address()], hex.EncodeToString(keypers[0].validatorKey))
+
+	var validators []QueryValidator
+	query(t, app, "/validators", &validators)
+
+	var blocksSeen map[common.Address]uint64
+	query(t, app, "/blocks-seen", &blocksSeen)
+	assert.DeepEqual(t, blocksSeen, map[common.Address]uint64{keypers[0].address(): 10})
+}
+
+func TestQueryPrunedDKG(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	addresses := []common.Address{keypers[0].address(), keypers[1].address(), keypers[2].address()}
+
+	runBlock(t, app, [][]byte{keypers[0].checkInTx(t), keypers[1].checkInTx(t)})
+	runBlock(t, app, [][]byte{
+		keypers[0].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+		keypers[1].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+	})
+	runBlock(t, app, [][]byte{
+		keypers[0].tx(t, shmsg.NewDKGResult(1, true)),
+		keypers[1].tx(t, shmsg.NewDKGResult(1, true)),
+	})
+
+	res := app.Query(abcitypes.RequestQuery{Path: "/dkg/1"})
+	assert.Equal(t, res.Code, uint32(1))
+	assert.Assert(t, strings.Contains(res.Log, "pruned"), res.Log)
+}
+
+func TestQueryErrors(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	runBlock(t, app, [][]byte{keypers[0].checkInTx(t)})
+
+	for _, path := range []string{"/unknown", "/configs/x", "/configs/5", "/dkg", "/dkg/42", "/validators/1"} {
+		res := app.Query(abcitypes.RequestQuery{Path: path})
+		assert.Equal(t, res.Code, uint32(1), path)
+		assert.Assert(t, res.Log != "", path)
+	}
+
+	res := app.Query(abcitypes.RequestQuery{Path: "/configs", Height: app.LastBlockHeight + 1})
+	assert.Equal(t, res.Code, uint32(1))
+	assert.Equal(t, res.Height, app.LastBlockHeight)
+}
+
+func TestQueryReturnsCommittedState(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	runBlock(t, app, [][]byte{keypers[0].tx(t, shmsg.NewBlockSeen(1))})
+
+	app.BeginBlock(abcitypes.RequestBeginBlock{})
+	res := app.DeliverTx(abcitypes.RequestDeliverTx{Tx: keypers[0].tx(t, shmsg.NewBlockSeen(2))})
+	assert.Equal(t, res.Code, uint32(0), res.Log)
+
+	var blocksSeen map[common.Address]uint64
+	query(t, app, "/blocks-seen", &blocksSeen)
+	assert.Equal(t, blocksSeen[keypers[0].address()], uint64(1))
+}
+
+func TestQueryForks(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	forkHeights := NewForkHeightsAllEnabled()
+	forkHeights.StatePruning = ForkHeight{Enabled: true, Height: 10}
+	forkHeights.CheckInUpdateNew = ForkHeight{Enabled: true, Eon: uint64Ptr(5)}
+	app := newTestChainApp(t, keypers, forkHeights)
+	runBlock(t, app, [][]byte{})
+
+	var forks []QueryFork
+	query(t, app, "/forks", &forks)
+	assert.DeepEqual(t, forks, []QueryFork{
+		{
+			Name:        ForkCheckInUpdate,
+			Description: Forks[0].Description,
+			Enabled:     true,
+			Eon:         uint64Ptr(5),
+			Active:      false,
+		},
+		{Name: ForkAppHash, Description: Forks[1].Description, Enabled: true, Active: true},
+		{Name: ForkStatePruning, Description: Forks[2].Description, Enabled: true, Height: 10, Active: false},
+	})
+}
+
+func TestQueryCommittedStateIncremental(t *testing.T) {
+	keypers := newTestKeypers(t, 3)
+	app := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	addresses := []common.Address{keypers[0].address(), keypers[1].address(), keypers[2].address()}
+
+	blocks := [][][]byte{
+		{keypers[0].checkInTx(t), keypers[1].checkInTx(t), keypers[0].tx(t, shmsg.NewBlockSeen(10))},
+		{},
+		{
+			keypers[0].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+			keypers[1].tx(t, shmsg.NewBatchConfig(20, addresses, 2, 1)),
+		},
+		{
+			keypers[0].tx(t, shmsg.NewPolyEval(1, []common.Address{keypers[1].address()}, [][]byte{{1}})),
+			keypers[1].tx(t, shmsg.NewAccusation(1, []common.Address{keypers[0].address()})),
+			keypers[0].tx(t, shmsg.NewApology(1, []common.Address{keypers[1].address()}, []*big.Int{big.NewInt(1)})),
+		},
+		{keypers[2].checkInTx(t), keypers[1].tx(t, shmsg.NewBlockSeen(20)), keypers[2].tx(t, shmsg.NewBlockSeen(20))},
+		{keypers[0].tx(t, shmsg.NewDKGResult(1, true)), keypers[1].tx(t, shmsg.NewDKGResult(1, true))},
+		{},
+	}
+	for i, txs := range blocks {
+		previous := app.committed
+		runBlock(t, app, txs)
+		incremental := app.committed
+		assert.Assert(t, incremental != previous)
+
+		app.committed = nil
+		app.updateCommittedState()
+		assert.Assert(t, reflect.DeepEqual(incremental, app.committed), "block %d", i)
+	}
+}
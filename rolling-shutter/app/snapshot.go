@@ -0,0 +1,315 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// SnapshotFormatAppState is the format of snapshots that contain the versioned app state
+// encoding, i.e. the same data that PersistToDisk writes. Format 1 was used for snapshots of the
+// gob encoded ShutterApp, which are neither created nor accepted anymore.
+const SnapshotFormatAppState uint32 = 2
+
+const snapshotFileSuffix = ".state"
+
+var (
+	// SnapshotInterval is the number of blocks between two state sync snapshots. If set to zero,
+	// no snapshots will be created. Like PersistMinDuration, this is node local configuration and
+	// therefore not a field of ShutterApp.
+	SnapshotInterval int64 = 1000
+
+	// SnapshotKeepRecent is the number of snapshots to keep on disk. Older ones are deleted
+	// whenever a new snapshot is created.
+	SnapshotKeepRecent = 2
+
+	// SnapshotChunkSize is the maximum size of a single snapshot chunk in bytes.
+	SnapshotChunkSize = 1 << 20
+)
+
+// snapshotRestore holds the state of a snapshot that is being restored via state sync.
+type snapshotRestore struct {
+	snapshot    *abcitypes.Snapshot
+	appHash     []byte
+	chunkHashes [][]byte
+	chunks      [][]byte
+}
+
+// snapshotDir returns the directory where snapshots are stored. The directory is located next to
+// the state file.
+func (app *ShutterApp) snapshotDir() string {
+	return filepath.Join(filepath.Dir(app.StatePath), "snapshots")
+}
+
+func (app *ShutterApp) snapshotPath(height uint64) string {
+	return filepath.Join(app.snapshotDir(), strconv.FormatUint(height, 10)+snapshotFileSuffix)
+}
+
+// maybeCreateSnapshot creates a snapshot of the current state if the current block height is a
+// multiple of SnapshotInterval. Snapshots are only created once the app hash fork is active as
+// otherwise restored state cannot be verified against the chain.
+func (app *ShutterApp) maybeCreateSnapshot() error {
+	if app.StatePath == "" || SnapshotInterval <= 0 || app.LastBlockHeight%SnapshotInterval != 0 {
+		return nil
+	}
+	if !app.IsAppHashForkActive() {
+		return nil
+	}
+	return app.createSnapshot()
+}
+
+func (app *ShutterApp) createSnapshot() error {
+	height := uint64(app.LastBlockHeight) //nolint:gosec // G115, block heights are positive
+	log.Info().Uint64("height", height).Msg("creating state sync snapshot")
+
+	data, err := EncodeAppState(app)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(app.snapshotDir(), 0o755)
+	if err != nil {
+		return err
+	}
+	path := app.snapshotPath(height)
+	tmppath := path + ".tmp"
+	err = os.WriteFile(tmppath, data, 0o600)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmppath, path)
+	if err != nil {
+		return err
+	}
+	return app.pruneSnapshots()
+}
+
+// snapshotHeights returns the heights of all snapshots stored on disk in ascending order.
+func (app *ShutterApp) snapshotHeights() ([]uint64, error) {
+	entries, err := os.ReadDir(app.snapshotDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var heights []uint64
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), snapshotFileSuffix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		height, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		heights = append(heights, height)
+	}
+	slices.Sort(heights)
+	return heights, nil
+}
+
+func (app *ShutterApp) pruneSnapshots() error {
+	heights, err := app.snapshotHeights()
+	if err != nil {
+		return err
+	}
+	for len(heights) > SnapshotKeepRecent {
+		path := app.snapshotPath(heights[0])
+		log.Info().Str("path", path).Msg("deleting old state sync snapshot")
+		err = os.Remove(path)
+		if err != nil {
+			return err
+		}
+		heights = heights[1:]
+	}
+	return nil
+}
+
+// splitChunks splits the snapshot data into chunks of at most SnapshotChunkSize bytes.
+func splitChunks(data []byte) [][]byte {
+	chunks := [][]byte{}
+	for len(data) > SnapshotChunkSize {
+		chunks = append(chunks, data[:SnapshotChunkSize])
+		data = data[SnapshotChunkSize:]
+	}
+	return append(chunks, data)
+}
+
+// makeSnapshot creates the ABCI snapshot description for the given snapshot data. The metadata
+// consists of the concatenated SHA256 hashes of all chunks, so that each chunk can be verified
+// individually when restoring.
+func makeSnapshot(height uint64, data []byte) *abcitypes.Snapshot {
+	chunks := splitChunks(data)
+	metadata := make([]byte, 0, len(chunks)*sha256.Size)
+	for _, chunk := range chunks {
+		h := sha256.Sum256(chunk)
+		metadata = append(metadata, h[:]...)
+	}
+	hash := sha256.Sum256(data)
+	return &abcitypes.Snapshot{
+		Height:   height,
+		Format:   SnapshotFormatAppState,
+		Chunks:   uint32(len(chunks)), //nolint:gosec // G115
+		Hash:     hash[:],
+		Metadata: metadata,
+	}
+}
+
+// ListSnapshots returns the snapshots available on disk.
+func (app *ShutterApp) ListSnapshots(abcitypes.RequestListSnapshots) abcitypes.ResponseListSnapshots {
+	if app.StatePath == "" {
+		return abcitypes.ResponseListSnapshots{}
+	}
+	heights, err := app.snapshotHeights()
+	if err != nil {
+		log.Error().Err(err).Msg("cannot list snapshots")
+		return abcitypes.ResponseListSnapshots{}
+	}
+	snapshots := []*abcitypes.Snapshot{}
+	for _, height := range heights {
+		data, err := os.ReadFile(app.snapshotPath(height))
+		if err != nil {
+			log.Error().Err(err).Uint64("height", height).Msg("cannot read snapshot")
+			continue
+		}
+		snapshots = append(snapshots, makeSnapshot(height, data))
+	}
+	return abcitypes.ResponseListSnapshots{Snapshots: snapshots}
+}
+
+// LoadSnapshotChunk returns a chunk of a snapshot stored on disk to a peer.
+func (app *ShutterApp) LoadSnapshotChunk(
+	req abcitypes.RequestLoadSnapshotChunk,
+) abcitypes.ResponseLoadSnapshotChunk {
+	if app.StatePath == "" || req.Format != SnapshotFormatAppState {
+		return abcitypes.ResponseLoadSnapshotChunk{}
+	}
+	data, err := os.ReadFile(app.snapshotPath(req.Height))
+	if err != nil {
+		log.Error().Err(err).Uint64("height", req.Height).Msg("cannot read snapshot")
+		return abcitypes.ResponseLoadSnapshotChunk{}
+	}
+	chunks := splitChunks(data)
+	if int(req.Chunk) >= len(chunks) {
+		return abcitypes.ResponseLoadSnapshotChunk{}
+	}
+	return abcitypes.ResponseLoadSnapshotChunk{Chunk: chunks[req.Chunk]}
+}
+
+// OfferSnapshot is called by tendermint when syncing a new node. It accepts the snapshot if it is
+// in a known format and can be verified against a non-empty app hash.
+func (app *ShutterApp) OfferSnapshot(req abcitypes.RequestOfferSnapshot) abcitypes.ResponseOfferSnapshot {
+	snapshot := req.Snapshot
+	if snapshot == nil {
+		return abcitypes.ResponseOfferSnapshot{Result: abcitypes.ResponseOfferSnapshot_REJECT}
+	}
+	if snapshot.Format != SnapshotFormatAppState {
+		return abcitypes.ResponseOfferSnapshot{Result: abcitypes.ResponseOfferSnapshot_REJECT_FORMAT}
+	}
+	if len(req.AppHash) == 0 {
+		log.Warn().Uint64("height", snapshot.Height).
+			Msg("rejecting snapshot without app hash, app hash fork was not active")
+		return abcitypes.ResponseOfferSnapshot{Result: abcitypes.ResponseOfferSnapshot_REJECT}
+	}
+	if snapshot.Chunks == 0 || len(snapshot.Metadata) != int(snapshot.Chunks)*sha256.Size {
+		return abcitypes.ResponseOfferSnapshot{Result: abcitypes.ResponseOfferSnapshot_REJECT}
+	}
+
+	chunkHashes := make([][]byte, snapshot.Chunks)
+	for i := range chunkHashes {
+		chunkHashes[i] = snapshot.Metadata[i*sha256.Size : (i+1)*sha256.Size]
+	}
+	app.restore = &snapshotRestore{
+		snapshot:    snapshot,
+		appHash:     req.AppHash,
+		chunkHashes: chunkHashes,
+		chunks:      make([][]byte, snapshot.Chunks),
+	}
+	log.Info().Uint64("height", snapshot.Height).Uint32("chunks", snapshot.Chunks).
+		Msg("accepted state sync snapshot")
+	return abcitypes.ResponseOfferSnapshot{Result: abcitypes.ResponseOfferSnapshot_ACCEPT}
+}
+
+// ApplySnapshotChunk verifies and stores a chunk of the snapshot being restored. Once all chunks
+// have been received, the state is decoded, checked against the trusted app hash and replaces
+// the current state.
+func (app *ShutterApp) ApplySnapshotChunk(
+	req abcitypes.RequestApplySnapshotChunk,
+) abcitypes.ResponseApplySnapshotChunk {
+	if app.restore == nil {
+		return abcitypes.ResponseApplySnapshotChunk{Result: abcitypes.ResponseApplySnapshotChunk_ABORT}
+	}
+	restore := app.restore
+	if int(req.Index) >= len(restore.chunks) {
+		return abcitypes.ResponseApplySnapshotChunk{Result: abcitypes.ResponseApplySnapshotChunk_REJECT_SNAPSHOT}
+	}
+	h := sha256.Sum256(req.Chunk)
+	if !bytes.Equal(h[:], restore.chunkHashes[req.Index]) {
+		log.Warn().Uint32("index", req.Index).Str("sender", req.Sender).Msg("received invalid snapshot chunk")
+		return abcitypes.ResponseApplySnapshotChunk{
+			Result:        abcitypes.ResponseApplySnapshotChunk_RETRY,
+			RefetchChunks: []uint32{req.Index},
+			RejectSenders: []string{req.Sender},
+		}
+	}
+	restore.chunks[req.Index] = req.Chunk
+
+	for _, chunk := range restore.chunks {
+		if chunk == nil {
+			return abcitypes.ResponseApplySnapshotChunk{Result: abcitypes.ResponseApplySnapshotChunk_ACCEPT}
+		}
+	}
+
+	app.restore = nil
+	err := app.restoreSnapshot(restore)
+	if err != nil {
+		log.Error().Err(err).Uint64("height", restore.snapshot.Height).Msg("failed to restore snapshot")
+		return abcitypes.ResponseApplySnapshotChunk{Result: abcitypes.ResponseApplySnapshotChunk_REJECT_SNAPSHOT}
+	}
+	return abcitypes.ResponseApplySnapshotChunk{Result: abcitypes.ResponseApplySnapshotChunk_ACCEPT}
+}
+
+// restoreSnapshot decodes the fully received snapshot and replaces the app state with it.
+func (app *ShutterApp) restoreSnapshot(restore *snapshotRestore) error {
+	data := bytes.Join(restore.chunks, nil)
+	hash := sha256.Sum256(data)
+	if !bytes.Equal(hash[:], restore.snapshot.Hash) {
+		return errors.New("snapshot hash mismatch")
+	}
+
+	restored, err := DecodeAppState(data)
+	if err != nil {
+		return errors.Wrap(err, "cannot decode snapshot")
+	}
+	// The fork height overrides are needed to check the app hash below.
+	restored.config = app.config
+	if uint64(restored.LastBlockHeight) != restore.snapshot.Height { //nolint:gosec // G115
+		return errors.Errorf(
+			"snapshot height mismatch (expected %d, got %d)",
+			restore.snapshot.Height,
+			restored.LastBlockHeight,
+		)
+	}
+	if !bytes.Equal(restored.AppHash(), restore.appHash) {
+		return errors.New("app hash mismatch")
+	}
+
+	// Keep node local configuration. DecodeAppState already initialized the CheckTx state.
+	restored.StatePath = app.StatePath
+	restored.DevMode = app.DevMode
+	*app = *restored
+	app.updateCommittedState()
+	log.Info().Int64("height", app.LastBlockHeight).Msg("restored state from snapshot")
+
+	if app.StatePath == "" {
+		return nil
+	}
+	return app.PersistToDisk()
+}
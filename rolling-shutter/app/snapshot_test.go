@@ -0,0 +1,136 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
+)
+
+func setSnapshotParams(t *testing.T, interval int64, keepRecent, chunkSize int) {
+	t.Helper()
+	oldInterval, oldKeepRecent, oldChunkSize := SnapshotInterval, SnapshotKeepRecent, SnapshotChunkSize
+	SnapshotInterval, SnapshotKeepRecent, SnapshotChunkSize = interval, keepRecent, chunkSize
+	t.Cleanup(func() {
+		SnapshotInterval, SnapshotKeepRecent, SnapshotChunkSize = oldInterval, oldKeepRecent, oldChunkSize
+	})
+}
+
+// newSnapshottingApp creates an app that has processed a few blocks and stored snapshots on disk.
+func newSnapshottingApp(t *testing.T) *ShutterApp {
+	t.Helper()
+	setSnapshotParams(t, 2, 2, 64)
+	keypers := newTestKeypers(t, 3)
+	app := newTestChainApp(t, keypers, NewForkHeightsAllEnabled())
+	app.StatePath = filepath.Join(t.TempDir(), "shutter.state")
+
+	runBlock(t, app, [][]byte{keypers[0].checkInTx(t), keypers[1].checkInTx(t)})
+	for i := uint64(1); i <= 5; i++ {
+		runBlock(t, app, [][]byte{keypers[0].tx(t, shmsg.NewBlockSeen(i))})
+	}
+	return app
+}
+
+func restoreFromPeer(t *testing.T, peer, app *ShutterApp, snapshot *abcitypes.Snapshot, appHash []byte) {
+	t.Helper()
+	offer := app.OfferSnapshot(abcitypes.RequestOfferSnapshot{Snapshot: snapshot, AppHash: appHash})
+	assert.Equal(t, offer.Result, abcitypes.ResponseOfferSnapshot_ACCEPT)
+	for i := uint32(0); i < snapshot.Chunks; i++ {
+		chunk := peer.LoadSnapshotChunk(abcitypes.RequestLoadSnapshotChunk{
+			Height: snapshot.Height,
+			Format: snapshot.Format,
+			Chunk:  i,
+		})
+		res := app.ApplySnapshotChunk(abcitypes.RequestApplySnapshotChunk{Index: i, Chunk: chunk.Chunk, Sender: "peer"})
+		assert.Equal(t, res.Result, abcitypes.ResponseApplySnapshotChunk_ACCEPT)
+	}
+}
+
+func TestListSnapshots(t *testing.T) {
+	app := newSnapshottingApp(t)
+
+	snapshots := app.ListSnapshots(abcitypes.RequestListSnapshots{}).Snapshots
+	assert.Equal(t, len(snapshots), 2)
+	assert.Equal(t, snapshots[0].Height, uint64(4))
+	assert.Equal(t, snapshots[1].Height, uint64(6))
+	for _, snapshot := range snapshots {
+		assert.Equal(t, snapshot.Format, SnapshotFormatAppState)
+		assert.Assert(t, snapshot.Chunks > 1)
+		assert.Equal(t, len(snapshot.Metadata), int(snapshot.Chunks)*32)
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	peer := newSnapshottingApp(t)
+	snapshots := peer.ListSnapshots(abcitypes.RequestListSnapshots{}).Snapshots
+	snapshot := snapshots[len(snapshots)-1]
+	appHash := peer.AppHash()
+
+	app := NewShutterApp(Config{})
+	app.StatePath = filepath.Join(t.TempDir(), "shutter.state")
+	restoreFromPeer(t, peer, app, snapshot, appHash)
+
+	info := app.Info(abcitypes.RequestInfo{})
+	assert.Equal(t, info.LastBlockHeight, peer.LastBlockHeight)
+	assert.DeepEqual(t, info.LastBlockAppHash, appHash)
+
+	loaded, err := LoadShutterAppFromFile(app.StatePath, Config{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, loaded.AppHash(), appHash)
+}
+
+func TestRestoreSnapshotRejectsInvalidData(t *testing.T) {
+	peer := newSnapshottingApp(t)
+	snapshots := peer.ListSnapshots(abcitypes.RequestListSnapshots{}).Snapshots
+	snapshot := snapshots[len(snapshots)-1]
+
+	t.Run("missing app hash", func(t *testing.T) {
+		app := NewShutterApp(Config{})
+		res := app.OfferSnapshot(abcitypes.RequestOfferSnapshot{Snapshot: snapshot})
+		assert.Equal(t, res.Result, abcitypes.ResponseOfferSnapshot_REJECT)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		for _, format := range []uint32{1, 42} {
+			app := NewShutterApp(Config{})
+			s := *snapshot
+			s.Format = format
+			res := app.OfferSnapshot(abcitypes.RequestOfferSnapshot{Snapshot: &s, AppHash: peer.AppHash()})
+			assert.Equal(t, res.Result, abcitypes.ResponseOfferSnapshot_REJECT_FORMAT)
+		}
+	})
+
+	t.Run("corrupted chunk", func(t *testing.T) {
+		app := NewShutterApp(Config{})
+		res := app.OfferSnapshot(abcitypes.RequestOfferSnapshot{Snapshot: snapshot, AppHash: peer.AppHash()})
+		assert.Equal(t, res.Result, abcitypes.ResponseOfferSnapshot_ACCEPT)
+		chunkRes := app.ApplySnapshotChunk(abcitypes.RequestApplySnapshotChunk{
+			Index:  0,
+			Chunk:  []byte("garbage"),
+			Sender: "bad-peer",
+		})
+		assert.Equal(t, chunkRes.Result, abcitypes.ResponseApplySnapshotChunk_RETRY)
+		assert.DeepEqual(t, chunkRes.RefetchChunks, []uint32{0})
+		assert.DeepEqual(t, chunkRes.RejectSenders, []string{"bad-peer"})
+	})
+
+	t.Run("app hash mismatch", func(t *testing.T) {
+		app := NewShutterApp(Config{})
+		res := app.OfferSnapshot(abcitypes.RequestOfferSnapshot{Snapshot: snapshot, AppHash: []byte("wrong")})
+		assert.Equal(t, res.Result, abcitypes.ResponseOfferSnapshot_ACCEPT)
+		var chunkRes abcitypes.ResponseApplySnapshotChunk
+		for i := uint32(0); i < snapshot.Chunks; i++ {
+			chunk := peer.LoadSnapshotChunk(abcitypes.RequestLoadSnapshotChunk{
+				Height: snapshot.Height,
+				Format: snapshot.Format,
+				Chunk:  i,
+			})
+			chunkRes = app.ApplySnapshotChunk(abcitypes.RequestApplySnapshotChunk{Index: i, Chunk: chunk.Chunk})
+		}
+		assert.Equal(t, chunkRes.Result, abcitypes.ResponseApplySnapshotChunk_REJECT_SNAPSHOT)
+		assert.Equal(t, app.LastBlockHeight, int64(0))
+	})
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
+)
+
+// The types below mirror the ShutterApp struct as it has been gob encoded in format version 0.
+// gob matches struct fields by name, so they must not be changed, even if the corresponding fields
+// of ShutterApp are. Fields that have not been persisted in every release are optional in gob and
+// decode to their zero value if missing.
+
+type batchConfigV0 struct {
+	Height                int64
+	Keypers               []common.Address
+	ActivationBlockNumber uint64
+	Threshold             uint64
+	KeyperConfigIndex     uint64
+	Started               bool
+	ValidatorsUpdated     bool
+}
+
+type votingV0[T any] struct {
+	Votes      map[common.Address]int
+	Candidates []T
+}
+
+type senderReceiverPairV0 struct {
+	Sender, Receiver common.Address
+}
+
+type dkgInstanceV0 struct {
+	Config              batchConfigV0
+	Eon                 uint64
+	SuccessVoting       votingV0[bool]
+	PolyEvalsSeen       map[senderReceiverPairV0]struct{}
+	PolyCommitmentsSeen map[common.Address]struct{}
+	AccusationsSeen     map[common.Address]struct{}
+	ApologiesSeen       map[common.Address]struct{}
+}
+
+type validatorPubkeyV0 struct {
+	Ed25519pubkey string
+}
+
+type nonceTrackerV0 struct {
+	RandomNonces   map[common.Address]map[uint64]bool
+	ExpiringNonces map[common.Address]map[uint64]bool
+}
+
+type forkHeightV0 struct {
+	Enabled bool
+	Height  int64
+	Eon     *uint64
+}
+
+type forkHeightsV0 struct {
+	CheckInUpdate    *int64
+	CheckInUpdateNew forkHeightV0
+	AppHash          forkHeightV0
+	StatePruning     forkHeightV0
+}
+
+type appStateV0 struct {
+	Configs         []*batchConfigV0
+	DKGMap          map[uint64]*dkgInstanceV0
+	ConfigVoting    votingV0[batchConfigV0]
+	LastBlockHeight int64
+	Identities      map[common.Address]validatorPubkeyV0
+	BlocksSeen      map[common.Address]uint64
+	Validators      map[validatorPubkeyV0]int64
+	EONCounter      uint64
+	DevMode         bool
+	NonceTracker    *nonceTrackerV0
+	ChainID         string
+	ForkHeights     *forkHeightsV0
+}
+
+func (cfg *batchConfigV0) toV1() *shmsg.AppStateBatchConfig {
+	return &shmsg.AppStateBatchConfig{
+		Height:                cfg.Height,
+		Keypers:               encodeAddresses(cfg.Keypers),
+		ActivationBlockNumber: cfg.ActivationBlockNumber,
+		Threshold:             cfg.Threshold,
+		KeyperConfigIndex:     cfg.KeyperConfigIndex,
+		Started:               cfg.Started,
+		ValidatorsUpdated:     cfg.ValidatorsUpdated,
+	}
+}
+
+func (fh *forkHeightsV0) toV1() []*shmsg.AppStateFork {
+	res := []*shmsg.AppStateFork{}
+	if fh == nil {
+		return res
+	}
+	checkInUpdate := fh.CheckInUpdateNew
+	if checkInUpdate == (forkHeightV0{}) && fh.CheckInUpdate != nil {
+		// see migrateForkHeights
+		checkInUpdate = forkHeightV0{Enabled: true, Height: *fh.CheckInUpdate}
+	}
+	forks := []struct {
+		name       ForkName
+		forkHeight forkHeightV0
+	}{
+		{ForkCheckInUpdate, checkInUpdate},
+		{ForkAppHash, fh.AppHash},
+		{ForkStatePruning, fh.StatePruning},
+	}
+	for _, fork := range forks {
+		res = append(res, &shmsg.AppStateFork{
+			Name:    string(fork.name),
+			Enabled: fork.forkHeight.Enabled,
+			Height:  fork.forkHeight.Height,
+			Eon:     fork.forkHeight.Eon,
+		})
+	}
+	return res
+}
+
+// upgradeStateV0 converts the gob encoded ShutterApp to format version 1.
+func upgradeStateV0(payload []byte) ([]byte, error) {
+	var v0 appStateV0
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&v0); err != nil {
+		return nil, err
+	}
+
+	v1 := &shmsg.AppState{
+		ChainId:         v0.ChainID,
+		LastBlockHeight: v0.LastBlockHeight,
+		EonCounter:      v0.EONCounter,
+		DevMode:         v0.DevMode,
+		ConfigVoting: &shmsg.AppStateConfigVoting{
+			Votes: encodeVotes(v0.ConfigVoting.Votes),
+		},
+		Forks: v0.ForkHeights.toV1(),
+	}
+	for _, cfg := range v0.Configs {
+		v1.Configs = append(v1.Configs, cfg.toV1())
+	}
+	for i := range v0.ConfigVoting.Candidates {
+		v1.ConfigVoting.Candidates = append(v1.ConfigVoting.Candidates, v0.ConfigVoting.Candidates[i].toV1())
+	}
+	for _, dkg := range v0.DKGMap {
+		dkgV1 := &shmsg.AppStateDKGInstance{
+			Eon:    dkg.Eon,
+			Config: dkg.Config.toV1(),
+			SuccessVoting: &shmsg.AppStateSuccessVoting{
+				Candidates: dkg.SuccessVoting.Candidates,
+				Votes:      encodeVotes(dkg.SuccessVoting.Votes),
+			},
+			PolyCommitmentsSeen: encodeAddresses(sortedAddresses(dkg.PolyCommitmentsSeen)),
+			AccusationsSeen:     encodeAddresses(sortedAddresses(dkg.AccusationsSeen)),
+			ApologiesSeen:       encodeAddresses(sortedAddresses(dkg.ApologiesSeen)),
+		}
+		for pair := range dkg.PolyEvalsSeen {
+			dkgV1.PolyEvalsSeen = append(dkgV1.PolyEvalsSeen, &shmsg.AppStateSenderReceiver{
+				Sender:   pair.Sender.Bytes(),
+				Receiver: pair.Receiver.Bytes(),
+			})
+		}
+		v1.DkgInstances = append(v1.DkgInstances, dkgV1)
+	}
+	for keyper, pubkey := range v0.Identities {
+		v1.Identities = append(v1.Identities, &shmsg.AppStateIdentity{
+			Keyper:             keyper.Bytes(),
+			ValidatorPublicKey: []byte(pubkey.Ed25519pubkey),
+		})
+	}
+	for keyper, blockNumber := range v0.BlocksSeen {
+		v1.BlocksSeen = append(v1.BlocksSeen, &shmsg.AppStateBlockSeen{
+			Keyper:      keyper.Bytes(),
+			BlockNumber: blockNumber,
+		})
+	}
+	for pubkey, power := range v0.Validators {
+		v1.Validators = append(v1.Validators, &shmsg.AppStateValidator{
+			PublicKey: []byte(pubkey.Ed25519pubkey),
+			Power:     power,
+		})
+	}
+	if v0.NonceTracker != nil {
+		v1.RandomNonces = encodeNonces(v0.NonceTracker.RandomNonces)
+		v1.ExpiringNonces = encodeNonces(v0.NonceTracker.ExpiringNonces)
+	}
+	// The order of repeated fields does not matter here, since the payload is decoded into maps
+	// right away.
+	return proto.Marshal(v1)
+}
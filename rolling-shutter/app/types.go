@@ -100,7 +100,7 @@ type ShutterApp struct {
 	DKGMap       map[uint64]*DKGInstance // map eon to DKGInstance
 	ConfigVoting ConfigVoting
 	// EonStartVotings map[uint64]*EonStartVoting
-	Gobpath         string
+	StatePath       string
 	LastSaved       time.Time
 	LastBlockHeight int64
 	Identities      map[common.Address]ValidatorPubkey
@@ -112,6 +112,11 @@ type ShutterApp struct {
 	NonceTracker    *NonceTracker
 	ChainID         string
 	ForkHeights     *ForkHeights
+
+	config    Config           // node local configuration, not persisted
+	restore   *snapshotRestore // snapshot currently being restored via state sync, not persisted
+	committed *committedState  // query responses for the last committed block, not persisted
+	changes   stateChanges     // changes since committed has been updated, not persisted
 }
 
 // CheckTxState is a part of the state used by CheckTx calls that is reset at every commit.
@@ -123,7 +128,13 @@ type CheckTxState struct {
 
 // NonceTracker tracks which nonces have been used and which have not.
 type NonceTracker struct {
+	// RandomNonces holds all nonces used before the state pruning fork. No nonces are added
+	// after the fork, and the ones whose random bits encode an expired block height are pruned
+	// like expiring nonces.
 	RandomNonces map[common.Address]map[uint64]bool
+	// ExpiringNonces holds nonces that encode a block height (see shmsg.NewNonce). They are
+	// removed once the block height is too old for the nonce to be accepted again.
+	ExpiringNonces map[common.Address]map[uint64]bool
 }
 
 type SenderReceiverPair struct {
@@ -143,27 +154,35 @@ type DKGInstance struct {
 
 // ForkHeights stores the configuration that controls when protocol forks
 // become active. A fork activates if the `Enabled` flag is true and the
-// current block height reaches `Height`, unless overridden in code for
-// specific chain IDs.
+// current block height reaches `Height` (or the current eon reaches `Eon` if
+// set), unless overridden for specific chain IDs (see Config).
+// Every fork needs a field here and an entry in the Forks registry.
 type ForkHeights struct {
 	// legacy and unused, only present for backwards compatible deserialization
 	CheckInUpdate *int64 `json:"checkInUpdate"`
 
 	CheckInUpdateNew ForkHeight `json:"checkInUpdateNew"`
+
+	// AppHash activates returning a hash of the app state from Commit and Info.
+	AppHash ForkHeight `json:"appHash"`
+
+	// StatePruning activates pruning of finalized DKG instances and expired nonces.
+	StatePruning ForkHeight `json:"statePruning"`
 }
 
 type ForkHeight struct {
 	Enabled bool
 	Height  int64
+	// Eon activates the fork at the given eon instead of at Height if set.
+	Eon *uint64
 }
 
-type ForkHeightOverrides struct {
-	CheckInUpdate *ForkHeightOverride
-}
+// ForkHeightOverrides maps fork names to the overrides for a single chain.
+type ForkHeightOverrides map[ForkName]*ForkHeightOverride
 
 type ForkHeightOverride struct {
-	Height *int64
-	Eon    *uint64
+	Height *int64  `json:"height,omitempty"`
+	Eon    *uint64 `json:"eon,omitempty"`
 }
 
 type (
@@ -15,6 +15,7 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/contract"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/contract/deployment"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/fx"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
 )
 
@@ -131,7 +132,7 @@ func bootstrap(config *Config) error {
 
 	threshold := cfg.Threshold
 
-	ms := fx.NewRPCMessageSender(shmcl, signingKey)
+	ms := fx.NewRPCMessageSender(shmcl, signer.NewLocal(signingKey))
 	activationBlockNumber := cfg.ActivationBlockNumber
 	batchConfigMsg := shmsg.NewBatchConfig(
 		activationBlockNumber,
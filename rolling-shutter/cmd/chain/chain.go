@@ -20,21 +20,45 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
 )
 
-var cfgFile string
+var (
+	cfgFile           string
+	forkOverridesFile string
+)
 
 func Cmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "chain",
 		Short: "Run a node for Shutter's Tendermint chain",
-		Long:  `This command runs a node that will connect to Shutter's Tendermint chain.`,
-		Args:  cobra.NoArgs,
+		Long: `This command runs a node that will connect to Shutter's Tendermint chain.
+
+Fork activations that have been decided on after a chain has been started are not part of its
+genesis file and have to be passed as fork height overrides instead. The overrides for the known
+chains are maintained in app/fork-overrides.json in the source repository and built in. Overrides
+read from a file take precedence over them.`,
+		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			chainMain()
 		},
 	}
 	cmd.Flags().StringVar(&cfgFile, "config", "", "config file (required)")
 	cmd.MarkFlagRequired("config")
+	cmd.Flags().Int64Var(
+		&app.SnapshotInterval,
+		"snapshot-interval",
+		app.SnapshotInterval,
+		"number of blocks between two state sync snapshots (0 disables snapshots)",
+	)
+	cmd.Flags().IntVar(&app.SnapshotKeepRecent, "snapshot-keep-recent", app.SnapshotKeepRecent, "number of state sync snapshots to keep")
+	cmd.Flags().StringVar(
+		&forkOverridesFile,
+		"fork-overrides",
+		"",
+		"JSON file with fork activations overriding the ones from the genesis file, keyed by chain id and fork name "+
+			"(default: "+app.ForkHeightOverridesFileName+" next to the genesis file, if present)",
+	)
 	cmd.AddCommand(initCmd())
+	cmd.AddCommand(queryCmd())
+	cmd.AddCommand(migrateStateCmd())
 	return cmd
 }
 
@@ -45,8 +69,13 @@ func chainMain() {
 		log.Error().Err(err).Msg("could not read config file")
 		os.Exit(2)
 	}
+	appConfig, err := readAppConfig(config)
+	if err != nil {
+		log.Error().Err(err).Msg("could not load fork height overrides")
+		os.Exit(2)
+	}
 
-	err = service.RunWithSighandler(context.Background(), &appService{config: config})
+	err = service.RunWithSighandler(context.Background(), &appService{config: config, appConfig: appConfig})
 	if err != nil {
 		log.Error().Err(err).Msg("service failed")
 		os.Exit(1)
@@ -54,7 +83,27 @@ func chainMain() {
 }
 
 type appService struct {
-	config *cfg.Config
+	config    *cfg.Config
+	appConfig app.Config
+}
+
+// readAppConfig reads the fork height overrides from the file given with --fork-overrides or, if
+// the flag is not set, from the directory of the genesis file if the file exists there. They are
+// applied on top of the built-in overrides of the known chains.
+func readAppConfig(config *cfg.Config) (app.Config, error) {
+	path := forkOverridesFile
+	if path == "" {
+		path = filepath.Join(filepath.Dir(config.GenesisFile()), app.ForkHeightOverridesFileName)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return app.Config{}, nil
+		}
+	}
+	overrides, err := app.ReadForkHeightOverrides(path)
+	if err != nil {
+		return app.Config{}, err
+	}
+	log.Info().Str("file", path).Int("chains", len(overrides)).Msg("loaded fork height overrides")
+	return app.Config{ForkHeightOverrides: overrides}, nil
 }
 
 func (as *appService) Start(ctx context.Context, runner service.Runner) error {
@@ -63,9 +112,7 @@ func (as *appService) Start(ctx context.Context, runner service.Runner) error {
 		return errors.Wrap(err, "failed to create tendermint logger")
 	}
 
-	shapp, err := app.LoadShutterAppFromFile(
-		filepath.Join(as.config.DBDir(), "shutter.gob"),
-	)
+	shapp, err := loadShutterApp(as.config.DBDir(), as.appConfig)
 	if err != nil {
 		return err
 	}
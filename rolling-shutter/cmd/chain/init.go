@@ -47,12 +47,12 @@ type Config struct {
 	Forks         ForkConfig `mapstructure:"forks"`
 }
 
-type ForkConfig struct {
-	CheckInUpdate Fork `mapstructure:"check-in-update"`
-}
+// ForkConfig maps the flag names of the forks in app.Forks to their configuration.
+type ForkConfig map[string]Fork
 
 type Fork struct {
 	Height   int64 `mapstructure:"height"`
+	Eon      int64 `mapstructure:"eon"` // activate by eon instead of height if not negative
 	Disabled bool  `mapstructure:"disabled"`
 }
 
@@ -83,8 +83,20 @@ func initCmd() *cobra.Command {
 	cmd.PersistentFlags().String("listen-address", "tcp://127.0.0.1:26657", "tendermint RPC listen address")
 	cmd.PersistentFlags().String("role", "validator", "tendermint node role (validator, isolated-validator, sentry, seed)")
 	cmd.PersistentFlags().Uint64("initial-eon", 0, "initial eon")
-	cmd.PersistentFlags().Int64("forks.check-in-update.height", 0, "block height at which to activate the check-in update fork")
-	cmd.PersistentFlags().Bool("forks.check-in-update.disabled", false, "whether the check-in update fork is disabled")
+	for _, fork := range app.Forks {
+		prefix := "forks." + fork.FlagName
+		cmd.PersistentFlags().Int64(
+			prefix+".height",
+			0,
+			fmt.Sprintf("block height at which to activate the %s fork (%s)", fork.FlagName, fork.Description),
+		)
+		cmd.PersistentFlags().Int64(
+			prefix+".eon",
+			-1,
+			fmt.Sprintf("eon at which to activate the %s fork instead of a block height (if not negative)", fork.FlagName),
+		)
+		cmd.PersistentFlags().Bool(prefix+".disabled", false, fmt.Sprintf("whether the %s fork is disabled", fork.FlagName))
+	}
 	return cmd
 }
 
@@ -169,18 +181,35 @@ func initFiles(_ *cobra.Command, config *Config, _ []string) error {
 	// let's overwrite it.
 	cfg.WriteConfigFile(config.RootDir+"/config/config.toml", tendermintCfg)
 	// Initialize fork heights according to config.
-	forkHeights := app.NewForkHeightsAllDisabled()
-	if !config.Forks.CheckInUpdate.Disabled {
-		forkHeights.CheckInUpdateNew = app.ForkHeight{
-			Enabled: true,
-			Height:  config.Forks.CheckInUpdate.Height,
-		}
+	forkHeights, err := newForkHeights(config.Forks)
+	if err != nil {
+		return err
 	}
 	appState := app.NewGenesisAppState(keypers, (2*len(keypers)+2)/3, config.InitialEon, forkHeights)
 
 	return initFilesWithConfig(tendermintCfg, config, appState)
 }
 
+// newForkHeights creates the fork heights to store in the genesis file.
+func newForkHeights(config ForkConfig) (*app.ForkHeights, error) {
+	forkHeights := app.NewForkHeightsAllDisabled()
+	for _, fork := range app.Forks {
+		forkConfig, ok := config[fork.FlagName]
+		if !ok || forkConfig.Disabled {
+			continue
+		}
+		forkHeight := app.ForkHeight{Enabled: true, Height: forkConfig.Height}
+		if forkConfig.Eon >= 0 {
+			eon := uint64(forkConfig.Eon)
+			forkHeight.Eon = &eon
+		}
+		if err := forkHeights.Set(fork.Name, forkHeight); err != nil {
+			return nil, err
+		}
+	}
+	return forkHeights, nil
+}
+
 func adjustPort(address string, keyperIndex int) (string, error) {
 	substrings := strings.Split(address, ":")
 	if len(substrings) < 2 {
@@ -284,13 +313,14 @@ func initFilesWithConfig(tendermintConfig *cfg.Config, config *Config, appState
 		log.Info().Str("path", nodeKeyFile).Str("id", string(nodeid)).Msg("Generated node key")
 	}
 
-	gobPath := filepath.Join(tendermintConfig.DBDir(), "shutter.gob")
+	statePath := filepath.Join(tendermintConfig.DBDir(), stateFileName)
+	legacyPath := filepath.Join(tendermintConfig.DBDir(), legacyStateFileName)
 
-	if tmos.FileExists(gobPath) {
-		log.Warn().Str("path", gobPath).Msg("Shutter app state file already exists")
+	if tmos.FileExists(statePath) || tmos.FileExists(legacyPath) {
+		log.Warn().Str("path", tendermintConfig.DBDir()).Msg("Shutter app state file already exists")
 	} else {
-		a := app.NewShutterApp()
-		a.Gobpath = gobPath
+		a := app.NewShutterApp(app.Config{})
+		a.StatePath = statePath
 		a.DevMode = config.DevMode
 		err = a.PersistToDisk()
 		if err != nil {
@@ -0,0 +1,78 @@
+package chain
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/app"
+)
+
+const (
+	stateFileName       = "shutter.state"
+	legacyStateFileName = "shutter.gob"
+)
+
+func migrateStateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-state <input> [output]",
+		Short: "Convert a persisted shuttermint app state to the current format",
+		Long: `This command reads the shuttermint app state from the input file, which may be
+written in any earlier format (e.g. a legacy shutter.gob file), and writes it in
+the current format to the output file. If no output file is given, the state is
+written to shutter.state next to the input file.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output := filepath.Join(filepath.Dir(args[0]), stateFileName)
+			if len(args) == 2 {
+				output = args[1]
+			}
+			return migrateState(args[0], output)
+		},
+	}
+	return cmd
+}
+
+func migrateState(input, output string) error {
+	if filepath.Clean(input) == filepath.Clean(output) {
+		return errors.New("input and output file must differ")
+	}
+	if _, err := os.Stat(output); err == nil {
+		return errors.Errorf("output file %s already exists", output)
+	}
+	version, err := app.MigrateStateFile(input, output)
+	if err != nil {
+		return errors.Wrapf(err, "failed to migrate app state from %s", input)
+	}
+	log.Info().
+		Str("input", input).
+		Str("output", output).
+		Uint32("from-version", version).
+		Uint32("to-version", app.AppStateVersion).
+		Msg("migrated app state")
+	return nil
+}
+
+// loadShutterApp loads the app state from the state file in dbDir. If only a legacy state file
+// exists, it is loaded instead and the state is written to the new state file from now on.
+func loadShutterApp(dbDir string, config app.Config) (app.ShutterApp, error) {
+	statePath := filepath.Join(dbDir, stateFileName)
+	legacyPath := filepath.Join(dbDir, legacyStateFileName)
+	if _, err := os.Stat(statePath); os.IsNotExist(err) {
+		if _, err := os.Stat(legacyPath); err == nil {
+			log.Warn().
+				Str("path", legacyPath).
+				Msg("loading legacy app state file, run 'chain migrate-state' to convert it ahead of time")
+			shapp, err := app.LoadShutterAppFromFile(legacyPath, config)
+			if err != nil {
+				return shapp, err
+			}
+			shapp.StatePath = statePath
+			return shapp, nil
+		}
+	}
+	return app.LoadShutterAppFromFile(statePath, config)
+}
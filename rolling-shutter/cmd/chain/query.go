@@ -0,0 +1,72 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/tendermint/rpc/client"
+	"github.com/tendermint/tendermint/rpc/client/http"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/app"
+)
+
+var (
+	queryShuttermintURL string
+	queryHeight         int64
+)
+
+func queryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query path",
+		Short: "Query the state of a running Shuttermint node",
+		Long: fmt.Sprintf(`This command queries the state of the Shuttermint app at the latest committed
+block height and prints the JSON encoded result. The following paths are supported:
+
+  %s`, strings.Join(app.QueryPaths, "\n  ")),
+		Example: "rolling-shutter chain query /dkg/1",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return query(cmd.Context(), args[0])
+		},
+	}
+	cmd.Flags().StringVarP(
+		&queryShuttermintURL,
+		"shuttermint-url",
+		"s",
+		"http://localhost:26657",
+		"Shuttermint RPC URL",
+	)
+	cmd.Flags().Int64Var(
+		&queryHeight,
+		"height",
+		0,
+		"block height to query (0 for the latest committed height)",
+	)
+	return cmd
+}
+
+func query(ctx context.Context, path string) error {
+	cl, err := http.New(queryShuttermintURL, "/websocket")
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to Shuttermint node at %s", queryShuttermintURL)
+	}
+	res, err := cl.ABCIQueryWithOptions(ctx, path, nil, client.ABCIQueryOptions{Height: queryHeight})
+	if err != nil {
+		return errors.Wrap(err, "failed to query Shuttermint node")
+	}
+	if res.Response.Code != 0 {
+		return errors.Errorf("query failed at height %d: %s", res.Response.Height, res.Response.Log)
+	}
+
+	var value bytes.Buffer
+	if err := json.Indent(&value, res.Response.Value, "", "  "); err != nil {
+		return errors.Wrap(err, "failed to decode query response")
+	}
+	fmt.Println(value.String())
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/gnosisaccessnode"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/gnosiskeyper"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/optimism"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/p2pcmd"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/p2pnode"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/primevkeyper"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/shutterservicekeyper"
@@ -29,6 +30,7 @@ func Subcommands() []*cobra.Command {
 		gnosisaccessnode.Cmd(),
 		cryptocmd.Cmd(),
 		p2pnode.Cmd(),
+		p2pcmd.Cmd(),
 		shutterservicekeyper.Cmd(),
 		primevkeyper.Cmd(),
 	}
@@ -29,12 +29,14 @@ func Cmd() *cobra.Command {
 		Short: "CLI tool to access crypto functions",
 		Long: `This command provides utility functions to manually encrypt messages with an eon
 key, decrypt them with a decryption key, and check that a decryption key is correct. It also hosts
-a tool to generate and run crypto tests in a JSON formatted collection.`,
+a tool to generate and run crypto tests in a JSON formatted collection, and a compiler for event
+trigger definitions.`,
 	}
 	cmd.AddCommand(encryptCmd())
 	cmd.AddCommand(decryptCmd())
 	cmd.AddCommand(verifyKeyCmd())
 	cmd.AddCommand(aggregateCmd())
+	cmd.AddCommand(triggerCmd())
 	cmd.AddCommand(GenerateTestdata())
 	cmd.AddCommand(RunJSONTests())
 	return cmd
@@ -0,0 +1,242 @@
+package cryptocmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/shutterservice"
+)
+
+var (
+	contractFlag string
+	eventFlags   []string
+)
+
+func triggerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trigger",
+		Short: "Compile, decode and explain event trigger definitions",
+		Long: `This command converts between event trigger definitions as registered with the
+event trigger registry and their description in the event trigger DSL, e.g.,
+
+  Transfer(address indexed from, address indexed to, uint256 value) where to == 0xabc.. and value >= 1e18
+
+The event signature determines the topic and data offsets of the arguments and implies a
+condition on the event id. The where clause combines comparisons of the arguments using and, or,
+not and parentheses. Comparisons are ==, !=, in [..], <, <=, >, >=, between .. and .., hasall
+and hasany for bit masks, and prefix and contains for non-indexed bytes and strings. Topics and
+data words can also be referenced directly as topic[i], word[i] and bytes[i].`,
+	}
+	cmd.AddCommand(triggerCompileCmd())
+	cmd.AddCommand(triggerDecodeCmd())
+	cmd.AddCommand(triggerExplainCmd())
+	return cmd
+}
+
+func triggerCompileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compile",
+		Short: "Compile the event trigger given in the DSL as positional argument to its definition",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return compileTrigger(args[0])
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&contractFlag, "contract", "c", "", "address of the contract emitting the events")
+	cmd.MarkPersistentFlagRequired("contract")
+
+	return cmd
+}
+
+func triggerDecodeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "decode",
+		Short: "Decode the event trigger definition given as positional argument to JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return decodeTrigger(args[0])
+		},
+	}
+}
+
+func triggerExplainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain",
+		Short: "Describe the event trigger definition given as positional argument in the DSL",
+		Long: `Describe the event trigger definition given as positional argument in the DSL.
+
+Conditions on events whose signature is passed with --event are described using the names of the
+event's arguments, all others with raw references to topics and data words.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return explainTrigger(args[0])
+		},
+	}
+
+	cmd.PersistentFlags().StringArrayVarP(&eventFlags, "event", "e", nil,
+		"event signature, e.g., \"Transfer(address indexed from, address indexed to, uint256 value)\"")
+
+	return cmd
+}
+
+func compileTrigger(source string) error {
+	if !common.IsHexAddress(contractFlag) {
+		return errors.Errorf("invalid contract address %s", contractFlag)
+	}
+	definition, err := shutterservice.CompileEventTrigger(common.HexToAddress(contractFlag), source)
+	if err != nil {
+		return err
+	}
+	fmt.Println("0x" + hex.EncodeToString(definition.MarshalBytes()))
+	return nil
+}
+
+func decodeTrigger(definitionHex string) error {
+	definition, err := parseTriggerDefinition(definitionHex)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(newDefinitionJSON(definition), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
+
+func explainTrigger(definitionHex string) error {
+	definition, err := parseTriggerDefinition(definitionHex)
+	if err != nil {
+		return err
+	}
+	events := []*abi.Event{}
+	for _, signature := range eventFlags {
+		event, err := shutterservice.ParseEventSignature(signature)
+		if err != nil {
+			return errors.Wrapf(err, "invalid event signature %q", signature)
+		}
+		events = append(events, event)
+	}
+	fmt.Print(shutterservice.ExplainEventTrigger(definition, events))
+	return nil
+}
+
+func parseTriggerDefinition(definitionHex string) (*shutterservice.EventTriggerDefinition, error) {
+	definitionBytes, err := parseHex(definitionHex)
+	if err != nil {
+		return nil, err
+	}
+	definition := &shutterservice.EventTriggerDefinition{}
+	if err := definition.UnmarshalBytes(definitionBytes); err != nil {
+		return nil, errors.Wrap(err, "invalid event trigger definition")
+	}
+	return definition, nil
+}
+
+// definitionJSON is the JSON representation of event trigger definitions printed by the decode
+// command.
+type definitionJSON struct {
+	Version       byte               `json:"version"`
+	Contract      *common.Address    `json:"contract,omitempty"`
+	LogPredicates []logPredicateJSON `json:"logPredicates,omitempty"`
+	Condition     *predicateNodeJSON `json:"condition,omitempty"`
+	MultiEvent    *multiEventJSON    `json:"multiEvent,omitempty"`
+}
+
+type logPredicateJSON struct {
+	LogValueRef    logValueRefJSON    `json:"logValueRef"`
+	ValuePredicate valuePredicateJSON `json:"valuePredicate"`
+}
+
+type logValueRefJSON struct {
+	Dynamic bool   `json:"dynamic"`
+	Offset  uint64 `json:"offset"`
+}
+
+type valuePredicateJSON struct {
+	Op       uint64          `json:"op"`
+	IntArgs  []*big.Int      `json:"intArgs"`
+	ByteArgs []hexutil.Bytes `json:"byteArgs"`
+}
+
+type predicateNodeJSON struct {
+	Kind     uint64               `json:"kind"`
+	Leaf     *logPredicateJSON    `json:"leaf,omitempty"`
+	Children []*predicateNodeJSON `json:"children,omitempty"`
+}
+
+type multiEventJSON struct {
+	Combinator uint64             `json:"combinator"`
+	Count      uint64             `json:"count"`
+	Window     uint64             `json:"window"`
+	Conditions []logConditionJSON `json:"conditions"`
+}
+
+type logConditionJSON struct {
+	Contract  common.Address     `json:"contract"`
+	Condition *predicateNodeJSON `json:"condition"`
+}
+
+func newDefinitionJSON(definition *shutterservice.EventTriggerDefinition) definitionJSON {
+	d := definitionJSON{Version: definition.MarshalBytes()[0]}
+	if definition.MultiEvent != nil {
+		m := definition.MultiEvent
+		d.MultiEvent = &multiEventJSON{
+			Combinator: uint64(m.Combinator),
+			Count:      m.Count,
+			Window:     m.Window,
+			Conditions: []logConditionJSON{},
+		}
+		for _, condition := range m.Conditions {
+			d.MultiEvent.Conditions = append(d.MultiEvent.Conditions, logConditionJSON{
+				Contract:  condition.Contract,
+				Condition: newPredicateNodeJSON(condition.Condition),
+			})
+		}
+		return d
+	}
+	d.Contract = &definition.Contract
+	for i := range definition.LogPredicates {
+		d.LogPredicates = append(d.LogPredicates, newLogPredicateJSON(&definition.LogPredicates[i]))
+	}
+	if definition.Condition != nil {
+		d.Condition = newPredicateNodeJSON(definition.Condition)
+	}
+	return d
+}
+
+func newPredicateNodeJSON(node *shutterservice.PredicateNode) *predicateNodeJSON {
+	n := &predicateNodeJSON{Kind: uint64(node.Kind)}
+	if node.Leaf != nil {
+		leaf := newLogPredicateJSON(node.Leaf)
+		n.Leaf = &leaf
+	}
+	for _, child := range node.Children {
+		n.Children = append(n.Children, newPredicateNodeJSON(child))
+	}
+	return n
+}
+
+func newLogPredicateJSON(p *shutterservice.LogPredicate) logPredicateJSON {
+	byteArgs := []hexutil.Bytes{}
+	for _, arg := range p.ValuePredicate.ByteArgs {
+		byteArgs = append(byteArgs, arg)
+	}
+	return logPredicateJSON{
+		LogValueRef: logValueRefJSON{Dynamic: p.LogValueRef.Dynamic, Offset: p.LogValueRef.Offset},
+		ValuePredicate: valuePredicateJSON{
+			Op:       uint64(p.ValuePredicate.Op),
+			IntArgs:  p.ValuePredicate.IntArgs,
+			ByteArgs: byteArgs,
+		},
+	}
+}
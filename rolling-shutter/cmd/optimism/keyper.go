@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/shversion"
+	corekeyper "github.com/shutter-network/rolling-shutter/rolling-shutter/keyper"
 	keyper "github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/optimism"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/optimism/config"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/optimism/database"
@@ -28,6 +29,24 @@ Shuttermint node which have to be started separately in advance.`,
 		command.WithGenerateConfigSubcommand(),
 	)
 	builder.AddInitDBCommand(initDB)
+	builder.AddFunctionSubcommand(
+		rotateSecretsKey,
+		"rotate-secrets-key",
+		"Encrypt all secrets in the database with the current key from the secrets config",
+		cobra.NoArgs,
+	)
+	builder.AddFunctionWithArgsSubcommand(
+		backup,
+		"backup <file>",
+		"Write an encrypted backup of the keyper's DKG state to the given file",
+		cobra.ExactArgs(1),
+	)
+	builder.AddFunctionWithArgsSubcommand(
+		restore,
+		"restore <file>",
+		"Restore a backup into a freshly initialized database",
+		cobra.ExactArgs(1),
+	)
 	return builder.Command()
 }
 
@@ -54,3 +73,15 @@ func initDB(cfg *config.Config) error {
 
 	return db.InitDB(ctx, dbpool, database.Definition.Name(), database.Definition)
 }
+
+func rotateSecretsKey(cfg *config.Config) error {
+	return corekeyper.RotateSecretsKey(context.Background(), cfg.DatabaseURL, cfg.Secrets)
+}
+
+func backup(cfg *config.Config, args []string) error {
+	return corekeyper.Backup(context.Background(), cfg.DatabaseURL, cfg.GetAddress(), cfg.InstanceID, cfg.Secrets, args[0])
+}
+
+func restore(cfg *config.Config, args []string) error {
+	return corekeyper.Restore(context.Background(), cfg.DatabaseURL, cfg.GetAddress(), cfg.InstanceID, cfg.Secrets, args[0])
+}
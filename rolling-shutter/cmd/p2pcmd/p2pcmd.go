@@ -0,0 +1,84 @@
+package p2pcmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	keyper "github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration/command"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/db"
+)
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "p2p",
+		Short: "Tools to inspect the p2p messaging of Shutter nodes",
+	}
+	cmd.AddCommand(replayCmd())
+	return cmd
+}
+
+func replayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay <capture-file>",
+		Short: "Feed a gossip capture file through the message handlers of a Gnosis keyper",
+		Long: `This command reads the messages of a capture file written by a node with
+P2P.Capture enabled and runs them through the validators and handlers a Gnosis
+keyper registers, in the order they have been received. Messages sent in response
+are dropped. The config file is the keyper's one, but its DatabaseURL should point
+to a scratch database, e.g. one restored from a backup, as handling the messages
+modifies the database.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := keyper.NewConfig()
+			err := command.ParseCLI(nil, cmd, cfg)
+			if err != nil {
+				return errors.WithMessage(err, "Please check your configuration")
+			}
+			return replay(cfg, args[0])
+		},
+	}
+	command.CommandAddConfigFileFlag(cmd)
+	return cmd
+}
+
+func replay(cfg *keyper.Config, path string) error {
+	ctx := context.Background()
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open capture file")
+	}
+	defer file.Close()
+
+	dbpool, err := pgxpool.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+	err = db.ValidateDBVersion(ctx, dbpool, database.Definition.Name())
+	if err != nil {
+		return err
+	}
+
+	messaging, err := keyper.NewReplayMessaging(ctx, cfg, dbpool)
+	if err != nil {
+		return err
+	}
+	stats, err := messaging.Replay(ctx, file)
+	log.Info().
+		Int("total", stats.Total).
+		Int("accepted", stats.Accepted).
+		Int("rejected", stats.Rejected).
+		Int("ignored", stats.Ignored).
+		Int("skipped", stats.Skipped).
+		Int("diverged", stats.Diverged).
+		Int("failed", stats.Failed).
+		Msg("replayed capture file")
+	return err
+}
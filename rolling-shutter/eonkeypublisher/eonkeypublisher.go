@@ -2,14 +2,11 @@ package eonkeypublisher
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	ethcrypto "github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -18,9 +15,11 @@ import (
 	obskeyperdb "github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver/db/keyper"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper"
 	corekeyperdb "github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
+	syncclient "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 )
 
 const (
@@ -31,18 +30,20 @@ const (
 // EonKeyPublisher is a service that publishes eon keys via a eon key publisher contract.
 type EonKeyPublisher struct {
 	dbpool           *pgxpool.Pool
-	client           *ethclient.Client
+	client           syncclient.Client
 	keyperSetManager *bindings.KeyperSetManager
-	privateKey       *ecdsa.PrivateKey
+	signer           signer.Signer
+	keyring          *kprsecrets.Keyring
 
 	keys chan keyper.EonPublicKey
 }
 
 func NewEonKeyPublisher(
 	dbpool *pgxpool.Pool,
-	client *ethclient.Client,
+	client syncclient.Client,
 	keyperSetManagerAddress common.Address,
-	privateKey *ecdsa.PrivateKey,
+	s signer.Signer,
+	keyring *kprsecrets.Keyring,
 ) (*EonKeyPublisher, error) {
 	keyperSetManager, err := bindings.NewKeyperSetManager(keyperSetManagerAddress, client)
 	if err != nil {
@@ -52,7 +53,8 @@ func NewEonKeyPublisher(
 		dbpool:           dbpool,
 		client:           client,
 		keyperSetManager: keyperSetManager,
-		privateKey:       privateKey,
+		signer:           s,
+		keyring:          keyring,
 
 		keys: make(chan keyper.EonPublicKey, eonKeyChannelSize),
 	}, nil
@@ -92,7 +94,7 @@ func (p *EonKeyPublisher) publishIfResponsible(ctx context.Context, key keyper.E
 			Msg("failed to check if eon key should be published")
 		return
 	}
-	keyperAddress := ethcrypto.PubkeyToAddress(p.privateKey.PublicKey)
+	keyperAddress := p.signer.Address()
 	keyperIndex, err := keyperSet.GetIndex(keyperAddress)
 	if err != nil {
 		log.Info().
@@ -119,7 +121,7 @@ func (p *EonKeyPublisher) publishOldKeys(ctx context.Context) {
 		if !dkgResultDB.Success {
 			continue
 		}
-		dkgResult, err := shdb.DecodePureDKGResult(dkgResultDB.PureResult)
+		dkgResult, err := corekeyperdb.DecodePureDKGResult(p.keyring, dkgResultDB.PureResult)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -159,7 +161,7 @@ func (p *EonKeyPublisher) tryPublish(ctx context.Context, key []byte, keyperSetI
 	if err != nil {
 		return err
 	}
-	keyperAddress := ethcrypto.PubkeyToAddress(p.privateKey.PublicKey)
+	keyperAddress := p.signer.Address()
 	hasAlreadyVoted, err := contract.HasKeyperVoted(&bind.CallOpts{}, keyperAddress)
 	if err != nil {
 		return errors.Wrap(err, "failed to query eon key publisher contract if keyper has already voted")
@@ -188,10 +190,7 @@ func (p *EonKeyPublisher) tryPublish(ctx context.Context, key []byte, keyperSetI
 	if err != nil {
 		return errors.Wrap(err, "failed to get chain ID")
 	}
-	opts, err := bind.NewKeyedTransactorWithChainID(p.privateKey, chainID)
-	if err != nil {
-		return errors.Wrap(err, "failed to construct tx opts")
-	}
+	opts := signer.NewTransactor(ctx, p.signer, chainID)
 	tx, err := contract.PublishEonKey(opts, key, keyperIndex)
 	if err != nil {
 		return errors.Wrap(err, "failed to send publish eon key tx")
@@ -3,6 +3,8 @@ package gnosisaccessnode
 import (
 	"io"
 
+	"github.com/pkg/errors"
+
 	gnosiskeyper "github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
@@ -17,7 +19,8 @@ type Config struct {
 	P2P        *p2p.Config
 	Metrics    *metricsserver.MetricsConfig
 
-	MaxNumKeysPerMessage uint64
+	MaxNumKeysPerMessage   uint64
+	KeyperStatusStaleAfter uint64 `comment:"seconds after which a keyper without a newer status is considered down"`
 }
 
 func (c *Config) Init() {
@@ -38,11 +41,15 @@ func (c *Config) Validate() error {
 	if err := c.Metrics.Validate(); err != nil {
 		return err
 	}
+	if c.KeyperStatusStaleAfter == 0 {
+		return errors.New("KeyperStatusStaleAfter must be positive")
+	}
 	return nil
 }
 
 func (c *Config) SetDefaultValues() error {
 	c.P2P.IsAccessNode = true
+	c.KeyperStatusStaleAfter = 120
 	return nil
 }
 
@@ -15,14 +15,16 @@ import (
 )
 
 type DecryptionKeysHandler struct {
-	config  *Config
-	storage *Storage
+	config     *Config
+	storage    *Storage
+	backfiller *gnosis.SlotBackfiller
 }
 
-func NewDecryptionKeysHandler(config *Config, storage *Storage) *DecryptionKeysHandler {
+func NewDecryptionKeysHandler(config *Config, storage *Storage, backfiller *gnosis.SlotBackfiller) *DecryptionKeysHandler {
 	return &DecryptionKeysHandler{
-		config:  config,
-		storage: storage,
+		config:     config,
+		storage:    storage,
+		backfiller: backfiller,
 	}
 }
 
@@ -109,6 +111,9 @@ func (handler *DecryptionKeysHandler) validateGnosisFields(keys *p2pmsg.Decrypti
 	return pubsub.ValidationAccept, nil
 }
 
-func (handler *DecryptionKeysHandler) HandleMessage(_ context.Context, _ p2pmsg.Message) ([]p2pmsg.Message, error) {
+func (handler *DecryptionKeysHandler) HandleMessage(_ context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	keys := msg.(*p2pmsg.DecryptionKeys)
+	extra := keys.Extra.(*p2pmsg.DecryptionKeys_Gnosis).Gnosis
+	handler.backfiller.Observe(keys.Eon, extra.Slot)
 	return nil, nil
 }
@@ -2,15 +2,20 @@ package gnosisaccessnode
 
 import (
 	"context"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
 	"github.com/shutter-network/shutter/shlib/shcrypto"
 
 	obskeyperdatabase "github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver/db/keyper"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprstatus"
+	gnosiskeyper "github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync"
 	syncevent "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/event"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/failover"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
@@ -36,12 +41,16 @@ func (node *GnosisAccessNode) Start(ctx context.Context, runner service.Runner)
 	if err != nil {
 		return errors.Wrap(err, "failed to initialize p2p messaging")
 	}
-	messageSender.AddMessageHandler(NewDecryptionKeysHandler(node.config, node.storage))
-	services = append(services, messageSender)
+	backfiller := gnosiskeyper.NewSlotBackfiller(messageSender, node.config.InstanceID)
+	messageSender.AddMessageHandler(NewDecryptionKeysHandler(node.config, node.storage, backfiller))
+	staleAfter := time.Duration(node.config.KeyperStatusStaleAfter) * time.Second //nolint:gosec // G115
+	statusView := kprstatus.NewView(staleAfter)
+	messageSender.AddMessageHandler(kprstatus.NewHandler(node.config.InstanceID, node.keyperSetMembers, statusView))
+	services = append(services, messageSender, backfiller)
 
 	chainSyncClient, err := chainsync.NewClient(
 		ctx,
-		chainsync.WithClientURL(node.config.GnosisNode.EthereumURL),
+		chainsync.WithClientURLs(node.config.GnosisNode.EthereumURLs()...),
 		chainsync.WithKeyperSetManager(node.config.Contracts.KeyperSetManager),
 		chainsync.WithKeyBroadcastContract(node.config.Contracts.KeyBroadcastContract),
 		chainsync.WithSyncNewKeyperSet(node.onNewKeyperSet),
@@ -53,6 +62,8 @@ func (node *GnosisAccessNode) Start(ctx context.Context, runner service.Runner)
 	services = append(services, chainSyncClient)
 
 	if node.config.Metrics.Enabled {
+		kprstatus.InitMetrics(statusView)
+		failover.InitMetrics()
 		metricsServer := metricsserver.New(node.config.Metrics)
 		services = append(services, metricsServer)
 	}
@@ -60,6 +71,19 @@ func (node *GnosisAccessNode) Start(ctx context.Context, runner service.Runner)
 	return runner.StartService(services...)
 }
 
+// keyperSetMembers returns the members of the keyper set with the eon as keyper config index.
+func (node *GnosisAccessNode) keyperSetMembers(_ context.Context, eon uint64) ([]common.Address, bool, error) {
+	keyperSet, ok := node.storage.GetKeyperSet(eon)
+	if !ok {
+		return nil, false, nil
+	}
+	members, err := shdb.DecodeAddresses(keyperSet.Keypers)
+	if err != nil {
+		return nil, false, err
+	}
+	return members, true, nil
+}
+
 func (node *GnosisAccessNode) onNewKeyperSet(_ context.Context, keyperSet *syncevent.KeyperSet) error {
 	obsKeyperSet := obskeyperdatabase.KeyperSet{
 		KeyperConfigIndex:     int64(keyperSet.Eon),
@@ -22,6 +22,8 @@ type KeysWatcher struct {
 	recentBlocksMux sync.Mutex
 	recentBlocks    map[uint64]*BlockReceivedEvent
 	mostRecentBlock uint64
+
+	backfiller *keyper.SlotBackfiller
 }
 
 func NewKeysWatcher(config *keyper.Config, blocksChannel chan *BlockReceivedEvent) *KeysWatcher {
@@ -40,10 +42,11 @@ func (w *KeysWatcher) Start(ctx context.Context, runner service.Runner) error {
 		return err
 	}
 	p2pService.AddMessageHandler(w)
+	w.backfiller = keyper.NewSlotBackfiller(p2pService, w.config.InstanceID)
 
 	runner.Go(func() error { return w.insertBlocks(ctx) })
 
-	return runner.StartService(p2pService)
+	return runner.StartService(p2pService, w.backfiller)
 }
 
 func (w *KeysWatcher) MessagePrototypes() []p2pmsg.Message {
@@ -60,6 +63,7 @@ func (w *KeysWatcher) HandleMessage(_ context.Context, msgUntyped p2pmsg.Message
 	t := time.Now()
 	msg := msgUntyped.(*p2pmsg.DecryptionKeys)
 	extra := msg.Extra.(*p2pmsg.DecryptionKeys_Gnosis).Gnosis
+	w.backfiller.Observe(msg.Eon, extra.Slot)
 
 	ev, ok := w.getRecentBlock(extra.Slot)
 	if !ok {
@@ -0,0 +1,453 @@
+package keyper
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprconfig"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/db"
+)
+
+// A backup contains the state the keyper derived from shuttermint, most importantly the DKG
+// state and results holding the eon secret key shares. This is the minimum needed to keep serving
+// key shares for current and future eons. Everything else, e.g. the state synced from the
+// Ethereum chain, is restored by the keyper itself when it is started. The data is sealed with
+// the key from the secrets config. The archive is versioned, so that backups taken with older
+// versions can still be restored.
+
+const (
+	backupMagic = "shutter-keyper-backup"
+	// BackupVersion is the version of the backup archives created by Backup.
+	BackupVersion = 1
+)
+
+// backupArchive is the outer, unencrypted part of a backup file.
+type backupArchive struct {
+	Magic   string `json:"magic"`
+	Version uint32 `json:"version"`
+	// KeyperAddress is informational only, the authenticated address is part of the sealed data.
+	KeyperAddress string    `json:"keyperAddress"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Data          []byte    `json:"data"`
+}
+
+type backupBatchConfig struct {
+	KeyperConfigIndex     int32    `json:"keyperConfigIndex"`
+	Height                int64    `json:"height"`
+	Keypers               []string `json:"keypers"`
+	Threshold             int32    `json:"threshold"`
+	Started               bool     `json:"started"`
+	ActivationBlockNumber int64    `json:"activationBlockNumber"`
+}
+
+type backupEncryptionKey struct {
+	Address             string `json:"address"`
+	EncryptionPublicKey []byte `json:"encryptionPublicKey"`
+	Height              int64  `json:"height"`
+}
+
+type backupEon struct {
+	Eon                   int64 `json:"eon"`
+	Height                int64 `json:"height"`
+	ActivationBlockNumber int64 `json:"activationBlockNumber"`
+	KeyperConfigIndex     int64 `json:"keyperConfigIndex"`
+}
+
+type backupPureDKG struct {
+	Eon     int64  `json:"eon"`
+	PureDKG []byte `json:"pureDKG"`
+}
+
+type backupDKGResult struct {
+	Eon        int64  `json:"eon"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	PureResult []byte `json:"pureResult,omitempty"`
+}
+
+type backupPolyEval struct {
+	Eon             int64  `json:"eon"`
+	ReceiverAddress string `json:"receiverAddress"`
+	Eval            []byte `json:"eval"`
+}
+
+// backupData is the sealed part of a backup. Secret columns are stored in plaintext, they are
+// sealed with the keyring of the restoring keyper.
+type backupData struct {
+	KeyperAddress       string                `json:"keyperAddress"`
+	InstanceID          uint64                `json:"instanceID"`
+	CurrentBlock        int64                 `json:"currentBlock"`
+	LastCommittedHeight int64                 `json:"lastCommittedHeight"`
+	LastBatchConfigSent int64                 `json:"lastBatchConfigSent"`
+	LastBlockSeen       int64                 `json:"lastBlockSeen"`
+	BatchConfigs        []backupBatchConfig   `json:"batchConfigs"`
+	EncryptionKeys      []backupEncryptionKey `json:"encryptionKeys"`
+	Eons                []backupEon           `json:"eons"`
+	PureDKGs            []backupPureDKG       `json:"pureDKGs"`
+	DKGResults          []backupDKGResult     `json:"dkgResults"`
+	PolyEvals           []backupPolyEval      `json:"polyEvals"`
+}
+
+func encodeBackup(data *backupData, keyring *kprsecrets.Keyring) ([]byte, error) {
+	if keyring == nil {
+		return nil, errors.Wrap(kprsecrets.ErrNoKey, "backups must be encrypted")
+	}
+	plaintext, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := keyring.Seal(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(backupArchive{
+		Magic:         backupMagic,
+		Version:       BackupVersion,
+		KeyperAddress: data.KeyperAddress,
+		CreatedAt:     time.Now().UTC(),
+		Data:          sealed,
+	}, "", "  ")
+}
+
+func decodeBackup(raw []byte, keyring *kprsecrets.Keyring) (*backupData, error) {
+	archive := backupArchive{}
+	if err := json.Unmarshal(raw, &archive); err != nil || archive.Magic != backupMagic {
+		return nil, errors.New("not a keyper backup")
+	}
+	if archive.Version != BackupVersion {
+		return nil, errors.Errorf("unsupported backup version %d, expected %d", archive.Version, BackupVersion)
+	}
+	if !kprsecrets.IsSealed(archive.Data) {
+		return nil, errors.New("backup data is not encrypted")
+	}
+	if keyring == nil {
+		return nil, errors.Wrap(kprsecrets.ErrNoKey, "backups are encrypted")
+	}
+	plaintext, err := keyring.Open(archive.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt backup")
+	}
+	data := &backupData{}
+	decoder := json.NewDecoder(bytes.NewReader(plaintext))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(data); err != nil {
+		return nil, errors.Wrap(err, "failed to decode backup")
+	}
+	return data, nil
+}
+
+// readBackupData reads the state to back up from the database. Secrets are opened with the given
+// keyring.
+func readBackupData(ctx context.Context, tx pgx.Tx, keyring *kprsecrets.Keyring) (*backupData, error) { //nolint:funlen
+	queries := database.New(tx)
+	data := &backupData{}
+
+	syncMeta, err := queries.TMGetSyncMeta(ctx)
+	if err == pgx.ErrNoRows {
+		return nil, errors.New("keyper has not synced with shuttermint yet, nothing to back up")
+	} else if err != nil {
+		return nil, err
+	}
+	data.CurrentBlock = syncMeta.CurrentBlock
+	data.LastCommittedHeight = syncMeta.LastCommittedHeight
+	data.LastBatchConfigSent, err = queries.GetLastBatchConfigProcessed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data.LastBlockSeen, err = queries.GetLastBlockSeen(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batchConfigs, err := queries.GetBatchConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range batchConfigs {
+		data.BatchConfigs = append(data.BatchConfigs, backupBatchConfig(c))
+	}
+	encryptionKeys, err := queries.GetEncryptionKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range encryptionKeys {
+		data.EncryptionKeys = append(data.EncryptionKeys, backupEncryptionKey(k))
+	}
+	eons, err := queries.GetAllEons(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range eons {
+		data.Eons = append(data.Eons, backupEon(e))
+	}
+
+	dkgs, err := queries.SelectPureDKG(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, dkg := range dkgs {
+		plaintext, err := keyring.Open(dkg.Puredkg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt DKG state of eon %d", dkg.Eon)
+		}
+		data.PureDKGs = append(data.PureDKGs, backupPureDKG{Eon: dkg.Eon, PureDKG: plaintext})
+	}
+	results, err := queries.GetAllDKGResults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		plaintext, err := keyring.Open(result.PureResult)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt DKG result of eon %d", result.Eon)
+		}
+		data.DKGResults = append(data.DKGResults, backupDKGResult{
+			Eon:        result.Eon,
+			Success:    result.Success,
+			Error:      result.Error.String,
+			PureResult: plaintext,
+		})
+	}
+	evals, err := queries.GetAllPolyEvals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, eval := range evals {
+		plaintext, err := keyring.Open(eval.Eval)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt poly eval of eon %d", eval.Eon)
+		}
+		data.PolyEvals = append(data.PolyEvals, backupPolyEval{
+			Eon:             eval.Eon,
+			ReceiverAddress: eval.ReceiverAddress,
+			Eval:            plaintext,
+		})
+	}
+	return data, nil
+}
+
+// writeBackupData writes the backed up state to an initialized database without DKG state.
+// Secrets are sealed with the given keyring.
+func writeBackupData(ctx context.Context, tx pgx.Tx, data *backupData, keyring *kprsecrets.Keyring) error { //nolint:funlen
+	queries := database.New(tx)
+
+	existingEons, err := queries.GetAllEons(ctx)
+	if err != nil {
+		return err
+	}
+	existingDKGs, err := queries.SelectPureDKG(ctx)
+	if err != nil {
+		return err
+	}
+	if len(existingEons) > 0 || len(existingDKGs) > 0 {
+		return errors.New("database already contains DKG state, restore requires a freshly initialized database")
+	}
+
+	err = queries.TMSetSyncMeta(ctx, database.TMSetSyncMetaParams{
+		CurrentBlock:        data.CurrentBlock,
+		LastCommittedHeight: data.LastCommittedHeight,
+		SyncTimestamp:       time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := queries.SetLastBatchConfigProcessed(ctx, data.LastBatchConfigSent); err != nil {
+		return err
+	}
+	if err := queries.SetLastBlockSeen(ctx, data.LastBlockSeen); err != nil {
+		return err
+	}
+	for _, c := range data.BatchConfigs {
+		if err := queries.InsertBatchConfig(ctx, database.InsertBatchConfigParams(c)); err != nil {
+			return err
+		}
+	}
+	for _, k := range data.EncryptionKeys {
+		if err := queries.InsertEncryptionKey(ctx, database.InsertEncryptionKeyParams(k)); err != nil {
+			return err
+		}
+	}
+	for _, e := range data.Eons {
+		if err := queries.InsertEon(ctx, database.InsertEonParams(e)); err != nil {
+			return err
+		}
+	}
+
+	for _, dkg := range data.PureDKGs {
+		sealed, err := keyring.Seal(dkg.PureDKG)
+		if err != nil {
+			return err
+		}
+		if err := queries.InsertPureDKG(ctx, database.InsertPureDKGParams{Eon: dkg.Eon, Puredkg: sealed}); err != nil {
+			return err
+		}
+	}
+	for _, result := range data.DKGResults {
+		var sealed []byte
+		if result.PureResult != nil {
+			sealed, err = keyring.Seal(result.PureResult)
+			if err != nil {
+				return err
+			}
+		}
+		err = queries.InsertDKGResult(ctx, database.InsertDKGResultParams{
+			Eon:        result.Eon,
+			Success:    result.Success,
+			Error:      sql.NullString{String: result.Error, Valid: result.Error != ""},
+			PureResult: sealed,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, eval := range data.PolyEvals {
+		sealed, err := keyring.Seal(eval.Eval)
+		if err != nil {
+			return err
+		}
+		err = queries.InsertPolyEval(ctx, database.InsertPolyEvalParams{
+			Eon:             eval.Eon,
+			ReceiverAddress: eval.ReceiverAddress,
+			Eval:            sealed,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Backup writes an encrypted backup of the keyper database to the given path. The backup is
+// encrypted with the current key from the secrets config. An existing file is not overwritten.
+func Backup(
+	ctx context.Context,
+	databaseURL string,
+	address common.Address,
+	instanceID uint64,
+	secrets *kprconfig.SecretsConfig,
+	path string,
+) error {
+	if !secrets.Enabled() {
+		return errors.New("no key to encrypt the backup with is configured in the secrets config")
+	}
+	keyring, err := secrets.Keyring()
+	if err != nil {
+		return errors.Wrap(err, "failed to load secrets key")
+	}
+	dbpool, err := pgxpool.Connect(ctx, databaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	var data *backupData
+	err = dbpool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		if err := database.Definition.Validate(ctx, tx); err != nil {
+			return err
+		}
+		dbAddress, err := db.New(tx).GetMeta(ctx, ethereumAddressMetaKey)
+		if err != nil {
+			return errors.Wrap(err, "failed to read keyper address from database")
+		}
+		if dbAddress != address.String() {
+			return errors.Errorf("database linked to wrong address %s, config address is %s", dbAddress, address)
+		}
+		data, err = readBackupData(ctx, tx, keyring)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	data.KeyperAddress = address.String()
+	data.InstanceID = instanceID
+
+	raw, err := encodeBackup(data, keyring)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(raw)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write backup")
+	}
+	log.Info().
+		Str("path", path).
+		Int("eons", len(data.Eons)).
+		Int64("shuttermint-height", data.LastCommittedHeight).
+		Msg("created keyper backup")
+	return nil
+}
+
+// Restore restores a backup created by Backup into a freshly initialized keyper database. It
+// refuses to restore a backup of another keyper or into a database linked to another keyper. The
+// secrets config must contain the key the backup has been encrypted with, restored secrets are
+// encrypted with its current key.
+func Restore(
+	ctx context.Context,
+	databaseURL string,
+	address common.Address,
+	instanceID uint64,
+	secrets *kprconfig.SecretsConfig,
+	path string,
+) error {
+	keyring, err := secrets.Keyring()
+	if err != nil {
+		return errors.Wrap(err, "failed to load secrets key")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	data, err := decodeBackup(raw, keyring)
+	if err != nil {
+		return err
+	}
+	if data.KeyperAddress != address.String() {
+		return errors.Errorf("backup belongs to keyper %s, config address is %s", data.KeyperAddress, address)
+	}
+	if data.InstanceID != instanceID {
+		return errors.Errorf("backup belongs to instance %d, config instance is %d", data.InstanceID, instanceID)
+	}
+
+	dbpool, err := pgxpool.Connect(ctx, databaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if err := database.Definition.Validate(ctx, tx); err != nil {
+			return err
+		}
+		if err := linkAddressToDB(ctx, tx, address); err != nil {
+			return err
+		}
+		return writeBackupData(ctx, tx, data, keyring)
+	})
+	if err != nil {
+		return err
+	}
+	log.Info().
+		Str("path", path).
+		Int("eons", len(data.Eons)).
+		Int64("shuttermint-height", data.LastCommittedHeight).
+		Msg("restored keyper backup")
+	return nil
+}
@@ -0,0 +1,131 @@
+package keyper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"gotest.tools/v3/assert"
+
+	keyperdb "github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testsetup"
+)
+
+func newBackupTestKeyring(t *testing.T, previous ...[]byte) (*kprsecrets.Keyring, []byte) {
+	t.Helper()
+	key, err := kprsecrets.GenerateKey()
+	assert.NilError(t, err)
+	keyring, err := kprsecrets.NewKeyring(key, previous...)
+	assert.NilError(t, err)
+	return keyring, key
+}
+
+func TestBackupEncoding(t *testing.T) {
+	keyring, key := newBackupTestKeyring(t)
+	data := &backupData{
+		KeyperAddress: secretsTestConfig{}.GetAddress().String(),
+		InstanceID:    55,
+		CurrentBlock:  10,
+		Eons:          []backupEon{{Eon: 1, Height: 2, ActivationBlockNumber: 3, KeyperConfigIndex: 1}},
+		DKGResults:    []backupDKGResult{{Eon: 1, Success: true, PureResult: []byte("secret key share")}},
+	}
+	raw, err := encodeBackup(data, keyring)
+	assert.NilError(t, err)
+	assert.Assert(t, !bytes.Contains(raw, []byte("secret key share")))
+
+	decoded, err := decodeBackup(raw, keyring)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, decoded, data)
+
+	// the backup can be restored after the key has been rotated
+	rotatedKeyring, _ := newBackupTestKeyring(t, key)
+	decoded, err = decodeBackup(raw, rotatedKeyring)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, decoded, data)
+
+	otherKeyring, _ := newBackupTestKeyring(t)
+	_, err = decodeBackup(raw, otherKeyring)
+	assert.ErrorIs(t, err, kprsecrets.ErrNoKey)
+	_, err = decodeBackup(raw, nil)
+	assert.ErrorIs(t, err, kprsecrets.ErrNoKey)
+	_, err = encodeBackup(data, nil)
+	assert.ErrorIs(t, err, kprsecrets.ErrNoKey)
+
+	archive := backupArchive{}
+	assert.NilError(t, json.Unmarshal(raw, &archive))
+	archive.Version = BackupVersion + 1
+	modified, err := json.Marshal(archive)
+	assert.NilError(t, err)
+	_, err = decodeBackup(modified, keyring)
+	assert.ErrorContains(t, err, "unsupported backup version")
+
+	_, err = decodeBackup([]byte(`{"magic": "something else"}`), keyring)
+	assert.ErrorContains(t, err, "not a keyper backup")
+}
+
+func TestBackupRestoreDatabase(t *testing.T) {
+	ctx := context.Background()
+	dbpool, dbclose := testsetup.NewTestDBPool(ctx, t, keyperdb.Definition)
+	t.Cleanup(dbclose)
+	queries := keyperdb.New(dbpool)
+
+	keyring, _ := newBackupTestKeyring(t)
+	eonKeys := testsetup.InitializeEon(ctx, t, dbpool, secretsTestConfig{}, 1)
+	assert.NilError(t, queries.TMSetSyncMeta(ctx, keyperdb.TMSetSyncMetaParams{
+		CurrentBlock:        100,
+		LastCommittedHeight: 99,
+		SyncTimestamp:       time.Now(),
+	}))
+	sealedEval, err := keyring.Seal([]byte{1, 2, 3})
+	assert.NilError(t, err)
+	assert.NilError(t, queries.InsertPolyEval(ctx, keyperdb.InsertPolyEvalParams{
+		Eon:             1,
+		ReceiverAddress: "0x0000000000000000000000000000000000000001",
+		Eval:            sealedEval,
+	}))
+
+	var data *backupData
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		data, err = readBackupData(ctx, tx, keyring)
+		return err
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(data.Eons), 1)
+	assert.Equal(t, len(data.DKGResults), 1)
+	assert.DeepEqual(t, data.PolyEvals[0].Eval, []byte{1, 2, 3})
+
+	// restoring into a database with DKG state fails
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return writeBackupData(ctx, tx, data, keyring)
+	})
+	assert.ErrorContains(t, err, "already contains DKG state")
+
+	newKeyring, _ := newBackupTestKeyring(t)
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `TRUNCATE eons, puredkg, dkg_result, poly_evals, tendermint_batch_config,
+			tendermint_encryption_key, tendermint_sync_meta`)
+		if err != nil {
+			return err
+		}
+		return writeBackupData(ctx, tx, data, newKeyring)
+	})
+	assert.NilError(t, err)
+
+	var restored *backupData
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		restored, err = readBackupData(ctx, tx, newKeyring)
+		return err
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, restored, data)
+
+	result, err := queries.GetDKGResult(ctx, 1)
+	assert.NilError(t, err)
+	pureResult, err := keyperdb.DecodePureDKGResult(newKeyring, result.PureResult)
+	assert.NilError(t, err)
+	assert.Assert(t, pureResult.SecretKeyShare.Equal(eonKeys.EonSecretKeyShare(1)))
+}
@@ -79,6 +79,89 @@ func (q *Queries) CountDecryptionKeyShares(ctx context.Context, arg CountDecrypt
 	return count, err
 }
 
+const deleteDecryptionKeySharesBeforeEon = `-- name: DeleteDecryptionKeySharesBeforeEon :execresult
+DELETE FROM decryption_key_share WHERE eon < $1
+`
+
+func (q *Queries) DeleteDecryptionKeySharesBeforeEon(ctx context.Context, eon int64) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteDecryptionKeySharesBeforeEon, eon)
+}
+
+const deleteDecryptionKeySharesByEpochIDs = `-- name: DeleteDecryptionKeySharesByEpochIDs :execresult
+DELETE FROM decryption_key_share
+WHERE eon = $1 AND epoch_id = ANY($2::bytea[])
+`
+
+type DeleteDecryptionKeySharesByEpochIDsParams struct {
+	Eon      int64
+	EpochIds [][]byte
+}
+
+func (q *Queries) DeleteDecryptionKeySharesByEpochIDs(ctx context.Context, arg DeleteDecryptionKeySharesByEpochIDsParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteDecryptionKeySharesByEpochIDs, arg.Eon, arg.EpochIds)
+}
+
+const deleteDecryptionKeySharesInRange = `-- name: DeleteDecryptionKeySharesInRange :execresult
+DELETE FROM decryption_key_share
+WHERE epoch_id >= $1 AND epoch_id < $2
+`
+
+type DeleteDecryptionKeySharesInRangeParams struct {
+	StartEpochID []byte
+	EndEpochID   []byte
+}
+
+func (q *Queries) DeleteDecryptionKeySharesInRange(ctx context.Context, arg DeleteDecryptionKeySharesInRangeParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteDecryptionKeySharesInRange, arg.StartEpochID, arg.EndEpochID)
+}
+
+const deleteDecryptionKeysBeforeEon = `-- name: DeleteDecryptionKeysBeforeEon :execresult
+DELETE FROM decryption_key WHERE eon < $1
+`
+
+func (q *Queries) DeleteDecryptionKeysBeforeEon(ctx context.Context, eon int64) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteDecryptionKeysBeforeEon, eon)
+}
+
+const deleteDecryptionKeysByEpochIDs = `-- name: DeleteDecryptionKeysByEpochIDs :execresult
+DELETE FROM decryption_key
+WHERE eon = $1 AND epoch_id = ANY($2::bytea[])
+`
+
+type DeleteDecryptionKeysByEpochIDsParams struct {
+	Eon      int64
+	EpochIds [][]byte
+}
+
+func (q *Queries) DeleteDecryptionKeysByEpochIDs(ctx context.Context, arg DeleteDecryptionKeysByEpochIDsParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteDecryptionKeysByEpochIDs, arg.Eon, arg.EpochIds)
+}
+
+const deleteDecryptionKeysInRange = `-- name: DeleteDecryptionKeysInRange :execresult
+DELETE FROM decryption_key
+WHERE epoch_id >= $1 AND epoch_id < $2
+`
+
+type DeleteDecryptionKeysInRangeParams struct {
+	StartEpochID []byte
+	EndEpochID   []byte
+}
+
+func (q *Queries) DeleteDecryptionKeysInRange(ctx context.Context, arg DeleteDecryptionKeysInRangeParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteDecryptionKeysInRange, arg.StartEpochID, arg.EndEpochID)
+}
+
+const deleteDecryptionTriggersBeforeEon = `-- name: DeleteDecryptionTriggersBeforeEon :execresult
+DELETE FROM decryption_trigger t
+WHERE t.epoch_id IN (SELECT s.epoch_id FROM decryption_key_share s WHERE s.eon < $1)
+`
+
+// Triggers are not stored with their eon, so we delete the ones for which we have key shares of
+// an eon before the given one. This must happen before the key shares are deleted.
+func (q *Queries) DeleteDecryptionTriggersBeforeEon(ctx context.Context, eon int64) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteDecryptionTriggersBeforeEon, eon)
+}
+
 const deletePolyEval = `-- name: DeletePolyEval :exec
 
 DELETE FROM poly_evals ev WHERE ev.eon=$1 AND ev.receiver_address=$2
@@ -202,6 +285,31 @@ func (q *Queries) GetAllDKGResults(ctx context.Context) ([]DkgResult, error) {
 	return items, nil
 }
 
+const getAllPolyEvals = `-- name: GetAllPolyEvals :many
+SELECT eon, receiver_address, eval FROM poly_evals
+ORDER BY eon, receiver_address
+`
+
+func (q *Queries) GetAllPolyEvals(ctx context.Context) ([]PolyEval, error) {
+	rows, err := q.db.Query(ctx, getAllPolyEvals)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PolyEval
+	for rows.Next() {
+		var i PolyEval
+		if err := rows.Scan(&i.Eon, &i.ReceiverAddress, &i.Eval); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAllEons = `-- name: GetAllEons :many
 SELECT eon, height, activation_block_number, keyper_config_index FROM eons ORDER BY eon
 `
@@ -421,6 +529,82 @@ func (q *Queries) GetDecryptionKeyShare(ctx context.Context, arg GetDecryptionKe
 	return i, err
 }
 
+const getDecryptionKeySharesByEpochIDs = `-- name: GetDecryptionKeySharesByEpochIDs :many
+SELECT eon, epoch_id, keyper_index, decryption_key_share FROM decryption_key_share
+WHERE eon = $1 AND keyper_index = $2 AND epoch_id = ANY($3::bytea[])
+ORDER BY epoch_id
+`
+
+type GetDecryptionKeySharesByEpochIDsParams struct {
+	Eon         int64
+	KeyperIndex int64
+	EpochIds    [][]byte
+}
+
+func (q *Queries) GetDecryptionKeySharesByEpochIDs(ctx context.Context, arg GetDecryptionKeySharesByEpochIDsParams) ([]DecryptionKeyShare, error) {
+	rows, err := q.db.Query(ctx, getDecryptionKeySharesByEpochIDs,
+		arg.Eon,
+		arg.KeyperIndex,
+		arg.EpochIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DecryptionKeyShare
+	for rows.Next() {
+		var i DecryptionKeyShare
+		if err := rows.Scan(
+			&i.Eon,
+			&i.EpochID,
+			&i.KeyperIndex,
+			&i.DecryptionKeyShare,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDecryptionKeysByEpochIDs = `-- name: GetDecryptionKeysByEpochIDs :many
+SELECT eon, epoch_id, decryption_key FROM decryption_key
+WHERE eon = $1 AND epoch_id = ANY($2::bytea[])
+ORDER BY epoch_id
+`
+
+type GetDecryptionKeysByEpochIDsParams struct {
+	Eon      int64
+	EpochIds [][]byte
+}
+
+func (q *Queries) GetDecryptionKeysByEpochIDs(ctx context.Context, arg GetDecryptionKeysByEpochIDsParams) ([]DecryptionKey, error) {
+	rows, err := q.db.Query(ctx, getDecryptionKeysByEpochIDs, arg.Eon, arg.EpochIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DecryptionKey
+	for rows.Next() {
+		var i DecryptionKey
+		if err := rows.Scan(
+			&i.Eon,
+			&i.EpochID,
+			&i.DecryptionKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getEncryptionKeys = `-- name: GetEncryptionKeys :many
 SELECT DISTINCT ON (address) address, encryption_public_key, height
 FROM tendermint_encryption_key
@@ -482,6 +666,51 @@ func (q *Queries) GetEonForBlockNumber(ctx context.Context, blockNumber int64) (
 	return i, err
 }
 
+const getKeyperPeerRecord = `-- name: GetKeyperPeerRecord :one
+SELECT keyper_address, peer_id, timestamp, signature FROM keyper_peer_record WHERE keyper_address = $1
+`
+
+func (q *Queries) GetKeyperPeerRecord(ctx context.Context, keyperAddress string) (KeyperPeerRecord, error) {
+	row := q.db.QueryRow(ctx, getKeyperPeerRecord, keyperAddress)
+	var i KeyperPeerRecord
+	err := row.Scan(
+		&i.KeyperAddress,
+		&i.PeerID,
+		&i.Timestamp,
+		&i.Signature,
+	)
+	return i, err
+}
+
+const getKeyperPeerRecords = `-- name: GetKeyperPeerRecords :many
+SELECT keyper_address, peer_id, timestamp, signature FROM keyper_peer_record ORDER BY keyper_address
+`
+
+func (q *Queries) GetKeyperPeerRecords(ctx context.Context) ([]KeyperPeerRecord, error) {
+	rows, err := q.db.Query(ctx, getKeyperPeerRecords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KeyperPeerRecord
+	for rows.Next() {
+		var i KeyperPeerRecord
+		if err := rows.Scan(
+			&i.KeyperAddress,
+			&i.PeerID,
+			&i.Timestamp,
+			&i.Signature,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getKeyperStateForEon = `-- name: GetKeyperStateForEon :one
 SELECT ($1::TEXT[] && tbc.keypers)::BOOL AS is_keyper
 FROM tendermint_batch_config AS tbc
@@ -746,6 +975,30 @@ func (q *Queries) InsertEonPublicKey(ctx context.Context, arg InsertEonPublicKey
 	return err
 }
 
+const insertKeyperPeerRecord = `-- name: InsertKeyperPeerRecord :execresult
+INSERT INTO keyper_peer_record (keyper_address, peer_id, timestamp, signature)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (keyper_address) DO UPDATE
+SET peer_id = EXCLUDED.peer_id, timestamp = EXCLUDED.timestamp, signature = EXCLUDED.signature
+WHERE keyper_peer_record.timestamp < EXCLUDED.timestamp
+`
+
+type InsertKeyperPeerRecordParams struct {
+	KeyperAddress string
+	PeerID        string
+	Timestamp     int64
+	Signature     []byte
+}
+
+func (q *Queries) InsertKeyperPeerRecord(ctx context.Context, arg InsertKeyperPeerRecordParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, insertKeyperPeerRecord,
+		arg.KeyperAddress,
+		arg.PeerID,
+		arg.Timestamp,
+		arg.Signature,
+	)
+}
+
 const insertPolyEval = `-- name: InsertPolyEval :exec
 INSERT INTO poly_evals (eon, receiver_address, eval)
 VALUES ($1, $2, $3)
@@ -966,3 +1219,34 @@ func (q *Queries) TMSetSyncMeta(ctx context.Context, arg TMSetSyncMetaParams) er
 	_, err := q.db.Exec(ctx, tMSetSyncMeta, arg.CurrentBlock, arg.LastCommittedHeight, arg.SyncTimestamp)
 	return err
 }
+
+const updateDKGPureResult = `-- name: UpdateDKGPureResult :exec
+UPDATE dkg_result SET pure_result=$2
+WHERE eon=$1
+`
+
+type UpdateDKGPureResultParams struct {
+	Eon        int64
+	PureResult []byte
+}
+
+func (q *Queries) UpdateDKGPureResult(ctx context.Context, arg UpdateDKGPureResultParams) error {
+	_, err := q.db.Exec(ctx, updateDKGPureResult, arg.Eon, arg.PureResult)
+	return err
+}
+
+const updatePolyEval = `-- name: UpdatePolyEval :exec
+UPDATE poly_evals SET eval=$3
+WHERE eon=$1 AND receiver_address=$2
+`
+
+type UpdatePolyEvalParams struct {
+	Eon             int64
+	ReceiverAddress string
+	Eval            []byte
+}
+
+func (q *Queries) UpdatePolyEval(ctx context.Context, arg UpdatePolyEvalParams) error {
+	_, err := q.db.Exec(ctx, updatePolyEval, arg.Eon, arg.ReceiverAddress, arg.Eval)
+	return err
+}
@@ -40,6 +40,13 @@ type Eon struct {
 	KeyperConfigIndex     int64
 }
 
+type KeyperPeerRecord struct {
+	KeyperAddress string
+	PeerID        string
+	Timestamp     int64
+	Signature     []byte
+}
+
 type LastBatchConfigSent struct {
 	EnforceOneRow     bool
 	KeyperConfigIndex int64
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/shutter-network/shutter/shlib/puredkg"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// The puredkg, dkg_result.pure_result and poly_evals.eval columns contain secret material. They
+// are sealed with the keyper's kprsecrets keyring before they are written and opened after they
+// are read. A nil keyring stores them in plaintext.
+
+// EncodePureDKGResult encodes and seals a DKG result for the dkg_result table.
+func EncodePureDKGResult(keyring *kprsecrets.Keyring, result *puredkg.Result) ([]byte, error) {
+	encoded, err := shdb.EncodePureDKGResult(result)
+	if err != nil {
+		return nil, err
+	}
+	return keyring.Seal(encoded)
+}
+
+// DecodePureDKGResult opens and decodes a DKG result from the dkg_result table.
+func DecodePureDKGResult(keyring *kprsecrets.Keyring, data []byte) (*puredkg.Result, error) {
+	opened, err := keyring.Open(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt DKG result")
+	}
+	return shdb.DecodePureDKGResult(opened)
+}
+
+// EncodePureDKG encodes and seals a DKG instance for the puredkg table.
+func EncodePureDKG(keyring *kprsecrets.Keyring, p *puredkg.PureDKG) ([]byte, error) {
+	encoded, err := shdb.EncodePureDKG(p)
+	if err != nil {
+		return nil, err
+	}
+	return keyring.Seal(encoded)
+}
+
+// DecodePureDKG opens and decodes a DKG instance from the puredkg table.
+func DecodePureDKG(keyring *kprsecrets.Keyring, data []byte) (*puredkg.PureDKG, error) {
+	opened, err := keyring.Open(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt DKG state")
+	}
+	return shdb.DecodePureDKG(opened)
+}
+
+// ResealSecrets seals all secret columns with the current key of the given keyring. Rows that
+// are stored in plaintext, e.g. because they have been written before encryption has been
+// enabled, or that are sealed with a previous key are rewritten. If keyring is nil, it only
+// checks that no row is sealed. It returns the number of rewritten rows.
+func (q *Queries) ResealSecrets(ctx context.Context, keyring *kprsecrets.Keyring) (int, error) {
+	reseal := func(data []byte) ([]byte, error) {
+		plaintext, err := keyring.Open(data)
+		if err != nil {
+			return nil, err
+		}
+		return keyring.Seal(plaintext)
+	}
+	count := 0
+
+	dkgs, err := q.SelectPureDKG(ctx)
+	if err != nil {
+		return count, err
+	}
+	for _, dkg := range dkgs {
+		if !keyring.NeedsReseal(dkg.Puredkg) {
+			continue
+		}
+		sealed, err := reseal(dkg.Puredkg)
+		if err != nil {
+			return count, errors.Wrapf(err, "failed to reseal DKG state of eon %d", dkg.Eon)
+		}
+		err = q.InsertPureDKG(ctx, InsertPureDKGParams{Eon: dkg.Eon, Puredkg: sealed})
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	results, err := q.GetAllDKGResults(ctx)
+	if err != nil {
+		return count, err
+	}
+	for _, result := range results {
+		if !keyring.NeedsReseal(result.PureResult) {
+			continue
+		}
+		sealed, err := reseal(result.PureResult)
+		if err != nil {
+			return count, errors.Wrapf(err, "failed to reseal DKG result of eon %d", result.Eon)
+		}
+		err = q.UpdateDKGPureResult(ctx, UpdateDKGPureResultParams{Eon: result.Eon, PureResult: sealed})
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	evals, err := q.GetAllPolyEvals(ctx)
+	if err != nil {
+		return count, err
+	}
+	for _, eval := range evals {
+		if !keyring.NeedsReseal(eval.Eval) {
+			continue
+		}
+		sealed, err := reseal(eval.Eval)
+		if err != nil {
+			return count, errors.Wrapf(err, "failed to reseal poly eval of eon %d for %s", eval.Eon, eval.ReceiverAddress)
+		}
+		err = q.UpdatePolyEval(ctx, UpdatePolyEvalParams{
+			Eon:             eval.Eon,
+			ReceiverAddress: eval.ReceiverAddress,
+			Eval:            sealed,
+		})
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,9 @@
+-- schema-version: keyper-3 --
+-- signed records binding the libp2p peer IDs of keypers to their Ethereum addresses
+
+CREATE TABLE keyper_peer_record (
+       keyper_address text PRIMARY KEY,
+       peer_id text NOT NULL,
+       timestamp bigint NOT NULL,
+       signature bytea NOT NULL
+);
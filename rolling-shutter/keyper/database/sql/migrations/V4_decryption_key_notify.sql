@@ -0,0 +1,17 @@
+-- schema-version: keyper-4 --
+-- notify listeners about inserted decryption keys, used to stream them via the HTTP API
+
+CREATE FUNCTION notify_decryption_key() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('decryption_key', json_build_object(
+        'eon', NEW.eon,
+        'identity', '0x' || encode(NEW.epoch_id, 'hex'),
+        'decryption_key', '0x' || encode(NEW.decryption_key, 'hex')
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER decryption_key_notify
+AFTER INSERT ON decryption_key
+FOR EACH ROW EXECUTE FUNCTION notify_decryption_key();
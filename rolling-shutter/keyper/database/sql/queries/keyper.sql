@@ -38,6 +38,44 @@ SELECT EXISTS (
 SELECT count(*) FROM decryption_key_share
 WHERE eon = $1 AND epoch_id = $2;
 
+-- name: GetDecryptionKeysByEpochIDs :many
+SELECT * FROM decryption_key
+WHERE eon = $1 AND epoch_id = ANY(sqlc.arg(epoch_ids)::bytea[])
+ORDER BY epoch_id;
+
+-- name: GetDecryptionKeySharesByEpochIDs :many
+SELECT * FROM decryption_key_share
+WHERE eon = $1 AND keyper_index = $2 AND epoch_id = ANY(sqlc.arg(epoch_ids)::bytea[])
+ORDER BY epoch_id;
+
+-- name: DeleteDecryptionKeysBeforeEon :execresult
+DELETE FROM decryption_key WHERE eon < $1;
+
+-- name: DeleteDecryptionKeySharesBeforeEon :execresult
+DELETE FROM decryption_key_share WHERE eon < $1;
+
+-- name: DeleteDecryptionKeysByEpochIDs :execresult
+DELETE FROM decryption_key
+WHERE eon = $1 AND epoch_id = ANY(sqlc.arg(epoch_ids)::bytea[]);
+
+-- name: DeleteDecryptionKeySharesByEpochIDs :execresult
+DELETE FROM decryption_key_share
+WHERE eon = $1 AND epoch_id = ANY(sqlc.arg(epoch_ids)::bytea[]);
+
+-- name: DeleteDecryptionKeysInRange :execresult
+DELETE FROM decryption_key
+WHERE epoch_id >= sqlc.arg(start_epoch_id) AND epoch_id < sqlc.arg(end_epoch_id);
+
+-- name: DeleteDecryptionKeySharesInRange :execresult
+DELETE FROM decryption_key_share
+WHERE epoch_id >= sqlc.arg(start_epoch_id) AND epoch_id < sqlc.arg(end_epoch_id);
+
+-- name: DeleteDecryptionTriggersBeforeEon :execresult
+-- Triggers are not stored with their eon, so we delete the ones for which we have key shares of
+-- an eon before the given one. This must happen before the key shares are deleted.
+DELETE FROM decryption_trigger t
+WHERE t.epoch_id IN (SELECT s.epoch_id FROM decryption_key_share s WHERE s.eon < $1);
+
 -- name: InsertBatchConfig :exec
 INSERT INTO tendermint_batch_config (keyper_config_index, height, keypers, threshold, started, activation_block_number)
 VALUES ($1, $2, $3, $4, $5, $6);
@@ -174,6 +212,14 @@ DELETE FROM poly_evals ev WHERE ev.eon=$1 AND ev.receiver_address=$2;
 -- name: DeletePolyEvalByEon :execresult
 DELETE FROM poly_evals ev WHERE ev.eon=$1;
 
+-- name: GetAllPolyEvals :many
+SELECT * FROM poly_evals
+ORDER BY eon, receiver_address;
+
+-- name: UpdatePolyEval :exec
+UPDATE poly_evals SET eval=$3
+WHERE eon=$1 AND receiver_address=$2;
+
 -- name: InsertDKGResult :exec
 INSERT INTO dkg_result (eon,success,error,pure_result)
 VALUES ($1,$2,$3,$4);
@@ -196,6 +242,10 @@ WHERE eon = (SELECT max(eon) FROM eons WHERE keyper_config_index = $1);
 SELECT * FROM dkg_result
 ORDER BY eon ASC;
 
+-- name: UpdateDKGPureResult :exec
+UPDATE dkg_result SET pure_result=$2
+WHERE eon=$1;
+
 -- name: InsertEonPublicKey :exec
 INSERT INTO outgoing_eon_keys (eon_public_key, eon)
 VALUES ($1, $2);
@@ -243,3 +293,16 @@ FROM eons
 WHERE keyper_config_index = $1
 ORDER BY eon DESC
 LIMIT 1;
+
+-- name: InsertKeyperPeerRecord :execresult
+INSERT INTO keyper_peer_record (keyper_address, peer_id, timestamp, signature)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (keyper_address) DO UPDATE
+SET peer_id = EXCLUDED.peer_id, timestamp = EXCLUDED.timestamp, signature = EXCLUDED.signature
+WHERE keyper_peer_record.timestamp < EXCLUDED.timestamp;
+
+-- name: GetKeyperPeerRecord :one
+SELECT * FROM keyper_peer_record WHERE keyper_address = $1;
+
+-- name: GetKeyperPeerRecords :many
+SELECT * FROM keyper_peer_record ORDER BY keyper_address;
@@ -12,6 +12,7 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprconfig"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 )
@@ -30,6 +31,7 @@ func newEonPubKeyHandler(keyper *KeyperCore) *eonPubKeyHandler {
 		dbpool:             keyper.dbpool,
 		config:             keyper.config,
 		messaging:          keyper.messaging,
+		signer:             keyper.signer,
 		eonPubkeyHandler:   keyper.opts.eonPubkeyHandler,
 		broadcastEonPubKey: keyper.opts.broadcastEonPubKey,
 		stopOnErrors:       false,
@@ -40,6 +42,7 @@ type eonPubKeyHandler struct {
 	dbpool    *pgxpool.Pool
 	config    *kprconfig.Config
 	messaging p2p.Messaging
+	signer    signer.Signer
 
 	eonPubkeyHandler   EonPublicKeyHandlerFunc
 	broadcastEonPubKey bool
@@ -75,12 +78,13 @@ func (pkh *eonPubKeyHandler) loop(ctx context.Context) error {
 
 func (pkh *eonPubKeyHandler) broadcastEonPublicKey(ctx context.Context, eonPubKey EonPublicKey) error {
 	msg, err := p2pmsg.NewSignedEonPublicKey(
+		ctx,
 		pkh.config.InstanceID,
 		eonPubKey.PublicKey,
 		eonPubKey.ActivationBlock,
 		eonPubKey.KeyperConfigIndex,
 		eonPubKey.Eon,
-		pkh.config.Ethereum.PrivateKey.Key,
+		pkh.signer,
 	)
 	if err != nil {
 		return errors.Wrap(err, "error while signing EonPublicKey")
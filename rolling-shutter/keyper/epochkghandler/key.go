@@ -14,19 +14,20 @@ import (
 	"github.com/shutter-network/shutter/shlib/shcrypto"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
-func NewDecryptionKeyHandler(config Config, dbpool *pgxpool.Pool) p2p.MessageHandler {
-	return &DecryptionKeyHandler{config: config, dbpool: dbpool}
+func NewDecryptionKeyHandler(config Config, dbpool *pgxpool.Pool, keyring *kprsecrets.Keyring) p2p.MessageHandler {
+	return &DecryptionKeyHandler{config: config, dbpool: dbpool, keyring: keyring}
 }
 
 type DecryptionKeyHandler struct {
-	config Config
-	dbpool *pgxpool.Pool
+	config  Config
+	dbpool  *pgxpool.Pool
+	keyring *kprsecrets.Keyring
 }
 
 func (*DecryptionKeyHandler) MessagePrototypes() []p2pmsg.Message {
@@ -66,7 +67,7 @@ func (handler *DecryptionKeyHandler) ValidateMessage(ctx context.Context, msg p2
 	if !dkgResultDB.Success {
 		return pubsub.ValidationReject, errors.Errorf("no successful DKG result found for eon %d", eon)
 	}
-	pureDKGResult, err := shdb.DecodePureDKGResult(dkgResultDB.PureResult)
+	pureDKGResult, err := database.DecodePureDKGResult(handler.keyring, dkgResultDB.PureResult)
 	if err != nil {
 		return pubsub.ValidationReject, errors.Wrapf(err, "error while decoding pure DKG result for eon %d", eon)
 	}
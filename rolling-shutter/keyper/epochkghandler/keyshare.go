@@ -16,6 +16,7 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/epochkg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/identitypreimage"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
@@ -23,13 +24,14 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
-func NewDecryptionKeyShareHandler(config Config, dbpool *pgxpool.Pool) p2p.MessageHandler {
-	return &DecryptionKeyShareHandler{config: config, dbpool: dbpool}
+func NewDecryptionKeyShareHandler(config Config, dbpool *pgxpool.Pool, keyring *kprsecrets.Keyring) p2p.MessageHandler {
+	return &DecryptionKeyShareHandler{config: config, dbpool: dbpool, keyring: keyring}
 }
 
 type DecryptionKeyShareHandler struct {
-	config Config
-	dbpool *pgxpool.Pool
+	config  Config
+	dbpool  *pgxpool.Pool
+	keyring *kprsecrets.Keyring
 }
 
 func (*DecryptionKeyShareHandler) MessagePrototypes() []p2pmsg.Message {
@@ -66,7 +68,7 @@ func (handler *DecryptionKeyShareHandler) ValidateMessage(ctx context.Context, m
 	if !dkgResultDB.Success {
 		return pubsub.ValidationReject, errors.Errorf("no successful DKG result found for eon %d", keyShare.Eon)
 	}
-	pureDKGResult, err := shdb.DecodePureDKGResult(dkgResultDB.PureResult)
+	pureDKGResult, err := database.DecodePureDKGResult(handler.keyring, dkgResultDB.PureResult)
 	if err != nil {
 		return pubsub.ValidationReject, errors.Errorf("error while decoding pure DKG result for eon %d", keyShare.Eon)
 	}
@@ -151,7 +153,7 @@ func (handler *DecryptionKeyShareHandler) HandleMessage(ctx context.Context, m p
 			Msg("ignoring decryption key share: eon key generation failed")
 		return nil, nil
 	}
-	pureDKGResult, err := shdb.DecodePureDKGResult(dkgResultDB.PureResult)
+	pureDKGResult, err := database.DecodePureDKGResult(handler.keyring, dkgResultDB.PureResult)
 	if err != nil {
 		return nil, err
 	}
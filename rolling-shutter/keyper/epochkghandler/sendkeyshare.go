@@ -11,7 +11,6 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/identitypreimage"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
 type Config interface {
@@ -101,7 +100,7 @@ func (ksh *KeyShareHandler) ConstructDecryptionKeyShares(
 	if !dkgResultDB.Success {
 		return nil, errors.Wrap(ErrEonDKGFailed, ErrIgnoreDecryptionRequest.Error())
 	}
-	pureDKGResult, err := shdb.DecodePureDKGResult(dkgResultDB.PureResult)
+	pureDKGResult, err := database.DecodePureDKGResult(ksh.Keyring, dkgResultDB.PureResult)
 	if err != nil {
 		return nil, err
 	}
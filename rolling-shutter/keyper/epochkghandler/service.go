@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/broker"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
@@ -22,6 +23,7 @@ type KeyShareHandler struct {
 	KeyperAddress        common.Address
 	MaxNumKeysPerMessage uint64
 	DBPool               *pgxpool.Pool
+	Keyring              *kprsecrets.Keyring
 
 	Messaging p2p.Messaging
 	Trigger   <-chan *broker.Event[*DecryptionTrigger]
@@ -2,7 +2,6 @@ package fx
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/binary"
@@ -14,6 +13,7 @@ import (
 	tmtypes "github.com/tendermint/tendermint/types"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/shutterevents/shtxresp"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
 )
 
@@ -46,9 +46,9 @@ type MessageSender interface {
 
 // RPCMessageSender signs messages and sends them via RPC to shuttermint.
 type RPCMessageSender struct {
-	rpcclient  client.Client
-	chainID    string
-	signingKey *ecdsa.PrivateKey
+	rpcclient client.Client
+	chainID   string
+	signer    signer.Signer
 }
 
 var _ MessageSender = &RPCMessageSender{}
@@ -63,11 +63,11 @@ var _ MessageSender = &MockMessageSender{}
 var mockMessageSenderBufferSize = 0x10000
 
 // NewRPCMessageSender creates a new RPCMessageSender.
-func NewRPCMessageSender(cl client.Client, signingKey *ecdsa.PrivateKey) RPCMessageSender {
+func NewRPCMessageSender(cl client.Client, s signer.Signer) RPCMessageSender {
 	ms := RPCMessageSender{
-		rpcclient:  cl,
-		chainID:    "",
-		signingKey: signingKey,
+		rpcclient: cl,
+		chainID:   "",
+		signer:    s,
 	}
 	return ms
 }
@@ -78,8 +78,12 @@ func (ms *RPCMessageSender) SendMessage(ctx context.Context, msg *shmsg.Message)
 		return err
 	}
 
-	msgWithNonce := ms.addNonceAndChainID(msg)
-	signedMessage, err := shmsg.SignMessage(msgWithNonce, ms.signingKey)
+	status, err := ms.rpcclient.Status(ctx)
+	if err != nil {
+		return err
+	}
+	msgWithNonce := ms.addNonceAndChainID(msg, status.SyncInfo.LatestBlockHeight)
+	signedMessage, err := shmsg.SignMessageWithSigner(ctx, msgWithNonce, ms.signer)
 	if err != nil {
 		return err
 	}
@@ -109,10 +113,12 @@ func (ms *RPCMessageSender) SendMessage(ctx context.Context, msg *shmsg.Message)
 	return nil
 }
 
-func (ms *RPCMessageSender) addNonceAndChainID(msg *shmsg.Message) *shmsg.MessageWithNonce {
+// addNonceAndChainID wraps the message with the chain id and a nonce bound to the given
+// shuttermint block height.
+func (ms *RPCMessageSender) addNonceAndChainID(msg *shmsg.Message, height int64) *shmsg.MessageWithNonce {
 	return &shmsg.MessageWithNonce{
 		ChainId:     []byte(ms.chainID),
-		RandomNonce: randomNonce(),
+		RandomNonce: shmsg.NewNonce(height, randomNonce()),
 		Msg:         msg,
 	}
 }
@@ -134,12 +140,12 @@ func (ms *RPCMessageSender) maybeFetchChainID(ctx context.Context) error {
 	return nil
 }
 
-func randomNonce() uint64 {
-	var bytes [8]byte
+func randomNonce() uint32 {
+	var bytes [4]byte
 	if _, err := rand.Read(bytes[:]); err != nil {
 		panic("Failed to read random bytes for nonce.")
 	}
-	return binary.LittleEndian.Uint64(bytes[:])
+	return binary.LittleEndian.Uint32(bytes[:])
 }
 
 // NewMockMessageSender creates a new MockMessageSender. We use a buffered channel with a rather
@@ -3,10 +3,10 @@ package keyper
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
@@ -22,14 +22,19 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/keypermetrics"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprapi"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprconfig"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprstatus"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/smobserver"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/broker"
+	syncclient "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/channel"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/db"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/failover"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
@@ -39,15 +44,24 @@ type KeyperCore struct {
 	trigger <-chan *broker.Event[*epochkghandler.DecryptionTrigger]
 	opts    *options
 	config  *kprconfig.Config
+	keyring *kprsecrets.Keyring
 
 	dbpool            *pgxpool.Pool
 	shuttermintClient client.Client
 	messaging         p2p.Messaging
 	messageSender     fx.RPCMessageSender
-	blockSyncClient   *ethclient.Client
+	blockSyncClient   syncclient.Client
+	signer            signer.Signer
 
 	shuttermintState *smobserver.ShuttermintState
 	metricsServer    *metricsserver.MetricsServer
+
+	// latestBlockNumber is the most recent block number seen by operateShuttermint
+	latestBlockNumber atomic.Uint64
+	// peerRegistry holds the peers bound to keypers, it is nil if peer records are disabled
+	peerRegistry *peerRegistry
+	// statusView holds the statuses of all keypers, it is nil if keyper statuses are disabled
+	statusView *kprstatus.View
 }
 
 func New(
@@ -62,28 +76,43 @@ func New(
 			return nil, err
 		}
 	}
+	keyring, err := config.Secrets.Keyring()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load secrets key")
+	}
 	sender := opts.messaging
 	if sender == nil {
-		var err error
 		sender, err = p2p.New(config.P2P)
 		if err != nil {
 			return nil, err
 		}
 	}
-	return &KeyperCore{config: config, trigger: trigger, messaging: sender, opts: opts}, nil
+	return &KeyperCore{config: config, keyring: keyring, trigger: trigger, messaging: sender, opts: opts}, nil
+}
+
+// Keyring returns the keyring sealing the keyper's secrets in the database. It is nil if
+// encryption is not enabled.
+func (kpr *KeyperCore) Keyring() *kprsecrets.Keyring {
+	return kpr.keyring
 }
 
 // LinkConfigToDB ensures that we use a database compatible with the given config. On first use
 // it stores the config's ethereum address into the database. On subsequent uses it compares the
 // stored value and raises an error if it doesn't match.
 func LinkConfigToDB(ctx context.Context, config *kprconfig.Config, dbpool *pgxpool.Pool) error {
-	const addressKey = "ethereum address"
-	cfgAddress := config.GetAddress().String()
-	queries := db.New(dbpool)
-	dbAddr, err := queries.GetMeta(ctx, addressKey)
+	return linkAddressToDB(ctx, dbpool, config.GetAddress())
+}
+
+// ethereumAddressMetaKey is the meta_inf key of the keyper address the database is linked to.
+const ethereumAddressMetaKey = "ethereum address"
+
+func linkAddressToDB(ctx context.Context, dbtx db.DBTX, address common.Address) error {
+	cfgAddress := address.String()
+	queries := db.New(dbtx)
+	dbAddr, err := queries.GetMeta(ctx, ethereumAddressMetaKey)
 	if err == pgx.ErrNoRows {
 		return queries.InsertMeta(ctx, db.InsertMetaParams{
-			Key:   addressKey,
+			Key:   ethereumAddressMetaKey,
 			Value: cfgAddress,
 		})
 	} else if err != nil {
@@ -114,12 +143,26 @@ func (kpr *KeyperCore) initOptions(ctx context.Context, runner service.Runner) e
 	} else {
 		kpr.dbpool = kpr.opts.dbpool
 	}
+	if kpr.opts.signer == nil {
+		kpr.signer, err = signer.New(kpr.config.Ethereum)
+		if err != nil {
+			return err
+		}
+	} else {
+		kpr.signer = kpr.opts.signer
+	}
 	if kpr.opts.blockSyncClient == nil {
-		var err error
-		kpr.blockSyncClient, err = ethclient.DialContext(ctx, kpr.config.Ethereum.EthereumURL)
+		var blockSyncClient *failover.Client
+		blockSyncClient, err = failover.Dial(ctx, kpr.config.Ethereum.EthereumURLs())
+		if err != nil {
+			return err
+		}
+		runner.Defer(blockSyncClient.Close)
+		err = runner.StartService(blockSyncClient)
 		if err != nil {
 			return err
 		}
+		kpr.blockSyncClient = blockSyncClient
 	} else {
 		kpr.blockSyncClient = kpr.opts.blockSyncClient
 	}
@@ -133,6 +176,10 @@ func (kpr *KeyperCore) Start(ctx context.Context, runner service.Runner) error {
 		return err
 	}
 
+	err = signer.Verify(ctx, kpr.signer)
+	if err != nil {
+		return errors.Wrap(err, "signer is not usable")
+	}
 	err = kpr.dbpool.BeginFunc(db.WrapContext(ctx, database.Definition.Validate))
 	if err != nil {
 		return err
@@ -141,31 +188,72 @@ func (kpr *KeyperCore) Start(ctx context.Context, runner service.Runner) error {
 	if err != nil {
 		return err
 	}
+	err = kpr.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return resealSecrets(ctx, tx, kpr.keyring)
+	})
+	if err != nil {
+		return err
+	}
 	shuttermintClient, err := tmhttp.New(config.Shuttermint.ShuttermintURL, "/websocket")
 	if err != nil {
 		return err
 	}
-	messageSender := fx.NewRPCMessageSender(shuttermintClient, config.Ethereum.PrivateKey.Key)
+	messageSender := fx.NewRPCMessageSender(shuttermintClient, kpr.signer)
 
 	if kpr.config.Metrics.Enabled {
 		keypermetrics.InitMetrics(kpr.dbpool, *kpr.config)
 		epochkghandler.InitMetrics()
 		deployment.InitMetrics()
+		failover.InitMetrics()
 		kpr.metricsServer = metricsserver.New(kpr.config.Metrics)
 	}
 
 	kpr.shuttermintClient = shuttermintClient
 	kpr.messageSender = messageSender
-	kpr.shuttermintState = smobserver.NewShuttermintState(config)
+	kpr.shuttermintState = smobserver.NewShuttermintState(config, kpr.keyring)
 
+	err = kpr.addMessageHandlers(ctx)
+	if err != nil {
+		return err
+	}
+	if kpr.config.Metrics.Enabled && kpr.statusView != nil {
+		kprstatus.InitMetrics(kpr.statusView)
+	}
+	return runner.StartService(kpr.getServices()...)
+}
+
+func (kpr *KeyperCore) addMessageHandlers(ctx context.Context) error {
+	if kpr.config.PeerRecords != nil {
+		err := kpr.initPeerRecords(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	if kpr.config.KeyperStatus != nil {
+		err := kpr.initKeyperStatus()
+		if err != nil {
+			return err
+		}
+	}
 	kpr.messaging.AddMessageHandler(
-		epochkghandler.NewDecryptionKeyHandler(kpr.config, kpr.dbpool),
-		epochkghandler.NewDecryptionKeyShareHandler(kpr.config, kpr.dbpool),
+		epochkghandler.NewDecryptionKeyHandler(kpr.config, kpr.dbpool, kpr.keyring),
+		epochkghandler.NewDecryptionKeyShareHandler(kpr.config, kpr.dbpool, kpr.keyring),
 		// this is purely used to subscribe to the public key topic for broadcast
 		epochkghandler.NewEonPublicKeyHandler(kpr.config, kpr.dbpool),
 	)
 	kpr.messaging.AddMessageHandler(kpr.opts.messageHandler...)
-	return runner.StartService(kpr.getServices()...)
+	return nil
+}
+
+// InitReplay registers the keyper's message handlers with its messaging without starting any of
+// its services. It is used to replay captured messages, see p2p.P2PMessaging.Replay. The database
+// has to be provided with the WithDBPool option.
+func (kpr *KeyperCore) InitReplay(ctx context.Context) error {
+	if kpr.opts.dbpool == nil {
+		return errors.New("replaying messages requires a database pool")
+	}
+	kpr.dbpool = kpr.opts.dbpool
+	return kpr.addMessageHandlers(ctx)
 }
 
 func (kpr *KeyperCore) getServices() []service.Service {
@@ -176,7 +264,7 @@ func (kpr *KeyperCore) getServices() []service.Service {
 	}
 	keyTrigger := kpr.trigger
 	if kpr.config.HTTPEnabled {
-		httpServer := kprapi.NewHTTPService(kpr.dbpool, kpr.config, kpr.messaging)
+		httpServer := kprapi.NewHTTPService(kpr.dbpool, kpr.config, kpr.keyring, kpr.messaging, kpr.statusView)
 		services = append(services, httpServer)
 		// combine two sources of decryption triggers
 		// and spawn the fan-in routine
@@ -190,6 +278,7 @@ func (kpr *KeyperCore) getServices() []service.Service {
 		KeyperAddress:        kpr.config.GetAddress(),
 		MaxNumKeysPerMessage: kpr.config.GetMaxNumKeysPerMessage(),
 		DBPool:               kpr.dbpool,
+		Keyring:              kpr.keyring,
 		Messaging:            kpr.messaging,
 		Trigger:              keyTrigger,
 	}
@@ -197,6 +286,15 @@ func (kpr *KeyperCore) getServices() []service.Service {
 	if kpr.config.Metrics.Enabled {
 		services = append(services, kpr.metricsServer)
 	}
+	if kpr.config.PeerRecords != nil {
+		services = append(services, service.Function{Func: kpr.publishPeerRecords})
+	}
+	if kpr.config.KeyperStatus != nil {
+		services = append(services, service.Function{Func: kpr.publishKeyperStatus})
+	}
+	if kpr.config.Retention != nil && kpr.config.Retention.Enabled {
+		services = append(services, service.Function{Func: kpr.runRetention})
+	}
 	return services
 }
 
@@ -408,6 +506,7 @@ func (kpr *KeyperCore) operateShuttermint(ctx context.Context, _ service.Runner)
 			return err
 		}
 		keypermetrics.MetricsKeyperCurrentBlockL1.Set(float64(syncBlockNumber))
+		kpr.latestBlockNumber.Store(syncBlockNumber)
 
 		err = smobserver.SyncAppWithDB(ctx, kpr.shuttermintClient, kpr.dbpool, kpr.shuttermintState)
 		if err != nil {
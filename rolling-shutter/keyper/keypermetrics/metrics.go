@@ -143,6 +143,44 @@ var MetricsExecutionClientVersion = prometheus.NewGaugeVec(
 	},
 	[]string{"version"})
 
+var MetricsKeyperRetentionRowsPruned = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "keyper",
+		Name:      "retention_rows_pruned_total",
+		Help:      "Number of rows deleted by the retention policy, partitioned by table",
+	},
+	[]string{"table"},
+)
+
+var MetricsKeyperRetentionCutoffEon = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "keyper",
+		Name:      "retention_cutoff_eon",
+		Help:      "Oldest eon whose decryption keys, shares and signatures are kept",
+	},
+)
+
+var MetricsKeyperKeySharesOrigin = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "keyper",
+		Name:      "key_shares_origin_total",
+		Help:      "Number of received key share messages, partitioned by how their origin was authorized",
+	},
+	[]string{"origin"},
+)
+
+var MetricsKeyperBoundPeers = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "keyper",
+		Name:      "bound_peers",
+		Help:      "Number of keypers whose peer ID is known from a signed peer record",
+	},
+)
+
 func InitMetrics(dbpool *pgxpool.Pool, config kprconfig.Config) {
 	prometheus.MustRegister(MetricsKeyperCurrentBlockL1)
 	prometheus.MustRegister(MetricsKeyperCurrentBlockShuttermint)
@@ -157,6 +195,10 @@ func InitMetrics(dbpool *pgxpool.Pool, config kprconfig.Config) {
 	prometheus.MustRegister(MetricsExecutionClientVersion)
 	prometheus.MustRegister(MetricsKeyperDKGMessagesSent)
 	prometheus.MustRegister(MetricsKeyperDKGMessagesReceived)
+	prometheus.MustRegister(MetricsKeyperRetentionRowsPruned)
+	prometheus.MustRegister(MetricsKeyperRetentionCutoffEon)
+	prometheus.MustRegister(MetricsKeyperKeySharesOrigin)
+	prometheus.MustRegister(MetricsKeyperBoundPeers)
 
 	ctx := context.Background()
 	queries := database.New(dbpool)
@@ -0,0 +1,133 @@
+package keyper
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	obskeyper "github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver/db/keyper"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/cmd/shversion"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprstatus"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+// keyperStatusInitialDelay is the time the keyper waits before publishing its status for the
+// first time, to give the p2p node time to connect to other peers.
+const keyperStatusInitialDelay = 30 * time.Second
+
+// initKeyperStatus creates the liveness view and registers the handler for keyper statuses.
+func (kpr *KeyperCore) initKeyperStatus() error {
+	if err := kpr.config.KeyperStatus.Validate(); err != nil {
+		return errors.Wrap(err, "invalid keyper status config")
+	}
+	staleAfter := time.Duration(kpr.config.KeyperStatus.StaleAfter) * time.Second //nolint:gosec // G115
+	kpr.statusView = kprstatus.NewView(staleAfter)
+	kpr.messaging.AddMessageHandler(
+		kprstatus.NewHandler(kpr.config.GetInstanceID(), kpr.keyperSetMembers, kpr.statusView),
+	)
+	return nil
+}
+
+// keyperSetMembers returns the members of the keyper set with the eon as keyper config index.
+func (kpr *KeyperCore) keyperSetMembers(ctx context.Context, eon uint64) ([]common.Address, bool, error) {
+	keyperSet, err := obskeyper.New(kpr.dbpool).GetKeyperSetByKeyperConfigIndex(ctx, int64(eon)) //nolint:gosec // G115
+	if err == pgx.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	members, err := shdb.DecodeAddresses(keyperSet.Keypers)
+	if err != nil {
+		return nil, false, err
+	}
+	return members, true, nil
+}
+
+// newKeyperStatus returns the status of the keyper or nil if it is not a member of the keyper set
+// active at the latest block.
+func (kpr *KeyperCore) newKeyperStatus(ctx context.Context) (*p2pmsg.KeyperStatus, error) {
+	blockNumber := kpr.latestBlockNumber.Load()
+	if blockNumber == 0 {
+		return nil, nil
+	}
+	keyperSet, err := obskeyper.New(kpr.dbpool).GetKeyperSet(ctx, int64(blockNumber)) //nolint:gosec // G115
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get keyper set from db")
+	}
+	keyperIndex, err := keyperSet.GetIndex(kpr.config.GetAddress())
+	if err != nil {
+		// not a member
+		return nil, nil //nolint:nilerr
+	}
+	shuttermintHeight, err := database.New(kpr.dbpool).GetLastCommittedHeight(ctx)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, errors.Wrap(err, "failed to get shuttermint sync height from db")
+	}
+	var slot uint64
+	if kpr.opts.statusSlot != nil {
+		slot = kpr.opts.statusSlot()
+	}
+	return &p2pmsg.KeyperStatus{
+		InstanceId:        kpr.config.GetInstanceID(),
+		KeyperIndex:       keyperIndex,
+		Eon:               uint64(keyperSet.KeyperConfigIndex), //nolint:gosec // G115
+		BlockNumber:       blockNumber,
+		Slot:              slot,
+		ShuttermintHeight: shuttermintHeight,
+		Version:           shversion.VersionShort(),
+		Timestamp:         uint64(time.Now().Unix()), //nolint:gosec // G115
+	}, nil
+}
+
+// publishKeyperStatus periodically publishes the keyper's own status.
+func (kpr *KeyperCore) publishKeyperStatus(ctx context.Context, _ service.Runner) error {
+	interval := time.Duration(kpr.config.KeyperStatus.PublishInterval) * time.Second //nolint:gosec // G115
+	delay := keyperStatusInitialDelay
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = interval
+		status, err := kpr.newKeyperStatus(ctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to get keyper status")
+			continue
+		}
+		if status == nil {
+			log.Debug().Msg("not publishing keyper status, not a member of the active keyper set")
+			continue
+		}
+		status, err = p2pmsg.NewSignedKeyperStatus(ctx, status, kpr.signer)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to sign keyper status")
+			continue
+		}
+		if err := kpr.publishStatus(ctx, status); err != nil {
+			log.Warn().Err(err).Msg("failed to publish keyper status")
+		}
+	}
+}
+
+// publishStatus publishes the keyper's own signed status and records it in the status view. Our
+// own messages are not passed to the message handlers, so the status has to be recorded here. This
+// must happen after publishing, as the validator ignores statuses that are not newer than the
+// known one.
+func (kpr *KeyperCore) publishStatus(ctx context.Context, status *p2pmsg.KeyperStatus) error {
+	err := kpr.messaging.SendMessage(ctx, status, retry.NumberOfRetries(3))
+	kpr.statusView.Update(status)
+	return err
+}
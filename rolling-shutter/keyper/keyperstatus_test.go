@@ -0,0 +1,91 @@
+package keyper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprstatus"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/address"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+var errStatusTestComplete = errors.New("test complete")
+
+func TestPublishStatusIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	config := p2p.NewConfig()
+	assert.NilError(t, configuration.SetExampleValuesRecursive(config))
+	port := 2200
+	listenAddr := &address.P2PAddress{}
+	assert.NilError(t, encodeable.FromString(listenAddr, fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", port)))
+	config.ListenAddresses = []*address.P2PAddress{listenAddr}
+	peerID, err := config.P2PKey.PeerID()
+	assert.NilError(t, err)
+	externalAddr := &address.P2PAddress{}
+	assert.NilError(t, encodeable.FromString(externalAddr, fmt.Sprintf("/ip4/127.0.0.1/tcp/%d/p2p/%s", port, peerID)))
+	config.CustomBootstrapAddresses = []*address.P2PAddress{externalAddr}
+
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	keyperSets := func(_ context.Context, _ uint64) ([]common.Address, bool, error) {
+		return []common.Address{ethcrypto.PubkeyToAddress(key.PublicKey)}, true, nil
+	}
+	messaging, err := p2p.New(config)
+	assert.NilError(t, err)
+	view := kprstatus.NewView(time.Minute)
+	messaging.AddMessageHandler(kprstatus.NewHandler(1, keyperSets, view))
+	kpr := &KeyperCore{messaging: messaging, statusView: view}
+
+	newStatus := func(instanceID uint64, timestamp time.Time) *p2pmsg.KeyperStatus {
+		status, err := p2pmsg.NewSignedKeyperStatus(ctx, &p2pmsg.KeyperStatus{
+			InstanceId: instanceID,
+			Eon:        1,
+			Timestamp:  uint64(timestamp.Unix()),
+		}, signer.NewLocal(key))
+		assert.NilError(t, err)
+		return status
+	}
+
+	testFn := func(ctx context.Context, _ service.Runner) error {
+		// Messages are dropped silently until the topic has been joined, so wait until an invalid
+		// status is rejected by the validator.
+		for messaging.SendMessage(ctx, newStatus(2, time.Now())) == nil {
+			select {
+			case <-ctx.Done():
+				t.Fatalf("waiting for topic to be joined: %s", ctx.Err())
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+
+		now := time.Now()
+		assert.NilError(t, kpr.publishStatus(ctx, newStatus(1, now.Add(-time.Second))))
+		known, ok := view.Get(ethcrypto.PubkeyToAddress(key.PublicKey))
+		assert.Assert(t, ok)
+		assert.Equal(t, known.Timestamp.Unix(), now.Add(-time.Second).Unix())
+
+		assert.NilError(t, kpr.publishStatus(ctx, newStatus(1, now)))
+		known, _ = view.Get(ethcrypto.PubkeyToAddress(key.PublicKey))
+		assert.Equal(t, known.Timestamp.Unix(), now.Unix())
+		return errStatusTestComplete
+	}
+
+	err = service.Run(ctx, messaging, service.Function{Func: testFn})
+	assert.ErrorIs(t, err, errStatusTestComplete)
+}
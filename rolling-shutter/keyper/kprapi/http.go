@@ -14,7 +14,6 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kproapi"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/broker"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/identitypreimage"
-	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
 func sendError(w http.ResponseWriter, code int, message string) {
@@ -96,7 +95,7 @@ func (srv *Server) GetEons(w http.ResponseWriter, r *http.Request) {
 			finished = true
 			successful = encodedDKGResult.Success
 			if successful {
-				dkgResult, err := shdb.DecodePureDKGResult(encodedDKGResult.PureResult)
+				dkgResult, err := database.DecodePureDKGResult(srv.keyring, encodedDKGResult.PureResult)
 				if err != nil {
 					sendError(w, http.StatusInternalServerError, err.Error())
 					return
@@ -119,6 +118,31 @@ func (srv *Server) GetEons(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(res)
 }
 
+func (srv *Server) GetKeyperStatuses(w http.ResponseWriter, _ *http.Request) {
+	if srv.statuses == nil {
+		sendError(w, http.StatusNotFound, "keyper statuses are disabled")
+		return
+	}
+	res := kproapi.KeyperStatuses{}
+	for _, status := range srv.statuses.Statuses() {
+		res = append(res, kproapi.KeyperStatus{
+			KeyperAddress:     status.KeyperAddress.Hex(),
+			KeyperIndex:       status.KeyperIndex,
+			Eon:               status.Eon,
+			BlockNumber:       status.BlockNumber,
+			Slot:              status.Slot,
+			ShuttermintHeight: status.ShuttermintHeight,
+			Version:           status.Version,
+			Timestamp:         status.Timestamp.Unix(),
+			ReceivedAt:        status.ReceivedAt.Unix(),
+			Live:              srv.statuses.IsLive(status),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
 func (srv *Server) SubmitDecryptionTrigger(w http.ResponseWriter, r *http.Request) {
 	var requestBody kproapi.SubmitDecryptionTriggerJSONRequestBody
 	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
@@ -18,6 +18,8 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/epochkghandler"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kproapi"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprstatus"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/broker"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
@@ -39,7 +41,10 @@ type Config interface {
 type Server struct {
 	dbpool      *pgxpool.Pool
 	config      Config
+	keyring     *kprsecrets.Keyring
 	p2p         P2PMessageSender
+	statuses    *kprstatus.View
+	keys        *keyStream
 	trigger     chan *broker.Event[*epochkghandler.DecryptionTrigger]
 	shutdownSig chan struct{}
 }
@@ -50,7 +55,9 @@ const decrTrigChanBufferSize = 0
 func NewHTTPService(
 	dbpool *pgxpool.Pool,
 	config Config,
+	keyring *kprsecrets.Keyring,
 	p2p P2PMessageSender,
+	statuses *kprstatus.View,
 ) *Server {
 	trigger := make(
 		chan *broker.Event[*epochkghandler.DecryptionTrigger],
@@ -59,7 +66,10 @@ func NewHTTPService(
 	return &Server{
 		dbpool:      dbpool,
 		config:      config,
+		keyring:     keyring,
 		p2p:         p2p,
+		statuses:    statuses,
+		keys:        newKeyStream(),
 		trigger:     trigger,
 		shutdownSig: make(chan struct{}),
 	}
@@ -116,6 +126,9 @@ func (srv *Server) Start(ctx context.Context, runner service.Runner) error { //n
 	runner.Defer(func() { close(srv.shutdownSig) })
 
 	runner.Go(httpServer.ListenAndServe)
+	runner.Go(func() error {
+		return srv.keys.run(ctx, srv.dbpool)
+	})
 	runner.Go(func() error {
 		return srv.waitShutdown(ctx)
 	})
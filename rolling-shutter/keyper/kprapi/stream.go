@@ -0,0 +1,233 @@
+package kprapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kproapi"
+)
+
+const (
+	// decryptionKeyChannel is the postgres notification channel decryption keys are published on
+	// when they are inserted, see the keyper-4 migration.
+	decryptionKeyChannel = "decryption_key"
+
+	maxStreamSubscribers     = 100
+	streamBufferSize         = 100
+	streamKeepAliveInterval  = 15 * time.Second
+	streamReconnectInterval  = 5 * time.Second
+	streamDecryptionKeyEvent = "decryptionKey"
+)
+
+var errTooManySubscribers = errors.New("too many subscribers")
+
+// keyStream listens for decryption keys inserted into the database and forwards them to the
+// subscribed clients. Subscribers that don't keep up are dropped instead of holding up the others.
+type keyStream struct {
+	mux         sync.Mutex
+	subscribers map[chan kproapi.StreamedDecryptionKey]struct{}
+	closed      bool
+}
+
+func newKeyStream() *keyStream {
+	return &keyStream{
+		subscribers: make(map[chan kproapi.StreamedDecryptionKey]struct{}),
+	}
+}
+
+// subscribe returns a channel receiving all keys published from now on. It is closed when the
+// subscriber is dropped or the stream is closed.
+func (s *keyStream) subscribe() (chan kproapi.StreamedDecryptionKey, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if len(s.subscribers) >= maxStreamSubscribers {
+		return nil, errTooManySubscribers
+	}
+	ch := make(chan kproapi.StreamedDecryptionKey, streamBufferSize)
+	if s.closed {
+		close(ch)
+		return ch, nil
+	}
+	s.subscribers[ch] = struct{}{}
+	return ch, nil
+}
+
+func (s *keyStream) unsubscribe(ch chan kproapi.StreamedDecryptionKey) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.subscribers[ch]; ok {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (s *keyStream) publish(key kproapi.StreamedDecryptionKey) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- key:
+		default:
+			log.Warn().Msg("dropping decryption key stream subscriber that doesn't keep up")
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (s *keyStream) close() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.closed = true
+	for ch := range s.subscribers {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
+
+// run listens for decryption key notifications until the context is canceled, reconnecting to
+// the database if the connection is lost.
+func (s *keyStream) run(ctx context.Context, dbpool *pgxpool.Pool) error {
+	defer s.close()
+	for {
+		err := s.listen(ctx, dbpool)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Warn().Err(err).Msg("failed to listen for decryption keys, reconnecting")
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(streamReconnectInterval):
+		}
+	}
+}
+
+func (s *keyStream) listen(ctx context.Context, dbpool *pgxpool.Pool) error {
+	conn, err := dbpool.Acquire(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to acquire database connection")
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, "LISTEN "+decryptionKeyChannel)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen on notification channel")
+	}
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var key kproapi.StreamedDecryptionKey
+		if err := json.Unmarshal([]byte(notification.Payload), &key); err != nil {
+			log.Warn().Err(err).Str("payload", notification.Payload).Msg("received invalid decryption key notification")
+			continue
+		}
+		s.publish(key)
+	}
+}
+
+// streamFilter selects the keys sent to a stream client.
+type streamFilter struct {
+	eon *int
+	// identityPrefix is lowercase hex without 0x prefix
+	identityPrefix string
+}
+
+// normalizeHex returns the given hex string in lowercase and without 0x prefix. It fails if the
+// string does not encode whole bytes.
+func normalizeHex(s string) (string, error) {
+	s = strings.ToLower(s)
+	s = strings.TrimPrefix(s, "0x")
+	if _, err := hex.DecodeString(s); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+func newStreamFilter(params kproapi.StreamDecryptionKeysParams) (streamFilter, error) {
+	filter := streamFilter{eon: params.Eon}
+	if params.IdentityPrefix != nil {
+		identityPrefix, err := normalizeHex(*params.IdentityPrefix)
+		if err != nil {
+			return streamFilter{}, errors.Wrap(err, "invalid identity prefix")
+		}
+		filter.identityPrefix = identityPrefix
+	}
+	return filter, nil
+}
+
+// matches checks if the key passes the filter.
+func (f streamFilter) matches(key kproapi.StreamedDecryptionKey) bool {
+	if f.eon != nil && key.Eon != uint64(*f.eon) { //nolint:gosec // G115
+		return false
+	}
+	identity := strings.TrimPrefix(strings.ToLower(key.Identity), "0x")
+	return strings.HasPrefix(identity, f.identityPrefix)
+}
+
+func (srv *Server) StreamDecryptionKeys(w http.ResponseWriter, r *http.Request, params kproapi.StreamDecryptionKeysParams) {
+	ctx := r.Context()
+	filter, err := newStreamFilter(params)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	keys, err := srv.keys.subscribe()
+	if err != nil {
+		sendError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	defer srv.keys.unsubscribe(keys)
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Warn().Err(err).Msg("failed to flush decryption key stream")
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+		case key, ok := <-keys:
+			if !ok {
+				return
+			}
+			if !filter.matches(key) {
+				continue
+			}
+			var data []byte
+			data, err = json.Marshal(key)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to encode streamed decryption key")
+				return
+			}
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", streamDecryptionKeyEvent, data)
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package kprapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kproapi"
+)
+
+type testConfig struct{}
+
+func (testConfig) GetHTTPListenAddress() string   { return "" }
+func (testConfig) GetAddress() common.Address     { return common.Address{} }
+func (testConfig) GetInstanceID() uint64          { return 0 }
+func (testConfig) GetEnableWriteOperations() bool { return false }
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	srv := NewHTTPService(nil, testConfig{}, nil, nil, nil)
+	httpServer := httptest.NewServer(srv.setupRouter())
+	t.Cleanup(httpServer.Close)
+	return srv, httpServer
+}
+
+// readEvent reads the next decryption key event from a server-sent event stream.
+func readEvent(t *testing.T, r *bufio.Reader) kproapi.StreamedDecryptionKey {
+	t.Helper()
+	event := ""
+	for {
+		line, err := r.ReadString('\n')
+		assert.NilError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			assert.Equal(t, event, streamDecryptionKeyEvent)
+			var key kproapi.StreamedDecryptionKey
+			assert.NilError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &key))
+			return key
+		}
+	}
+}
+
+func TestStreamDecryptionKeys(t *testing.T) {
+	srv, httpServer := newTestServer(t)
+
+	res, err := http.Get(httpServer.URL + "/v1/decryptionKeys/stream?eon=1&identityPrefix=AB")
+	assert.NilError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Equal(t, res.Header.Get("Content-Type"), "text/event-stream")
+
+	wrongEon := kproapi.StreamedDecryptionKey{Eon: 2, Identity: "0xab01", DecryptionKey: "0x01"}
+	wrongIdentity := kproapi.StreamedDecryptionKey{Eon: 1, Identity: "0xcd01", DecryptionKey: "0x02"}
+	matching := kproapi.StreamedDecryptionKey{Eon: 1, Identity: "0xab02", DecryptionKey: "0x03"}
+	srv.keys.publish(wrongEon)
+	srv.keys.publish(wrongIdentity)
+	srv.keys.publish(matching)
+
+	r := bufio.NewReader(res.Body)
+	assert.DeepEqual(t, readEvent(t, r), matching)
+}
+
+func TestStreamDecryptionKeysInvalidFilter(t *testing.T) {
+	_, httpServer := newTestServer(t)
+
+	for _, identityPrefix := range []string{"0xabc", "0xzz", "xyz", "0x0x"} {
+		res, err := http.Get(httpServer.URL + "/v1/decryptionKeys/stream?identityPrefix=" + identityPrefix)
+		assert.NilError(t, err)
+		res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusBadRequest, identityPrefix)
+	}
+}
+
+func TestStreamFilterIdentityPrefix(t *testing.T) {
+	key := kproapi.StreamedDecryptionKey{Eon: 1, Identity: "0xabcd01", DecryptionKey: "0x01"}
+	for _, identityPrefix := range []string{"", "0x", "0xab", "ab", "0XAB", "AbCd", "0xabcd01"} {
+		filter, err := newStreamFilter(kproapi.StreamDecryptionKeysParams{IdentityPrefix: &identityPrefix})
+		assert.NilError(t, err)
+		assert.Assert(t, filter.matches(key), identityPrefix)
+	}
+	for _, identityPrefix := range []string{"0xcd", "abcd0102"} {
+		filter, err := newStreamFilter(kproapi.StreamDecryptionKeysParams{IdentityPrefix: &identityPrefix})
+		assert.NilError(t, err)
+		assert.Assert(t, !filter.matches(key), identityPrefix)
+	}
+	for _, identityPrefix := range []string{"0xa", "0xgg", "0x0xab"} {
+		_, err := newStreamFilter(kproapi.StreamDecryptionKeysParams{IdentityPrefix: &identityPrefix})
+		assert.ErrorContains(t, err, "invalid identity prefix")
+	}
+}
+
+func TestKeyStreamDropsSlowSubscribers(t *testing.T) {
+	s := newKeyStream()
+	ch, err := s.subscribe()
+	assert.NilError(t, err)
+	for i := 0; i < streamBufferSize+1; i++ {
+		s.publish(kproapi.StreamedDecryptionKey{Eon: uint64(i)})
+	}
+	n := 0
+	for range ch {
+		n++
+	}
+	assert.Equal(t, n, streamBufferSize)
+}
+
+func TestKeyStreamMaxSubscribers(t *testing.T) {
+	s := newKeyStream()
+	for i := 0; i < maxStreamSubscribers; i++ {
+		_, err := s.subscribe()
+		assert.NilError(t, err)
+	}
+	_, err := s.subscribe()
+	assert.ErrorIs(t, err, errTooManySubscribers)
+
+	s.close()
+	ch, err := s.subscribe()
+	assert.NilError(t, err)
+	_, ok := <-ch
+	assert.Assert(t, !ok)
+}
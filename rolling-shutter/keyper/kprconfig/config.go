@@ -26,16 +26,20 @@ type Config struct {
 	HTTPReadOnly      bool
 	HTTPListenAddress string
 
-	P2P         *p2p.Config
-	Shuttermint *ShuttermintConfig
-	Ethereum    *configuration.EthnodeConfig
-	Metrics     *metricsserver.MetricsConfig
+	P2P          *p2p.Config
+	Shuttermint  *ShuttermintConfig
+	Ethereum     *configuration.EthnodeConfig
+	Metrics      *metricsserver.MetricsConfig
+	Secrets      *SecretsConfig
+	Retention    *RetentionConfig
+	PeerRecords  *PeerRecordsConfig
+	KeyperStatus *KeyperStatusConfig
 
 	MaxNumKeysPerMessage uint64
 }
 
 func (c *Config) GetAddress() common.Address {
-	return c.Ethereum.PrivateKey.EthereumAddress()
+	return c.Ethereum.Address()
 }
 
 func (c *Config) GetDKGPhaseLength() *dkgphase.PhaseLength {
@@ -0,0 +1,55 @@
+package kprconfig
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var _ configuration.Config = &KeyperStatusConfig{}
+
+func NewKeyperStatusConfig() *KeyperStatusConfig {
+	c := &KeyperStatusConfig{}
+	c.Init()
+	return c
+}
+
+// KeyperStatusConfig configures the signed status messages keypers publish periodically to report
+// their liveness and sync state. The statuses received from all keypers are aggregated into a
+// liveness view which is exported as metrics and served by the HTTP API.
+type KeyperStatusConfig struct {
+	PublishInterval uint64 `comment:"seconds between two publications of the keyper's own status"`
+	StaleAfter      uint64 `comment:"seconds after which a keyper without a newer status is considered down"`
+}
+
+func (c *KeyperStatusConfig) Init() {}
+
+func (c *KeyperStatusConfig) Name() string {
+	return "keyperstatus"
+}
+
+func (c *KeyperStatusConfig) Validate() error {
+	if c.PublishInterval == 0 {
+		return errors.New("PublishInterval must be positive")
+	}
+	if c.StaleAfter <= c.PublishInterval {
+		return errors.New("StaleAfter must be greater than PublishInterval")
+	}
+	return nil
+}
+
+func (c *KeyperStatusConfig) SetDefaultValues() error {
+	c.PublishInterval = 30
+	c.StaleAfter = 4 * 30
+	return nil
+}
+
+func (c *KeyperStatusConfig) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c KeyperStatusConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
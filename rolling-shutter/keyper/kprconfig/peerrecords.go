@@ -0,0 +1,56 @@
+package kprconfig
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var _ configuration.Config = &PeerRecordsConfig{}
+
+func NewPeerRecordsConfig() *PeerRecordsConfig {
+	c := &PeerRecordsConfig{}
+	c.Init()
+	return c
+}
+
+// PeerRecordsConfig configures the signed records binding the libp2p peer IDs of keypers to their
+// Ethereum addresses. Every keyper publishes its own record periodically. Key shares published by
+// a peer bound to a different keyper than the one the shares claim to be from are always
+// rejected. If Enforce is set, key shares are also ignored if neither the publishing nor the
+// forwarding peer is bound to a keyper. It is unset by default and should only be set once all
+// keypers of the set publish peer records, as shares of keypers whose record is not known yet are
+// ignored otherwise.
+type PeerRecordsConfig struct {
+	PublishInterval uint64 `comment:"seconds between two publications of the keyper's own peer record"`
+	Enforce         bool   `comment:"ignore key shares not published or forwarded by a peer bound to a keyper"`
+}
+
+func (c *PeerRecordsConfig) Init() {}
+
+func (c *PeerRecordsConfig) Name() string {
+	return "peerrecords"
+}
+
+func (c *PeerRecordsConfig) Validate() error {
+	if c.PublishInterval == 0 {
+		return errors.New("PublishInterval must be positive")
+	}
+	return nil
+}
+
+func (c *PeerRecordsConfig) SetDefaultValues() error {
+	c.PublishInterval = 5 * 60
+	c.Enforce = false
+	return nil
+}
+
+func (c *PeerRecordsConfig) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c PeerRecordsConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,62 @@
+package kprconfig
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var _ configuration.Config = &RetentionConfig{}
+
+func NewRetentionConfig() *RetentionConfig {
+	c := &RetentionConfig{}
+	c.Init()
+	return c
+}
+
+// RetentionConfig defines which decryption keys, key shares, triggers and signatures are deleted
+// from the database. Data of an eon is deleted if it is not among the KeepEons most recent eons or
+// if the eon has been superseded by another one more than MaxAgeBlocks blocks ago. A value of 0
+// disables the respective limit.
+type RetentionConfig struct {
+	Enabled             bool
+	Interval            uint64 `comment:"seconds between two pruning runs"`
+	KeepEons            uint64 `comment:"number of most recent eons to keep data for"`
+	MaxAgeBlocks        uint64 `comment:"number of blocks data of superseded eons is kept for"`
+	MaxAgeSlots         uint64 `comment:"number of slots slot based data is kept for, if the keyper implementation has any"`
+	PruneDecryptionKeys bool   `comment:"also delete decryption keys, ignored if the HTTP API serving them is enabled"`
+}
+
+func (c *RetentionConfig) Init() {}
+
+func (c *RetentionConfig) Name() string {
+	return "retention"
+}
+
+func (c *RetentionConfig) Validate() error {
+	if c.Enabled && c.Interval == 0 {
+		return errors.New("Interval must be positive")
+	}
+	return nil
+}
+
+func (c *RetentionConfig) SetDefaultValues() error {
+	c.Enabled = false
+	c.Interval = 60 * 60
+	return nil
+}
+
+func (c *RetentionConfig) SetExampleValues() error {
+	err := c.SetDefaultValues()
+	if err != nil {
+		return err
+	}
+	c.KeepEons = 2
+	return nil
+}
+
+func (c RetentionConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
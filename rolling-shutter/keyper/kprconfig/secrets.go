@@ -0,0 +1,96 @@
+package kprconfig
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var _ configuration.Config = &SecretsConfig{}
+
+func NewSecretsConfig() *SecretsConfig {
+	c := &SecretsConfig{}
+	c.Init()
+	return c
+}
+
+// SecretsConfig references the key used to encrypt the keyper's DKG secrets in the database. If
+// neither KeyFile nor KeyEnvVar is set, secrets are stored unencrypted.
+type SecretsConfig struct {
+	KeyFile            string   `comment:"Optional, file containing the hex encoded 32 byte key used to encrypt DKG secrets in the database"` //nolint:lll
+	KeyEnvVar          string   `comment:"Optional, environment variable containing the key, alternative to KeyFile"`
+	PreviousKeyFiles   []string `comment:"Optional, files containing keys used before, only needed while rotating the key"`
+	PreviousKeyEnvVars []string `comment:"Optional, environment variables containing keys used before"`
+}
+
+func (c *SecretsConfig) Init() {
+	c.PreviousKeyFiles = []string{}
+	c.PreviousKeyEnvVars = []string{}
+}
+
+func (c *SecretsConfig) Name() string {
+	return "secrets"
+}
+
+func (c *SecretsConfig) Validate() error {
+	if c.KeyFile != "" && c.KeyEnvVar != "" {
+		return errors.New("only one of KeyFile and KeyEnvVar can be set")
+	}
+	if !c.Enabled() && (len(c.PreviousKeyFiles) > 0 || len(c.PreviousKeyEnvVars) > 0) {
+		return errors.New("previous keys are configured, but no current key")
+	}
+	return nil
+}
+
+func (c *SecretsConfig) SetDefaultValues() error {
+	return nil
+}
+
+func (c *SecretsConfig) SetExampleValues() error {
+	return nil
+}
+
+func (c SecretsConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
+
+// Enabled reports if a key to encrypt secrets is configured.
+func (c *SecretsConfig) Enabled() bool {
+	return c != nil && (c.KeyFile != "" || c.KeyEnvVar != "")
+}
+
+// Keyring loads the configured keys. It returns nil if encryption is not enabled.
+func (c *SecretsConfig) Keyring() (*kprsecrets.Keyring, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+	var current []byte
+	var err error
+	if c.KeyFile != "" {
+		current, err = kprsecrets.LoadKeyFile(c.KeyFile)
+	} else {
+		current, err = kprsecrets.LoadKeyEnv(c.KeyEnvVar)
+	}
+	if err != nil {
+		return nil, err
+	}
+	previous := [][]byte{}
+	for _, path := range c.PreviousKeyFiles {
+		key, err := kprsecrets.LoadKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+	for _, name := range c.PreviousKeyEnvVars {
+		key, err := kprsecrets.LoadKeyEnv(name)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+	return kprsecrets.NewKeyring(current, previous...)
+}
@@ -48,6 +48,46 @@ type Error struct {
 	Message string `json:"message"`
 }
 
+// KeyperStatus defines model for KeyperStatus.
+type KeyperStatus struct {
+	BlockNumber   uint64 `json:"block_number"`
+	Eon           uint64 `json:"eon"`
+	KeyperAddress string `json:"keyper_address"`
+	KeyperIndex   uint64 `json:"keyper_index"`
+
+	// Live Whether the status has been received recently enough for the keyper to be considered live
+	Live bool `json:"live"`
+
+	// ReceivedAt Unix time the status has been received at
+	ReceivedAt        int64  `json:"received_at"`
+	ShuttermintHeight int64  `json:"shuttermint_height"`
+	Slot              uint64 `json:"slot"`
+
+	// Timestamp Unix time the keyper has created the status at
+	Timestamp int64  `json:"timestamp"`
+	Version   string `json:"version"`
+}
+
+// KeyperStatuses defines model for KeyperStatuses.
+type KeyperStatuses = []KeyperStatus
+
+// StreamedDecryptionKey defines model for StreamedDecryptionKey.
+type StreamedDecryptionKey struct {
+	DecryptionKey string `json:"decryption_key"`
+	Eon           uint64 `json:"eon"`
+	Identity      string `json:"identity"`
+}
+
+// StreamDecryptionKeysParams defines parameters for StreamDecryptionKeys.
+type StreamDecryptionKeysParams struct {
+	// Eon Only stream keys of this eon
+	Eon *int `form:"eon,omitempty" json:"eon,omitempty"`
+
+	// IdentityPrefix Only stream keys for identities starting with this prefix, given as hex encoded bytes
+	// with optional 0x prefix. Case is ignored.
+	IdentityPrefix *string `form:"identityPrefix,omitempty" json:"identityPrefix,omitempty"`
+}
+
 // SubmitDecryptionTriggerJSONRequestBody defines body for SubmitDecryptionTrigger for application/json ContentType.
 type SubmitDecryptionTriggerJSONRequestBody = DecryptionTrigger
 
@@ -57,12 +97,18 @@ type ServerInterface interface {
 	// (GET /decryptionKey/{eon}/{epochID})
 	GetDecryptionKey(w http.ResponseWriter, r *http.Request, eon int, epochID EpochID)
 
+	// (GET /decryptionKeys/stream)
+	StreamDecryptionKeys(w http.ResponseWriter, r *http.Request, params StreamDecryptionKeysParams)
+
 	// (POST /decryptionTrigger)
 	SubmitDecryptionTrigger(w http.ResponseWriter, r *http.Request)
 
 	// (GET /eons)
 	GetEons(w http.ResponseWriter, r *http.Request)
 
+	// (GET /keyperStatuses)
+	GetKeyperStatuses(w http.ResponseWriter, r *http.Request)
+
 	// (GET /ping)
 	Ping(w http.ResponseWriter, r *http.Request)
 
@@ -79,6 +125,11 @@ func (_ Unimplemented) GetDecryptionKey(w http.ResponseWriter, r *http.Request,
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// (GET /decryptionKeys/stream)
+func (_ Unimplemented) StreamDecryptionKeys(w http.ResponseWriter, r *http.Request, params StreamDecryptionKeysParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // (POST /decryptionTrigger)
 func (_ Unimplemented) SubmitDecryptionTrigger(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
@@ -89,6 +140,11 @@ func (_ Unimplemented) GetEons(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// (GET /keyperStatuses)
+func (_ Unimplemented) GetKeyperStatuses(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // (GET /ping)
 func (_ Unimplemented) Ping(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
@@ -143,6 +199,42 @@ func (siw *ServerInterfaceWrapper) GetDecryptionKey(w http.ResponseWriter, r *ht
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
+// StreamDecryptionKeys operation middleware
+func (siw *ServerInterfaceWrapper) StreamDecryptionKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params StreamDecryptionKeysParams
+
+	// ------------- Optional query parameter "eon" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "eon", r.URL.Query(), &params.Eon)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "eon", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "identityPrefix" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "identityPrefix", r.URL.Query(), &params.IdentityPrefix)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "identityPrefix", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.StreamDecryptionKeys(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // SubmitDecryptionTrigger operation middleware
 func (siw *ServerInterfaceWrapper) SubmitDecryptionTrigger(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -173,6 +265,21 @@ func (siw *ServerInterfaceWrapper) GetEons(w http.ResponseWriter, r *http.Reques
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
+// GetKeyperStatuses operation middleware
+func (siw *ServerInterfaceWrapper) GetKeyperStatuses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetKeyperStatuses(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // Ping operation middleware
 func (siw *ServerInterfaceWrapper) Ping(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -319,12 +426,18 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/decryptionKey/{eon}/{epochID}", wrapper.GetDecryptionKey)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/decryptionKeys/stream", wrapper.StreamDecryptionKeys)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/decryptionTrigger", wrapper.SubmitDecryptionTrigger)
 	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/eons", wrapper.GetEons)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/keyperStatuses", wrapper.GetKeyperStatuses)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/ping", wrapper.Ping)
 	})
@@ -338,21 +451,31 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/8xV32/bNhD+V4hbgb3IltoGAaa3DgkGYxhQIHnLsoCWThIb6aiRpzSCof99IMXEki07",
-	"67ACfbNF3o/vu+/j7SDTTasJiS2kO7BZhY30P68wM33LStPv2LsPrWRGQ5DCX8nzXbL6Ra6K+93lxfAO",
-	"IuC+RUjBslFUwhBNwm+NKks0PoXRLRpW6Ctsa509PlDXbMfTQptGMqTQKeLLi31WRYwuwxABtjqrHlT+",
-	"rQ0NERj8u1MGc0jv9mmieRf3r4F6+wUzdiWvNR33LjNWT9LBeziE0ShSTddAmiwC0PTweI7Q95fLhBaK",
-	"lK3QIw+HW61rlOROFeX4/HZ122UZWlt09VKaA5LGnNFJrHswk+5mNU7Q6RlUjI3/8c5gASn8FO+lGAcd",
-	"xo774TWJNEb2Pocb3+bq21V5bYxeUGKmc5wpUBF//LAowAatlSVO6DshMZ9zf/+YisGPrdBjA8QyY/eT",
-	"ZOOzVp0DtiLkr9o8QgSdqSGFirlN4zgcr8Nx7FrL0WZGec9BCreVsmL8tEUruEJhdF0rKkUI/tmKR+xb",
-	"NOLT5w1EUKsMyeKkiT82t55+xbX7exAfoiGCJzR2rJqs368TF6NbJNkqSOHjOlknELlJVZ7tOJ8+LfEO",
-	"NQ3xDseZDu5GiZ6KOaDfkD2KfbRrQBTaCEnCh4vNFfjSxst1k49h86fMdWJkg4zGQnp3WOZak9DFUiXW",
-	"wjXmpgaphwPRC1OoCabzZ9NhFJ7Tt2w5REc9BDD/pZFgjXPNnHVciB+Ge5fCtprsaJIPSfIiVSQ/H9m2",
-	"tco81fEXOz6T/67KfCLeCofqPUTtRHWRXBzrAp2nhVpkqpJWkGaxRSRRIjlhYC565NEwhexq/t9Qja/L",
-	"ApqO8LnFzJXGcCeC55VBma801f04Ivdx4o3p3tR2wRDhwiHmANP9nXrjyBg33bZRfLynR+Wg5V913n+H",
-	"ib/UWeDp0xQLB3i2xUwVvXt4RpNLyoVfROJ1Ec21Piwrd14rrKkfTAeFrO0oBAx78uRb6HaHW1eOK7nV",
-	"HQtZ18KyNIz5n7T0EPrd+x1t7fMv4N6ca1V4pD+oHVu32k9N4bOictysFs0TmgXS3ZU549TV9alqbrHm",
-	"+iud9vxN1bFwV/Zll2Z985LordJBb8PwTwAAAP//rMNaTQoMAAA=",
+	"H4sIAAAAAAAC/8xYe2/bRhL/Kou9AJccqEcSX4DTP4e0cQM3aBPAKVrAdo0VORQ3JmeZ3aEjwuB3L/Yh",
+	"iaRISQkSwH+ZJncev5nfPFYPPFZFqRCQDF88cBNnUAj3+AZiXZckFb6D2r4oBRFo5Av+93x9NZ/8T0zS",
+	"m4dXZ80THnGqS+ALbkhLXPEmaol/1HK1Au1UaFWCJgnOwjJX8d0tVsXSf02VLgTxBa8k0quznVaJBFZD",
+	"E3EoVZzdyuRrHWoiruFzJTUkfHG1UxN1vbjZCqrlJ4jJmjxXuO+7iEneCwvvtg+jkCiLquCL+SAAhbd3",
+	"hwL6/NVwQFOJ0mTgkIePS6VyEGi/Skxgfdy6qeIYjEmrfEhNL0heZzSKdQem5V3Hxkg4XQQlQeEenmhI",
+	"+YL/a7aj4izwcGZj32yVCK1F7XTY9F28+XpWnmutBpgYqwQ6DJRIL18MErAAY8QKWuEboZjTuTs/FIp3",
+	"UJegL0lQZb5TeSg89eidM34rkkSDMWOhfD355ebhbD4czaBiy71TzOby3gUvARNr6RoEX/A/M6AMNKMM",
+	"mHHxYJkwbAmATEMM8h4S94CU1wxQVauMpcoLeDcYKbYEFis0MgENCXOmooFi2Wi8FbTvyh8o14xkAYed",
+	"EcSjHeBxvCarbFQLiXSbgVxl1CfamGCu6NSgWncNiaI8BieEysKJNQiCpI3yVEz3oI30TDtcAz2O9Rjj",
+	"6drrwAH4YOB2ltuQu/kMDDtWbnB6D2qLDTWjS9IgCkj2B2annJPt58H+/3Tbu140z/4zWG5fUdwyASRJ",
+	"32CmPyhdsLfqoj6M/ThbDRJT5fsqkogdjVEUzozP6gSBvih9xyNe6ZwveEZULmaz8HkaPs8sli6fP2bS",
+	"MP9qCcaxV6s8l7hiQfjfZkPz1x8uHB9iQAMtJ367+OirhnL7b08+SLfYtuDz6fPp3MqoElCUki/4y+l8",
+	"OueRDW/mEjxL2gSYPYDCZvYAflQ19sQKBvrNWyCHYidtHXDNTSBz4uziDXemtZvCF4kX6xLOeqJFAQTa",
+	"8MVV38y5QqbSIUukmHXMZo0vHBwebSLl078jBOkKorAlHts2mmjPhwDmWxwJE/+QMwcXiSDfNDdWhSkV",
+	"hh7wYj7fUBXQ5UeUZS5jF+rZJ+OL7jQr3Yy4Uuizt4/akupsfrbPC9BaaSYHI2X7NyryI2kFaIkBCauB",
+	"fMGkosrpu6HyS9MAmgphXUJsTUM4E/H1RINIJgrz2qfIvuzWhpkZ1zNHa8K31B5qw4RhBvQ96IkBJAb3",
+	"1k33Vim0fymDmgltB5qyG8Cybk29KTsXcXaNTsxRsC7bkX0HNYuF1hIMU+ikGKBd4xKr+9fL979Pr3Gv",
+	"EL2vncybY8X4HvOa+Rh4ZK4gpGGh3doznyvQdb8Sv7Xy9gza9hK6usVrSGiyPfCLpMy7UmpI5TpiK3kP",
+	"LrgZrLfxWNYE5hrdaeWMiJzN10Foyn4WBpg0TK7QJmJ6PQZrM1k+OMEOwtbcejq/Wv918+z/T9tb6dgA",
+	"O17gBGuaORpMdkQ8rRaGh/1AbbzehFulfR7bGv3v/OWhkleKFQJrFufSc1xD0GeTJHJbYvXjr/X21V+Z",
+	"gUIPB/r9LbQ0+297Du7XXrUsJO3/1OCnBBj6SSX1D+juGzuDeW9hoQDPlBDLtLa58wNdYMLc2su2a293",
+	"rjXDJO7aCjftR8aDVOTGEwHCVX9077F7ol1mbazEUlXERJ77ZgTJQK99C+R+PviBI9zpH8B9cchV5pA+",
+	"0nK827v2HNxDc0FgaHMl1FAqh3BZMxBxtlmtwwYn0ZDAGIYy1btu/cCc9SyN7F1dYCpt4zm+hQXcJhhx",
+	"HTmRRixzSB5r5ks7EMfy/UHiyt+f/E41UG72SDdvWOX5mDV7fUrUFxzv9pdZRcwe2ZkdqvLLjaJjpkOn",
+	"aZp/BgCkkweqxxYAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
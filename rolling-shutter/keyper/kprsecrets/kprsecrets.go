@@ -0,0 +1,251 @@
+// Package kprsecrets implements envelope encryption of the keyper's secret material at rest.
+//
+// Every value is encrypted with a fresh random data key, which is in turn encrypted with a key
+// encryption key (KEK) configured by the operator. Sealed values have the layout
+//
+//	sealedPrefix | KEK id (8 bytes) | encrypted data key | nonce | ciphertext
+//
+// where the data key and the value are encrypted with AES-256-GCM. Values without the prefix are
+// treated as legacy plaintext, so that databases written before encryption has been enabled can
+// still be read and encrypted in place.
+package kprsecrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// KeySize is the size of a key encryption key in bytes.
+const KeySize = 32
+
+const (
+	keyIDSize       = 8
+	nonceSize       = 12
+	tagSize         = 16
+	wrappedKeySize  = nonceSize + KeySize + tagSize
+	sealedHeaderLen = len(sealedPrefix) + keyIDSize + wrappedKeySize + nonceSize
+)
+
+// sealedPrefix marks sealed values. It starts with a zero byte, which neither gob streams nor the
+// big endian encoding of big integers start with.
+const sealedPrefix = "\x00shsecret1"
+
+var ErrNoKey = errors.New("value is encrypted, but no key to decrypt it is configured")
+
+// KeyID identifies a key encryption key. It is derived from the key itself.
+type KeyID [keyIDSize]byte
+
+func (id KeyID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+type kek struct {
+	id   KeyID
+	aead cipher.AEAD
+}
+
+func newKEK(key []byte) (*kek, error) {
+	if len(key) != KeySize {
+		return nil, errors.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	k := &kek{aead: aead}
+	digest := sha256.Sum256(key)
+	copy(k.id[:], digest[:])
+	return k, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Keyring holds the key encryption key used to seal new values and the keys that have been used
+// before, which are only used to open values.
+type Keyring struct {
+	current *kek
+	keys    map[KeyID]*kek
+}
+
+// NewKeyring creates a keyring sealing values with current and opening values sealed with current
+// or any of the previous keys.
+func NewKeyring(current []byte, previous ...[]byte) (*Keyring, error) {
+	k, err := newKEK(current)
+	if err != nil {
+		return nil, err
+	}
+	keyring := &Keyring{current: k, keys: map[KeyID]*kek{k.id: k}}
+	for _, key := range previous {
+		k, err := newKEK(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid previous key")
+		}
+		if _, ok := keyring.keys[k.id]; !ok {
+			keyring.keys[k.id] = k
+		}
+	}
+	return keyring, nil
+}
+
+// CurrentKeyID returns the id of the key used to seal values.
+func (k *Keyring) CurrentKeyID() KeyID {
+	return k.current.id
+}
+
+// Seal encrypts plaintext with the current key. A nil keyring returns plaintext unchanged.
+func (k *Keyring) Seal(plaintext []byte) ([]byte, error) {
+	if k == nil || plaintext == nil {
+		return plaintext, nil
+	}
+	dataKey := make([]byte, KeySize)
+	nonces := make([]byte, 2*nonceSize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, nonces); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := make([]byte, 0, sealedHeaderLen+len(plaintext)+tagSize)
+	sealed = append(sealed, sealedPrefix...)
+	sealed = append(sealed, k.current.id[:]...)
+	sealed = append(sealed, nonces[:nonceSize]...)
+	sealed = k.current.aead.Seal(sealed, nonces[:nonceSize], dataKey, k.current.id[:])
+	sealed = append(sealed, nonces[nonceSize:]...)
+	return aead.Seal(sealed, nonces[nonceSize:], plaintext, sealed[:sealedHeaderLen-nonceSize]), nil
+}
+
+// Open decrypts a value sealed with any key of the keyring. Values that are not sealed are
+// returned unchanged.
+func (k *Keyring) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if len(data) < sealedHeaderLen+tagSize {
+		return nil, errors.New("sealed value is too short")
+	}
+	id, err := SealedKeyID(data)
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		return nil, ErrNoKey
+	}
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, errors.Wrapf(ErrNoKey, "value is encrypted with unknown key %s", id)
+	}
+
+	offset := len(sealedPrefix) + keyIDSize
+	wrapped := data[offset : offset+wrappedKeySize]
+	dataKey, err := key.aead.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], id[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt data key")
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := data[sealedHeaderLen-nonceSize : sealedHeaderLen]
+	plaintext, err := aead.Open(nil, nonce, data[sealedHeaderLen:], data[:sealedHeaderLen-nonceSize])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt value")
+	}
+	return plaintext, nil
+}
+
+// NeedsReseal reports if data is not sealed with the current key of the keyring, i.e. if it is
+// plaintext or sealed with a previous key. For a nil keyring, only sealed values need a reseal.
+func (k *Keyring) NeedsReseal(data []byte) bool {
+	if data == nil {
+		return false
+	}
+	if k == nil {
+		return IsSealed(data)
+	}
+	id, err := SealedKeyID(data)
+	return err != nil || id != k.current.id
+}
+
+// IsSealed reports if data has been sealed by a keyring.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(sealedPrefix))
+}
+
+// SealedKeyID returns the id of the key a sealed value has been encrypted with.
+func SealedKeyID(data []byte) (KeyID, error) {
+	var id KeyID
+	if !IsSealed(data) || len(data) < len(sealedPrefix)+keyIDSize {
+		return id, errors.New("value is not sealed")
+	}
+	copy(id[:], data[len(sealedPrefix):])
+	return id, nil
+}
+
+// ParseKey parses a hex encoded key encryption key. Surrounding whitespace and a 0x prefix are
+// ignored.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	key, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "key is not hex encoded")
+	}
+	if len(key) != KeySize {
+		return nil, errors.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// LoadKeyFile reads a hex encoded key encryption key from the given file.
+func LoadKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read key file")
+	}
+	key, err := ParseKey(string(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid key in file %s", path)
+	}
+	return key, nil
+}
+
+// LoadKeyEnv reads a hex encoded key encryption key from the given environment variable.
+func LoadKeyEnv(name string) ([]byte, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, errors.Errorf("environment variable %s is not set", name)
+	}
+	key, err := ParseKey(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid key in environment variable %s", name)
+	}
+	return key, nil
+}
+
+// GenerateKey returns a new random key encryption key.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,147 @@
+package kprsecrets
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func newTestKeyring(t *testing.T, previous ...[]byte) (*Keyring, []byte) {
+	t.Helper()
+	key, err := GenerateKey()
+	assert.NilError(t, err)
+	keyring, err := NewKeyring(key, previous...)
+	assert.NilError(t, err)
+	return keyring, key
+}
+
+func TestSealOpen(t *testing.T) {
+	keyring, _ := newTestKeyring(t)
+	plaintext := []byte("secret key share")
+
+	sealed, err := keyring.Seal(plaintext)
+	assert.NilError(t, err)
+	assert.Assert(t, IsSealed(sealed))
+	assert.Assert(t, !IsSealed(plaintext))
+	id, err := SealedKeyID(sealed)
+	assert.NilError(t, err)
+	assert.Equal(t, id, keyring.CurrentKeyID())
+
+	opened, err := keyring.Open(sealed)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, opened, plaintext)
+
+	sealedAgain, err := keyring.Seal(plaintext)
+	assert.NilError(t, err)
+	assert.Assert(t, hex.EncodeToString(sealed) != hex.EncodeToString(sealedAgain))
+
+	empty, err := keyring.Seal([]byte{})
+	assert.NilError(t, err)
+	opened, err = keyring.Open(empty)
+	assert.NilError(t, err)
+	assert.Equal(t, len(opened), 0)
+}
+
+func TestOpenPlaintext(t *testing.T) {
+	keyring, _ := newTestKeyring(t)
+	plaintext := []byte{1, 2, 3}
+
+	opened, err := keyring.Open(plaintext)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, opened, plaintext)
+	assert.Assert(t, keyring.NeedsReseal(plaintext))
+
+	var noKeyring *Keyring
+	opened, err = noKeyring.Open(plaintext)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, opened, plaintext)
+	assert.Assert(t, !noKeyring.NeedsReseal(plaintext))
+	sealed, err := noKeyring.Seal(plaintext)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, sealed, plaintext)
+}
+
+func TestOpenRequiresKey(t *testing.T) {
+	keyring, _ := newTestKeyring(t)
+	other, _ := newTestKeyring(t)
+	sealed, err := keyring.Seal([]byte("secret"))
+	assert.NilError(t, err)
+
+	_, err = other.Open(sealed)
+	assert.ErrorIs(t, err, ErrNoKey)
+
+	var noKeyring *Keyring
+	_, err = noKeyring.Open(sealed)
+	assert.ErrorIs(t, err, ErrNoKey)
+	assert.Assert(t, noKeyring.NeedsReseal(sealed))
+}
+
+func TestOpenDetectsTampering(t *testing.T) {
+	keyring, _ := newTestKeyring(t)
+	sealed, err := keyring.Seal([]byte("secret"))
+	assert.NilError(t, err)
+
+	for i := len(sealedPrefix) + keyIDSize; i < len(sealed); i++ {
+		tampered := append([]byte{}, sealed...)
+		tampered[i] ^= 1
+		_, err := keyring.Open(tampered)
+		assert.Assert(t, err != nil, "tampering with byte %d not detected", i)
+	}
+	_, err = keyring.Open(sealed[:len(sealed)-1])
+	assert.Assert(t, err != nil)
+	_, err = keyring.Open([]byte(sealedPrefix))
+	assert.ErrorContains(t, err, "too short")
+}
+
+func TestRotation(t *testing.T) {
+	oldKeyring, oldKey := newTestKeyring(t)
+	sealed, err := oldKeyring.Seal([]byte("secret"))
+	assert.NilError(t, err)
+
+	keyring, _ := newTestKeyring(t, oldKey)
+	assert.Assert(t, keyring.NeedsReseal(sealed))
+	opened, err := keyring.Open(sealed)
+	assert.NilError(t, err)
+	resealed, err := keyring.Seal(opened)
+	assert.NilError(t, err)
+	assert.Assert(t, !keyring.NeedsReseal(resealed))
+
+	_, err = oldKeyring.Open(resealed)
+	assert.ErrorIs(t, err, ErrNoKey)
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := GenerateKey()
+	assert.NilError(t, err)
+
+	parsed, err := ParseKey(" 0x" + hex.EncodeToString(key) + "\n")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, parsed, key)
+
+	_, err = ParseKey(hex.EncodeToString(key[:16]))
+	assert.ErrorContains(t, err, "must be 32 bytes")
+	_, err = ParseKey("not hex")
+	assert.ErrorContains(t, err, "not hex encoded")
+}
+
+func TestLoadKey(t *testing.T) {
+	key, err := GenerateKey()
+	assert.NilError(t, err)
+
+	path := filepath.Join(t.TempDir(), "secrets.key")
+	assert.NilError(t, os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0o600))
+	loaded, err := LoadKeyFile(path)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, loaded, key)
+
+	t.Setenv("KEYPER_TEST_SECRETS_KEY", hex.EncodeToString(key))
+	loaded, err = LoadKeyEnv("KEYPER_TEST_SECRETS_KEY")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, loaded, key)
+
+	_, err = LoadKeyEnv("KEYPER_TEST_SECRETS_KEY_UNSET")
+	assert.ErrorContains(t, err, "is not set")
+}
@@ -0,0 +1,95 @@
+package kprstatus
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// maxClockSkew is how far the timestamp of a status may lie in the future.
+const maxClockSkew = time.Minute
+
+// KeyperSetFunc returns the members of the keyper set of the given eon. ok is false if the keyper
+// set is not known (yet).
+type KeyperSetFunc func(ctx context.Context, eon uint64) (members []common.Address, ok bool, err error)
+
+// Handler validates the statuses published by the keypers and stores them in the view.
+type Handler struct {
+	instanceID uint64
+	keyperSets KeyperSetFunc
+	view       *View
+	now        func() time.Time
+}
+
+func NewHandler(instanceID uint64, keyperSets KeyperSetFunc, view *View) *Handler {
+	return &Handler{
+		instanceID: instanceID,
+		keyperSets: keyperSets,
+		view:       view,
+		now:        time.Now,
+	}
+}
+
+func (*Handler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.KeyperStatus{}}
+}
+
+func (h *Handler) ValidateMessage(ctx context.Context, msg p2pmsg.Message) (pubsub.ValidationResult, error) {
+	status := msg.(*p2pmsg.KeyperStatus)
+	if status.GetInstanceId() != h.instanceID {
+		return pubsub.ValidationReject,
+			errors.Errorf("instance ID mismatch (want=%d, have=%d)", h.instanceID, status.GetInstanceId())
+	}
+	address := status.GetKeyperAddressValue()
+	signatureValid, err := p2pmsg.VerifySignature(status, address)
+	if err != nil {
+		return pubsub.ValidationReject, errors.Wrap(err, "failed to verify keyper status signature")
+	}
+	if !signatureValid {
+		return pubsub.ValidationReject, errors.Errorf("keyper status not signed by keyper %s", address.Hex())
+	}
+
+	timestamp := time.Unix(int64(status.Timestamp), 0) //nolint:gosec // G115
+	now := h.now()
+	if timestamp.After(now.Add(maxClockSkew)) {
+		return pubsub.ValidationIgnore, errors.Errorf("keyper status of keyper %s is from the future", address.Hex())
+	}
+	if now.Sub(timestamp) >= h.view.StaleAfter() {
+		// stale statuses would not tell anything about the liveness of the keyper
+		return pubsub.ValidationIgnore, errors.Errorf("keyper status of keyper %s is stale", address.Hex())
+	}
+
+	members, ok, err := h.keyperSets(ctx, status.Eon)
+	if err != nil {
+		return pubsub.ValidationIgnore, errors.Wrapf(err, "failed to get keyper set of eon %d", status.Eon)
+	}
+	if !ok {
+		return pubsub.ValidationIgnore, errors.Errorf("unknown keyper set for eon %d", status.Eon)
+	}
+	if status.KeyperIndex >= uint64(len(members)) {
+		return pubsub.ValidationReject, errors.Errorf(
+			"keyper index %d out of range for keyper set of size %d", status.KeyperIndex, len(members),
+		)
+	}
+	if members[status.KeyperIndex] != address {
+		return pubsub.ValidationReject, errors.Errorf(
+			"keyper %s is not keyper %d of eon %d", address.Hex(), status.KeyperIndex, status.Eon,
+		)
+	}
+
+	if known, ok := h.view.Get(address); ok && !timestamp.After(known.Timestamp) {
+		// we already know this or a newer status
+		return pubsub.ValidationIgnore, nil
+	}
+	return pubsub.ValidationAccept, nil
+}
+
+func (h *Handler) HandleMessage(_ context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	h.view.Update(msg.(*p2pmsg.KeyperStatus))
+	return nil, nil
+}
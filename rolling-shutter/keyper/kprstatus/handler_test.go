@@ -0,0 +1,170 @@
+package kprstatus
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const testInstanceID = 42
+
+type testKeypers struct {
+	keys    []*ecdsa.PrivateKey
+	members []common.Address
+}
+
+func newTestKeypers(t *testing.T, n int) *testKeypers {
+	t.Helper()
+	k := &testKeypers{}
+	for i := 0; i < n; i++ {
+		key, err := ethcrypto.GenerateKey()
+		assert.NilError(t, err)
+		k.keys = append(k.keys, key)
+		k.members = append(k.members, ethcrypto.PubkeyToAddress(key.PublicKey))
+	}
+	return k
+}
+
+func (k *testKeypers) keyperSets(_ context.Context, eon uint64) ([]common.Address, bool, error) {
+	if eon != 1 {
+		return nil, false, nil
+	}
+	return k.members, true, nil
+}
+
+func (k *testKeypers) status(t *testing.T, keyper int, index uint64, timestamp time.Time) *p2pmsg.KeyperStatus {
+	t.Helper()
+	status, err := p2pmsg.NewSignedKeyperStatus(context.Background(), &p2pmsg.KeyperStatus{
+		InstanceId:        testInstanceID,
+		KeyperIndex:       index,
+		Eon:               1,
+		BlockNumber:       100,
+		ShuttermintHeight: 50,
+		Version:           "v1.0.0",
+		Timestamp:         uint64(timestamp.Unix()),
+	}, signer.NewLocal(k.keys[keyper]))
+	assert.NilError(t, err)
+	return status
+}
+
+func TestHandlerValidateMessage(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(10000, 0)
+	keypers := newTestKeypers(t, 3)
+	view := NewView(2 * time.Minute)
+	view.now = func() time.Time { return now }
+	handler := NewHandler(testInstanceID, keypers.keyperSets, view)
+	handler.now = view.now
+
+	res, err := handler.ValidateMessage(ctx, keypers.status(t, 1, 1, now))
+	assert.NilError(t, err)
+	assert.Equal(t, res, pubsub.ValidationAccept)
+
+	testCases := []struct {
+		name   string
+		status func() *p2pmsg.KeyperStatus
+		result pubsub.ValidationResult
+	}{
+		{
+			name: "wrong instance",
+			status: func() *p2pmsg.KeyperStatus {
+				s := keypers.status(t, 1, 1, now)
+				s.InstanceId++
+				return s
+			},
+			result: pubsub.ValidationReject,
+		},
+		{
+			name: "invalid signature",
+			status: func() *p2pmsg.KeyperStatus {
+				s := keypers.status(t, 1, 1, now)
+				s.BlockNumber++
+				return s
+			},
+			result: pubsub.ValidationReject,
+		},
+		{
+			name:   "wrong index",
+			status: func() *p2pmsg.KeyperStatus { return keypers.status(t, 1, 2, now) },
+			result: pubsub.ValidationReject,
+		},
+		{
+			name:   "index out of range",
+			status: func() *p2pmsg.KeyperStatus { return keypers.status(t, 1, 3, now) },
+			result: pubsub.ValidationReject,
+		},
+		{
+			name:   "from the future",
+			status: func() *p2pmsg.KeyperStatus { return keypers.status(t, 1, 1, now.Add(2*time.Minute)) },
+			result: pubsub.ValidationIgnore,
+		},
+		{
+			name:   "stale",
+			status: func() *p2pmsg.KeyperStatus { return keypers.status(t, 1, 1, now.Add(-2*time.Minute)) },
+			result: pubsub.ValidationIgnore,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := handler.ValidateMessage(ctx, tc.status())
+			assert.Assert(t, err != nil)
+			assert.Equal(t, res, tc.result)
+		})
+	}
+
+	// statuses of unknown eons are ignored, not rejected, as the keyper set might not be synced yet
+	unknownEon := keypers.status(t, 1, 1, now)
+	unknownEon.Eon = 2
+	unknownEon, err = p2pmsg.NewSignedKeyperStatus(ctx, unknownEon, signer.NewLocal(keypers.keys[1]))
+	assert.NilError(t, err)
+	res, _ = handler.ValidateMessage(ctx, unknownEon)
+	assert.Equal(t, res, pubsub.ValidationIgnore)
+
+	// older statuses than the known one are ignored
+	_, err = handler.HandleMessage(ctx, keypers.status(t, 1, 1, now))
+	assert.NilError(t, err)
+	res, err = handler.ValidateMessage(ctx, keypers.status(t, 1, 1, now.Add(-time.Second)))
+	assert.NilError(t, err)
+	assert.Equal(t, res, pubsub.ValidationIgnore)
+}
+
+func TestView(t *testing.T) {
+	now := time.Unix(10000, 0)
+	keypers := newTestKeypers(t, 3)
+	view := NewView(2 * time.Minute)
+	view.now = func() time.Time { return now }
+
+	assert.Assert(t, view.Update(keypers.status(t, 2, 2, now)))
+	assert.Assert(t, view.Update(keypers.status(t, 0, 0, now)))
+	assert.Assert(t, !view.Update(keypers.status(t, 0, 0, now)))
+	assert.Equal(t, view.NumLive(1), 2)
+
+	statuses := view.Statuses()
+	assert.Equal(t, len(statuses), 2)
+	assert.Equal(t, statuses[0].KeyperAddress, keypers.members[0])
+	assert.Equal(t, statuses[1].KeyperAddress, keypers.members[2])
+	assert.Equal(t, statuses[1].BlockNumber, uint64(100))
+	assert.Equal(t, statuses[1].ReceivedAt, now)
+
+	// keyper 2 goes down, keyper 0 keeps publishing
+	now = now.Add(time.Minute)
+	assert.Assert(t, view.Update(keypers.status(t, 0, 0, now)))
+	now = now.Add(90 * time.Second)
+	status, ok := view.Get(keypers.members[2])
+	assert.Assert(t, ok)
+	assert.Assert(t, !view.IsLive(status))
+	status, ok = view.Get(keypers.members[0])
+	assert.Assert(t, ok)
+	assert.Assert(t, view.IsLive(status))
+	assert.Equal(t, view.NumLive(1), 1)
+	assert.Equal(t, view.NumLive(2), 0)
+}
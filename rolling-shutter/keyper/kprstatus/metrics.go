@@ -0,0 +1,100 @@
+package kprstatus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var metricsStatusEon = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "keyper_status",
+		Name:      "eon",
+		Help:      "Eon reported in the latest status of a keyper",
+	},
+	[]string{"keyper"},
+)
+
+var metricsStatusBlockNumber = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "keyper_status",
+		Name:      "block_number",
+		Help:      "Latest synced block number reported by a keyper",
+	},
+	[]string{"keyper"},
+)
+
+var metricsStatusSlot = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "keyper_status",
+		Name:      "slot",
+		Help:      "Latest slot reported by a keyper, zero if the keyper does not track slots",
+	},
+	[]string{"keyper"},
+)
+
+var metricsStatusShuttermintHeight = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "keyper_status",
+		Name:      "shuttermint_height",
+		Help:      "Shuttermint height a keyper has synced to",
+	},
+	[]string{"keyper"},
+)
+
+var metricsStatusTimestamp = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "keyper_status",
+		Name:      "timestamp_seconds",
+		Help:      "Unix timestamp of the latest status of a keyper",
+	},
+	[]string{"keyper"},
+)
+
+var metricsStatusVersion = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "keyper_status",
+		Name:      "version_info",
+		Help:      "Software version a keyper runs, the value is always 1",
+	},
+	[]string{"keyper", "version"},
+)
+
+func reportStatus(status Status) {
+	keyper := status.KeyperAddress.Hex()
+	metricsStatusEon.WithLabelValues(keyper).Set(float64(status.Eon))
+	metricsStatusBlockNumber.WithLabelValues(keyper).Set(float64(status.BlockNumber))
+	metricsStatusSlot.WithLabelValues(keyper).Set(float64(status.Slot))
+	metricsStatusShuttermintHeight.WithLabelValues(keyper).Set(float64(status.ShuttermintHeight))
+	metricsStatusTimestamp.WithLabelValues(keyper).Set(float64(status.Timestamp.Unix()))
+	metricsStatusVersion.WithLabelValues(keyper, status.Version).Set(1)
+}
+
+// InitMetrics registers the metrics of the keyper statuses aggregated in the view.
+func InitMetrics(view *View) {
+	prometheus.MustRegister(metricsStatusEon)
+	prometheus.MustRegister(metricsStatusBlockNumber)
+	prometheus.MustRegister(metricsStatusSlot)
+	prometheus.MustRegister(metricsStatusShuttermintHeight)
+	prometheus.MustRegister(metricsStatusTimestamp)
+	prometheus.MustRegister(metricsStatusVersion)
+	prometheus.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: "shutter",
+			Subsystem: "keyper_status",
+			Name:      "live_keypers",
+			Help:      "Number of keypers of the latest reported eon whose latest status is not stale",
+		},
+		func() float64 {
+			eon, ok := view.latestEon()
+			if !ok {
+				return 0
+			}
+			return float64(view.NumLive(eon))
+		},
+	))
+}
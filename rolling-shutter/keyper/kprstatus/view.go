@@ -0,0 +1,138 @@
+// Package kprstatus aggregates the status messages published by the keypers of an instance into
+// a liveness view.
+package kprstatus
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// Status is the latest status reported by a keyper.
+type Status struct {
+	KeyperAddress     common.Address
+	KeyperIndex       uint64
+	Eon               uint64
+	BlockNumber       uint64
+	Slot              uint64
+	ShuttermintHeight int64
+	Version           string
+	// Timestamp is the time the keyper has created the status at.
+	Timestamp time.Time
+	// ReceivedAt is the time we have received the status at.
+	ReceivedAt time.Time
+}
+
+// View holds the latest status of every keyper. A keyper is considered live if its latest status
+// has been received less than staleAfter ago.
+type View struct {
+	staleAfter time.Duration
+	now        func() time.Time
+
+	mux      sync.RWMutex
+	statuses map[common.Address]Status
+}
+
+func NewView(staleAfter time.Duration) *View {
+	return &View{
+		staleAfter: staleAfter,
+		now:        time.Now,
+		statuses:   make(map[common.Address]Status),
+	}
+}
+
+// StaleAfter returns the time after which a keyper without a newer status is considered down.
+func (v *View) StaleAfter() time.Duration {
+	return v.staleAfter
+}
+
+// Update stores the status if it is newer than the one known for the keyper. It returns false if
+// the status has been dropped.
+func (v *View) Update(msg *p2pmsg.KeyperStatus) bool {
+	status := Status{
+		KeyperAddress:     msg.GetKeyperAddressValue(),
+		KeyperIndex:       msg.KeyperIndex,
+		Eon:               msg.Eon,
+		BlockNumber:       msg.BlockNumber,
+		Slot:              msg.Slot,
+		ShuttermintHeight: msg.ShuttermintHeight,
+		Version:           msg.Version,
+		Timestamp:         time.Unix(int64(msg.Timestamp), 0), //nolint:gosec // G115
+		ReceivedAt:        v.now(),
+	}
+
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	previous, known := v.statuses[status.KeyperAddress]
+	if known && !status.Timestamp.After(previous.Timestamp) {
+		return false
+	}
+	v.statuses[status.KeyperAddress] = status
+	if known && previous.Version != status.Version {
+		metricsStatusVersion.DeleteLabelValues(status.KeyperAddress.Hex(), previous.Version)
+	}
+	reportStatus(status)
+	return true
+}
+
+// Get returns the latest status of the keyper.
+func (v *View) Get(address common.Address) (Status, bool) {
+	v.mux.RLock()
+	defer v.mux.RUnlock()
+	status, ok := v.statuses[address]
+	return status, ok
+}
+
+// IsLive checks if the status has been received recently enough for its keyper to be considered
+// live.
+func (v *View) IsLive(status Status) bool {
+	return v.now().Sub(status.ReceivedAt) < v.staleAfter
+}
+
+// Statuses returns the latest statuses of all keypers, ordered by eon and keyper index.
+func (v *View) Statuses() []Status {
+	v.mux.RLock()
+	statuses := make([]Status, 0, len(v.statuses))
+	for _, status := range v.statuses {
+		statuses = append(statuses, status)
+	}
+	v.mux.RUnlock()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Eon != statuses[j].Eon {
+			return statuses[i].Eon < statuses[j].Eon
+		}
+		return statuses[i].KeyperIndex < statuses[j].KeyperIndex
+	})
+	return statuses
+}
+
+// NumLive returns the number of keypers of the given eon considered live.
+func (v *View) NumLive(eon uint64) int {
+	v.mux.RLock()
+	defer v.mux.RUnlock()
+	n := 0
+	for _, status := range v.statuses {
+		if status.Eon == eon && v.IsLive(status) {
+			n++
+		}
+	}
+	return n
+}
+
+// latestEon returns the highest eon any keyper has reported.
+func (v *View) latestEon() (uint64, bool) {
+	v.mux.RLock()
+	defer v.mux.RUnlock()
+	var eon uint64
+	for _, status := range v.statuses {
+		if status.Eon > eon {
+			eon = status.Eon
+		}
+	}
+	return eon, len(v.statuses) > 0
+}
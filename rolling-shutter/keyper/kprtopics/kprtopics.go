@@ -5,5 +5,7 @@ const (
 	DecryptionKeys      = "decryptionKeys"
 	DecryptionKeyShares = "decryptionKeyShares"
 	EonPublicKey        = "EonPublicKey"
+	KeyperPeerRecord    = "keyperPeerRecord"
+	KeyperStatus        = "keyperStatus"
 	PrimevCommitment    = "primevCommitment"
 )
@@ -5,10 +5,11 @@ import (
 	"errors"
 	"reflect"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4/pgxpool"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/contract"
+	syncclient "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 )
 
@@ -18,9 +19,12 @@ type options struct {
 	dbpool             *pgxpool.Pool
 	broadcastEonPubKey bool
 	messaging          p2p.Messaging
-	blockSyncClient    *ethclient.Client
+	blockSyncClient    syncclient.Client
 	messageHandler     []p2p.MessageHandler
 	eonPubkeyHandler   EonPublicKeyHandlerFunc
+	pruneFuncs         []PruneFunc
+	signer             signer.Signer
+	statusSlot         func() uint64
 }
 
 func newDefaultOptions() *options {
@@ -30,6 +34,8 @@ func newDefaultOptions() *options {
 		blockSyncClient:    nil,
 		messageHandler:     []p2p.MessageHandler{},
 		eonPubkeyHandler:   nil,
+		pruneFuncs:         []PruneFunc{},
+		signer:             nil,
 	}
 }
 
@@ -90,7 +96,7 @@ func WithMessageHandler(h p2p.MessageHandler) Option {
 // WithBlockSyncClient passes an Ethereum JSON-RPC client
 // to the keyper. This client will be used to sync activation
 // block numbers of e.g. the keyper set changes.
-func WithBlockSyncClient(client *ethclient.Client) Option {
+func WithBlockSyncClient(client syncclient.Client) Option {
 	return func(o *options) error {
 		o.blockSyncClient = client
 		return nil
@@ -106,3 +112,30 @@ func WithMessaging(sender p2p.Messaging) Option {
 		return nil
 	}
 }
+
+// WithPruneFunc registers a function that deletes implementation specific data according to the
+// retention policy. It is called in the same transaction the keyper prunes its own tables in.
+func WithPruneFunc(f PruneFunc) Option {
+	return func(o *options) error {
+		o.pruneFuncs = append(o.pruneFuncs, f)
+		return nil
+	}
+}
+
+// WithSigner passes the signer to sign shuttermint and P2P messages with to the keyper. If this
+// option is not given, a signer is created from the Ethereum config.
+func WithSigner(s signer.Signer) Option {
+	return func(o *options) error {
+		o.signer = s
+		return nil
+	}
+}
+
+// WithStatusSlot passes a function returning the latest slot the keyper has processed. It is
+// reported in the keyper's status messages.
+func WithStatusSlot(f func() uint64) Option {
+	return func(o *options) error {
+		o.statusSlot = f
+		return nil
+	}
+}
@@ -0,0 +1,333 @@
+package keyper
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	obskeyper "github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver/db/keyper"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/keypermetrics"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+const (
+	// peerRecordMaxClockSkew is how far the timestamp of a peer record may lie in the future.
+	peerRecordMaxClockSkew = time.Minute
+	// boundPeerScore is added to the gossipsub score of peers bound to a keyper, so that they are
+	// preferred when forming the mesh.
+	boundPeerScore = 50.
+	// peerRecordInitialDelay is the time the keyper waits before publishing its peer record for
+	// the first time, to give the p2p node time to connect to other peers.
+	peerRecordInitialDelay = 30 * time.Second
+)
+
+// peerRegistry keeps track of the peers bound to keyper addresses by signed peer records.
+type peerRegistry struct {
+	mux       sync.RWMutex
+	addresses map[peer.ID]common.Address
+	peers     map[common.Address]peer.ID
+}
+
+func newPeerRegistry() *peerRegistry {
+	return &peerRegistry{
+		addresses: make(map[peer.ID]common.Address),
+		peers:     make(map[common.Address]peer.ID),
+	}
+}
+
+// bind binds the peer to the address, replacing the peer the address has been bound to before and
+// the address the peer has been bound to before.
+func (r *peerRegistry) bind(address common.Address, peerID peer.ID) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if previous, ok := r.peers[address]; ok {
+		delete(r.addresses, previous)
+	}
+	if previous, ok := r.addresses[peerID]; ok {
+		delete(r.peers, previous)
+	}
+	r.peers[address] = peerID
+	r.addresses[peerID] = address
+	keypermetrics.MetricsKeyperBoundPeers.Set(float64(len(r.peers)))
+}
+
+// address returns the keyper address the peer is bound to.
+func (r *peerRegistry) address(peerID peer.ID) (common.Address, bool) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	address, ok := r.addresses[peerID]
+	return address, ok
+}
+
+func (r *peerRegistry) load(ctx context.Context, queries *database.Queries) error {
+	records, err := queries.GetKeyperPeerRecords(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get peer records from db")
+	}
+	for _, record := range records {
+		address, err := shdb.DecodeAddress(record.KeyperAddress)
+		if err != nil {
+			return errors.Wrapf(err, "invalid keyper address %s in peer records", record.KeyperAddress)
+		}
+		peerID, err := peer.Decode(record.PeerID)
+		if err != nil {
+			return errors.Wrapf(err, "invalid peer ID %s in peer records", record.PeerID)
+		}
+		r.bind(address, peerID)
+	}
+	return nil
+}
+
+func (r *peerRegistry) score(peerID peer.ID) float64 {
+	if _, ok := r.address(peerID); ok {
+		return boundPeerScore
+	}
+	return 0
+}
+
+// isCurrentOrUpcomingKeyper checks if the address is a member of the keyper set active at the
+// given block or of any keyper set activated later. The keyper sets must be sorted by activation
+// block number.
+func isCurrentOrUpcomingKeyper(keyperSets []obskeyper.KeyperSet, blockNumber int64, address common.Address) bool {
+	start := 0
+	for i, keyperSet := range keyperSets {
+		if keyperSet.ActivationBlockNumber <= blockNumber {
+			start = i
+		}
+	}
+	encodedAddress := shdb.EncodeAddress(address)
+	for _, keyperSet := range keyperSets[start:] {
+		for _, keyper := range keyperSet.Keypers {
+			if keyper == encodedAddress {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// peerRecordHandler validates the peer records published by keypers and stores the bindings
+// between peer IDs and keyper addresses they contain.
+type peerRecordHandler struct {
+	instanceID        uint64
+	dbpool            *pgxpool.Pool
+	registry          *peerRegistry
+	latestBlockNumber func() uint64
+	now               func() time.Time
+}
+
+func (*peerRecordHandler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.KeyperPeerRecord{}}
+}
+
+func (h *peerRecordHandler) ValidateMessage(ctx context.Context, msg p2pmsg.Message) (pubsub.ValidationResult, error) {
+	record := msg.(*p2pmsg.KeyperPeerRecord)
+	if record.GetInstanceId() != h.instanceID {
+		return pubsub.ValidationReject,
+			errors.Errorf("instance ID mismatch (want=%d, have=%d)", h.instanceID, record.GetInstanceId())
+	}
+	peerID, err := record.GetPeerIDValue()
+	if err != nil {
+		return pubsub.ValidationReject, err
+	}
+	if source, ok := p2p.MessageSourceFromContext(ctx); ok && source.Origin != peerID {
+		return pubsub.ValidationReject, errors.Errorf("peer record of peer %s published by peer %s", peerID, source.Origin)
+	}
+	address := record.GetKeyperAddressValue()
+	signatureValid, err := p2pmsg.VerifySignature(record, address)
+	if err != nil {
+		return pubsub.ValidationReject, errors.Wrap(err, "failed to verify peer record signature")
+	}
+	if !signatureValid {
+		return pubsub.ValidationReject, errors.Errorf("peer record not signed by keyper %s", address.Hex())
+	}
+	if time.Unix(int64(record.Timestamp), 0).After(h.now().Add(peerRecordMaxClockSkew)) { //nolint:gosec // G115
+		return pubsub.ValidationIgnore, errors.Errorf("peer record of keyper %s is from the future", address.Hex())
+	}
+
+	keyperSets, err := obskeyper.New(h.dbpool).GetKeyperSets(ctx)
+	if err != nil {
+		return pubsub.ValidationIgnore, errors.Wrap(err, "failed to get keyper sets from db")
+	}
+	blockNumber := int64(h.latestBlockNumber()) //nolint:gosec // G115
+	if !isCurrentOrUpcomingKeyper(keyperSets, blockNumber, address) {
+		return pubsub.ValidationIgnore, errors.Errorf("%s is not a member of the current or an upcoming keyper set", address.Hex())
+	}
+
+	stored, err := database.New(h.dbpool).GetKeyperPeerRecord(ctx, shdb.EncodeAddress(address))
+	if err != nil && err != pgx.ErrNoRows {
+		return pubsub.ValidationIgnore, errors.Wrap(err, "failed to get peer record from db")
+	}
+	if err == nil && stored.Timestamp >= int64(record.Timestamp) { //nolint:gosec // G115
+		// we already know this or a newer record
+		return pubsub.ValidationIgnore, nil
+	}
+	return pubsub.ValidationAccept, nil
+}
+
+func (h *peerRecordHandler) HandleMessage(ctx context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	record := msg.(*p2pmsg.KeyperPeerRecord)
+	address := record.GetKeyperAddressValue()
+	peerID, err := record.GetPeerIDValue()
+	if err != nil {
+		return nil, err
+	}
+	tag, err := database.New(h.dbpool).InsertKeyperPeerRecord(ctx, database.InsertKeyperPeerRecordParams{
+		KeyperAddress: shdb.EncodeAddress(address),
+		PeerID:        peerID.String(),
+		Timestamp:     int64(record.Timestamp), //nolint:gosec // G115
+		Signature:     record.Signature,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to insert peer record into db")
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+	h.registry.bind(address, peerID)
+	log.Info().
+		Str("keyper", address.Hex()).
+		Str("peer-id", peerID.String()).
+		Msg("bound peer to keyper")
+	return nil, nil
+}
+
+// keySharesOriginValidator checks that key shares are published by the peer bound to the keyper
+// they claim to be from. Shares whose origin is unknown are accepted if they are forwarded by a
+// peer bound to a keyper, as that peer has checked the origin already. Otherwise, they are only
+// ignored if enforce is set.
+type keySharesOriginValidator struct {
+	dbpool   *pgxpool.Pool
+	registry *peerRegistry
+	self     peer.ID
+	enforce  bool
+}
+
+func (v *keySharesOriginValidator) validate(ctx context.Context, msg p2pmsg.Message) (pubsub.ValidationResult, error) {
+	source, ok := p2p.MessageSourceFromContext(ctx)
+	if !ok || source.Origin == v.self {
+		return pubsub.ValidationAccept, nil
+	}
+	keyShares := msg.(*p2pmsg.DecryptionKeyShares)
+	if keyShares.Eon > math.MaxInt32 {
+		return pubsub.ValidationReject, errors.Errorf("eon %d overflows int32", keyShares.Eon)
+	}
+	batchConfig, err := database.New(v.dbpool).GetBatchConfig(ctx, int32(keyShares.Eon))
+	if err == pgx.ErrNoRows {
+		return pubsub.ValidationIgnore, errors.Errorf("unknown keyper set for eon %d", keyShares.Eon)
+	}
+	if err != nil {
+		return pubsub.ValidationIgnore, errors.Wrapf(err, "failed to get keyper set for eon %d from db", keyShares.Eon)
+	}
+	if keyShares.KeyperIndex >= uint64(len(batchConfig.Keypers)) {
+		return pubsub.ValidationReject, errors.Errorf(
+			"keyper index %d out of range for keyper set of size %d", keyShares.KeyperIndex, len(batchConfig.Keypers),
+		)
+	}
+	keyperAddress, err := shdb.DecodeAddress(batchConfig.Keypers[keyShares.KeyperIndex])
+	if err != nil {
+		return pubsub.ValidationIgnore, err
+	}
+
+	if originAddress, ok := v.registry.address(source.Origin); ok {
+		if originAddress != keyperAddress {
+			keypermetrics.MetricsKeyperKeySharesOrigin.WithLabelValues("mismatch").Inc()
+			return pubsub.ValidationReject, errors.Errorf(
+				"key shares of keyper %s published by peer %s bound to keyper %s",
+				keyperAddress.Hex(), source.Origin, originAddress.Hex(),
+			)
+		}
+		keypermetrics.MetricsKeyperKeySharesOrigin.WithLabelValues("bound").Inc()
+		return pubsub.ValidationAccept, nil
+	}
+	if _, ok := v.registry.address(source.Forwarder); ok {
+		keypermetrics.MetricsKeyperKeySharesOrigin.WithLabelValues("forwarded").Inc()
+		return pubsub.ValidationAccept, nil
+	}
+	keypermetrics.MetricsKeyperKeySharesOrigin.WithLabelValues("unknown").Inc()
+	if !v.enforce {
+		return pubsub.ValidationAccept, nil
+	}
+	return pubsub.ValidationIgnore, errors.Errorf(
+		"key shares of keyper %s published by unknown peer %s", keyperAddress.Hex(), source.Origin,
+	)
+}
+
+// initPeerRecords loads the known peer records and registers the handler for peer records, the
+// origin check for key shares and the score of bound peers.
+func (kpr *KeyperCore) initPeerRecords(ctx context.Context) error {
+	if err := kpr.config.PeerRecords.Validate(); err != nil {
+		return errors.Wrap(err, "invalid peer records config")
+	}
+	self, err := kpr.config.P2P.P2PKey.PeerID()
+	if err != nil {
+		return err
+	}
+	kpr.peerRegistry = newPeerRegistry()
+	err = kpr.peerRegistry.load(ctx, database.New(kpr.dbpool))
+	if err != nil {
+		return err
+	}
+	originValidator := &keySharesOriginValidator{
+		dbpool:   kpr.dbpool,
+		registry: kpr.peerRegistry,
+		self:     self.ID,
+		enforce:  kpr.config.PeerRecords.Enforce,
+	}
+	// register the origin check before the handler validator, so that shares of unauthorized
+	// peers are dropped before their expensive verification
+	kpr.messaging.AddValidator(originValidator.validate, &p2pmsg.DecryptionKeyShares{})
+	kpr.messaging.AddMessageHandler(&peerRecordHandler{
+		instanceID:        kpr.config.GetInstanceID(),
+		dbpool:            kpr.dbpool,
+		registry:          kpr.peerRegistry,
+		latestBlockNumber: kpr.latestBlockNumber.Load,
+		now:               time.Now,
+	})
+	kpr.messaging.AddPeerScore(kpr.peerRegistry.score)
+	return nil
+}
+
+// publishPeerRecords periodically publishes the keyper's own peer record.
+func (kpr *KeyperCore) publishPeerRecords(ctx context.Context, _ service.Runner) error {
+	peerID, err := kpr.config.P2P.P2PKey.PeerID()
+	if err != nil {
+		return err
+	}
+	interval := time.Duration(kpr.config.PeerRecords.PublishInterval) * time.Second //nolint:gosec // G115
+	delay := peerRecordInitialDelay
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = interval
+		record, err := p2pmsg.NewSignedKeyperPeerRecord(
+			ctx,
+			kpr.config.GetInstanceID(),
+			peerID.ID,
+			uint64(time.Now().Unix()), //nolint:gosec // G115
+			kpr.signer,
+		)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to sign peer record")
+		} else if err := kpr.messaging.SendMessage(ctx, record, retry.NumberOfRetries(3)); err != nil {
+			log.Warn().Err(err).Msg("failed to publish peer record")
+		}
+	}
+}
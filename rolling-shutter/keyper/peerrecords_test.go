@@ -0,0 +1,250 @@
+package keyper
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	p2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"gotest.tools/v3/assert"
+
+	obskeyper "github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver/db/keyper"
+	keyperdb "github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprconfig"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testsetup"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+func newTestPeerID(t *testing.T) peer.ID {
+	t.Helper()
+	key, _, err := p2pcrypto.GenerateEd25519Key(rand.Reader)
+	assert.NilError(t, err)
+	peerID, err := peer.IDFromPrivateKey(key)
+	assert.NilError(t, err)
+	return peerID
+}
+
+func TestIsCurrentOrUpcomingKeyper(t *testing.T) {
+	a := common.HexToAddress("0x000000000000000000000000000000000000000a")
+	b := common.HexToAddress("0x000000000000000000000000000000000000000b")
+	c := common.HexToAddress("0x000000000000000000000000000000000000000c")
+	keyperSets := []obskeyper.KeyperSet{
+		{ActivationBlockNumber: 10, Keypers: []string{shdb.EncodeAddress(a)}},
+		{ActivationBlockNumber: 20, Keypers: []string{shdb.EncodeAddress(b)}},
+		{ActivationBlockNumber: 30, Keypers: []string{shdb.EncodeAddress(c)}},
+	}
+
+	testCases := []struct {
+		name        string
+		blockNumber int64
+		address     common.Address
+		expected    bool
+	}{
+		{name: "before first set", blockNumber: 5, address: a, expected: true},
+		{name: "current set", blockNumber: 15, address: a, expected: true},
+		{name: "upcoming set", blockNumber: 15, address: c, expected: true},
+		{name: "previous set", blockNumber: 25, address: a, expected: false},
+		{name: "last set", blockNumber: 35, address: c, expected: true},
+		{name: "unknown", blockNumber: 15, address: common.Address{}, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, isCurrentOrUpcomingKeyper(keyperSets, tc.blockNumber, tc.address), tc.expected)
+		})
+	}
+}
+
+func TestPeerRegistryRebind(t *testing.T) {
+	registry := newPeerRegistry()
+	address := common.HexToAddress("0x000000000000000000000000000000000000000a")
+	oldPeer := newTestPeerID(t)
+	newPeer := newTestPeerID(t)
+
+	registry.bind(address, oldPeer)
+	assert.Equal(t, registry.score(oldPeer), boundPeerScore)
+	registry.bind(address, newPeer)
+
+	_, ok := registry.address(oldPeer)
+	assert.Assert(t, !ok)
+	assert.Equal(t, registry.score(oldPeer), 0.)
+	bound, ok := registry.address(newPeer)
+	assert.Assert(t, ok)
+	assert.Equal(t, bound, address)
+
+	// the peer moves to another address, so the old one is no longer bound to any peer
+	otherAddress := common.HexToAddress("0x000000000000000000000000000000000000000b")
+	registry.bind(otherAddress, newPeer)
+	bound, ok = registry.address(newPeer)
+	assert.Assert(t, ok)
+	assert.Equal(t, bound, otherAddress)
+	_, ok = registry.peers[address]
+	assert.Assert(t, !ok)
+	assert.Equal(t, len(registry.peers), 1)
+	assert.Equal(t, len(registry.addresses), 1)
+}
+
+func TestPeerRecordHandler(t *testing.T) {
+	ctx := context.Background()
+	dbpool, dbclose := testsetup.NewTestDBPool(ctx, t, keyperdb.Definition)
+	t.Cleanup(dbclose)
+
+	privKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	keyperSigner := signer.NewLocal(privKey)
+	outsiderKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	err = obskeyper.New(dbpool).InsertKeyperSet(ctx, obskeyper.InsertKeyperSetParams{
+		KeyperConfigIndex:     1,
+		ActivationBlockNumber: 0,
+		Keypers:               []string{shdb.EncodeAddress(keyperSigner.Address())},
+		Threshold:             1,
+	})
+	assert.NilError(t, err)
+
+	now := time.Unix(10000, 0)
+	registry := newPeerRegistry()
+	handler := &peerRecordHandler{
+		instanceID:        secretsTestConfig{}.GetInstanceID(),
+		dbpool:            dbpool,
+		registry:          registry,
+		latestBlockNumber: func() uint64 { return 1 },
+		now:               func() time.Time { return now },
+	}
+	peerID := newTestPeerID(t)
+	newRecord := func(s signer.Signer, timestamp time.Time) *p2pmsg.KeyperPeerRecord {
+		t.Helper()
+		record, err := p2pmsg.NewSignedKeyperPeerRecord(
+			ctx, handler.instanceID, peerID, uint64(timestamp.Unix()), s, //nolint:gosec // G115
+		)
+		assert.NilError(t, err)
+		return record
+	}
+
+	record := newRecord(keyperSigner, now)
+	result, err := handler.ValidateMessage(ctx, record)
+	assert.NilError(t, err)
+	assert.Equal(t, result, pubsub.ValidationAccept)
+	_, err = handler.HandleMessage(ctx, record)
+	assert.NilError(t, err)
+	address, ok := registry.address(peerID)
+	assert.Assert(t, ok)
+	assert.Equal(t, address, keyperSigner.Address())
+
+	// the same record again is ignored
+	result, _ = handler.ValidateMessage(ctx, record)
+	assert.Equal(t, result, pubsub.ValidationIgnore)
+
+	// bindings are loaded from the db
+	loaded := newPeerRegistry()
+	assert.NilError(t, loaded.load(ctx, keyperdb.New(dbpool)))
+	_, ok = loaded.address(peerID)
+	assert.Assert(t, ok)
+
+	result, _ = handler.ValidateMessage(ctx, newRecord(keyperSigner, now.Add(time.Hour)))
+	assert.Equal(t, result, pubsub.ValidationIgnore)
+	result, _ = handler.ValidateMessage(ctx, newRecord(signer.NewLocal(outsiderKey), now))
+	assert.Equal(t, result, pubsub.ValidationIgnore)
+
+	forged := newRecord(keyperSigner, now.Add(time.Second))
+	forged.PeerId = newTestPeerID(t).String()
+	result, _ = handler.ValidateMessage(ctx, forged)
+	assert.Equal(t, result, pubsub.ValidationReject)
+
+	sourceCtx := p2p.ContextWithMessageSource(ctx, p2p.MessageSource{Origin: newTestPeerID(t)})
+	result, _ = handler.ValidateMessage(sourceCtx, newRecord(keyperSigner, now.Add(time.Second)))
+	assert.Equal(t, result, pubsub.ValidationReject)
+}
+
+func TestKeySharesOriginValidator(t *testing.T) {
+	ctx := context.Background()
+	dbpool, dbclose := testsetup.NewTestDBPool(ctx, t, keyperdb.Definition)
+	t.Cleanup(dbclose)
+	testsetup.InitializeEon(ctx, t, dbpool, secretsTestConfig{}, 1)
+
+	self := newTestPeerID(t)
+	bound := newTestPeerID(t)
+	other := newTestPeerID(t)
+	unknown := newTestPeerID(t)
+	registry := newPeerRegistry()
+	registry.bind(secretsTestConfig{}.GetAddress(), bound)
+	registry.bind(common.HexToAddress("0x1111111111111111111111111111111111111111"), other)
+
+	testCases := []struct {
+		name        string
+		source      *p2p.MessageSource
+		keyperIndex uint64
+		enforce     bool
+		expected    pubsub.ValidationResult
+	}{
+		{name: "no source", keyperIndex: 1, expected: pubsub.ValidationAccept},
+		{name: "self", source: &p2p.MessageSource{Origin: self}, keyperIndex: 1, enforce: true, expected: pubsub.ValidationAccept},
+		{name: "bound", source: &p2p.MessageSource{Origin: bound}, keyperIndex: 1, enforce: true, expected: pubsub.ValidationAccept},
+		{name: "impersonation", source: &p2p.MessageSource{Origin: other}, keyperIndex: 1, expected: pubsub.ValidationReject},
+		{
+			name:        "forwarded",
+			source:      &p2p.MessageSource{Origin: unknown, Forwarder: other},
+			keyperIndex: 1,
+			enforce:     true,
+			expected:    pubsub.ValidationAccept,
+		},
+		{name: "unknown", source: &p2p.MessageSource{Origin: unknown}, keyperIndex: 1, expected: pubsub.ValidationAccept},
+		{
+			name:        "unknown enforced",
+			source:      &p2p.MessageSource{Origin: unknown},
+			keyperIndex: 1,
+			enforce:     true,
+			expected:    pubsub.ValidationIgnore,
+		},
+		{name: "index out of range", source: &p2p.MessageSource{Origin: bound}, keyperIndex: 3, expected: pubsub.ValidationReject},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := &keySharesOriginValidator{dbpool: dbpool, registry: registry, self: self, enforce: tc.enforce}
+			msgCtx := ctx
+			if tc.source != nil {
+				msgCtx = p2p.ContextWithMessageSource(ctx, *tc.source)
+			}
+			result, _ := validator.validate(msgCtx, &p2pmsg.DecryptionKeyShares{
+				Eon:         secretsTestConfig{}.GetEon(),
+				KeyperIndex: tc.keyperIndex,
+			})
+			assert.Equal(t, result, tc.expected)
+		})
+	}
+}
+
+func TestKeySharesOriginValidatorWithoutRecords(t *testing.T) {
+	ctx := context.Background()
+	dbpool, dbclose := testsetup.NewTestDBPool(ctx, t, keyperdb.Definition)
+	t.Cleanup(dbclose)
+	testsetup.InitializeEon(ctx, t, dbpool, secretsTestConfig{}, 1)
+
+	// with the default config, shares are accepted while no peer records are known yet, e.g.,
+	// during a rolling upgrade or after the database has been restored
+	config := kprconfig.NewPeerRecordsConfig()
+	assert.NilError(t, config.SetDefaultValues())
+	assert.Assert(t, !config.Enforce)
+	validator := &keySharesOriginValidator{
+		dbpool:   dbpool,
+		registry: newPeerRegistry(),
+		self:     newTestPeerID(t),
+		enforce:  config.Enforce,
+	}
+	for keyperIndex := uint64(0); keyperIndex < 3; keyperIndex++ {
+		msgCtx := p2p.ContextWithMessageSource(ctx, p2p.MessageSource{Origin: newTestPeerID(t)})
+		result, err := validator.validate(msgCtx, &p2pmsg.DecryptionKeyShares{
+			Eon:         secretsTestConfig{}.GetEon(),
+			KeyperIndex: keyperIndex,
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, result, pubsub.ValidationAccept)
+	}
+}
@@ -0,0 +1,141 @@
+package keyper
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/keypermetrics"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprconfig"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+)
+
+// RetentionCutoff describes which data is deleted by a pruning run.
+type RetentionCutoff struct {
+	// Eon is the oldest eon whose data is kept.
+	Eon int64
+	// MaxAgeSlots is the number of slots slot based data is kept for, or 0 if it is kept forever.
+	MaxAgeSlots uint64
+	// PruneDecryptionKeys is true if decryption keys are deleted as well, which is not the case if
+	// the config says to keep them or they are served by the HTTP API.
+	PruneDecryptionKeys bool
+}
+
+// PruneFunc deletes implementation specific data according to the retention cutoff. It returns
+// the number of deleted rows per table.
+type PruneFunc func(ctx context.Context, tx pgx.Tx, cutoff RetentionCutoff) (map[string]int64, error)
+
+// retentionCutoffEon returns the oldest eon whose data must be kept according to the config,
+// given the eons sorted by eon and the current block number. It returns 0 if no data can be
+// deleted. The most recent eon is always kept.
+func retentionCutoffEon(eons []database.Eon, config *kprconfig.RetentionConfig, blockNumber int64) int64 {
+	if len(eons) == 0 {
+		return 0
+	}
+	cutoff := int64(0)
+	if config.KeepEons > 0 && uint64(len(eons)) > config.KeepEons {
+		cutoff = eons[uint64(len(eons))-config.KeepEons].Eon
+	}
+	if config.MaxAgeBlocks > 0 && blockNumber > 0 {
+		// An eon is superseded when the next one is activated. All eons before the latest one
+		// activated more than MaxAgeBlocks ago have been superseded for at least that long.
+		maxActivationBlock := blockNumber - int64(config.MaxAgeBlocks) //nolint:gosec // G115
+		for _, eon := range eons {
+			if eon.ActivationBlockNumber <= maxActivationBlock && eon.Eon > cutoff {
+				cutoff = eon.Eon
+			}
+		}
+	}
+	return cutoff
+}
+
+// prune deletes all data that is no longer needed according to the retention policy.
+func (kpr *KeyperCore) prune(ctx context.Context) error {
+	config := kpr.config.Retention
+	pruneKeys := config.PruneDecryptionKeys && !kpr.config.HTTPEnabled
+	pruned := map[string]int64{}
+	var cutoff RetentionCutoff
+	err := kpr.dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		db := database.New(tx)
+		eons, err := db.GetAllEons(ctx)
+		if err != nil {
+			return err
+		}
+		blockNumber := int64(kpr.latestBlockNumber.Load()) //nolint:gosec // G115
+		cutoff = RetentionCutoff{
+			Eon:                 retentionCutoffEon(eons, config, blockNumber),
+			MaxAgeSlots:         config.MaxAgeSlots,
+			PruneDecryptionKeys: pruneKeys,
+		}
+
+		// triggers must be deleted before the key shares, see DeleteDecryptionTriggersBeforeEon
+		tag, err := db.DeleteDecryptionTriggersBeforeEon(ctx, cutoff.Eon)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete decryption triggers")
+		}
+		pruned["decryption_trigger"] = tag.RowsAffected()
+		tag, err = db.DeleteDecryptionKeySharesBeforeEon(ctx, cutoff.Eon)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete decryption key shares")
+		}
+		pruned["decryption_key_share"] = tag.RowsAffected()
+		if pruneKeys {
+			tag, err = db.DeleteDecryptionKeysBeforeEon(ctx, cutoff.Eon)
+			if err != nil {
+				return errors.Wrap(err, "failed to delete decryption keys")
+			}
+			pruned["decryption_key"] = tag.RowsAffected()
+		}
+
+		for _, pruneFunc := range kpr.opts.pruneFuncs {
+			counts, err := pruneFunc(ctx, tx, cutoff)
+			if err != nil {
+				return err
+			}
+			for table, count := range counts {
+				pruned[table] += count
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	keypermetrics.MetricsKeyperRetentionCutoffEon.Set(float64(cutoff.Eon))
+	total := int64(0)
+	for table, count := range pruned {
+		keypermetrics.MetricsKeyperRetentionRowsPruned.WithLabelValues(table).Add(float64(count))
+		total += count
+	}
+	if total > 0 {
+		log.Info().
+			Int64("cutoff-eon", cutoff.Eon).
+			Interface("rows", pruned).
+			Msg("pruned data according to retention policy")
+	}
+	return nil
+}
+
+func (kpr *KeyperCore) runRetention(ctx context.Context, _ service.Runner) error {
+	config := kpr.config.Retention
+	if config.PruneDecryptionKeys && kpr.config.HTTPEnabled {
+		log.Warn().Msg("keeping decryption keys despite retention policy, as they are served by the HTTP API")
+	}
+	interval := time.Duration(config.Interval) * time.Second //nolint:gosec // G115
+	for {
+		if err := kpr.prune(ctx); err != nil {
+			// pruning is not critical, so we try again in the next run
+			log.Error().Err(err).Msg("failed to prune data according to retention policy")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package keyper
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprconfig"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testsetup"
+)
+
+func TestRetentionCutoffEon(t *testing.T) {
+	eons := []database.Eon{
+		{Eon: 1, ActivationBlockNumber: 100},
+		{Eon: 2, ActivationBlockNumber: 200},
+		{Eon: 3, ActivationBlockNumber: 300},
+		{Eon: 4, ActivationBlockNumber: 400},
+	}
+	testCases := []struct {
+		name        string
+		config      kprconfig.RetentionConfig
+		eons        []database.Eon
+		blockNumber int64
+		expectedEon int64
+	}{
+		{name: "no eons", config: kprconfig.RetentionConfig{KeepEons: 2}, eons: nil, expectedEon: 0},
+		{name: "no limits", config: kprconfig.RetentionConfig{}, eons: eons, blockNumber: 1000, expectedEon: 0},
+		{name: "keep eons", config: kprconfig.RetentionConfig{KeepEons: 2}, eons: eons, expectedEon: 3},
+		{name: "keep all eons", config: kprconfig.RetentionConfig{KeepEons: 4}, eons: eons, expectedEon: 0},
+		{name: "keep more eons than exist", config: kprconfig.RetentionConfig{KeepEons: 10}, eons: eons, expectedEon: 0},
+		{
+			name:        "max age",
+			config:      kprconfig.RetentionConfig{MaxAgeBlocks: 150},
+			eons:        eons,
+			blockNumber: 460,
+			expectedEon: 3,
+		},
+		{
+			name:        "max age keeps latest eon",
+			config:      kprconfig.RetentionConfig{MaxAgeBlocks: 10},
+			eons:        eons,
+			blockNumber: 10000,
+			expectedEon: 4,
+		},
+		{
+			name:        "max age without block number",
+			config:      kprconfig.RetentionConfig{MaxAgeBlocks: 10},
+			eons:        eons,
+			blockNumber: 0,
+			expectedEon: 0,
+		},
+		{
+			name:        "stricter limit wins",
+			config:      kprconfig.RetentionConfig{KeepEons: 3, MaxAgeBlocks: 150},
+			eons:        eons,
+			blockNumber: 460,
+			expectedEon: 3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			eon := retentionCutoffEon(tc.eons, &tc.config, tc.blockNumber)
+			assert.Equal(t, eon, tc.expectedEon)
+		})
+	}
+}
+
+func TestPruneDeletesDataOfOldEons(t *testing.T) {
+	ctx := context.Background()
+	dbpool, dbclose := testsetup.NewTestDBPool(ctx, t, database.Definition)
+	t.Cleanup(dbclose)
+	db := database.New(dbpool)
+
+	// epochID is used in every eon
+	epochID := []byte("epoch")
+	for eon := int64(1); eon <= 3; eon++ {
+		assert.NilError(t, db.InsertEon(ctx, database.InsertEonParams{
+			Eon:                   eon,
+			ActivationBlockNumber: eon * 100,
+			KeyperConfigIndex:     eon,
+		}))
+		assert.NilError(t, db.InsertDecryptionKeyShare(ctx, database.InsertDecryptionKeyShareParams{
+			Eon:                eon,
+			EpochID:            epochID,
+			KeyperIndex:        0,
+			DecryptionKeyShare: []byte("share"),
+		}))
+		_, err := db.InsertDecryptionKey(ctx, database.InsertDecryptionKeyParams{
+			Eon:           eon,
+			EpochID:       epochID,
+			DecryptionKey: []byte("key"),
+		})
+		assert.NilError(t, err)
+	}
+
+	kpr := &KeyperCore{
+		config: &kprconfig.Config{
+			Retention: &kprconfig.RetentionConfig{KeepEons: 2, PruneDecryptionKeys: true},
+		},
+		dbpool: dbpool,
+		opts:   newDefaultOptions(),
+	}
+	assert.NilError(t, kpr.prune(ctx))
+
+	countRows := func(table string, eon int64) int {
+		t.Helper()
+		var count int
+		err := dbpool.QueryRow(ctx, "SELECT count(*) FROM "+table+" WHERE eon = $1", eon).Scan(&count)
+		assert.NilError(t, err)
+		return count
+	}
+	for _, table := range []string{"decryption_key_share", "decryption_key"} {
+		assert.Equal(t, countRows(table, 1), 0, table)
+	}
+	for eon := int64(2); eon <= 3; eon++ {
+		assert.Equal(t, countRows("decryption_key_share", eon), 1)
+		assert.Equal(t, countRows("decryption_key", eon), 1)
+	}
+}
@@ -0,0 +1,64 @@
+package keyper
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprconfig"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
+)
+
+// resealSecrets encrypts the secrets in the database with the current key of the keyring. This
+// encrypts rows written before encryption has been enabled and completes key rotations. Without a
+// keyring, it fails if the database contains encrypted secrets.
+func resealSecrets(ctx context.Context, tx pgx.Tx, keyring *kprsecrets.Keyring) error {
+	count, err := database.New(tx).ResealSecrets(ctx, keyring)
+	if errors.Is(err, kprsecrets.ErrNoKey) {
+		return errors.Wrap(err, "database contains secrets encrypted with a key that is not configured")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt secrets in database")
+	}
+	if count > 0 {
+		log.Info().
+			Int("rows", count).
+			Str("key-id", keyring.CurrentKeyID().String()).
+			Msg("encrypted secrets in database with current key")
+	}
+	return nil
+}
+
+// RotateSecretsKey re-encrypts all secrets in the keyper database with the current key from the
+// given config. Secrets encrypted with one of the previous keys in the config or stored in
+// plaintext are rewritten, so that the previous keys can be removed from the config afterwards.
+func RotateSecretsKey(ctx context.Context, databaseURL string, config *kprconfig.SecretsConfig) error {
+	if !config.Enabled() {
+		return errors.New("no key to encrypt secrets is configured")
+	}
+	keyring, err := config.Keyring()
+	if err != nil {
+		return errors.Wrap(err, "failed to load secrets key")
+	}
+	dbpool, err := pgxpool.Connect(ctx, databaseURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer dbpool.Close()
+
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if err := database.Definition.Validate(ctx, tx); err != nil {
+			return err
+		}
+		return resealSecrets(ctx, tx, keyring)
+	})
+	if err != nil {
+		return err
+	}
+	log.Info().Str("key-id", keyring.CurrentKeyID().String()).Msg("all secrets are encrypted with the current key")
+	return nil
+}
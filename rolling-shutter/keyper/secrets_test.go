@@ -0,0 +1,98 @@
+package keyper
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+	"gotest.tools/v3/assert"
+
+	keyperdb "github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testsetup"
+)
+
+type secretsTestConfig struct{}
+
+func (secretsTestConfig) GetAddress() common.Address {
+	return common.HexToAddress("0x2222222222222222222222222222222222222222")
+}
+
+func (secretsTestConfig) GetInstanceID() uint64 {
+	return 55
+}
+
+func (secretsTestConfig) GetEon() uint64 {
+	return 1
+}
+
+func (secretsTestConfig) GetCollatorKey() *ecdsa.PrivateKey {
+	return nil
+}
+
+func TestResealSecrets(t *testing.T) {
+	ctx := context.Background()
+	dbpool, dbclose := testsetup.NewTestDBPool(ctx, t, keyperdb.Definition)
+	t.Cleanup(dbclose)
+	queries := keyperdb.New(dbpool)
+
+	// rows written before encryption has been enabled
+	eonKeys := testsetup.InitializeEon(ctx, t, dbpool, secretsTestConfig{}, 0)
+	assert.NilError(t, queries.InsertPureDKG(ctx, keyperdb.InsertPureDKGParams{Eon: 1, Puredkg: []byte("dkg")}))
+	assert.NilError(t, queries.InsertPolyEval(ctx, keyperdb.InsertPolyEvalParams{
+		Eon:             1,
+		ReceiverAddress: "0x0000000000000000000000000000000000000001",
+		Eval:            []byte{1, 2, 3},
+	}))
+
+	assertSealedWith := func(keyring *kprsecrets.Keyring) {
+		t.Helper()
+		dkgs, err := queries.SelectPureDKG(ctx)
+		assert.NilError(t, err)
+		results, err := queries.GetAllDKGResults(ctx)
+		assert.NilError(t, err)
+		evals, err := queries.GetAllPolyEvals(ctx)
+		assert.NilError(t, err)
+		for _, data := range [][]byte{dkgs[0].Puredkg, results[0].PureResult, evals[0].Eval} {
+			assert.Assert(t, !keyring.NeedsReseal(data))
+		}
+		plaintext, err := keyring.Open(evals[0].Eval)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, plaintext, []byte{1, 2, 3})
+
+		result, err := keyperdb.DecodePureDKGResult(keyring, results[0].PureResult)
+		assert.NilError(t, err)
+		assert.Equal(t, result.Eon, uint64(1))
+		assert.Assert(t, result.SecretKeyShare.Equal(eonKeys.EonSecretKeyShare(0)))
+	}
+
+	oldKey, err := kprsecrets.GenerateKey()
+	assert.NilError(t, err)
+	oldKeyring, err := kprsecrets.NewKeyring(oldKey)
+	assert.NilError(t, err)
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return resealSecrets(ctx, tx, oldKeyring)
+	})
+	assert.NilError(t, err)
+	assertSealedWith(oldKeyring)
+
+	// without the key, the keyper refuses to start
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return resealSecrets(ctx, tx, nil)
+	})
+	assert.ErrorIs(t, err, kprsecrets.ErrNoKey)
+
+	newKey, err := kprsecrets.GenerateKey()
+	assert.NilError(t, err)
+	newKeyring, err := kprsecrets.NewKeyring(newKey, oldKey)
+	assert.NilError(t, err)
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return resealSecrets(ctx, tx, newKeyring)
+	})
+	assert.NilError(t, err)
+	onlyNewKeyring, err := kprsecrets.NewKeyring(newKey)
+	assert.NilError(t, err)
+	assertSealedWith(onlyNewKeyring)
+}
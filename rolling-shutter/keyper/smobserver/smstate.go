@@ -24,6 +24,7 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/dkgphase"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/keypermetrics"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprsecrets"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/shutterevents"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
@@ -56,15 +57,17 @@ type PhaseLength interface {
 // stored in the database, and what we have here is kind of a cache.
 type ShuttermintState struct {
 	config       Config
+	keyring      *kprsecrets.Keyring
 	synchronized bool // are we synchronized
 	isKeyper     bool
 	dkg          map[uint64]*ActiveDKG
 	phaseLength  PhaseLength
 }
 
-func NewShuttermintState(config Config) *ShuttermintState {
+func NewShuttermintState(config Config, keyring *kprsecrets.Keyring) *ShuttermintState {
 	return &ShuttermintState{
 		config:      config,
+		keyring:     keyring,
 		dkg:         make(map[uint64]*ActiveDKG),
 		phaseLength: config.GetDKGPhaseLength(),
 	}
@@ -72,7 +75,7 @@ func NewShuttermintState(config Config) *ShuttermintState {
 
 // Invalidate invalidates the current state. This is being called, when an error happens.
 func (st *ShuttermintState) Invalidate() {
-	*st = *NewShuttermintState(st.config)
+	*st = *NewShuttermintState(st.config, st.keyring)
 }
 
 func (st *ShuttermintState) Load(ctx context.Context, queries *database.Queries) error {
@@ -99,7 +102,7 @@ func (st *ShuttermintState) loadDKG(ctx context.Context, queries *database.Queri
 		return err
 	}
 	for _, dkg := range dkgs {
-		pure, err := shdb.DecodePureDKG(dkg.Puredkg)
+		pure, err := database.DecodePureDKG(st.keyring, dkg.Puredkg)
 		if err != nil {
 			return err
 		}
@@ -142,7 +145,7 @@ func (st *ShuttermintState) Save(ctx context.Context, queries *database.Queries)
 		if !a.dirty {
 			continue
 		}
-		pureBytes, err := shdb.EncodePureDKG(a.pure)
+		pureBytes, err := database.EncodePureDKG(st.keyring, a.pure)
 		if err != nil {
 			return err
 		}
@@ -216,7 +219,11 @@ func (st *ShuttermintState) sendPolyEvals(ctx context.Context, queries *database
 		if err != nil {
 			return fmt.Errorf("failed to decode encryption public key for %s from db: %w", eval.ReceiverAddress, err)
 		}
-		encrypted, err := ecies.Encrypt(rand.Reader, pubkey, eval.Eval, nil, nil)
+		polyEval, err := st.keyring.Open(eval.Eval)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decrypt poly eval for %s", eval.ReceiverAddress)
+		}
+		encrypted, err := ecies.Encrypt(rand.Reader, pubkey, polyEval, nil, nil)
 		if err != nil {
 			return err
 		}
@@ -390,10 +397,14 @@ func (st *ShuttermintState) startPhase1Dealing(
 	).Inc()
 
 	for _, eval := range polyEvals {
+		sealedEval, err := st.keyring.Seal(shdb.EncodeBigint(eval.Eval))
+		if err != nil {
+			return err
+		}
 		err = queries.InsertPolyEval(ctx, database.InsertPolyEvalParams{
 			Eon:             int64(eon),
 			ReceiverAddress: shdb.EncodeAddress(dkg.keypers[eval.Receiver]),
-			Eval:            shdb.EncodeBigint(eval.Eval),
+			Eval:            sealedEval,
 		})
 		if err != nil {
 			return err
@@ -504,7 +515,7 @@ func (st *ShuttermintState) finalizeDKG(
 	} else {
 		keypermetrics.MetricsKeyperDKGStatus.WithLabelValues(strconv.FormatUint(eon, 10)).Set(1)
 		log.Info().Uint64("eon", eon).Bool("success", true).Msg("DKG process succeeded")
-		pureResult, err = shdb.EncodePureDKGResult(&dkgresult)
+		pureResult, err = database.EncodePureDKGResult(st.keyring, &dkgresult)
 		if err != nil {
 			return err
 		}
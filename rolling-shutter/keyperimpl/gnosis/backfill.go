@@ -0,0 +1,183 @@
+package gnosis
+
+import (
+	"bytes"
+	"context"
+	"math"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	obskeyperdatabase "github.com/shutter-network/rolling-shutter/rolling-shutter/chainobserver/db/keyper"
+	corekeyperdatabase "github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// backfillMaxSlots is the maximum number of slots served for a single backfill request.
+const backfillMaxSlots = 64
+
+// backfillSlots answers backfill requests for slot ranges, the only kind of backfill requests
+// served by keypers. The keys and key shares of a slot are
+// reconstructed from the slot decryption signatures, the transaction submitted events and the
+// decryption keys and key shares stored in the database.
+func (i *MessagingMiddleware) backfillSlots(ctx context.Context, req *p2pmsg.BackfillRequest) ([]p2pmsg.Message, error) {
+	if req.InstanceId != i.config.InstanceID {
+		return nil, errors.Errorf("instance ID mismatch (want=%d, have=%d)", i.config.InstanceID, req.InstanceId)
+	}
+	if req.Eon > math.MaxInt64 {
+		return nil, errors.Errorf("eon %d overflows int64", req.Eon)
+	}
+	slots := req.GetSlots()
+	if slots == nil {
+		return nil, errors.New("only slot ranges are supported")
+	}
+	if slots.Start >= slots.End {
+		return nil, errors.New("empty slot range")
+	}
+	if slots.End > math.MaxInt64 {
+		return nil, errors.Errorf("slot %d overflows int64", slots.End)
+	}
+	endSlot := slots.End
+	if endSlot-slots.Start > backfillMaxSlots {
+		endSlot = slots.Start + backfillMaxSlots
+	}
+
+	keyperSet, err := obskeyperdatabase.New(i.dbpool).GetKeyperSetByKeyperConfigIndex(ctx, int64(req.Eon))
+	if err == pgx.ErrNoRows {
+		return nil, errors.Errorf("unknown eon %d", req.Eon)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get keyper set from database for eon %d", req.Eon)
+	}
+	signatures, err := database.New(i.dbpool).GetSlotDecryptionSignaturesInRange(ctx, database.GetSlotDecryptionSignaturesInRangeParams{
+		Eon:       int64(req.Eon),
+		StartSlot: int64(slots.Start),
+		EndSlot:   int64(endSlot),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get slot decryption signatures for eon %d", req.Eon)
+	}
+
+	msgs := []p2pmsg.Message{}
+	for _, group := range groupSlotDecryptionSignatures(signatures) {
+		slotMsgs, err := i.backfillSlot(ctx, req, &keyperSet, group)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, slotMsgs...)
+	}
+	return msgs, nil
+}
+
+// groupSlotDecryptionSignatures splits the signatures, which must be ordered by slot, tx pointer
+// and identities hash, into groups signing the same data.
+func groupSlotDecryptionSignatures(signatures []database.SlotDecryptionSignature) [][]database.SlotDecryptionSignature {
+	groups := [][]database.SlotDecryptionSignature{}
+	for j, signature := range signatures {
+		if j == 0 || !sameSlotDecryptionData(signatures[j-1], signature) {
+			groups = append(groups, []database.SlotDecryptionSignature{})
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], signature)
+	}
+	return groups
+}
+
+func sameSlotDecryptionData(a, b database.SlotDecryptionSignature) bool {
+	return a.Eon == b.Eon &&
+		a.Slot == b.Slot &&
+		a.TxPointer == b.TxPointer &&
+		bytes.Equal(a.IdentitiesHash, b.IdentitiesHash)
+}
+
+func (i *MessagingMiddleware) backfillSlot(
+	ctx context.Context,
+	req *p2pmsg.BackfillRequest,
+	keyperSet *obskeyperdatabase.KeyperSet,
+	signatures []database.SlotDecryptionSignature,
+) ([]p2pmsg.Message, error) {
+	first := signatures[0]
+	identityPreimages, err := getDecryptionIdentityPreimages(
+		ctx, i.dbpool, i.config.Gnosis, uint64(first.Slot), first.Eon, first.TxPointer,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(computeIdentitiesHash(identityPreimages), first.IdentitiesHash) {
+		// this happens if the transactions have been pruned or the gas limits have changed
+		log.Debug().
+			Int64("eon", first.Eon).
+			Int64("slot", first.Slot).
+			Int64("tx-pointer", first.TxPointer).
+			Msg("cannot reconstruct identities of slot for backfill")
+		return nil, nil
+	}
+	epochIDs := [][]byte{}
+	for _, identityPreimage := range identityPreimages {
+		epochIDs = append(epochIDs, identityPreimage.Bytes())
+	}
+	coreKeyperDB := corekeyperdatabase.New(i.dbpool)
+
+	msgs := []p2pmsg.Message{}
+	if req.Keys && len(signatures) >= int(keyperSet.Threshold) {
+		rows, err := coreKeyperDB.GetDecryptionKeysByEpochIDs(ctx, corekeyperdatabase.GetDecryptionKeysByEpochIDsParams{
+			Eon:      first.Eon,
+			EpochIds: epochIDs,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get decryption keys for slot %d", first.Slot)
+		}
+		if len(rows) == len(epochIDs) {
+			keys := []*p2pmsg.Key{}
+			for _, row := range rows {
+				keys = append(keys, &p2pmsg.Key{IdentityPreimage: row.EpochID, Key: row.DecryptionKey})
+			}
+			extra := &p2pmsg.GnosisDecryptionKeysExtra{
+				Slot:      uint64(first.Slot),
+				TxPointer: uint64(first.TxPointer),
+			}
+			for _, signature := range signatures[:keyperSet.Threshold] {
+				extra.SignerIndices = append(extra.SignerIndices, uint64(signature.KeyperIndex))
+				extra.Signatures = append(extra.Signatures, signature.Signature)
+			}
+			msgs = append(msgs, &p2pmsg.DecryptionKeys{
+				InstanceId: req.InstanceId,
+				Eon:        req.Eon,
+				Keys:       keys,
+				Extra:      &p2pmsg.DecryptionKeys_Gnosis{Gnosis: extra},
+			})
+		}
+	}
+	if req.KeyShares {
+		for _, signature := range signatures {
+			rows, err := coreKeyperDB.GetDecryptionKeySharesByEpochIDs(ctx, corekeyperdatabase.GetDecryptionKeySharesByEpochIDsParams{
+				Eon:         first.Eon,
+				KeyperIndex: signature.KeyperIndex,
+				EpochIds:    epochIDs,
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get decryption key shares for slot %d", first.Slot)
+			}
+			if len(rows) != len(epochIDs) {
+				continue
+			}
+			shares := []*p2pmsg.KeyShare{}
+			for _, row := range rows {
+				shares = append(shares, &p2pmsg.KeyShare{IdentityPreimage: row.EpochID, Share: row.DecryptionKeyShare})
+			}
+			msgs = append(msgs, &p2pmsg.DecryptionKeyShares{
+				InstanceId:  req.InstanceId,
+				Eon:         req.Eon,
+				KeyperIndex: uint64(signature.KeyperIndex),
+				Shares:      shares,
+				Extra: &p2pmsg.DecryptionKeyShares_Gnosis{Gnosis: &p2pmsg.GnosisDecryptionKeySharesExtra{
+					Slot:      uint64(first.Slot),
+					TxPointer: uint64(first.TxPointer),
+					Signature: signature.Signature,
+				}},
+			})
+		}
+	}
+	return msgs, nil
+}
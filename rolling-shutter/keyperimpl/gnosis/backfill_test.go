@@ -0,0 +1,61 @@
+package gnosis
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+func TestGroupSlotDecryptionSignatures(t *testing.T) {
+	signatures := []database.SlotDecryptionSignature{
+		{Eon: 1, Slot: 10, KeyperIndex: 0, TxPointer: 5, IdentitiesHash: []byte{1}},
+		{Eon: 1, Slot: 10, KeyperIndex: 1, TxPointer: 5, IdentitiesHash: []byte{1}},
+		{Eon: 1, Slot: 10, KeyperIndex: 2, TxPointer: 5, IdentitiesHash: []byte{2}},
+		{Eon: 1, Slot: 11, KeyperIndex: 0, TxPointer: 7, IdentitiesHash: []byte{3}},
+	}
+	groups := groupSlotDecryptionSignatures(signatures)
+	assert.Equal(t, len(groups), 3)
+	assert.Equal(t, len(groups[0]), 2)
+	assert.Equal(t, groups[1][0].KeyperIndex, int64(2))
+	assert.Equal(t, groups[2][0].Slot, int64(11))
+
+	assert.Equal(t, len(groupSlotDecryptionSignatures(nil)), 0)
+}
+
+func TestSlotBackfillerObserve(t *testing.T) {
+	backfiller := NewSlotBackfiller(nil, 42)
+
+	receiveRange := func() *p2pmsg.SlotRange {
+		t.Helper()
+		select {
+		case req := <-backfiller.requests:
+			assert.Equal(t, req.InstanceId, uint64(42))
+			assert.Assert(t, req.Keys)
+			return req.GetSlots()
+		default:
+			return nil
+		}
+	}
+
+	backfiller.Observe(1, 100)
+	assert.Assert(t, receiveRange() == nil)
+	backfiller.Observe(1, 101)
+	assert.Assert(t, receiveRange() == nil)
+
+	backfiller.Observe(1, 105)
+	slots := receiveRange()
+	assert.Equal(t, slots.Start, uint64(102))
+	assert.Equal(t, slots.End, uint64(105))
+
+	// older slots, e.g. from backfilled messages, are ignored
+	backfiller.Observe(1, 103)
+	assert.Assert(t, receiveRange() == nil)
+
+	backfiller.Observe(1, 1000)
+	slots = receiveRange()
+	assert.Equal(t, slots.Start, uint64(1000-backfillMaxSlots))
+	assert.Equal(t, slots.End, uint64(1000))
+}
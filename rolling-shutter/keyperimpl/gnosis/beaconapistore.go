@@ -0,0 +1,105 @@
+package gnosis
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/beaconapiclient"
+)
+
+// numStoredProposerDutyEpochs is the number of epochs, counted back from the most recently stored
+// one, for which proposer duties are kept in the database.
+const numStoredProposerDutyEpochs = 4
+
+var _ beaconapiclient.Store = &BeaconAPIStore{}
+
+// BeaconAPIStore persists the proposer duties and validator pubkeys cached by the beacon API
+// client in the database.
+type BeaconAPIStore struct {
+	DBPool *pgxpool.Pool
+}
+
+func (s *BeaconAPIStore) GetProposerDuties(ctx context.Context, epoch uint64) (*beaconapiclient.GetProposerDutiesResponse, error) {
+	rows, err := database.New(s.DBPool).GetProposerDuties(ctx, int64(epoch)) //nolint:gosec // G115
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query proposer duties")
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	duties := &beaconapiclient.GetProposerDutiesResponse{
+		DependentRoot: rows[0].DependentRoot,
+		Data:          make([]beaconapiclient.ProposerDuty, 0, len(rows)),
+	}
+	for _, row := range rows {
+		duties.Data = append(duties.Data, beaconapiclient.ProposerDuty{
+			Pubkey:         row.Pubkey,
+			ValidatorIndex: uint64(row.ValidatorIndex), //nolint:gosec // G115
+			Slot:           uint64(row.Slot),           //nolint:gosec // G115
+		})
+	}
+	return duties, nil
+}
+
+// PutProposerDuties stores the proposer duties of the given epoch, replacing those stored before,
+// and deletes those of epochs that are too old to be of use.
+func (s *BeaconAPIStore) PutProposerDuties(
+	ctx context.Context,
+	epoch uint64,
+	duties *beaconapiclient.GetProposerDutiesResponse,
+) error {
+	return s.DBPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		db := database.New(tx)
+		for _, duty := range duties.Data {
+			err := db.InsertProposerDuty(ctx, database.InsertProposerDutyParams{
+				Slot:           int64(duty.Slot),           //nolint:gosec // G115
+				Epoch:          int64(epoch),               //nolint:gosec // G115
+				ValidatorIndex: int64(duty.ValidatorIndex), //nolint:gosec // G115
+				Pubkey:         duty.Pubkey,
+				DependentRoot:  duties.DependentRoot,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to insert proposer duty")
+			}
+		}
+		if epoch >= numStoredProposerDutyEpochs {
+			_, err := db.DeleteProposerDutiesBefore(ctx, int64(epoch-numStoredProposerDutyEpochs)) //nolint:gosec // G115
+			if err != nil {
+				return errors.Wrap(err, "failed to delete old proposer duties")
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BeaconAPIStore) GetValidatorPubkeys(ctx context.Context, validatorIndices []int64) (map[int64]string, error) {
+	rows, err := database.New(s.DBPool).GetValidatorPubkeys(ctx, validatorIndices)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query validator pubkeys")
+	}
+	pubkeys := make(map[int64]string, len(rows))
+	for _, row := range rows {
+		pubkeys[row.ValidatorIndex] = row.Pubkey
+	}
+	return pubkeys, nil
+}
+
+func (s *BeaconAPIStore) PutValidatorPubkeys(ctx context.Context, pubkeys map[int64]string) error {
+	return s.DBPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		db := database.New(tx)
+		for validatorIndex, pubkey := range pubkeys {
+			err := db.InsertValidatorPubkey(ctx, database.InsertValidatorPubkeyParams{
+				ValidatorIndex: validatorIndex,
+				Pubkey:         pubkey,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to insert validator pubkey")
+			}
+		}
+		return nil
+	})
+}
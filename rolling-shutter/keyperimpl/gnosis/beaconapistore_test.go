@@ -0,0 +1,51 @@
+package gnosis
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+
+	gnosisDatabase "github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/beaconapiclient"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testsetup"
+)
+
+func TestBeaconAPIStoreProposerDutiesIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	dbpool, dbclose := testsetup.NewTestDBPool(ctx, t, gnosisDatabase.Definition)
+	t.Cleanup(dbclose)
+	store := &BeaconAPIStore{DBPool: dbpool}
+
+	newDuties := func(epoch uint64, dependentRoot string) *beaconapiclient.GetProposerDutiesResponse {
+		duties := &beaconapiclient.GetProposerDutiesResponse{DependentRoot: dependentRoot}
+		for slot := epoch * 2; slot < (epoch+1)*2; slot++ {
+			duties.Data = append(duties.Data, beaconapiclient.ProposerDuty{
+				Pubkey:         dependentRoot,
+				ValidatorIndex: slot,
+				Slot:           slot,
+			})
+		}
+		return duties
+	}
+
+	duties, err := store.GetProposerDuties(ctx, 3)
+	assert.NilError(t, err)
+	assert.Assert(t, duties == nil)
+
+	// duties changed by a reorg replace the stored ones
+	assert.NilError(t, store.PutProposerDuties(ctx, 3, newDuties(3, "0x01")))
+	assert.NilError(t, store.PutProposerDuties(ctx, 3, newDuties(3, "0x02")))
+	duties, err = store.GetProposerDuties(ctx, 3)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, duties, newDuties(3, "0x02"))
+
+	// old epochs are deleted
+	assert.NilError(t, store.PutProposerDuties(ctx, 3+numStoredProposerDutyEpochs+1, newDuties(8, "0x03")))
+	duties, err = store.GetProposerDuties(ctx, 3)
+	assert.NilError(t, err)
+	assert.Assert(t, duties == nil)
+}
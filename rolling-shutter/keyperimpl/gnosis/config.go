@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprconfig"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/beaconapiclient"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
@@ -35,6 +36,11 @@ func (c *Config) Init() {
 	c.Gnosis = NewGnosisConfig()
 	c.Shuttermint = kprconfig.NewShuttermintConfig()
 	c.Metrics = metricsserver.NewConfig()
+	c.Secrets = kprconfig.NewSecretsConfig()
+	c.Retention = kprconfig.NewRetentionConfig()
+	c.PeerRecords = kprconfig.NewPeerRecordsConfig()
+	c.KeyperStatus = kprconfig.NewKeyperStatusConfig()
+	c.BeaconAPI = beaconapiclient.NewConfig()
 }
 
 type Config struct {
@@ -46,10 +52,15 @@ type Config struct {
 	HTTPReadOnly      bool
 	HTTPListenAddress string
 
-	Gnosis      *GnosisConfig
-	P2P         *p2p.Config
-	Shuttermint *kprconfig.ShuttermintConfig
-	Metrics     *metricsserver.MetricsConfig
+	Gnosis       *GnosisConfig
+	P2P          *p2p.Config
+	Shuttermint  *kprconfig.ShuttermintConfig
+	Metrics      *metricsserver.MetricsConfig
+	Secrets      *kprconfig.SecretsConfig
+	Retention    *kprconfig.RetentionConfig
+	PeerRecords  *kprconfig.PeerRecordsConfig
+	KeyperStatus *kprconfig.KeyperStatusConfig
+	BeaconAPI    *beaconapiclient.Config
 
 	MaxNumKeysPerMessage uint64
 }
@@ -62,7 +73,7 @@ func (c *Config) Validate() error {
 	if c.Gnosis.GenesisSlotTimestamp > maxGenesisSlotTime {
 		return errors.Errorf("genesis slot timestamp is too big (%d > %d)", c.Gnosis.GenesisSlotTimestamp, maxGenesisSlotTime)
 	}
-	return nil
+	return c.Gnosis.Node.Validate()
 }
 
 func (c *Config) Name() string {
@@ -96,7 +107,7 @@ func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
 }
 
 func (c *Config) GetAddress() common.Address {
-	return c.Gnosis.Node.PrivateKey.EthereumAddress()
+	return c.Gnosis.Node.Address()
 }
 
 type GnosisConfig struct {
@@ -12,6 +12,29 @@ import (
 	"github.com/jackc/pgconn"
 )
 
+const deleteProposerDutiesBefore = `-- name: DeleteProposerDutiesBefore :execresult
+DELETE FROM proposer_duties
+WHERE epoch < $1
+`
+
+func (q *Queries) DeleteProposerDutiesBefore(ctx context.Context, epoch int64) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteProposerDutiesBefore, epoch)
+}
+
+const deleteSlotDecryptionSignaturesBefore = `-- name: DeleteSlotDecryptionSignaturesBefore :execresult
+DELETE FROM slot_decryption_signatures
+WHERE eon < $1 OR slot < $2
+`
+
+type DeleteSlotDecryptionSignaturesBeforeParams struct {
+	Eon  int64
+	Slot int64
+}
+
+func (q *Queries) DeleteSlotDecryptionSignaturesBefore(ctx context.Context, arg DeleteSlotDecryptionSignaturesBeforeParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteSlotDecryptionSignaturesBefore, arg.Eon, arg.Slot)
+}
+
 const deleteTransactionSubmittedEventsFromBlockNumber = `-- name: DeleteTransactionSubmittedEventsFromBlockNumber :exec
 DELETE FROM transaction_submitted_event WHERE block_number >= $1
 `
@@ -49,6 +72,38 @@ func (q *Queries) GetNumValidatorRegistrations(ctx context.Context) (int64, erro
 	return count, err
 }
 
+const getProposerDuties = `-- name: GetProposerDuties :many
+SELECT slot, epoch, validator_index, pubkey, dependent_root FROM proposer_duties
+WHERE epoch = $1
+ORDER BY slot
+`
+
+func (q *Queries) GetProposerDuties(ctx context.Context, epoch int64) ([]ProposerDuty, error) {
+	rows, err := q.db.Query(ctx, getProposerDuties, epoch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProposerDuty
+	for rows.Next() {
+		var i ProposerDuty
+		if err := rows.Scan(
+			&i.Slot,
+			&i.Epoch,
+			&i.ValidatorIndex,
+			&i.Pubkey,
+			&i.DependentRoot,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSlotDecryptionSignatures = `-- name: GetSlotDecryptionSignatures :many
 SELECT eon, slot, keyper_index, tx_pointer, identities_hash, signature FROM slot_decryption_signatures
 WHERE eon = $1 AND slot = $2 AND tx_pointer = $3 AND identities_hash = $4
@@ -97,6 +152,85 @@ func (q *Queries) GetSlotDecryptionSignatures(ctx context.Context, arg GetSlotDe
 	return items, nil
 }
 
+const getSlotDecryptionSignaturesInRange = `-- name: GetSlotDecryptionSignaturesInRange :many
+SELECT eon, slot, keyper_index, tx_pointer, identities_hash, signature FROM slot_decryption_signatures
+WHERE eon = $1 AND slot >= $2 AND slot < $3
+ORDER BY slot, tx_pointer, identities_hash, keyper_index
+`
+
+type GetSlotDecryptionSignaturesInRangeParams struct {
+	Eon       int64
+	StartSlot int64
+	EndSlot   int64
+}
+
+func (q *Queries) GetSlotDecryptionSignaturesInRange(ctx context.Context, arg GetSlotDecryptionSignaturesInRangeParams) ([]SlotDecryptionSignature, error) {
+	rows, err := q.db.Query(ctx, getSlotDecryptionSignaturesInRange, arg.Eon, arg.StartSlot, arg.EndSlot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SlotDecryptionSignature
+	for rows.Next() {
+		var i SlotDecryptionSignature
+		if err := rows.Scan(
+			&i.Eon,
+			&i.Slot,
+			&i.KeyperIndex,
+			&i.TxPointer,
+			&i.IdentitiesHash,
+			&i.Signature,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSlotDecryptionTxPointerRanges = `-- name: GetSlotDecryptionTxPointerRanges :many
+SELECT
+    eon,
+    (min(tx_pointer) FILTER (WHERE slot < $1))::bigint AS start_tx_pointer,
+    coalesce(min(tx_pointer) FILTER (WHERE slot >= $1), max(tx_pointer))::bigint AS end_tx_pointer
+FROM slot_decryption_signatures
+GROUP BY eon
+HAVING min(slot) < $1
+ORDER BY eon
+`
+
+type GetSlotDecryptionTxPointerRangesRow struct {
+	Eon            int64
+	StartTxPointer int64
+	EndTxPointer   int64
+}
+
+// Returns for every eon with signatures of slots before the given one the range of tx pointers
+// that have been decrypted in those slots. The range ends at the tx pointer of the first later
+// slot or, if there is none, at the tx pointer of the last earlier slot.
+func (q *Queries) GetSlotDecryptionTxPointerRanges(ctx context.Context, slot int64) ([]GetSlotDecryptionTxPointerRangesRow, error) {
+	rows, err := q.db.Query(ctx, getSlotDecryptionTxPointerRanges, slot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSlotDecryptionTxPointerRangesRow
+	for rows.Next() {
+		var i GetSlotDecryptionTxPointerRangesRow
+		if err := rows.Scan(&i.Eon, &i.StartTxPointer, &i.EndTxPointer); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTransactionSubmittedEventCount = `-- name: GetTransactionSubmittedEventCount :one
 SELECT
     cast(coalesce(max(index) + 1, 0) AS bigint)
@@ -154,6 +288,48 @@ func (q *Queries) GetTransactionSubmittedEvents(ctx context.Context, arg GetTran
 	return items, nil
 }
 
+const getTransactionSubmittedEventsInRange = `-- name: GetTransactionSubmittedEventsInRange :many
+SELECT index, block_number, block_hash, tx_index, log_index, eon, identity_prefix, sender, gas_limit FROM transaction_submitted_event
+WHERE eon = $1 AND index >= $2 AND index < $3
+ORDER BY index ASC
+`
+
+type GetTransactionSubmittedEventsInRangeParams struct {
+	Eon        int64
+	StartIndex int64
+	EndIndex   int64
+}
+
+func (q *Queries) GetTransactionSubmittedEventsInRange(ctx context.Context, arg GetTransactionSubmittedEventsInRangeParams) ([]TransactionSubmittedEvent, error) {
+	rows, err := q.db.Query(ctx, getTransactionSubmittedEventsInRange, arg.Eon, arg.StartIndex, arg.EndIndex)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TransactionSubmittedEvent
+	for rows.Next() {
+		var i TransactionSubmittedEvent
+		if err := rows.Scan(
+			&i.Index,
+			&i.BlockNumber,
+			&i.BlockHash,
+			&i.TxIndex,
+			&i.LogIndex,
+			&i.Eon,
+			&i.IdentityPrefix,
+			&i.Sender,
+			&i.GasLimit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTransactionSubmittedEventsSyncedUntil = `-- name: GetTransactionSubmittedEventsSyncedUntil :one
 SELECT enforce_one_row, block_hash, block_number, slot FROM transaction_submitted_events_synced_until LIMIT 1
 `
@@ -182,6 +358,31 @@ func (q *Queries) GetTxPointer(ctx context.Context, eon int64) (TxPointer, error
 	return i, err
 }
 
+const getValidatorPubkeys = `-- name: GetValidatorPubkeys :many
+SELECT validator_index, pubkey FROM validator_pubkeys
+WHERE validator_index = ANY($1::bigint[])
+`
+
+func (q *Queries) GetValidatorPubkeys(ctx context.Context, validatorIndices []int64) ([]ValidatorPubkey, error) {
+	rows, err := q.db.Query(ctx, getValidatorPubkeys, validatorIndices)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ValidatorPubkey
+	for rows.Next() {
+		var i ValidatorPubkey
+		if err := rows.Scan(&i.ValidatorIndex, &i.Pubkey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getValidatorRegistrationNonceBefore = `-- name: GetValidatorRegistrationNonceBefore :one
 SELECT nonce FROM validator_registrations
 WHERE validator_index = $1 AND block_number <= $2 AND tx_index <= $3 AND log_index <= $4
@@ -250,6 +451,32 @@ func (q *Queries) InitTxPointer(ctx context.Context, arg InitTxPointerParams) er
 	return err
 }
 
+const insertProposerDuty = `-- name: InsertProposerDuty :exec
+INSERT INTO proposer_duties (slot, epoch, validator_index, pubkey, dependent_root)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (slot) DO UPDATE
+SET epoch = $2, validator_index = $3, pubkey = $4, dependent_root = $5
+`
+
+type InsertProposerDutyParams struct {
+	Slot           int64
+	Epoch          int64
+	ValidatorIndex int64
+	Pubkey         string
+	DependentRoot  string
+}
+
+func (q *Queries) InsertProposerDuty(ctx context.Context, arg InsertProposerDutyParams) error {
+	_, err := q.db.Exec(ctx, insertProposerDuty,
+		arg.Slot,
+		arg.Epoch,
+		arg.ValidatorIndex,
+		arg.Pubkey,
+		arg.DependentRoot,
+	)
+	return err
+}
+
 const insertSlotDecryptionSignature = `-- name: InsertSlotDecryptionSignature :exec
 INSERT INTO slot_decryption_signatures (eon, slot, keyper_index, tx_pointer, identities_hash, signature)
 VALUES ($1, $2, $3, $4, $5, $6)
@@ -326,6 +553,22 @@ func (q *Queries) InsertTransactionSubmittedEvent(ctx context.Context, arg Inser
 	)
 }
 
+const insertValidatorPubkey = `-- name: InsertValidatorPubkey :exec
+INSERT INTO validator_pubkeys (validator_index, pubkey)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type InsertValidatorPubkeyParams struct {
+	ValidatorIndex int64
+	Pubkey         string
+}
+
+func (q *Queries) InsertValidatorPubkey(ctx context.Context, arg InsertValidatorPubkeyParams) error {
+	_, err := q.db.Exec(ctx, insertValidatorPubkey, arg.ValidatorIndex, arg.Pubkey)
+	return err
+}
+
 const insertValidatorRegistration = `-- name: InsertValidatorRegistration :exec
 INSERT INTO validator_registrations (
     block_number,
@@ -15,6 +15,14 @@ type CurrentDecryptionTrigger struct {
 	IdentitiesHash []byte
 }
 
+type ProposerDuty struct {
+	Slot           int64
+	Epoch          int64
+	ValidatorIndex int64
+	Pubkey         string
+	DependentRoot  string
+}
+
 type SlotDecryptionSignature struct {
 	Eon            int64
 	Slot           int64
@@ -54,6 +62,11 @@ type TxPointer struct {
 	Value int64
 }
 
+type ValidatorPubkey struct {
+	ValidatorIndex int64
+	Pubkey         string
+}
+
 type ValidatorRegistration struct {
 	BlockNumber    int64
 	BlockHash      []byte
@@ -0,0 +1,4 @@
+-- schema-version: gnosiskeyper-3 --
+-- index used to prune old signatures by slot
+
+CREATE INDEX IF NOT EXISTS slot_decryption_signatures_slot_idx ON slot_decryption_signatures (slot);
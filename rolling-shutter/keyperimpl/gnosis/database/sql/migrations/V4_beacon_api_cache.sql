@@ -0,0 +1,15 @@
+-- schema-version: gnosiskeyper-4 --
+-- cache of beacon API responses, so that they don't have to be fetched again after a restart
+
+CREATE TABLE proposer_duties(
+    slot bigint PRIMARY KEY CHECK (slot >= 0),
+    epoch bigint NOT NULL CHECK (epoch >= 0),
+    validator_index bigint NOT NULL CHECK (validator_index >= 0),
+    pubkey text NOT NULL
+);
+CREATE INDEX proposer_duties_epoch_idx ON proposer_duties (epoch);
+
+CREATE TABLE validator_pubkeys(
+    validator_index bigint PRIMARY KEY CHECK (validator_index >= 0),
+    pubkey text NOT NULL
+);
@@ -0,0 +1,6 @@
+-- schema-version: gnosiskeyper-5 --
+-- proposer duties change if the block they depend on is reorged, so they are stored with the root
+-- of that block. Duties cached without it are dropped, they will just be fetched again.
+
+DELETE FROM proposer_duties;
+ALTER TABLE proposer_duties ADD COLUMN dependent_root text NOT NULL;
@@ -26,6 +26,11 @@ WHERE eon = $1 AND index >= $2 AND index < $2 + $3
 ORDER BY index ASC
 LIMIT $3;
 
+-- name: GetTransactionSubmittedEventsInRange :many
+SELECT * FROM transaction_submitted_event
+WHERE eon = $1 AND index >= sqlc.arg(start_index) AND index < sqlc.arg(end_index)
+ORDER BY index ASC;
+
 -- name: SetTransactionSubmittedEventsSyncedUntil :exec
 INSERT INTO transaction_submitted_events_synced_until (block_hash, block_number, slot) VALUES ($1, $2, $3)
 ON CONFLICT (enforce_one_row) DO UPDATE
@@ -83,12 +88,34 @@ INSERT INTO slot_decryption_signatures (eon, slot, keyper_index, tx_pointer, ide
 VALUES ($1, $2, $3, $4, $5, $6)
 ON CONFLICT DO NOTHING;
 
+-- name: DeleteSlotDecryptionSignaturesBefore :execresult
+DELETE FROM slot_decryption_signatures
+WHERE eon < $1 OR slot < $2;
+
 -- name: GetSlotDecryptionSignatures :many
 SELECT * FROM slot_decryption_signatures
 WHERE eon = $1 AND slot = $2 AND tx_pointer = $3 AND identities_hash = $4
 ORDER BY keyper_index ASC
 LIMIT $5;
 
+-- name: GetSlotDecryptionSignaturesInRange :many
+SELECT * FROM slot_decryption_signatures
+WHERE eon = $1 AND slot >= sqlc.arg(start_slot) AND slot < sqlc.arg(end_slot)
+ORDER BY slot, tx_pointer, identities_hash, keyper_index;
+
+-- name: GetSlotDecryptionTxPointerRanges :many
+-- Returns for every eon with signatures of slots before the given one the range of tx pointers
+-- that have been decrypted in those slots. The range ends at the tx pointer of the first later
+-- slot or, if there is none, at the tx pointer of the last earlier slot.
+SELECT
+    eon,
+    (min(tx_pointer) FILTER (WHERE slot < $1))::bigint AS start_tx_pointer,
+    coalesce(min(tx_pointer) FILTER (WHERE slot >= $1), max(tx_pointer))::bigint AS end_tx_pointer
+FROM slot_decryption_signatures
+GROUP BY eon
+HAVING min(slot) < $1
+ORDER BY eon;
+
 -- name: InsertValidatorRegistration :exec
 INSERT INTO validator_registrations (
     block_number,
@@ -121,4 +148,28 @@ ORDER BY block_number DESC, tx_index DESC, log_index DESC
 LIMIT 1;
 
 -- name: GetNumValidatorRegistrations :one
-SELECT COUNT(*) FROM validator_registrations;
\ No newline at end of file
+SELECT COUNT(*) FROM validator_registrations;
+
+-- name: GetProposerDuties :many
+SELECT * FROM proposer_duties
+WHERE epoch = $1
+ORDER BY slot;
+
+-- name: InsertProposerDuty :exec
+INSERT INTO proposer_duties (slot, epoch, validator_index, pubkey, dependent_root)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (slot) DO UPDATE
+SET epoch = $2, validator_index = $3, pubkey = $4, dependent_root = $5;
+
+-- name: DeleteProposerDutiesBefore :execresult
+DELETE FROM proposer_duties
+WHERE epoch < $1;
+
+-- name: GetValidatorPubkeys :many
+SELECT * FROM validator_pubkeys
+WHERE validator_index = ANY(sqlc.arg(validator_indices)::bigint[]);
+
+-- name: InsertValidatorPubkey :exec
+INSERT INTO validator_pubkeys (validator_index, pubkey)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
@@ -4,13 +4,14 @@
 package gnosisssztypes
 
 import (
-	"crypto/ecdsa"
+	"context"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/identitypreimage"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 )
 
 type IdentityPreimage struct {
@@ -53,12 +54,12 @@ func NewSlotDecryptionSignatureData(
 	}, nil
 }
 
-func (d *SlotDecryptionSignatureData) ComputeSignature(key *ecdsa.PrivateKey) ([]byte, error) {
+func (d *SlotDecryptionSignatureData) ComputeSignature(ctx context.Context, s signer.Signer) ([]byte, error) {
 	h, err := d.HashTreeRoot()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to compute hash tree root of slot decryption signature data")
 	}
-	return crypto.Sign(h[:], key)
+	return s.SignHash(ctx, h[:])
 }
 
 func (d *SlotDecryptionSignatureData) CheckSignature(signature []byte, address common.Address) (bool, error) {
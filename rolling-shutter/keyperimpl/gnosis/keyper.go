@@ -3,9 +3,9 @@ package gnosis
 import (
 	"context"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	gethLog "github.com/ethereum/go-ethereum/log"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
@@ -23,7 +23,9 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync"
 	syncevent "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/event"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/db"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/failover"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/slotticker"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 )
@@ -42,6 +44,8 @@ type Keyper struct {
 	core            *keyper.KeyperCore
 	config          *Config
 	dbpool          *pgxpool.Pool
+	signer          signer.Signer
+	ethClient       *failover.Client
 	beaconAPIClient *beaconapiclient.Client
 
 	chainSyncClient     *chainsync.Client
@@ -50,6 +54,8 @@ type Keyper struct {
 	eonKeyPublisher     *eonkeypublisher.EonKeyPublisher
 	latestTriggeredSlot *uint64
 	syncMonitor         *SyncMonitor
+	// latestSlot is the most recent slot seen by the slot ticker
+	latestSlot atomic.Uint64
 
 	// input events
 	newBlocks        chan *syncevent.LatestBlock
@@ -91,7 +97,21 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to connect to database")
 	}
-	kpr.beaconAPIClient, err = beaconapiclient.New(kpr.config.BeaconAPIURL)
+	kpr.signer, err = signer.New(kpr.config.Gnosis.Node)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize signer")
+	}
+	kpr.ethClient, err = failover.Dial(ctx, kpr.config.Gnosis.Node.EthereumURLs())
+	if err != nil {
+		return errors.Wrap(err, "failed to dial ethereum node")
+	}
+	kpr.beaconAPIClient, err = beaconapiclient.New(
+		kpr.config.BeaconAPIURL,
+		append(
+			kpr.config.BeaconAPI.Options(),
+			beaconapiclient.WithStore(&BeaconAPIStore{DBPool: kpr.dbpool}),
+		)...,
+	)
 	if err != nil {
 		return errors.Wrap(err, "failed to initialize beacon API client")
 	}
@@ -102,7 +122,8 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 	}
 	messageSender.AddMessageHandler(&DecryptionKeySharesHandler{kpr.dbpool})
 	messageSender.AddMessageHandler(&DecryptionKeysHandler{kpr.dbpool})
-	messagingMiddleware := NewMessagingMiddleware(messageSender, kpr.dbpool, kpr.config)
+	messagingMiddleware := NewMessagingMiddleware(messageSender, kpr.dbpool, kpr.config, kpr.signer)
+	messagingMiddleware.SetBackfillProvider(messagingMiddleware.backfillSlots)
 
 	kpr.core, err = NewKeyper(kpr, messagingMiddleware)
 	if err != nil {
@@ -111,27 +132,24 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 
 	kpr.chainSyncClient, err = chainsync.NewClient(
 		ctx,
-		chainsync.WithClientURL(kpr.config.Gnosis.Node.EthereumURL),
+		chainsync.WithClient(kpr.ethClient),
 		chainsync.WithKeyperSetManager(kpr.config.Gnosis.Contracts.KeyperSetManager),
 		chainsync.WithKeyBroadcastContract(kpr.config.Gnosis.Contracts.KeyBroadcastContract),
 		chainsync.WithSyncNewBlock(kpr.channelNewBlock),
 		chainsync.WithSyncNewKeyperSet(kpr.channelNewKeyperSet),
-		chainsync.WithPrivateKey(kpr.config.Gnosis.Node.PrivateKey.Key),
+		chainsync.WithSigner(kpr.signer),
 		chainsync.WithLogger(gethLog.NewLogger(slog.Default().Handler())),
 	)
 	if err != nil {
 		return err
 	}
 
-	eonKeyPublisherClient, err := ethclient.DialContext(ctx, kpr.config.Gnosis.Node.EthereumURL)
-	if err != nil {
-		return errors.Wrapf(err, "failed to dial ethereum node at %s", kpr.config.Gnosis.Node.EthereumURL)
-	}
 	kpr.eonKeyPublisher, err = eonkeypublisher.NewEonKeyPublisher(
 		kpr.dbpool,
-		eonKeyPublisherClient,
+		kpr.ethClient,
 		kpr.config.Gnosis.Contracts.KeyperSetManager,
-		kpr.config.Gnosis.Node.PrivateKey.Key,
+		kpr.signer,
+		kpr.core.Keyring(),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to initialize eon key publisher")
@@ -166,7 +184,14 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 	}
 
 	runner.Go(func() error { return kpr.processInputs(ctx) })
-	return runner.StartService(kpr.core, kpr.chainSyncClient, kpr.slotTicker, kpr.eonKeyPublisher)
+	return runner.StartService(
+		kpr.ethClient,
+		kpr.beaconAPIClient,
+		kpr.core,
+		kpr.chainSyncClient,
+		kpr.slotTicker,
+		kpr.eonKeyPublisher,
+	)
 }
 
 func NewKeyper(kpr *Keyper, messagingMiddleware *MessagingMiddleware) (*keyper.KeyperCore, error) {
@@ -181,13 +206,21 @@ func NewKeyper(kpr *Keyper, messagingMiddleware *MessagingMiddleware) (*keyper.K
 			Ethereum:             kpr.config.Gnosis.Node,
 			Shuttermint:          kpr.config.Shuttermint,
 			Metrics:              kpr.config.Metrics,
+			Secrets:              kpr.config.Secrets,
+			Retention:            kpr.config.Retention,
+			PeerRecords:          kpr.config.PeerRecords,
+			KeyperStatus:         kpr.config.KeyperStatus,
 			MaxNumKeysPerMessage: kpr.config.MaxNumKeysPerMessage,
 		},
 		kpr.decryptionTriggerChannel,
 		keyper.WithDBPool(kpr.dbpool),
+		keyper.WithSigner(kpr.signer),
+		keyper.WithBlockSyncClient(kpr.ethClient),
 		keyper.NoBroadcastEonPublicKey(),
 		keyper.WithEonPublicKeyHandler(kpr.channelNewEonPublicKey),
 		keyper.WithMessaging(messagingMiddleware),
+		keyper.WithPruneFunc(kpr.prune),
+		keyper.WithStatusSlot(kpr.latestSlot.Load),
 	)
 	return core, err
 }
@@ -196,22 +229,17 @@ func NewKeyper(kpr *Keyper, messagingMiddleware *MessagingMiddleware) (*keyper.K
 // keyper set. Otherwise, the syncer will only be initialized once such a keyper set is observed to
 // be added, as only then we will know which eon(s) we are responsible for.
 func (kpr *Keyper) initSequencerSyncer(ctx context.Context) error {
-	client, err := ethclient.DialContext(ctx, kpr.config.Gnosis.Node.EthereumURL)
-	if err != nil {
-		return errors.Wrap(err, "failed to dial Ethereum execution node")
-	}
-
 	log.Info().
 		Str("contract-address", kpr.config.Gnosis.Contracts.KeyperSetManager.Hex()).
 		Msg("initializing sequencer syncer")
-	contract, err := sequencerBindings.NewSequencer(kpr.config.Gnosis.Contracts.Sequencer, client)
+	contract, err := sequencerBindings.NewSequencer(kpr.config.Gnosis.Contracts.Sequencer, kpr.ethClient)
 	if err != nil {
 		return err
 	}
 	kpr.sequencerSyncer = &SequencerSyncer{
 		Contract:             contract,
 		DBPool:               kpr.dbpool,
-		ExecutionClient:      client,
+		ExecutionClient:      kpr.ethClient,
 		GenesisSlotTimestamp: kpr.config.Gnosis.GenesisSlotTimestamp,
 		SecondsPerSlot:       kpr.config.Gnosis.SecondsPerSlot,
 		SyncStartBlockNumber: kpr.config.Gnosis.SyncStartBlockNumber,
@@ -219,7 +247,7 @@ func (kpr *Keyper) initSequencerSyncer(ctx context.Context) error {
 
 	// Perform an initial sync now because it might take some time and doing so during regular
 	// slot processing might hold up things
-	latestHeader, err := client.HeaderByNumber(ctx, nil)
+	latestHeader, err := kpr.ethClient.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return errors.Wrap(err, "failed to get latest block header")
 	}
@@ -232,17 +260,13 @@ func (kpr *Keyper) initSequencerSyncer(ctx context.Context) error {
 }
 
 func (kpr *Keyper) initValidatorSyncer(ctx context.Context) error {
-	validatorSyncerClient, err := ethclient.DialContext(ctx, kpr.config.Gnosis.Node.EthereumURL)
-	if err != nil {
-		return errors.Wrap(err, "failed to dial ethereum node")
-	}
-	chainID, err := validatorSyncerClient.ChainID(ctx)
+	chainID, err := kpr.ethClient.ChainID(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to get chain ID")
 	}
 	validatorRegistryContract, err := validatorRegistryBindings.NewValidatorregistry(
 		kpr.config.Gnosis.Contracts.ValidatorRegistry,
-		validatorSyncerClient,
+		kpr.ethClient,
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to instantiate validator registry contract")
@@ -251,7 +275,7 @@ func (kpr *Keyper) initValidatorSyncer(ctx context.Context) error {
 		Contract:                               validatorRegistryContract,
 		DBPool:                                 kpr.dbpool,
 		BeaconAPIClient:                        kpr.beaconAPIClient,
-		ExecutionClient:                        validatorSyncerClient,
+		ExecutionClient:                        kpr.ethClient,
 		ChainID:                                chainID.Uint64(),
 		SyncStartBlockNumber:                   kpr.config.Gnosis.SyncStartBlockNumber,
 		EnableAggregateValidatorRegistrationV1: kpr.config.Gnosis.EnableAggregateValidatorRegistrationV1,
@@ -259,7 +283,7 @@ func (kpr *Keyper) initValidatorSyncer(ctx context.Context) error {
 
 	// Perform an initial sync now because it might take some time and doing so during regular
 	// slot processing might hold up things
-	latestHeader, err := validatorSyncerClient.HeaderByNumber(ctx, nil)
+	latestHeader, err := kpr.ethClient.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return errors.Wrap(err, "failed to get latest block header")
 	}
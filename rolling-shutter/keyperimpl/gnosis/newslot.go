@@ -27,6 +27,7 @@ import (
 )
 
 func (kpr *Keyper) processNewSlot(ctx context.Context, slot slotticker.Slot) error {
+	kpr.latestSlot.Store(slot.Number)
 	return kpr.maybeTriggerDecryption(ctx, slot.Number)
 }
 
@@ -239,7 +240,7 @@ func (kpr *Keyper) triggerDecryption(
 		return err
 	}
 
-	identityPreimages, err := kpr.getDecryptionIdentityPreimages(ctx, slot, keyperSet.KeyperConfigIndex, txPointer)
+	identityPreimages, err := getDecryptionIdentityPreimages(ctx, kpr.dbpool, kpr.config.Gnosis, slot, keyperSet.KeyperConfigIndex, txPointer)
 	if err != nil {
 		return err
 	}
@@ -273,13 +274,15 @@ func (kpr *Keyper) triggerDecryption(
 	return nil
 }
 
-func (kpr *Keyper) getDecryptionIdentityPreimages(
-	ctx context.Context, slot uint64, eon int64, txPointer int64,
+// getDecryptionIdentityPreimages returns the sorted identity preimages to decrypt in the given
+// slot, starting with the transaction at txPointer.
+func getDecryptionIdentityPreimages(
+	ctx context.Context, dbpool *pgxpool.Pool, config *GnosisConfig, slot uint64, eon int64, txPointer int64,
 ) ([]identitypreimage.IdentityPreimage, error) {
 	identityPreimages := []identitypreimage.IdentityPreimage{}
 
-	queries := gnosisdatabase.New(kpr.dbpool)
-	limitUint64 := kpr.config.Gnosis.EncryptedGasLimit/kpr.config.Gnosis.MinGasPerTransaction + 1
+	queries := gnosisdatabase.New(dbpool)
+	limitUint64 := config.EncryptedGasLimit/config.MinGasPerTransaction + 1
 	if limitUint64 > math.MaxInt32 {
 		return identityPreimages, errors.New("gas limit too big")
 	}
@@ -302,7 +305,7 @@ func (kpr *Keyper) getDecryptionIdentityPreimages(
 		gas += uint64(event.GasLimit)
 		// We need to add at least one transaction otherwise the tx-pointer will get stuck
 		// if the next tx has a gas limit higher than the configured gas limit
-		if gas > kpr.config.Gnosis.EncryptedGasLimit && len(identityPreimages) > 1 {
+		if gas > config.EncryptedGasLimit && len(identityPreimages) > 1 {
 			break
 		}
 		identityPreimage, err := transactionSubmittedEventToIdentityPreimage(event)
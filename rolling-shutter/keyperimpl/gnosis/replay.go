@@ -0,0 +1,39 @@
+package gnosis
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+)
+
+// NewReplayMessaging returns messaging that is not connected to the network, with the same
+// validators and handlers registered as by a running keyper. Replaying messages with it modifies
+// the given database, so it should be a scratch copy of the keyper's database.
+func NewReplayMessaging(ctx context.Context, config *Config, dbpool *pgxpool.Pool) (*p2p.P2PMessaging, error) {
+	s, err := signer.New(config.Gnosis.Node)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize signer")
+	}
+	kpr := New(config)
+	kpr.dbpool = dbpool
+	kpr.signer = s
+
+	messaging := p2p.NewReplayMessaging()
+	messaging.AddMessageHandler(&DecryptionKeySharesHandler{dbpool})
+	messaging.AddMessageHandler(&DecryptionKeysHandler{dbpool})
+	messagingMiddleware := NewMessagingMiddleware(messaging, dbpool, config, s)
+
+	kpr.core, err = NewKeyper(kpr, messagingMiddleware)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't instantiate keyper core")
+	}
+	err = kpr.core.InitReplay(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return messaging, nil
+}
@@ -0,0 +1,131 @@
+package gnosis
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper"
+	corekeyperdatabase "github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
+)
+
+// prune deletes the decryption signatures of pruned eons as well as the decryption signatures,
+// key shares and keys of slots older than the configured maximum age.
+func (kpr *Keyper) prune(ctx context.Context, tx pgx.Tx, cutoff keyper.RetentionCutoff) (map[string]int64, error) {
+	slot := int64(0)
+	if cutoff.MaxAgeSlots > 0 {
+		currentSlot := medley.BlockTimestampToSlot(
+			uint64(time.Now().Unix()),
+			kpr.config.Gnosis.GenesisSlotTimestamp,
+			kpr.config.Gnosis.SecondsPerSlot,
+		)
+		if currentSlot > cutoff.MaxAgeSlots {
+			slot = int64(currentSlot - cutoff.MaxAgeSlots) //nolint:gosec // G115
+		}
+	}
+	return pruneSlots(ctx, tx, cutoff, slot)
+}
+
+// pruneSlots deletes the decryption signatures of eons before the cutoff eon and of slots before
+// the given one. The key shares and, if allowed by the cutoff, the keys of those slots are deleted
+// too, identified by the slot identity preimages and the identity preimages of the transactions
+// decrypted in them. The latter are known from the tx pointers of the signatures, so this has to
+// happen before the signatures are deleted.
+func pruneSlots(ctx context.Context, tx pgx.Tx, cutoff keyper.RetentionCutoff, slot int64) (map[string]int64, error) {
+	db := database.New(tx)
+	coreDB := corekeyperdatabase.New(tx)
+	pruned := map[string]int64{}
+
+	if slot > 0 {
+		txPointerRanges, err := db.GetSlotDecryptionTxPointerRanges(ctx, slot)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query tx pointers of old slots")
+		}
+		eons, err := coreDB.GetAllEons(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query eons")
+		}
+		for _, txPointerRange := range txPointerRanges {
+			// The eon of the gnosis tables is the keyper config index.
+			events, err := db.GetTransactionSubmittedEventsInRange(ctx, database.GetTransactionSubmittedEventsInRangeParams{
+				Eon:        txPointerRange.Eon,
+				StartIndex: txPointerRange.StartTxPointer,
+				EndIndex:   txPointerRange.EndTxPointer,
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to query transaction submitted events of old slots")
+			}
+			if len(events) == 0 {
+				continue
+			}
+			epochIDs := make([][]byte, 0, len(events))
+			for _, event := range events {
+				identityPreimage, err := transactionSubmittedEventToIdentityPreimage(event)
+				if err != nil {
+					return nil, err
+				}
+				epochIDs = append(epochIDs, identityPreimage.Bytes())
+			}
+			for _, eon := range eons {
+				if eon.KeyperConfigIndex != txPointerRange.Eon {
+					continue
+				}
+				tag, err := coreDB.DeleteDecryptionKeySharesByEpochIDs(ctx, corekeyperdatabase.DeleteDecryptionKeySharesByEpochIDsParams{
+					Eon:      eon.Eon,
+					EpochIds: epochIDs,
+				})
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to delete decryption key shares of old transactions")
+				}
+				pruned["decryption_key_share"] += tag.RowsAffected()
+				if cutoff.PruneDecryptionKeys {
+					tag, err = coreDB.DeleteDecryptionKeysByEpochIDs(ctx, corekeyperdatabase.DeleteDecryptionKeysByEpochIDsParams{
+						Eon:      eon.Eon,
+						EpochIds: epochIDs,
+					})
+					if err != nil {
+						return nil, errors.Wrap(err, "failed to delete decryption keys of old transactions")
+					}
+					pruned["decryption_key"] += tag.RowsAffected()
+				}
+			}
+		}
+
+		// Slot identity preimages sort before all transaction identity preimages and in the order
+		// of their slots, see makeSlotIdentityPreimage.
+		startEpochID := makeSlotIdentityPreimage(0).Bytes()
+		endEpochID := makeSlotIdentityPreimage(uint64(slot)).Bytes()
+		tag, err := coreDB.DeleteDecryptionKeySharesInRange(ctx, corekeyperdatabase.DeleteDecryptionKeySharesInRangeParams{
+			StartEpochID: startEpochID,
+			EndEpochID:   endEpochID,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to delete decryption key shares of old slots")
+		}
+		pruned["decryption_key_share"] += tag.RowsAffected()
+		if cutoff.PruneDecryptionKeys {
+			tag, err = coreDB.DeleteDecryptionKeysInRange(ctx, corekeyperdatabase.DeleteDecryptionKeysInRangeParams{
+				StartEpochID: startEpochID,
+				EndEpochID:   endEpochID,
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to delete decryption keys of old slots")
+			}
+			pruned["decryption_key"] += tag.RowsAffected()
+		}
+	}
+
+	tag, err := db.DeleteSlotDecryptionSignaturesBefore(ctx, database.DeleteSlotDecryptionSignaturesBeforeParams{
+		Eon:  cutoff.Eon,
+		Slot: slot,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete slot decryption signatures")
+	}
+	pruned["slot_decryption_signatures"] = tag.RowsAffected()
+	return pruned, nil
+}
@@ -0,0 +1,134 @@
+package gnosis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper"
+	corekeyperdatabase "github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
+	gnosisDatabase "github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testsetup"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
+)
+
+func TestPruneSlotsIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	t.Run("PruneKeys", func(t *testing.T) { testPruneSlots(t, true) })
+	t.Run("KeepKeys", func(t *testing.T) { testPruneSlots(t, false) })
+}
+
+func testPruneSlots(t *testing.T, pruneKeys bool) {
+	t.Helper()
+	ctx := context.Background()
+	dbpool, dbclose := testsetup.NewTestDBPool(ctx, t, gnosisDatabase.Definition)
+	t.Cleanup(dbclose)
+	db := gnosisDatabase.New(dbpool)
+	coreDB := corekeyperdatabase.New(dbpool)
+
+	// A single eon in which slot 10 decrypts the transactions 0 and 1, slot 11 transaction 2
+	// and slot 12 transaction 3.
+	eon := int64(1)
+	assert.NilError(t, coreDB.InsertEon(ctx, corekeyperdatabase.InsertEonParams{
+		Eon:                   eon,
+		ActivationBlockNumber: 0,
+		KeyperConfigIndex:     eon,
+	}))
+	insertKey := func(epochID []byte) {
+		t.Helper()
+		assert.NilError(t, coreDB.InsertDecryptionKeyShare(ctx, corekeyperdatabase.InsertDecryptionKeyShareParams{
+			Eon:                eon,
+			EpochID:            epochID,
+			KeyperIndex:        0,
+			DecryptionKeyShare: []byte("share"),
+		}))
+		_, err := coreDB.InsertDecryptionKey(ctx, corekeyperdatabase.InsertDecryptionKeyParams{
+			Eon:           eon,
+			EpochID:       epochID,
+			DecryptionKey: []byte("key"),
+		})
+		assert.NilError(t, err)
+	}
+
+	txEpochIDs := [][]byte{}
+	for index := int64(0); index < 4; index++ {
+		params := gnosisDatabase.InsertTransactionSubmittedEventParams{
+			Index:          index,
+			BlockHash:      []byte{},
+			Eon:            eon,
+			IdentityPrefix: common.BigToHash(common.Big1).Bytes(),
+			Sender:         shdb.EncodeAddress(common.HexToAddress("0x1000000000000000000000000000000000000000")),
+		}
+		params.IdentityPrefix[0] = byte(index)
+		_, err := db.InsertTransactionSubmittedEvent(ctx, params)
+		assert.NilError(t, err)
+		identityPreimage, err := transactionSubmittedEventToIdentityPreimage(gnosisDatabase.TransactionSubmittedEvent{
+			IdentityPrefix: params.IdentityPrefix,
+			Sender:         params.Sender,
+		})
+		assert.NilError(t, err)
+		txEpochIDs = append(txEpochIDs, identityPreimage.Bytes())
+		insertKey(identityPreimage.Bytes())
+	}
+	slotEpochIDs := map[int64][]byte{}
+	for slot, txPointer := range map[int64]int64{10: 0, 11: 2, 12: 3} {
+		assert.NilError(t, db.InsertSlotDecryptionSignature(ctx, gnosisDatabase.InsertSlotDecryptionSignatureParams{
+			Eon:            eon,
+			Slot:           slot,
+			KeyperIndex:    0,
+			TxPointer:      txPointer,
+			IdentitiesHash: []byte{},
+			Signature:      []byte{},
+		}))
+		slotEpochIDs[slot] = makeSlotIdentityPreimage(uint64(slot)).Bytes()
+		insertKey(slotEpochIDs[slot])
+	}
+
+	cutoff := keyper.RetentionCutoff{Eon: eon, MaxAgeSlots: 1, PruneDecryptionKeys: pruneKeys}
+	err := dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := pruneSlots(ctx, tx, cutoff, 12)
+		return err
+	})
+	assert.NilError(t, err)
+
+	countRows := func(table string, epochID []byte) int {
+		t.Helper()
+		var count int
+		err := dbpool.QueryRow(ctx, "SELECT count(*) FROM "+table+" WHERE eon = $1 AND epoch_id = $2", eon, epochID).Scan(&count)
+		assert.NilError(t, err)
+		return count
+	}
+	assertPruned := func(epochID []byte, pruned bool) {
+		t.Helper()
+		expected := 1
+		if pruned {
+			expected = 0
+		}
+		assert.Equal(t, countRows("decryption_key_share", epochID), expected)
+		if !pruneKeys {
+			expected = 1
+		}
+		assert.Equal(t, countRows("decryption_key", epochID), expected)
+	}
+	assertPruned(slotEpochIDs[10], true)
+	assertPruned(slotEpochIDs[11], true)
+	assertPruned(slotEpochIDs[12], false)
+	assertPruned(txEpochIDs[0], true)
+	assertPruned(txEpochIDs[1], true)
+	assertPruned(txEpochIDs[2], true)
+	assertPruned(txEpochIDs[3], false)
+
+	signatures, err := db.GetSlotDecryptionSignaturesInRange(ctx, gnosisDatabase.GetSlotDecryptionSignaturesInRangeParams{
+		Eon:       eon,
+		StartSlot: 0,
+		EndSlot:   100,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(signatures), 1)
+	assert.Equal(t, signatures[0].Slot, int64(12))
+}
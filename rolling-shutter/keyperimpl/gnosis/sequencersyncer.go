@@ -9,7 +9,6 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
@@ -18,6 +17,7 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis/database"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
+	syncclient "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
@@ -27,7 +27,7 @@ const AssumedReorgDepth = 10
 type SequencerSyncer struct {
 	Contract             *sequencerBindings.Sequencer
 	DBPool               *pgxpool.Pool
-	ExecutionClient      *ethclient.Client
+	ExecutionClient      syncclient.Client
 	GenesisSlotTimestamp uint64
 	SecondsPerSlot       uint64
 	SyncStartBlockNumber uint64
@@ -0,0 +1,100 @@
+package gnosis
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// slotBackfillQueueSize is the number of backfill requests waiting to be sent. Further gaps are
+// dropped while the queue is full.
+const slotBackfillQueueSize = 16
+
+// SlotBackfiller requests the keys of slots that have been skipped on the gossip topic from
+// other peers. The keys received are validated and handled like keys received via gossip.
+type SlotBackfiller struct {
+	messaging  *p2p.P2PMessaging
+	instanceID uint64
+
+	mux      sync.Mutex
+	lastSlot uint64
+	requests chan *p2pmsg.BackfillRequest
+}
+
+func NewSlotBackfiller(messaging *p2p.P2PMessaging, instanceID uint64) *SlotBackfiller {
+	return &SlotBackfiller{
+		messaging:  messaging,
+		instanceID: instanceID,
+		requests:   make(chan *p2pmsg.BackfillRequest, slotBackfillQueueSize),
+	}
+}
+
+// Observe has to be called with the eon and slot of every keys message received. If slots have
+// been skipped since the most recent message, a backfill request for them is scheduled. Note
+// that keys are not released for every slot, so not every gap means messages have been missed.
+func (b *SlotBackfiller) Observe(eon uint64, slot uint64) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if slot <= b.lastSlot {
+		return
+	}
+	lastSlot := b.lastSlot
+	b.lastSlot = slot
+	if lastSlot == 0 || slot == lastSlot+1 {
+		return
+	}
+	start := lastSlot + 1
+	if slot-start > backfillMaxSlots {
+		start = slot - backfillMaxSlots
+	}
+	req := &p2pmsg.BackfillRequest{
+		InstanceId: b.instanceID,
+		Eon:        eon,
+		Keys:       true,
+		Range: &p2pmsg.BackfillRequest_Slots{
+			Slots: &p2pmsg.SlotRange{Start: start, End: slot},
+		},
+	}
+	select {
+	case b.requests <- req:
+	default:
+		log.Warn().Uint64("start", start).Uint64("end", slot).Msg("backfill queue full, dropping request")
+	}
+}
+
+func (b *SlotBackfiller) Start(ctx context.Context, runner service.Runner) error { //nolint:unparam
+	runner.Go(func() error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case req := <-b.requests:
+				b.backfill(ctx, req)
+			}
+		}
+	})
+	return nil
+}
+
+func (b *SlotBackfiller) backfill(ctx context.Context, req *p2pmsg.BackfillRequest) {
+	slots := req.GetSlots()
+	numAccepted, err := b.messaging.Backfill(ctx, req)
+	if err != nil {
+		log.Info().
+			Err(err).
+			Uint64("start", slots.Start).
+			Uint64("end", slots.End).
+			Msg("failed to backfill slots")
+		return
+	}
+	log.Debug().
+		Uint64("start", slots.Start).
+		Uint64("end", slots.End).
+		Int("num-messages", numAccepted).
+		Msg("backfilled slots")
+}
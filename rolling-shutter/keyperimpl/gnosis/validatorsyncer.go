@@ -9,7 +9,6 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
@@ -21,6 +20,7 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/gnosis/database"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/beaconapiclient"
+	syncclient "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/validatorregistry"
 )
 
@@ -32,7 +32,7 @@ type ValidatorSyncer struct {
 	Contract                               *validatorRegistryBindings.Validatorregistry
 	DBPool                                 *pgxpool.Pool
 	BeaconAPIClient                        *beaconapiclient.Client
-	ExecutionClient                        *ethclient.Client
+	ExecutionClient                        syncclient.Client
 	ChainID                                uint64
 	SyncStartBlockNumber                   uint64
 	EnableAggregateValidatorRegistrationV1 bool
@@ -167,32 +167,9 @@ func (v *ValidatorSyncer) filterEvents(
 		pubKeys := make([]*blst.P1Affine, 0)
 		validatorIndices := msg.ValidatorIndices()
 
-		// Split indices into chunks of 64 to respect GetValidatorByIndices limit
-		const maxIndicesPerRequest = 64
-		validatorMap := make(map[int64]*beaconapiclient.ValidatorData)
-
-		for i := 0; i < len(validatorIndices); i += maxIndicesPerRequest {
-			end := i + maxIndicesPerRequest
-			if end > len(validatorIndices) {
-				end = len(validatorIndices)
-			}
-
-			chunk := validatorIndices[i:end]
-			validators, err := v.BeaconAPIClient.GetValidatorByIndices(ctx, "head", chunk)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to get validators for chunk starting at index %d", i)
-			}
-
-			// Add validators from this chunk to our map
-			for _, validator := range validators.Data {
-				if validator.Index > math.MaxInt64 {
-					evLog.Warn().
-						Uint64("validator-index", validator.Index).
-						Msg("ignoring validator with index larger than MaxInt64")
-					continue
-				}
-				validatorMap[int64(validator.Index)] = &validator
-			}
+		validatorPubkeys, err := v.BeaconAPIClient.GetValidatorPubkeys(ctx, validatorIndices)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get validator pubkeys")
 		}
 
 		for _, validatorIndex := range validatorIndices {
@@ -218,13 +195,13 @@ func (v *ValidatorSyncer) filterEvents(
 				continue
 			}
 
-			validatorData, exists := validatorMap[validatorIndex]
+			pubkeyHex, exists := validatorPubkeys[validatorIndex]
 			if !exists {
 				evLog.Warn().Msg("ignoring registration message for unknown validator")
 				continue
 			}
 
-			pubkey, err := validatorData.Validator.GetPubkey()
+			pubkey, err := beaconapiclient.ParsePubkey(pubkeyHex)
 			if err != nil {
 				return nil, errors.Wrapf(err, "failed to get pubkey of validator %d", validatorIndex)
 			}
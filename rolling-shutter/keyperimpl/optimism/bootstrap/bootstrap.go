@@ -11,6 +11,7 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/fx"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/event"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shmsg"
 )
 
@@ -33,7 +34,7 @@ func BootstrapValidators(config *Config) error {
 		log.Fatal().Err(err).Msg("failed to connect to Shuttermint node")
 	}
 
-	ms := fx.NewRPCMessageSender(shmcl, config.SigningKey.Key)
+	ms := fx.NewRPCMessageSender(shmcl, signer.NewLocal(config.SigningKey.Key))
 	batchConfigMsg := shmsg.NewBatchConfig(
 		ks.ActivationBlock,
 		ks.Members,
@@ -24,6 +24,10 @@ func (c *Config) Init() {
 	c.Optimism = NewEthnodeConfig()
 	c.Shuttermint = kprconfig.NewShuttermintConfig()
 	c.Metrics = metricsserver.NewConfig()
+	c.Secrets = kprconfig.NewSecretsConfig()
+	c.Retention = kprconfig.NewRetentionConfig()
+	c.PeerRecords = kprconfig.NewPeerRecordsConfig()
+	c.KeyperStatus = kprconfig.NewKeyperStatusConfig()
 }
 
 type Config struct {
@@ -33,16 +37,20 @@ type Config struct {
 	HTTPEnabled       bool
 	HTTPListenAddress string
 
-	P2P         *p2p.Config
-	Optimism    *OptimismConfig
-	Shuttermint *kprconfig.ShuttermintConfig
-	Metrics     *metricsserver.MetricsConfig
+	P2P          *p2p.Config
+	Optimism     *OptimismConfig
+	Shuttermint  *kprconfig.ShuttermintConfig
+	Metrics      *metricsserver.MetricsConfig
+	Secrets      *kprconfig.SecretsConfig
+	Retention    *kprconfig.RetentionConfig
+	PeerRecords  *kprconfig.PeerRecordsConfig
+	KeyperStatus *kprconfig.KeyperStatusConfig
 
 	MaxNumKeysPerMessage uint64
 }
 
 func (c *Config) Validate() error {
-	return nil
+	return c.Optimism.Validate()
 }
 
 func (c *Config) Name() string {
@@ -71,5 +79,5 @@ func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
 }
 
 func (c *Config) GetAddress() common.Address {
-	return c.Optimism.PrivateKey.EthereumAddress()
+	return c.Optimism.Address()
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/keys"
 )
@@ -18,12 +20,14 @@ func NewEthnodeConfig() *OptimismConfig {
 }
 
 type OptimismConfig struct {
-	PrivateKey *keys.ECDSAPrivate `shconfig:",required"`
-	JSONRPCURL string             `                     comment:"The op-geth JSON RPC endpoint"`
+	PrivateKey   *keys.ECDSAPrivate `comment:"The private key to sign with, required unless a remote signer is configured"`
+	RemoteSigner *configuration.RemoteSignerConfig
+	JSONRPCURL   string `comment:"The op-geth JSON RPC endpoint"`
 }
 
 func (c *OptimismConfig) Init() {
 	c.PrivateKey = &keys.ECDSAPrivate{}
+	c.RemoteSigner = &configuration.RemoteSignerConfig{}
 }
 
 func (c *OptimismConfig) Name() string {
@@ -31,7 +35,15 @@ func (c *OptimismConfig) Name() string {
 }
 
 func (c *OptimismConfig) Validate() error {
-	return nil
+	return configuration.ValidateSigner(c.PrivateKey, c.RemoteSigner)
+}
+
+// Address returns the address of the configured key.
+func (c *OptimismConfig) Address() common.Address {
+	if c.RemoteSigner.Enabled() {
+		return c.RemoteSigner.Address
+	}
+	return c.PrivateKey.EthereumAddress()
 }
 
 func (c *OptimismConfig) SetDefaultValues() error {
@@ -53,5 +65,5 @@ func (c *OptimismConfig) SetExampleValues() error {
 }
 
 func (c OptimismConfig) TOMLWriteHeader(w io.Writer) (int, error) {
-	return fmt.Fprintf(w, "# Ethereum address: %s\n", c.PrivateKey.EthereumAddress())
+	return fmt.Fprintf(w, "# Ethereum address: %s\n", c.Address())
 }
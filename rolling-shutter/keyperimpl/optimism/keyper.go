@@ -23,6 +23,7 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/db"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/identitypreimage"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
 )
 
@@ -58,6 +59,11 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 	ethConfig := configuration.NewEthnodeConfig()
 	ethConfig.EthereumURL = kpr.config.Optimism.JSONRPCURL
 	ethConfig.PrivateKey = kpr.config.Optimism.PrivateKey
+	ethConfig.RemoteSigner = kpr.config.Optimism.RemoteSigner
+	sgn, err := signer.New(ethConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize signer")
+	}
 	kpr.core, err = keyper.New(
 		&kprconfig.Config{
 			InstanceID:           kpr.config.InstanceID,
@@ -68,10 +74,15 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 			Ethereum:             ethConfig,
 			Shuttermint:          kpr.config.Shuttermint,
 			Metrics:              kpr.config.Metrics,
+			Secrets:              kpr.config.Secrets,
+			Retention:            kpr.config.Retention,
+			PeerRecords:          kpr.config.PeerRecords,
+			KeyperStatus:         kpr.config.KeyperStatus,
 			MaxNumKeysPerMessage: kpr.config.MaxNumKeysPerMessage,
 		},
 		trigger,
 		keyper.WithDBPool(dbpool),
+		keyper.WithSigner(sgn),
 		keyper.NoBroadcastEonPublicKey(),
 		keyper.WithEonPublicKeyHandler(kpr.newEonPublicKey),
 	)
@@ -84,7 +95,7 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 		chainsync.WithClientURL(kpr.config.Optimism.JSONRPCURL),
 		chainsync.WithSyncNewBlock(kpr.newBlock),
 		chainsync.WithSyncNewKeyperSet(kpr.newKeyperSet),
-		chainsync.WithPrivateKey(kpr.config.Optimism.PrivateKey.Key),
+		chainsync.WithSigner(sgn),
 	)
 	if err != nil {
 		return err
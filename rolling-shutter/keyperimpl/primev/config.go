@@ -19,10 +19,14 @@ type Config struct {
 
 	Primev *PrimevConfig
 
-	Chain       *ChainConfig
-	P2P         *p2p.Config
-	Shuttermint *kprconfig.ShuttermintConfig
-	Metrics     *metricsserver.MetricsConfig
+	Chain        *ChainConfig
+	P2P          *p2p.Config
+	Shuttermint  *kprconfig.ShuttermintConfig
+	Metrics      *metricsserver.MetricsConfig
+	Secrets      *kprconfig.SecretsConfig
+	Retention    *kprconfig.RetentionConfig
+	PeerRecords  *kprconfig.PeerRecordsConfig
+	KeyperStatus *kprconfig.KeyperStatusConfig
 
 	MaxNumKeysPerMessage uint64
 }
@@ -39,11 +43,15 @@ func (c *Config) Init() {
 	c.Shuttermint = kprconfig.NewShuttermintConfig()
 	c.Chain = NewChainConfig()
 	c.Metrics = metricsserver.NewConfig()
+	c.Secrets = kprconfig.NewSecretsConfig()
+	c.Retention = kprconfig.NewRetentionConfig()
+	c.PeerRecords = kprconfig.NewPeerRecordsConfig()
+	c.KeyperStatus = kprconfig.NewKeyperStatusConfig()
 }
 
 func (c *Config) Validate() error {
 	// TODO: needs to be implemented
-	return nil
+	return c.Chain.Node.Validate()
 }
 
 func (c *Config) Name() string {
@@ -71,7 +79,7 @@ func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
 }
 
 func (c *Config) GetAddress() common.Address {
-	return c.Chain.Node.PrivateKey.EthereumAddress()
+	return c.Chain.Node.Address()
 }
 
 type PrimevConfig struct {
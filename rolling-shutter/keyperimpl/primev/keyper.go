@@ -20,16 +20,20 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync"
 	syncevent "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/event"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/db"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/failover"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 )
 
 var ErrParseKeyperSet = errors.New("cannot parse KeyperSet")
 
 type Keyper struct {
-	core   *keyper.KeyperCore
-	config *Config
-	dbpool *pgxpool.Pool
+	core      *keyper.KeyperCore
+	config    *Config
+	dbpool    *pgxpool.Pool
+	signer    signer.Signer
+	ethClient *failover.Client
 
 	chainSyncClient        *chainsync.Client
 	providerRegistrySyncer *ProviderRegistrySyncer
@@ -60,6 +64,14 @@ func (k *Keyper) Start(ctx context.Context, runner service.Runner) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to connect to database")
 	}
+	k.signer, err = signer.New(k.config.Chain.Node)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize signer")
+	}
+	k.ethClient, err = failover.Dial(ctx, k.config.Chain.Node.EthereumURLs())
+	if err != nil {
+		return errors.Wrap(err, "failed to dial ethereum node")
+	}
 
 	messageSender, err := p2p.New(k.config.P2P)
 	if err != nil {
@@ -79,27 +91,24 @@ func (k *Keyper) Start(ctx context.Context, runner service.Runner) error {
 
 	k.chainSyncClient, err = chainsync.NewClient(
 		ctx,
-		chainsync.WithClientURL(k.config.Chain.Node.EthereumURL),
+		chainsync.WithClient(k.ethClient),
 		chainsync.WithKeyperSetManager(k.config.Chain.Contracts.KeyperSetManager),
 		chainsync.WithKeyBroadcastContract(k.config.Chain.Contracts.KeyBroadcastContract),
 		chainsync.WithSyncNewKeyperSet(k.channelNewKeyperSet),
 		chainsync.WithSyncNewBlock(k.channelNewBlock),
-		chainsync.WithPrivateKey(k.config.Chain.Node.PrivateKey.Key),
+		chainsync.WithSigner(k.signer),
 		chainsync.WithLogger(gethLog.NewLogger(slog.Default().Handler())),
 	)
 	if err != nil {
 		return err
 	}
 
-	eonKeyPublisherClient, err := ethclient.DialContext(ctx, k.config.Chain.Node.EthereumURL)
-	if err != nil {
-		return errors.Wrapf(err, "failed to dial ethereum node at %s", k.config.Chain.Node.EthereumURL)
-	}
 	k.eonKeyPublisher, err = eonkeypublisher.NewEonKeyPublisher(
 		k.dbpool,
-		eonKeyPublisherClient,
+		k.ethClient,
 		k.config.Chain.Contracts.KeyperSetManager,
-		k.config.Chain.Node.PrivateKey.Key,
+		k.signer,
+		k.core.Keyring(),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to initialize eon key publisher")
@@ -111,7 +120,7 @@ func (k *Keyper) Start(ctx context.Context, runner service.Runner) error {
 	}
 
 	runner.Go(func() error { return k.processInputs(ctx) })
-	return runner.StartService(k.core, k.chainSyncClient, k.eonKeyPublisher)
+	return runner.StartService(k.ethClient, k.core, k.chainSyncClient, k.eonKeyPublisher)
 }
 
 func NewKeyper(kpr *Keyper, messagingMiddleware p2p.Messaging) (*keyper.KeyperCore, error) {
@@ -126,10 +135,16 @@ func NewKeyper(kpr *Keyper, messagingMiddleware p2p.Messaging) (*keyper.KeyperCo
 			Ethereum:             kpr.config.Chain.Node,
 			Shuttermint:          kpr.config.Shuttermint,
 			Metrics:              kpr.config.Metrics,
+			Secrets:              kpr.config.Secrets,
+			Retention:            kpr.config.Retention,
+			PeerRecords:          kpr.config.PeerRecords,
+			KeyperStatus:         kpr.config.KeyperStatus,
 			MaxNumKeysPerMessage: kpr.config.MaxNumKeysPerMessage,
 		},
 		kpr.decryptionTriggerChannel,
 		keyper.WithDBPool(kpr.dbpool),
+		keyper.WithSigner(kpr.signer),
+		keyper.WithBlockSyncClient(kpr.ethClient),
 		keyper.NoBroadcastEonPublicKey(),
 		keyper.WithEonPublicKeyHandler(kpr.channelNewEonPublicKey),
 		keyper.WithMessaging(messagingMiddleware),
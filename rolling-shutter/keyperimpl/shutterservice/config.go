@@ -27,6 +27,10 @@ func (c *Config) Init() {
 	c.P2P = p2p.NewConfig()
 	c.Shuttermint = kprconfig.NewShuttermintConfig()
 	c.Metrics = metricsserver.NewConfig()
+	c.Secrets = kprconfig.NewSecretsConfig()
+	c.Retention = kprconfig.NewRetentionConfig()
+	c.PeerRecords = kprconfig.NewPeerRecordsConfig()
+	c.KeyperStatus = kprconfig.NewKeyperStatusConfig()
 	c.Chain = NewChainConfig()
 }
 
@@ -38,17 +42,24 @@ type Config struct {
 	HTTPReadOnly      bool
 	HTTPListenAddress string
 
-	Chain       *ChainConfig
-	P2P         *p2p.Config
-	Shuttermint *kprconfig.ShuttermintConfig
-	Metrics     *metricsserver.MetricsConfig
+	Chain        *ChainConfig
+	P2P          *p2p.Config
+	Shuttermint  *kprconfig.ShuttermintConfig
+	Metrics      *metricsserver.MetricsConfig
+	Secrets      *kprconfig.SecretsConfig
+	Retention    *kprconfig.RetentionConfig
+	PeerRecords  *kprconfig.PeerRecordsConfig
+	KeyperStatus *kprconfig.KeyperStatusConfig
 
 	MaxNumKeysPerMessage uint64
+	// MaxDynamicValueLength is the maximum length in bytes of dynamic values read from event logs
+	// when matching them against event triggers.
+	MaxDynamicValueLength uint64
 }
 
 func (c *Config) Validate() error {
 	// TODO: needs to be implemented
-	return nil
+	return c.Chain.Node.Validate()
 }
 
 func (c *Config) Name() string {
@@ -59,6 +70,7 @@ func (c *Config) SetDefaultValues() error {
 	c.HTTPEnabled = false
 	c.HTTPListenAddress = ":3000"
 	c.MaxNumKeysPerMessage = 500
+	c.MaxDynamicValueLength = DefaultMaxDynamicValueLength
 	c.HTTPReadOnly = true
 	return nil
 }
@@ -78,7 +90,7 @@ func (c Config) TOMLWriteHeader(_ io.Writer) (int, error) {
 }
 
 func (c *Config) GetAddress() common.Address {
-	return c.Chain.Node.PrivateKey.EthereumAddress()
+	return c.Chain.Node.Address()
 }
 
 func (c *Config) EventBasedTriggersEnabled() bool {
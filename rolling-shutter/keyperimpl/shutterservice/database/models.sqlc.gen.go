@@ -17,6 +17,16 @@ type DecryptionSignature struct {
 	Signature      []byte
 }
 
+type EventTriggerPartialMatch struct {
+	Eon            int64
+	Identity       []byte
+	ConditionIndex int64
+	BlockNumber    int64
+	BlockHash      []byte
+	TxIndex        int64
+	LogIndex       int64
+}
+
 type EventTriggerRegisteredEvent struct {
 	BlockNumber           int64
 	BlockHash             []byte
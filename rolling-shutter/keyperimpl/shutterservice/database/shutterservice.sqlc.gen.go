@@ -11,6 +11,60 @@ import (
 	"github.com/jackc/pgconn"
 )
 
+const deleteDecryptionSignaturesBeforeEon = `-- name: DeleteDecryptionSignaturesBeforeEon :execresult
+DELETE FROM decryption_signatures WHERE eon < $1
+`
+
+func (q *Queries) DeleteDecryptionSignaturesBeforeEon(ctx context.Context, eon int64) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteDecryptionSignaturesBeforeEon, eon)
+}
+
+const deleteEventTriggerPartialMatchesBeforeBlockNumber = `-- name: DeleteEventTriggerPartialMatchesBeforeBlockNumber :exec
+DELETE FROM event_trigger_partial_match
+WHERE eon = $1 AND identity = $2 AND block_number < $3
+`
+
+type DeleteEventTriggerPartialMatchesBeforeBlockNumberParams struct {
+	Eon         int64
+	Identity    []byte
+	BlockNumber int64
+}
+
+func (q *Queries) DeleteEventTriggerPartialMatchesBeforeBlockNumber(ctx context.Context, arg DeleteEventTriggerPartialMatchesBeforeBlockNumberParams) error {
+	_, err := q.db.Exec(ctx, deleteEventTriggerPartialMatchesBeforeBlockNumber, arg.Eon, arg.Identity, arg.BlockNumber)
+	return err
+}
+
+const deleteEventTriggerPartialMatchesFromBlockNumber = `-- name: DeleteEventTriggerPartialMatchesFromBlockNumber :exec
+DELETE FROM event_trigger_partial_match WHERE block_number >= $1
+`
+
+func (q *Queries) DeleteEventTriggerPartialMatchesFromBlockNumber(ctx context.Context, blockNumber int64) error {
+	_, err := q.db.Exec(ctx, deleteEventTriggerPartialMatchesFromBlockNumber, blockNumber)
+	return err
+}
+
+const deleteEventTriggerPartialMatchesOfInactiveTriggers = `-- name: DeleteEventTriggerPartialMatchesOfInactiveTriggers :exec
+DELETE FROM event_trigger_partial_match m
+USING event_trigger_registered_event e
+WHERE m.eon = e.eon AND m.identity = e.identity
+AND (
+    e.expiration_block_number < $1
+    OR EXISTS (
+        SELECT 1 FROM fired_triggers f
+        WHERE f.eon = m.eon
+        AND f.identity = m.identity
+        AND f.block_number < $1
+    )
+)
+`
+
+// Deletes the partial matches of triggers that have expired or fired before the given block.
+func (q *Queries) DeleteEventTriggerPartialMatchesOfInactiveTriggers(ctx context.Context, blockNumber int64) error {
+	_, err := q.db.Exec(ctx, deleteEventTriggerPartialMatchesOfInactiveTriggers, blockNumber)
+	return err
+}
+
 const deleteEventTriggerRegisteredEventsFromBlockNumber = `-- name: DeleteEventTriggerRegisteredEventsFromBlockNumber :exec
 DELETE FROM event_trigger_registered_event WHERE block_number >= $1
 `
@@ -131,6 +185,45 @@ func (q *Queries) GetDecryptionSignatures(ctx context.Context, arg GetDecryption
 	return items, nil
 }
 
+const getEventTriggerPartialMatches = `-- name: GetEventTriggerPartialMatches :many
+SELECT eon, identity, condition_index, block_number, block_hash, tx_index, log_index FROM event_trigger_partial_match
+WHERE eon = $1 AND identity = $2
+ORDER BY block_number ASC, tx_index ASC, log_index ASC, condition_index DESC
+`
+
+type GetEventTriggerPartialMatchesParams struct {
+	Eon      int64
+	Identity []byte
+}
+
+func (q *Queries) GetEventTriggerPartialMatches(ctx context.Context, arg GetEventTriggerPartialMatchesParams) ([]EventTriggerPartialMatch, error) {
+	rows, err := q.db.Query(ctx, getEventTriggerPartialMatches, arg.Eon, arg.Identity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EventTriggerPartialMatch
+	for rows.Next() {
+		var i EventTriggerPartialMatch
+		if err := rows.Scan(
+			&i.Eon,
+			&i.Identity,
+			&i.ConditionIndex,
+			&i.BlockNumber,
+			&i.BlockHash,
+			&i.TxIndex,
+			&i.LogIndex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getIdentityRegisteredEventsSyncedUntil = `-- name: GetIdentityRegisteredEventsSyncedUntil :one
 SELECT enforce_one_row, block_hash, block_number FROM identity_registered_events_synced_until LIMIT 1
 `
@@ -284,6 +377,35 @@ func (q *Queries) InsertDecryptionSignature(ctx context.Context, arg InsertDecry
 	return err
 }
 
+const insertEventTriggerPartialMatch = `-- name: InsertEventTriggerPartialMatch :exec
+INSERT INTO event_trigger_partial_match (eon, identity, condition_index, block_number, block_hash, tx_index, log_index)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT DO NOTHING
+`
+
+type InsertEventTriggerPartialMatchParams struct {
+	Eon            int64
+	Identity       []byte
+	ConditionIndex int64
+	BlockNumber    int64
+	BlockHash      []byte
+	TxIndex        int64
+	LogIndex       int64
+}
+
+func (q *Queries) InsertEventTriggerPartialMatch(ctx context.Context, arg InsertEventTriggerPartialMatchParams) error {
+	_, err := q.db.Exec(ctx, insertEventTriggerPartialMatch,
+		arg.Eon,
+		arg.Identity,
+		arg.ConditionIndex,
+		arg.BlockNumber,
+		arg.BlockHash,
+		arg.TxIndex,
+		arg.LogIndex,
+	)
+	return err
+}
+
 const insertEventTriggerRegisteredEvent = `-- name: InsertEventTriggerRegisteredEvent :execresult
 INSERT INTO event_trigger_registered_event (
     block_number,
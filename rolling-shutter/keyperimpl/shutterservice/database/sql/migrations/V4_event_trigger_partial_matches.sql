@@ -0,0 +1,16 @@
+-- schema-version: shutterservicekeyper-4 --
+-- logs matching a condition of a multi event trigger that has not fired yet
+
+CREATE TABLE event_trigger_partial_match (
+    eon bigint NOT NULL,
+    identity bytea NOT NULL,
+    condition_index bigint NOT NULL CHECK (condition_index >= 0),
+    block_number bigint NOT NULL CHECK (block_number >= 0),
+    block_hash bytea NOT NULL,
+    tx_index bigint NOT NULL CHECK (tx_index >= 0),
+    log_index bigint NOT NULL CHECK (log_index >= 0),
+    PRIMARY KEY (eon, identity, condition_index, block_number, tx_index, log_index),
+    FOREIGN KEY (eon, identity) REFERENCES event_trigger_registered_event (eon, identity) ON DELETE CASCADE
+);
+
+CREATE INDEX event_trigger_partial_match_block_number_idx ON event_trigger_partial_match (block_number);
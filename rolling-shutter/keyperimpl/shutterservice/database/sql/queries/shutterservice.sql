@@ -21,6 +21,9 @@ INSERT INTO decryption_signatures (eon, keyper_index, identities_hash, signature
 VALUES ($1, $2, $3, $4)
 ON CONFLICT DO NOTHING;
 
+-- name: DeleteDecryptionSignaturesBeforeEon :execresult
+DELETE FROM decryption_signatures WHERE eon < $1;
+
 -- name: GetDecryptionSignatures :many
 SELECT * FROM decryption_signatures
 WHERE eon = $1 AND identities_hash = $2
@@ -144,3 +147,35 @@ WHERE NOT EXISTS (  -- not decrypted yet
     AND e.identity = f.identity
     AND e.decrypted = true
 );
+
+-- name: InsertEventTriggerPartialMatch :exec
+INSERT INTO event_trigger_partial_match (eon, identity, condition_index, block_number, block_hash, tx_index, log_index)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT DO NOTHING;
+
+-- name: GetEventTriggerPartialMatches :many
+SELECT * FROM event_trigger_partial_match
+WHERE eon = $1 AND identity = $2
+ORDER BY block_number ASC, tx_index ASC, log_index ASC, condition_index DESC;
+
+-- name: DeleteEventTriggerPartialMatchesBeforeBlockNumber :exec
+DELETE FROM event_trigger_partial_match
+WHERE eon = $1 AND identity = $2 AND block_number < $3;
+
+-- name: DeleteEventTriggerPartialMatchesFromBlockNumber :exec
+DELETE FROM event_trigger_partial_match WHERE block_number >= $1;
+
+-- name: DeleteEventTriggerPartialMatchesOfInactiveTriggers :exec
+-- Deletes the partial matches of triggers that have expired or fired before the given block.
+DELETE FROM event_trigger_partial_match m
+USING event_trigger_registered_event e
+WHERE m.eon = e.eon AND m.identity = e.identity
+AND (
+    e.expiration_block_number < @block_number
+    OR EXISTS (
+        SELECT 1 FROM fired_triggers f
+        WHERE f.eon = m.eon
+        AND f.identity = m.identity
+        AND f.block_number < @block_number
+    )
+);
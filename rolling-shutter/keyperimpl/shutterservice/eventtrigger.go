@@ -2,6 +2,7 @@ package shutterservice
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -16,12 +17,37 @@ import (
 const (
 	Word    = 32
 	Version = 0x2
+	// VersionPredicateTree is the version of definitions that combine their log predicates with a
+	// tree of AND, OR and NOT nodes instead of requiring all of them to match.
+	VersionPredicateTree = 0x3
+	// VersionMultiEvent is the version of definitions that are satisfied by multiple logs, possibly
+	// from different contracts, instead of a single one.
+	VersionMultiEvent = 0x4
+	// DefaultMaxDynamicValueLength is the default maximum length in bytes of dynamic values read
+	// from log data.
+	DefaultMaxDynamicValueLength = 1 << 16
 )
 
+// ErrMalformedLogData is returned when a value can't be read from a log because its data is not
+// encoded as expected.
+var ErrMalformedLogData = errors.New("malformed log data")
+
 // EventTriggerDefinition specifies an event-based trigger.
+//
+// Definitions of version 2 consist of a list of log predicates that all have to match. Definitions
+// of version 3 have a predicate tree in Condition instead and must not have any LogPredicates.
+// Definitions of version 4 only have a MultiEvent condition, which names the contracts itself.
 type EventTriggerDefinition struct {
 	Contract      common.Address
 	LogPredicates []LogPredicate
+	Condition     *PredicateNode       `rlp:"-"`
+	MultiEvent    *MultiEventCondition `rlp:"-"`
+}
+
+// eventTriggerDefinitionV3 is the RLP encoding of version 3 event trigger definitions.
+type eventTriggerDefinitionV3 struct {
+	Contract  common.Address
+	Condition *PredicateNode
 }
 
 // LogPredicate defines a condition on the events emitted by a contract that must be satisfied for a
@@ -60,12 +86,34 @@ func (d *EventTriggerDefinition) UnmarshalBytes(data []byte) error {
 		return fmt.Errorf("data is empty")
 	}
 	version := data[0]
-	if version != Version {
-		return fmt.Errorf("unsupported version %d, expected %d", version, Version)
-	}
-
-	if err := rlp.DecodeBytes(data[1:], d); err != nil {
-		return fmt.Errorf("failed to decode EventTriggerDefinitionRLP: %w", err)
+	switch version {
+	case Version:
+		d.Condition = nil
+		d.MultiEvent = nil
+		if err := rlp.DecodeBytes(data[1:], d); err != nil {
+			return fmt.Errorf("failed to decode EventTriggerDefinitionRLP: %w", err)
+		}
+	case VersionPredicateTree:
+		var v3 eventTriggerDefinitionV3
+		if err := rlp.DecodeBytes(data[1:], &v3); err != nil {
+			return fmt.Errorf("failed to decode EventTriggerDefinitionRLP: %w", err)
+		}
+		d.Contract = v3.Contract
+		d.LogPredicates = nil
+		d.Condition = v3.Condition
+		d.MultiEvent = nil
+	case VersionMultiEvent:
+		multiEvent := new(MultiEventCondition)
+		if err := rlp.DecodeBytes(data[1:], multiEvent); err != nil {
+			return fmt.Errorf("failed to decode EventTriggerDefinitionRLP: %w", err)
+		}
+		d.Contract = common.Address{}
+		d.LogPredicates = nil
+		d.Condition = nil
+		d.MultiEvent = multiEvent
+	default:
+		return fmt.Errorf("unsupported version %d, expected one of %d, %d or %d",
+			version, Version, VersionPredicateTree, VersionMultiEvent)
 	}
 	if err := d.Validate(); err != nil {
 		return fmt.Errorf("invalid EventTriggerDefinitionRLP: %w", err)
@@ -73,10 +121,22 @@ func (d *EventTriggerDefinition) UnmarshalBytes(data []byte) error {
 	return nil
 }
 
+// MarshalBytes encodes the definition as version 4 if it has a MultiEvent condition, as version 3
+// if it has a Condition and as version 2 otherwise.
 func (d *EventTriggerDefinition) MarshalBytes() []byte {
 	var buf bytes.Buffer
-	buf.WriteByte(Version)
-	if err := rlp.Encode(&buf, d); err != nil {
+	var err error
+	if d.MultiEvent != nil {
+		buf.WriteByte(VersionMultiEvent)
+		err = rlp.Encode(&buf, d.MultiEvent)
+	} else if d.Condition != nil {
+		buf.WriteByte(VersionPredicateTree)
+		err = rlp.Encode(&buf, &eventTriggerDefinitionV3{Contract: d.Contract, Condition: d.Condition})
+	} else {
+		buf.WriteByte(Version)
+		err = rlp.Encode(&buf, d)
+	}
+	if err != nil {
 		// This should never happen as
 		// - we're encoding into a bytes.Buffer which never returns an error
 		// - EventTriggerDefinition is RLP-encodable
@@ -87,10 +147,35 @@ func (d *EventTriggerDefinition) MarshalBytes() []byte {
 
 // Validate checks if the event trigger definition is valid.
 //
-// A trigger definition is valid if
+// A version 2 trigger definition is valid if
 //   - all log predicates are valid and
 //   - there are no two log BytesEq predicates for the same topic
+//
+// A version 3 trigger definition is valid if it has no log predicates and a valid predicate tree,
+// see PredicateNode.Validate.
+//
+// A version 4 trigger definition is valid if it has neither a contract, nor log predicates, nor a
+// predicate tree, but a valid multi event condition, see MultiEventCondition.Validate.
 func (d *EventTriggerDefinition) Validate() error {
+	if d.MultiEvent != nil {
+		if d.Contract != (common.Address{}) || len(d.LogPredicates) != 0 || d.Condition != nil {
+			return fmt.Errorf("multi event definition must only have a multi event condition")
+		}
+		if err := d.MultiEvent.Validate(); err != nil {
+			return fmt.Errorf("invalid multi event condition: %w", err)
+		}
+		return nil
+	}
+	if d.Condition != nil {
+		if len(d.LogPredicates) != 0 {
+			return fmt.Errorf("definition with a predicate tree must not have log predicates, got %d", len(d.LogPredicates))
+		}
+		if err := d.Condition.Validate(); err != nil {
+			return fmt.Errorf("invalid predicate tree: %w", err)
+		}
+		return nil
+	}
+
 	for i, lp := range d.LogPredicates {
 		if err := lp.Validate(); err != nil {
 			return fmt.Errorf("invalid log predicate at index %d: %w", i, err)
@@ -115,7 +200,9 @@ func (d *EventTriggerDefinition) Validate() error {
 //
 // The returned filter includes:
 //   - Contract address filtering: Only events from the specified contract are matched
-//   - Topic filtering: BytesEq operations on topics are converted to topic filters
+//   - Topic filtering: BytesEq operations on topics are converted to topic filters. For predicate
+//     trees, BytesEq operations combined by OR nodes and BytesIn operations on 32-byte values are
+//     converted to lists of alternative topics.
 //
 // Any other operation is not included in the filter and must be checked by the caller.
 //
@@ -124,7 +211,19 @@ func (d *EventTriggerDefinition) Validate() error {
 //   - the argument for a topic BytesEq log predicate is not a 32-byte value
 //
 // These errors do not occur if Validate passes.
+//
+// Multi event definitions don't have a single filter query, use LogCondition.ToFilterQuery for
+// each of their conditions instead.
 func (d *EventTriggerDefinition) ToFilterQuery() (ethereum.FilterQuery, error) {
+	if d.MultiEvent != nil {
+		return ethereum.FilterQuery{}, fmt.Errorf("multi event definitions have one filter query per log condition")
+	}
+	if d.Condition != nil {
+		return ethereum.FilterQuery{
+			Addresses: []common.Address{d.Contract},
+			Topics:    d.Condition.topicConstraints().topics(),
+		}, nil
+	}
 	topics := [][]common.Hash{}
 	for _, logPredicate := range d.LogPredicates {
 		if !logPredicate.LogValueRef.IsTopic() {
@@ -158,16 +257,35 @@ func (d *EventTriggerDefinition) ToFilterQuery() (ethereum.FilterQuery, error) {
 	}, nil
 }
 
-// Match checks if the log matches the event trigger definition by checking all log predicates.
+// Match checks if the log matches the event trigger definition by checking all log predicates or
+// evaluating the predicate tree. Dynamic values longer than maxDynamicLength bytes are not read.
+// Logs whose data can't be decoded don't match.
 //
 // This may panic if Validate does not pass.
 // We need to match ABI encoding: https://docs.soliditylang.org/en/latest/abi-spec.html
-func (d *EventTriggerDefinition) Match(log *types.Log) (bool, error) {
+//
+// Multi event definitions can't be matched by a single log, use MultiEventCondition.Satisfied
+// instead.
+func (d *EventTriggerDefinition) Match(log *types.Log, maxDynamicLength uint64) (bool, error) {
+	if d.MultiEvent != nil {
+		return false, fmt.Errorf("multi event definitions can't be matched against a single log")
+	}
 	if log.Address != d.Contract {
 		return false, nil
 	}
+	match, err := d.matchPredicates(log, maxDynamicLength)
+	if errors.Is(err, ErrMalformedLogData) {
+		return false, nil
+	}
+	return match, err
+}
+
+func (d *EventTriggerDefinition) matchPredicates(log *types.Log, maxDynamicLength uint64) (bool, error) {
+	if d.Condition != nil {
+		return d.Condition.Match(log, maxDynamicLength)
+	}
 	for _, logPredicate := range d.LogPredicates {
-		match, err := logPredicate.Match(log)
+		match, err := logPredicate.Match(log, maxDynamicLength)
 		if err != nil {
 			return false, err
 		}
@@ -188,8 +306,11 @@ func (p *LogPredicate) Validate() error {
 	return nil
 }
 
-func (p *LogPredicate) Match(log *types.Log) (bool, error) {
-	value := p.LogValueRef.GetValue(log)
+func (p *LogPredicate) Match(log *types.Log, maxDynamicLength uint64) (bool, error) {
+	value, err := p.LogValueRef.GetValue(log, maxDynamicLength)
+	if err != nil {
+		return false, err
+	}
 	return p.ValuePredicate.Match(value)
 }
 
@@ -213,39 +334,29 @@ func (r *LogValueRef) IsTopic() bool {
 //
 // In case the referenced slice exceeds the log's data length, the
 // result will be zero-padded on the right to the expected length.
-func (r *LogValueRef) GetValue(log *types.Log) []byte {
+// For dynamic values, an error wrapping ErrMalformedLogData is returned
+// if the offset or length can't be read from the log's data, or if the
+// length exceeds maxDynamicLength.
+func (r *LogValueRef) GetValue(log *types.Log, maxDynamicLength uint64) ([]byte, error) {
 	if r.IsTopic() {
 		if uint64(len(log.Topics)) <= r.Offset {
-			return nil
+			return nil, nil
 		}
-		return log.Topics[r.Offset].Bytes()
+		return log.Topics[r.Offset].Bytes(), nil
 	}
 
 	if r.Dynamic {
-		return r.getOffsetDataValue(log)
+		return r.getOffsetDataValue(log, maxDynamicLength)
 	}
-	dataOffset := r.Offset - 4
-	value := make([]byte, Word)
-
-	startByte := dataOffset * Word
-	endByte := (dataOffset + 1) * Word
-
-	if startByte < uint64(len(log.Data)) {
-		availableEnd := uint64(len(log.Data))
-		if endByte < availableEnd {
-			availableEnd = endByte
-		}
-		copy(value, log.Data[startByte:availableEnd])
-	}
-
-	return value
+	return readPadded(log.Data, (r.Offset-4)*Word, Word), nil
 }
 
 // getOffsetDataValue retrieves a "complex" data value from the log based on the LogValueRef.
 //
 // In case a slice of log data is referenced and the slice exceeds the log's data length, the
-// result will be zero-padded on the right to the expected length.
-func (r *LogValueRef) getOffsetDataValue(log *types.Log) []byte {
+// result will be zero-padded on the right to the expected length. The words containing the
+// offset and the length must be fully contained in the log's data though.
+func (r *LogValueRef) getOffsetDataValue(log *types.Log, maxDynamicLength uint64) ([]byte, error) {
 	// abi encoded log data:
 	// W1: first argument value (simple) or offset_0 (complex)
 	// W2: second argument value (simple) or offset_1 (complex)
@@ -260,25 +371,40 @@ func (r *LogValueRef) getOffsetDataValue(log *types.Log) []byte {
 	//		- reading the `value_length` from `data[internal_offset:internal_offset+WORD]`
 	//		- reading the `value` from `data[internal_offset+WORD:internal_offset+WORD+value_length]`
 	//
-	dataOffset := r.Offset - 4
-
-	offsetStartByte := dataOffset * Word
+	lengthByteOffset, err := readWord(log.Data, (r.Offset-4)*Word)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offset of dynamic value: %w", err)
+	}
+	length, err := readWord(log.Data, lengthByteOffset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read length of dynamic value: %w", err)
+	}
+	if length > maxDynamicLength {
+		return nil, fmt.Errorf("%w: dynamic value length %d exceeds maximum of %d",
+			ErrMalformedLogData, length, maxDynamicLength)
+	}
+	return readPadded(log.Data, lengthByteOffset+Word, length), nil
+}
 
-	x := log.Data[offsetStartByte : offsetStartByte+Word]
+// readWord reads the word at the given byte offset from the data as an unsigned integer. It fails
+// if the word is not fully contained in the data or if its value does not fit into a uint64.
+func readWord(data []byte, offset uint64) (uint64, error) {
+	if offset > uint64(len(data)) || uint64(len(data))-offset < Word {
+		return 0, fmt.Errorf("%w: word at byte %d exceeds data length %d", ErrMalformedLogData, offset, len(data))
+	}
+	n := new(big.Int).SetBytes(data[offset : offset+Word])
+	if !n.IsUint64() {
+		return 0, fmt.Errorf("%w: word at byte %d exceeds 64 bits", ErrMalformedLogData, offset)
+	}
+	return n.Uint64(), nil
+}
 
-	lengthByteOffset := new(big.Int).SetBytes(x).Uint64()
-	y := log.Data[lengthByteOffset : lengthByteOffset+Word]
-	length := new(big.Int).SetBytes(y).Uint64()
+// readPadded reads length bytes at the given byte offset from the data. Bytes exceeding the data
+// are zero.
+func readPadded(data []byte, offset, length uint64) []byte {
 	value := make([]byte, length)
-	startByte := lengthByteOffset + Word
-	endByte := startByte + length
-
-	if startByte < uint64(len(log.Data)) {
-		availableEnd := uint64(len(log.Data))
-		if endByte < availableEnd {
-			availableEnd = endByte
-		}
-		copy(value, log.Data[startByte:availableEnd])
+	if offset < uint64(len(data)) {
+		copy(value, data[offset:])
 	}
 	return value
 }
@@ -290,11 +416,36 @@ const (
 	UintGt
 	UintGte
 	BytesEq
+	// IntLt and IntGt interpret the value as a two's complement signed integer of its length, i.e.,
+	// as an int256 for topics and static data values.
+	IntLt
+	IntGt
+	// UintInRange checks if the value is within the range [IntArgs[0], IntArgs[1]].
+	UintInRange
+	BytesPrefix
+	BytesContains
+	// BitMaskAll checks if all bits set in the mask IntArgs[0] are set in the value as well.
+	BitMaskAll
+	// BitMaskAny checks if at least one of the bits set in the mask IntArgs[0] is set in the value.
+	BitMaskAny
+	// BytesIn checks if the value is one of a set of values of equal length. IntArgs[0] is the
+	// length of the elements and ByteArgs[0] is their concatenation.
+	BytesIn
+)
+
+// MaxBytesInElements is the maximum number of elements in the set of a BytesIn predicate.
+const MaxBytesInElements = 256
+
+var (
+	twoTo256  = new(big.Int).Lsh(big.NewInt(1), 256)
+	maxInt256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1))
+	minInt256 = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 255))
 )
 
 func (op Op) Validate() error {
 	switch op {
-	case UintLt, UintLte, UintEq, UintGt, UintGte, BytesEq:
+	case UintLt, UintLte, UintEq, UintGt, UintGte, BytesEq,
+		IntLt, IntGt, UintInRange, BytesPrefix, BytesContains, BitMaskAll, BitMaskAny, BytesIn:
 		return nil
 	default:
 		return fmt.Errorf("invalid operation: %d", op)
@@ -303,9 +454,11 @@ func (op Op) Validate() error {
 
 func (op Op) NumIntArgs() int {
 	switch op {
-	case UintLt, UintLte, UintEq, UintGt, UintGte:
+	case UintLt, UintLte, UintEq, UintGt, UintGte, IntLt, IntGt, BitMaskAll, BitMaskAny, BytesIn:
 		return 1
-	case BytesEq:
+	case UintInRange:
+		return 2
+	case BytesEq, BytesPrefix, BytesContains:
 		return 0
 	default:
 		return 0
@@ -314,19 +467,28 @@ func (op Op) NumIntArgs() int {
 
 func (op Op) NumByteArgs() int {
 	switch op {
-	case UintLt, UintLte, UintEq, UintGt, UintGte:
+	case UintLt, UintLte, UintEq, UintGt, UintGte, IntLt, IntGt, UintInRange, BitMaskAll, BitMaskAny:
 		return 0
-	case BytesEq:
+	case BytesEq, BytesPrefix, BytesContains, BytesIn:
 		return 1
 	default:
 		return 0
 	}
 }
 
+// hasSignedArgs checks if the integer arguments of the operation are signed. As RLP can only
+// encode non-negative integers, they are encoded as 256 bit two's complement.
+func (op Op) hasSignedArgs() bool {
+	return op == IntLt || op == IntGt
+}
+
 func (p *ValuePredicate) EncodeRLP(w io.Writer) error {
 	var elements []interface{}
 	elements = append(elements, uint64(p.Op))
 	for _, intArg := range p.IntArgs {
+		if p.Op.hasSignedArgs() {
+			intArg = toTwosComplement(intArg)
+		}
 		elements = append(elements, intArg)
 	}
 	for _, byteArg := range p.ByteArgs {
@@ -355,6 +517,12 @@ func (p *ValuePredicate) DecodeRLP(s *rlp.Stream) error {
 		if err != nil {
 			return fmt.Errorf("failed to read integer argument %d: %w", i, err)
 		}
+		if op.hasSignedArgs() {
+			if intArg.BitLen() > 256 {
+				return fmt.Errorf("signed integer argument %d exceeds 256 bits", i)
+			}
+			intArg = fromTwosComplement(intArg)
+		}
 		intArgs = append(intArgs, intArg)
 	}
 
@@ -411,10 +579,48 @@ func (p *ValuePredicate) validateArgValues() error {
 		if arg == nil {
 			return fmt.Errorf("integer argument %d cannot be nil for operation %d", i, p.Op)
 		}
-		if arg.Sign() < 0 {
+		if arg.Sign() < 0 && !p.Op.hasSignedArgs() {
 			return fmt.Errorf("integer argument %d cannot be negative for operation %d", i, p.Op)
 		}
 	}
+
+	switch p.Op {
+	case IntLt, IntGt:
+		if p.IntArgs[0].Cmp(minInt256) < 0 || p.IntArgs[0].Cmp(maxInt256) > 0 {
+			return fmt.Errorf("integer argument 0 must be within the int256 range for operation %d", p.Op)
+		}
+	case UintInRange:
+		if p.IntArgs[0].Cmp(p.IntArgs[1]) > 0 {
+			return fmt.Errorf("lower bound %s must not be greater than upper bound %s", p.IntArgs[0], p.IntArgs[1])
+		}
+	case BitMaskAll, BitMaskAny:
+		if p.IntArgs[0].Sign() == 0 {
+			return fmt.Errorf("bit mask cannot be zero for operation %d", p.Op)
+		}
+	case BytesPrefix, BytesContains:
+		if len(p.ByteArgs[0]) == 0 {
+			return fmt.Errorf("bytes argument cannot be empty for operation %d", p.Op)
+		}
+	case BytesIn:
+		return p.validateSet()
+	case UintLt, UintLte, UintEq, UintGt, UintGte, BytesEq:
+	}
+	return nil
+}
+
+// validateSet checks that the set of a BytesIn predicate consists of at least one and at most
+// MaxBytesInElements elements of the given length.
+func (p *ValuePredicate) validateSet() error {
+	elementLength, set := p.IntArgs[0], p.ByteArgs[0]
+	if elementLength.Sign() == 0 || elementLength.Cmp(big.NewInt(int64(len(set)))) > 0 {
+		return fmt.Errorf("element length must be between 1 and the size of the set, got %s", elementLength)
+	}
+	if uint64(len(set))%elementLength.Uint64() != 0 {
+		return fmt.Errorf("set size %d is not a multiple of the element length %s", len(set), elementLength)
+	}
+	if uint64(len(set))/elementLength.Uint64() > MaxBytesInElements {
+		return fmt.Errorf("set must not have more than %d elements", MaxBytesInElements)
+	}
 	return nil
 }
 
@@ -433,6 +639,62 @@ func (p *ValuePredicate) Match(value []byte) (bool, error) {
 		return n.Cmp(p.IntArgs[0]) >= 0, nil
 	case BytesEq:
 		return bytes.Equal(value, p.ByteArgs[0]), nil
+	case IntLt:
+		return toSigned(value).Cmp(p.IntArgs[0]) < 0, nil
+	case IntGt:
+		return toSigned(value).Cmp(p.IntArgs[0]) > 0, nil
+	case UintInRange:
+		return n.Cmp(p.IntArgs[0]) >= 0 && n.Cmp(p.IntArgs[1]) <= 0, nil
+	case BytesPrefix:
+		return bytes.HasPrefix(value, p.ByteArgs[0]), nil
+	case BytesContains:
+		return bytes.Contains(value, p.ByteArgs[0]), nil
+	case BitMaskAll:
+		return new(big.Int).And(n, p.IntArgs[0]).Cmp(p.IntArgs[0]) == 0, nil
+	case BitMaskAny:
+		return new(big.Int).And(n, p.IntArgs[0]).Sign() != 0, nil
+	case BytesIn:
+		return p.setContains(value), nil
 	}
 	return false, fmt.Errorf("unknown operation %d", p.Op)
 }
+
+// setContains checks if the value is an element of the set of a BytesIn predicate.
+func (p *ValuePredicate) setContains(value []byte) bool {
+	set := p.ByteArgs[0]
+	elementLength := int(p.IntArgs[0].Uint64()) //nolint:gosec // G115, bounded by len(set) in Validate
+	if len(value) != elementLength {
+		return false
+	}
+	for i := 0; i+elementLength <= len(set); i += elementLength {
+		if bytes.Equal(value, set[i:i+elementLength]) {
+			return true
+		}
+	}
+	return false
+}
+
+// toSigned interprets the value as a big-endian two's complement signed integer.
+func toSigned(value []byte) *big.Int {
+	n := new(big.Int).SetBytes(value)
+	if len(value) > 0 && value[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(value))*8))
+	}
+	return n
+}
+
+// toTwosComplement converts an int256 to its 256 bit two's complement representation.
+func toTwosComplement(n *big.Int) *big.Int {
+	if n.Sign() >= 0 {
+		return n
+	}
+	return new(big.Int).Add(n, twoTo256)
+}
+
+// fromTwosComplement converts a 256 bit two's complement representation back to an int256.
+func fromTwosComplement(n *big.Int) *big.Int {
+	if n.Cmp(maxInt256) <= 0 {
+		return n
+	}
+	return new(big.Int).Sub(n, twoTo256)
+}
@@ -0,0 +1,269 @@
+package shutterservice
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/assert"
+)
+
+// fuzzEventABI describes the event whose logs the fuzz tests match against. The log has the
+// topics [id, signed, account] and the data words amount (offset 4), flags (offset 5), the offset
+// of data (offset 6) and owner (offset 7).
+const fuzzEventABI = `[{"type": "event", "name": "Fuzzed", "inputs": [
+	{"name": "signed", "type": "int256", "indexed": true},
+	{"name": "account", "type": "address", "indexed": true},
+	{"name": "amount", "type": "int256", "indexed": false},
+	{"name": "flags", "type": "uint256", "indexed": false},
+	{"name": "data", "type": "bytes", "indexed": false},
+	{"name": "owner", "type": "address", "indexed": false}
+]}]`
+
+var fuzzEventID, fuzzEventNonIndexed = func() (common.Hash, abi.Arguments) {
+	contractABI, err := abi.JSON(strings.NewReader(fuzzEventABI))
+	if err != nil {
+		panic(err)
+	}
+	event := contractABI.Events["Fuzzed"]
+	return event.ID, event.Inputs.NonIndexed()
+}()
+
+type fuzzEvent struct {
+	signed  *big.Int
+	account common.Address
+	amount  *big.Int
+	flags   *big.Int
+	data    []byte
+	owner   common.Address
+}
+
+func (e fuzzEvent) log(t *testing.T) *types.Log {
+	t.Helper()
+	topics, err := abi.MakeTopics([]interface{}{e.signed}, []interface{}{e.account})
+	assert.NilError(t, err)
+	data, err := fuzzEventNonIndexed.Pack(e.amount, e.flags, e.data, e.owner)
+	assert.NilError(t, err)
+	return &types.Log{
+		Address: treeContract,
+		Topics:  []common.Hash{fuzzEventID, topics[0][0], topics[1][0]},
+		Data:    data,
+	}
+}
+
+// checkMatch checks that the predicate on the referenced value matches the log as expected, both
+// before and after a round trip through the definition encoding.
+func checkMatch(t *testing.T, log *types.Log, ref LogValueRef, predicate ValuePredicate, want bool) {
+	t.Helper()
+	definition := EventTriggerDefinition{
+		Contract:      treeContract,
+		LogPredicates: []LogPredicate{{LogValueRef: ref, ValuePredicate: predicate}},
+	}
+	assert.NilError(t, definition.Validate())
+	match, err := definition.Match(log, DefaultMaxDynamicValueLength)
+	assert.NilError(t, err)
+	assert.Equal(t, match, want, "op %d on offset %d", predicate.Op, ref.Offset)
+
+	var decoded EventTriggerDefinition
+	assert.NilError(t, decoded.UnmarshalBytes(definition.MarshalBytes()))
+	match, err = decoded.Match(log, DefaultMaxDynamicValueLength)
+	assert.NilError(t, err)
+	assert.Equal(t, match, want, "op %d on offset %d after round trip", predicate.Op, ref.Offset)
+}
+
+// fuzzInt256 creates a value covering the whole int256 range from an int64 by shifting it.
+func fuzzInt256(v int64, shift uint8) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(v), uint(shift%192))
+}
+
+func FuzzSignedPredicates(f *testing.F) {
+	f.Add(int64(-5), int64(3), uint8(0))
+	f.Add(int64(-1), int64(-1), uint8(0))
+	f.Add(int64(100), int64(-100), uint8(191))
+	f.Add(int64(-9223372036854775808), int64(9223372036854775807), uint8(191))
+
+	f.Fuzz(func(t *testing.T, value, arg int64, shift uint8) {
+		v := fuzzInt256(value, shift)
+		a := fuzzInt256(arg, shift)
+		log := fuzzEvent{signed: v, amount: v, flags: new(big.Int), data: []byte{}}.log(t)
+
+		for _, ref := range []LogValueRef{{Offset: 1}, {Offset: 4}} {
+			checkMatch(t, log, ref, ValuePredicate{Op: IntLt, IntArgs: []*big.Int{a}, ByteArgs: [][]byte{}}, v.Cmp(a) < 0)
+			checkMatch(t, log, ref, ValuePredicate{Op: IntGt, IntArgs: []*big.Int{a}, ByteArgs: [][]byte{}}, v.Cmp(a) > 0)
+		}
+	})
+}
+
+func FuzzUintPredicates(f *testing.F) {
+	f.Add(uint64(15), uint64(10), uint64(20), uint64(0b1010))
+	f.Add(uint64(10), uint64(10), uint64(10), uint64(1))
+	f.Add(uint64(0), uint64(1), uint64(2), uint64(0xffffffffffffffff))
+
+	f.Fuzz(func(t *testing.T, value, lower, upper, mask uint64) {
+		v := new(big.Int).SetUint64(value)
+		log := fuzzEvent{signed: new(big.Int), amount: new(big.Int), flags: v, data: []byte{}}.log(t)
+		ref := LogValueRef{Offset: 5}
+
+		if lower <= upper {
+			inRange := ValuePredicate{
+				Op:       UintInRange,
+				IntArgs:  []*big.Int{new(big.Int).SetUint64(lower), new(big.Int).SetUint64(upper)},
+				ByteArgs: [][]byte{},
+			}
+			checkMatch(t, log, ref, inRange, lower <= value && value <= upper)
+		}
+		if mask != 0 {
+			m := new(big.Int).SetUint64(mask)
+			checkMatch(t, log, ref, ValuePredicate{Op: BitMaskAll, IntArgs: []*big.Int{m}, ByteArgs: [][]byte{}}, value&mask == mask)
+			checkMatch(t, log, ref, ValuePredicate{Op: BitMaskAny, IntArgs: []*big.Int{m}, ByteArgs: [][]byte{}}, value&mask != 0)
+		}
+	})
+}
+
+func FuzzBytesPredicates(f *testing.F) {
+	f.Add([]byte("ipfs://bafybeigdyrzt"), []byte("ipfs://"))
+	f.Add([]byte("see ipfs://bafybeigdyrzt"), []byte("ipfs://"))
+	f.Add(bytes.Repeat([]byte{0xab}, 100), []byte{0xab, 0xab})
+	f.Add([]byte{}, []byte{0x00})
+
+	f.Fuzz(func(t *testing.T, data, arg []byte) {
+		if len(arg) == 0 {
+			return
+		}
+		log := fuzzEvent{signed: new(big.Int), amount: new(big.Int), flags: new(big.Int), data: data}.log(t)
+		ref := LogValueRef{Offset: 6, Dynamic: true}
+
+		checkMatch(t, log, ref, ValuePredicate{Op: BytesPrefix, IntArgs: []*big.Int{}, ByteArgs: [][]byte{arg}},
+			bytes.HasPrefix(data, arg))
+		checkMatch(t, log, ref, ValuePredicate{Op: BytesContains, IntArgs: []*big.Int{}, ByteArgs: [][]byte{arg}},
+			bytes.Contains(data, arg))
+	})
+}
+
+func FuzzBytesInPredicate(f *testing.F) {
+	f.Add([]byte{0x01}, []byte{0x02}, []byte{0x01})
+	f.Add([]byte{0x01}, []byte{0x02}, []byte{0x03})
+	f.Add(bytes.Repeat([]byte{0xff}, 20), []byte{}, []byte{})
+
+	f.Fuzz(func(t *testing.T, first, second, value []byte) {
+		set := []common.Address{common.BytesToAddress(first), common.BytesToAddress(second)}
+		account := common.BytesToAddress(value)
+		log := fuzzEvent{
+			signed:  new(big.Int),
+			account: account,
+			amount:  new(big.Int),
+			flags:   new(big.Int),
+			data:    []byte{},
+			owner:   account,
+		}.log(t)
+		want := account == set[0] || account == set[1]
+
+		// addresses are left-padded to a word in topics and data
+		words := []byte{}
+		for _, address := range set {
+			words = append(words, common.BytesToHash(address.Bytes()).Bytes()...)
+		}
+		for _, ref := range []LogValueRef{{Offset: 2}, {Offset: 7}} {
+			predicate := ValuePredicate{Op: BytesIn, IntArgs: []*big.Int{big.NewInt(Word)}, ByteArgs: [][]byte{words}}
+			checkMatch(t, log, ref, predicate, want)
+		}
+	})
+}
+
+// fuzzMaxDynamicLength is the maximum dynamic value length used by the fuzz tests. It is small so
+// that lengths around it are easily found.
+const fuzzMaxDynamicLength = 256
+
+func FuzzLogValueRefGetValue(f *testing.F) {
+	f.Add([]byte{}, uint8(0), true)
+	f.Add(make([]byte, 31), uint8(0), true)
+	f.Add(append(common.LeftPadBytes([]byte{32}, Word), common.LeftPadBytes([]byte{2}, Word)...), uint8(0), true)
+	f.Add(append(common.LeftPadBytes([]byte{32}, Word), bytes.Repeat([]byte{0xff}, Word)...), uint8(0), true)
+	f.Add(bytes.Repeat([]byte{0xff}, 3*Word), uint8(1), false)
+
+	f.Fuzz(func(t *testing.T, data []byte, offset uint8, dynamic bool) {
+		ref := LogValueRef{Offset: 4 + uint64(offset%8), Dynamic: dynamic}
+		value, err := ref.GetValue(&types.Log{Data: data}, fuzzMaxDynamicLength)
+		if !dynamic {
+			assert.NilError(t, err)
+			assert.Equal(t, len(value), Word)
+			return
+		}
+		if err != nil {
+			assert.Assert(t, errors.Is(err, ErrMalformedLogData), "got %v", err)
+			return
+		}
+		assert.Assert(t, len(value) <= fuzzMaxDynamicLength)
+
+		// the value is a zero-padded slice of the data
+		start := new(big.Int).SetBytes(data[(ref.Offset-4)*Word : (ref.Offset-3)*Word]).Uint64()
+		start += Word
+		if start < uint64(len(data)) {
+			assert.Assert(t, bytes.HasPrefix(data[start:], bytes.TrimRight(value, "\x00")))
+		}
+	})
+}
+
+// FuzzEventTriggerDefinition decodes arbitrary definitions and matches them against arbitrary
+// logs. Decodable definitions must be valid, encode canonically, and match without errors.
+func FuzzEventTriggerDefinition(f *testing.F) {
+	staticEq := EventTriggerDefinition{
+		Contract: treeContract,
+		LogPredicates: []LogPredicate{{
+			LogValueRef:    LogValueRef{Offset: 4},
+			ValuePredicate: ValuePredicate{Op: UintEq, IntArgs: []*big.Int{big.NewInt(1)}, ByteArgs: [][]byte{}},
+		}},
+	}
+	dynamicPrefix := EventTriggerDefinition{
+		Contract: treeContract,
+		Condition: Not(Leaf(LogPredicate{
+			LogValueRef:    LogValueRef{Offset: 4, Dynamic: true},
+			ValuePredicate: ValuePredicate{Op: BytesPrefix, IntArgs: []*big.Int{}, ByteArgs: [][]byte{{0xaa}}},
+		})),
+	}
+	tree := EventTriggerDefinition{Contract: treeContract, Condition: And(topicEq(0, transferTopic), Not(amountEq(0)))}
+	multi := EventTriggerDefinition{MultiEvent: multiEvent(CombineAll, 2, 10, transferCondition(treeContract))}
+	dynamicData := append(common.LeftPadBytes([]byte{32}, Word), common.LeftPadBytes([]byte{1}, Word)...)
+	for _, definition := range []EventTriggerDefinition{staticEq, dynamicPrefix, tree, multi} {
+		f.Add(definition.MarshalBytes(), []byte{}, []byte{})
+		f.Add(definition.MarshalBytes(), transferTopic.Bytes(), append(dynamicData, 0xaa))
+		f.Add(definition.MarshalBytes(), []byte{}, make([]byte, Word-1))
+	}
+	f.Add([]byte{Version, 0xc0}, []byte{}, []byte{})
+	f.Add([]byte{VersionPredicateTree}, []byte{}, []byte{})
+
+	f.Fuzz(func(t *testing.T, encoded, topics, data []byte) {
+		var definition EventTriggerDefinition
+		if err := definition.UnmarshalBytes(encoded); err != nil {
+			return
+		}
+		assert.NilError(t, definition.Validate())
+
+		marshaled := definition.MarshalBytes()
+		var decoded EventTriggerDefinition
+		assert.NilError(t, decoded.UnmarshalBytes(marshaled))
+		assert.Assert(t, bytes.Equal(decoded.MarshalBytes(), marshaled))
+
+		log := &types.Log{Data: data}
+		for i := 0; i < 4 && len(topics) > 0; i++ {
+			log.Topics = append(log.Topics, common.BytesToHash(topics[:min(Word, len(topics))]))
+			topics = topics[min(Word, len(topics)):]
+		}
+		if definition.MultiEvent != nil {
+			for _, condition := range definition.MultiEvent.Conditions {
+				log.Address = condition.Contract
+				_, err := condition.Match(log, fuzzMaxDynamicLength)
+				assert.NilError(t, err)
+			}
+			return
+		}
+		log.Address = definition.Contract
+		_, err := definition.Match(log, fuzzMaxDynamicLength)
+		assert.NilError(t, err)
+	})
+}
@@ -2,12 +2,14 @@ package shutterservice
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -54,8 +56,48 @@ func TestOpValidate(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:    "invalid operation value 6",
-			op:      Op(6),
+			name:    "IntLt is valid",
+			op:      IntLt,
+			wantErr: false,
+		},
+		{
+			name:    "IntGt is valid",
+			op:      IntGt,
+			wantErr: false,
+		},
+		{
+			name:    "UintInRange is valid",
+			op:      UintInRange,
+			wantErr: false,
+		},
+		{
+			name:    "BytesPrefix is valid",
+			op:      BytesPrefix,
+			wantErr: false,
+		},
+		{
+			name:    "BytesContains is valid",
+			op:      BytesContains,
+			wantErr: false,
+		},
+		{
+			name:    "BitMaskAll is valid",
+			op:      BitMaskAll,
+			wantErr: false,
+		},
+		{
+			name:    "BitMaskAny is valid",
+			op:      BitMaskAny,
+			wantErr: false,
+		},
+		{
+			name:    "BytesIn is valid",
+			op:      BytesIn,
+			wantErr: false,
+		},
+		{
+			name:    "invalid operation value 14",
+			op:      Op(14),
 			wantErr: true,
 		},
 		{
@@ -272,6 +314,190 @@ func TestValuePredicateValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid IntLt predicate with negative argument",
+			predicate: ValuePredicate{
+				Op:       IntLt,
+				IntArgs:  []*big.Int{big.NewInt(-100)},
+				ByteArgs: [][]byte{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid IntGt predicate with minimum int256",
+			predicate: ValuePredicate{
+				Op:       IntGt,
+				IntArgs:  []*big.Int{minInt256},
+				ByteArgs: [][]byte{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "IntLt with argument below int256 range",
+			predicate: ValuePredicate{
+				Op:       IntLt,
+				IntArgs:  []*big.Int{new(big.Int).Sub(minInt256, big.NewInt(1))},
+				ByteArgs: [][]byte{},
+			},
+			wantErr: true,
+			errMsg:  "must be within the int256 range",
+		},
+		{
+			name: "IntGt with argument above int256 range",
+			predicate: ValuePredicate{
+				Op:       IntGt,
+				IntArgs:  []*big.Int{new(big.Int).Add(maxInt256, big.NewInt(1))},
+				ByteArgs: [][]byte{},
+			},
+			wantErr: true,
+			errMsg:  "must be within the int256 range",
+		},
+		{
+			name: "valid UintInRange predicate",
+			predicate: ValuePredicate{
+				Op:       UintInRange,
+				IntArgs:  []*big.Int{big.NewInt(10), big.NewInt(10)},
+				ByteArgs: [][]byte{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "UintInRange with one integer argument",
+			predicate: ValuePredicate{
+				Op:       UintInRange,
+				IntArgs:  []*big.Int{big.NewInt(10)},
+				ByteArgs: [][]byte{},
+			},
+			wantErr: true,
+			errMsg:  "requires exactly 2 integer argument(s), got 1",
+		},
+		{
+			name: "UintInRange with empty range",
+			predicate: ValuePredicate{
+				Op:       UintInRange,
+				IntArgs:  []*big.Int{big.NewInt(11), big.NewInt(10)},
+				ByteArgs: [][]byte{},
+			},
+			wantErr: true,
+			errMsg:  "lower bound 11 must not be greater than upper bound 10",
+		},
+		{
+			name: "UintInRange with negative lower bound",
+			predicate: ValuePredicate{
+				Op:       UintInRange,
+				IntArgs:  []*big.Int{big.NewInt(-1), big.NewInt(10)},
+				ByteArgs: [][]byte{},
+			},
+			wantErr: true,
+			errMsg:  "integer argument 0 cannot be negative",
+		},
+		{
+			name: "valid BytesPrefix predicate",
+			predicate: ValuePredicate{
+				Op:       BytesPrefix,
+				IntArgs:  []*big.Int{},
+				ByteArgs: [][]byte{[]byte("ipfs://")},
+			},
+			wantErr: false,
+		},
+		{
+			name: "BytesPrefix with empty prefix",
+			predicate: ValuePredicate{
+				Op:       BytesPrefix,
+				IntArgs:  []*big.Int{},
+				ByteArgs: [][]byte{{}},
+			},
+			wantErr: true,
+			errMsg:  "bytes argument cannot be empty",
+		},
+		{
+			name: "BytesContains with empty argument",
+			predicate: ValuePredicate{
+				Op:       BytesContains,
+				IntArgs:  []*big.Int{},
+				ByteArgs: [][]byte{{}},
+			},
+			wantErr: true,
+			errMsg:  "bytes argument cannot be empty",
+		},
+		{
+			name: "valid BitMaskAll predicate",
+			predicate: ValuePredicate{
+				Op:       BitMaskAll,
+				IntArgs:  []*big.Int{big.NewInt(0b101)},
+				ByteArgs: [][]byte{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "BitMaskAny with zero mask",
+			predicate: ValuePredicate{
+				Op:       BitMaskAny,
+				IntArgs:  []*big.Int{big.NewInt(0)},
+				ByteArgs: [][]byte{},
+			},
+			wantErr: true,
+			errMsg:  "bit mask cannot be zero",
+		},
+		{
+			name: "BitMaskAll with byte argument",
+			predicate: ValuePredicate{
+				Op:       BitMaskAll,
+				IntArgs:  []*big.Int{big.NewInt(1)},
+				ByteArgs: [][]byte{{0x01}},
+			},
+			wantErr: true,
+			errMsg:  "requires exactly 0 bytes argument(s), got 1",
+		},
+		{
+			name: "valid BytesIn predicate",
+			predicate: ValuePredicate{
+				Op:       BytesIn,
+				IntArgs:  []*big.Int{big.NewInt(20)},
+				ByteArgs: [][]byte{make([]byte, 60)},
+			},
+			wantErr: false,
+		},
+		{
+			name: "BytesIn with zero element length",
+			predicate: ValuePredicate{
+				Op:       BytesIn,
+				IntArgs:  []*big.Int{big.NewInt(0)},
+				ByteArgs: [][]byte{make([]byte, 60)},
+			},
+			wantErr: true,
+			errMsg:  "element length must be between 1 and the size of the set",
+		},
+		{
+			name: "BytesIn with empty set",
+			predicate: ValuePredicate{
+				Op:       BytesIn,
+				IntArgs:  []*big.Int{big.NewInt(20)},
+				ByteArgs: [][]byte{{}},
+			},
+			wantErr: true,
+			errMsg:  "element length must be between 1 and the size of the set",
+		},
+		{
+			name: "BytesIn with incomplete element",
+			predicate: ValuePredicate{
+				Op:       BytesIn,
+				IntArgs:  []*big.Int{big.NewInt(20)},
+				ByteArgs: [][]byte{make([]byte, 50)},
+			},
+			wantErr: true,
+			errMsg:  "set size 50 is not a multiple of the element length 20",
+		},
+		{
+			name: "BytesIn with too many elements",
+			predicate: ValuePredicate{
+				Op:       BytesIn,
+				IntArgs:  []*big.Int{big.NewInt(1)},
+				ByteArgs: [][]byte{make([]byte, MaxBytesInElements+1)},
+			},
+			wantErr: true,
+			errMsg:  "set must not have more than 256 elements",
+		},
 	}
 
 	for _, tt := range tests {
@@ -613,6 +839,212 @@ func TestValuePredicateMatch(t *testing.T) {
 			}(),
 			want: true,
 		},
+		// IntLt and IntGt tests
+		{
+			name: "IntLt - negative value less than positive argument",
+			predicate: ValuePredicate{
+				Op:      IntLt,
+				IntArgs: []*big.Int{big.NewInt(1)},
+			},
+			value: int256Word(-5),
+			want:  true,
+		},
+		{
+			name: "IntLt - negative value less than negative argument",
+			predicate: ValuePredicate{
+				Op:      IntLt,
+				IntArgs: []*big.Int{big.NewInt(-4)},
+			},
+			value: int256Word(-5),
+			want:  true,
+		},
+		{
+			name: "IntLt - value equal to negative argument",
+			predicate: ValuePredicate{
+				Op:      IntLt,
+				IntArgs: []*big.Int{big.NewInt(-5)},
+			},
+			value: int256Word(-5),
+			want:  false,
+		},
+		{
+			name: "IntGt - positive value greater than negative argument",
+			predicate: ValuePredicate{
+				Op:      IntGt,
+				IntArgs: []*big.Int{big.NewInt(-1)},
+			},
+			value: int256Word(0),
+			want:  true,
+		},
+		{
+			name: "IntGt - negative value is not greater than positive argument",
+			predicate: ValuePredicate{
+				Op:      IntGt,
+				IntArgs: []*big.Int{big.NewInt(100)},
+			},
+			value: int256Word(-1), // 0xff...ff, which is greater than 100 as an unsigned integer
+			want:  false,
+		},
+		{
+			name: "IntGt - sign of dynamic value depends on its length",
+			predicate: ValuePredicate{
+				Op:      IntGt,
+				IntArgs: []*big.Int{big.NewInt(0)},
+			},
+			value: []byte{0x80, 0x00},
+			want:  false,
+		},
+		// UintInRange tests
+		{
+			name: "UintInRange - value at lower bound",
+			predicate: ValuePredicate{
+				Op:      UintInRange,
+				IntArgs: []*big.Int{big.NewInt(10), big.NewInt(20)},
+			},
+			value: int256Word(10),
+			want:  true,
+		},
+		{
+			name: "UintInRange - value at upper bound",
+			predicate: ValuePredicate{
+				Op:      UintInRange,
+				IntArgs: []*big.Int{big.NewInt(10), big.NewInt(20)},
+			},
+			value: int256Word(20),
+			want:  true,
+		},
+		{
+			name: "UintInRange - value above range",
+			predicate: ValuePredicate{
+				Op:      UintInRange,
+				IntArgs: []*big.Int{big.NewInt(10), big.NewInt(20)},
+			},
+			value: int256Word(21),
+			want:  false,
+		},
+		{
+			name: "UintInRange - value below range",
+			predicate: ValuePredicate{
+				Op:      UintInRange,
+				IntArgs: []*big.Int{big.NewInt(10), big.NewInt(20)},
+			},
+			value: int256Word(9),
+			want:  false,
+		},
+		// BytesPrefix and BytesContains tests
+		{
+			name: "BytesPrefix - value starts with prefix",
+			predicate: ValuePredicate{
+				Op:       BytesPrefix,
+				ByteArgs: [][]byte{[]byte("ipfs://")},
+			},
+			value: []byte("ipfs://bafybeigdyrzt"),
+			want:  true,
+		},
+		{
+			name: "BytesPrefix - prefix elsewhere in value",
+			predicate: ValuePredicate{
+				Op:       BytesPrefix,
+				ByteArgs: [][]byte{[]byte("ipfs://")},
+			},
+			value: []byte("see ipfs://bafybeigdyrzt"),
+			want:  false,
+		},
+		{
+			name: "BytesPrefix - value shorter than prefix",
+			predicate: ValuePredicate{
+				Op:       BytesPrefix,
+				ByteArgs: [][]byte{[]byte("ipfs://")},
+			},
+			value: []byte("ipfs"),
+			want:  false,
+		},
+		{
+			name: "BytesContains - value contains argument",
+			predicate: ValuePredicate{
+				Op:       BytesContains,
+				ByteArgs: [][]byte{[]byte("ipfs://")},
+			},
+			value: []byte("see ipfs://bafybeigdyrzt"),
+			want:  true,
+		},
+		{
+			name: "BytesContains - value does not contain argument",
+			predicate: ValuePredicate{
+				Op:       BytesContains,
+				ByteArgs: [][]byte{[]byte("ipfs://")},
+			},
+			value: []byte("see https://example.com"),
+			want:  false,
+		},
+		// BitMaskAll and BitMaskAny tests
+		{
+			name: "BitMaskAll - all bits set",
+			predicate: ValuePredicate{
+				Op:      BitMaskAll,
+				IntArgs: []*big.Int{big.NewInt(0b1010)},
+			},
+			value: int256Word(0b1110),
+			want:  true,
+		},
+		{
+			name: "BitMaskAll - some bits set",
+			predicate: ValuePredicate{
+				Op:      BitMaskAll,
+				IntArgs: []*big.Int{big.NewInt(0b1010)},
+			},
+			value: int256Word(0b0110),
+			want:  false,
+		},
+		{
+			name: "BitMaskAny - some bits set",
+			predicate: ValuePredicate{
+				Op:      BitMaskAny,
+				IntArgs: []*big.Int{big.NewInt(0b1010)},
+			},
+			value: int256Word(0b0110),
+			want:  true,
+		},
+		{
+			name: "BitMaskAny - no bits set",
+			predicate: ValuePredicate{
+				Op:      BitMaskAny,
+				IntArgs: []*big.Int{big.NewInt(0b1010)},
+			},
+			value: int256Word(0b0101),
+			want:  false,
+		},
+		// BytesIn tests
+		{
+			name: "BytesIn - value in set",
+			predicate: ValuePredicate{
+				Op:       BytesIn,
+				IntArgs:  []*big.Int{big.NewInt(2)},
+				ByteArgs: [][]byte{{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}},
+			},
+			value: []byte{0x03, 0x04},
+			want:  true,
+		},
+		{
+			name: "BytesIn - value spans two elements",
+			predicate: ValuePredicate{
+				Op:       BytesIn,
+				IntArgs:  []*big.Int{big.NewInt(2)},
+				ByteArgs: [][]byte{{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}},
+			},
+			value: []byte{0x02, 0x03},
+			want:  false,
+		},
+		{
+			name: "BytesIn - value of different length",
+			predicate: ValuePredicate{
+				Op:       BytesIn,
+				IntArgs:  []*big.Int{big.NewInt(2)},
+				ByteArgs: [][]byte{{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}},
+			},
+			value: []byte{0x01, 0x02, 0x03},
+			want:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -739,6 +1171,46 @@ func TestValuePredicateEncodeDecode(t *testing.T) {
 				ByteArgs: [][]byte{},
 			},
 		},
+		{
+			name: "IntLt predicate with negative value",
+			predicate: ValuePredicate{
+				Op:       IntLt,
+				IntArgs:  []*big.Int{big.NewInt(-42)},
+				ByteArgs: [][]byte{},
+			},
+		},
+		{
+			name: "IntGt predicate with minimum int256 value",
+			predicate: ValuePredicate{
+				Op:       IntGt,
+				IntArgs:  []*big.Int{minInt256},
+				ByteArgs: [][]byte{},
+			},
+		},
+		{
+			name: "UintInRange predicate",
+			predicate: ValuePredicate{
+				Op:       UintInRange,
+				IntArgs:  []*big.Int{big.NewInt(10), big.NewInt(20)},
+				ByteArgs: [][]byte{},
+			},
+		},
+		{
+			name: "BitMaskAny predicate",
+			predicate: ValuePredicate{
+				Op:       BitMaskAny,
+				IntArgs:  []*big.Int{big.NewInt(0xff)},
+				ByteArgs: [][]byte{},
+			},
+		},
+		{
+			name: "BytesIn predicate",
+			predicate: ValuePredicate{
+				Op:       BytesIn,
+				IntArgs:  []*big.Int{big.NewInt(20)},
+				ByteArgs: [][]byte{make([]byte, 40)},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -866,6 +1338,17 @@ func TestValuePredicateDecodeErrors(t *testing.T) {
 			}(),
 			expectedErr: "failed to decode ValuePredicate",
 		},
+		{
+			name: "signed integer argument exceeding 256 bits",
+			encodedData: func() []byte {
+				var buf bytes.Buffer
+				elements := []interface{}{uint64(IntLt), twoTo256}
+				err := rlp.Encode(&buf, elements)
+				assert.NilError(t, err, "Encoding should not fail")
+				return buf.Bytes()
+			}(),
+			expectedErr: "signed integer argument 0 exceeds 256 bits",
+		},
 	}
 
 	for _, tt := range tests {
@@ -879,11 +1362,19 @@ func TestValuePredicateDecodeErrors(t *testing.T) {
 }
 
 func TestLogValueRefGetValue(t *testing.T) {
+	// dynamicData encodes a dynamic value with the given offset and length words and content.
+	dynamicData := func(offset, length []byte, content []byte) []byte {
+		data := common.LeftPadBytes(offset, Word)
+		data = append(data, common.LeftPadBytes(length, Word)...)
+		return append(data, content...)
+	}
+
 	tests := []struct {
-		name string
-		ref  LogValueRef
-		log  *types.Log
-		want []byte
+		name    string
+		ref     LogValueRef
+		log     *types.Log
+		want    []byte
+		wantErr bool
 	}{
 		// Topic tests
 		{
@@ -1061,11 +1552,81 @@ func TestLogValueRefGetValue(t *testing.T) {
 			},
 			want: make([]byte, 32), // Should return 32 zero bytes
 		},
+		// Malformed dynamic data tests
+		{
+			name: "dynamic value exceeding data length - zero padded",
+			ref:  LogValueRef{Offset: 4, Dynamic: true},
+			log:  &types.Log{Data: dynamicData([]byte{32}, []byte{4}, []byte{0xaa, 0xbb})},
+			want: []byte{0xaa, 0xbb, 0x00, 0x00},
+		},
+		{
+			name: "dynamic value of maximum length",
+			ref:  LogValueRef{Offset: 4, Dynamic: true},
+			log: &types.Log{
+				Data: dynamicData([]byte{32}, big.NewInt(DefaultMaxDynamicValueLength).Bytes(), []byte{}),
+			},
+			want: make([]byte, DefaultMaxDynamicValueLength),
+		},
+		{
+			name:    "dynamic value from empty log data",
+			ref:     LogValueRef{Offset: 4, Dynamic: true},
+			log:     &types.Log{Data: []byte{}},
+			wantErr: true,
+		},
+		{
+			name:    "dynamic value with truncated offset word",
+			ref:     LogValueRef{Offset: 4, Dynamic: true},
+			log:     &types.Log{Data: make([]byte, 31)},
+			wantErr: true,
+		},
+		{
+			name:    "dynamic value with offset beyond data",
+			ref:     LogValueRef{Offset: 4, Dynamic: true},
+			log:     &types.Log{Data: dynamicData([]byte{64}, []byte{0}, []byte{})},
+			wantErr: true,
+		},
+		{
+			name: "dynamic value with offset exceeding 64 bits",
+			ref:  LogValueRef{Offset: 4, Dynamic: true},
+			log: &types.Log{
+				Data: dynamicData(bytes.Repeat([]byte{0xff}, Word), []byte{0}, []byte{}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "dynamic value with offset overflowing when adding a word",
+			ref:  LogValueRef{Offset: 4, Dynamic: true},
+			log: &types.Log{
+				Data: dynamicData(bytes.Repeat([]byte{0xff}, 8), []byte{0}, []byte{}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "dynamic value with length exceeding 64 bits",
+			ref:  LogValueRef{Offset: 4, Dynamic: true},
+			log: &types.Log{
+				Data: dynamicData([]byte{32}, bytes.Repeat([]byte{0xff}, Word), []byte{}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "dynamic value exceeding maximum length",
+			ref:  LogValueRef{Offset: 4, Dynamic: true},
+			log: &types.Log{
+				Data: dynamicData([]byte{32}, big.NewInt(DefaultMaxDynamicValueLength+1).Bytes(), []byte{}),
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := tt.ref.GetValue(tt.log)
+			result, err := tt.ref.GetValue(tt.log, DefaultMaxDynamicValueLength)
+			if tt.wantErr {
+				assert.Assert(t, errors.Is(err, ErrMalformedLogData), "got %v", err)
+				return
+			}
+			assert.NilError(t, err)
 			assert.DeepEqual(t, tt.want, result)
 		})
 	}
@@ -1660,11 +2221,39 @@ func TestEventTriggerDefinitionMatch(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "dynamic value exceeding maximum length",
+			definition: EventTriggerDefinition{
+				Contract: contractAddr,
+				LogPredicates: []LogPredicate{
+					{
+						LogValueRef: LogValueRef{Offset: 4, Dynamic: true},
+						ValuePredicate: ValuePredicate{
+							Op:       BytesEq,
+							IntArgs:  []*big.Int{},
+							ByteArgs: [][]byte{{}},
+						},
+					},
+				},
+			},
+			log: &types.Log{
+				Address: contractAddr,
+				Data: func() []byte {
+					data := make([]byte, 64)
+					// Offset: 32
+					data[31] = 32
+					// Length: 2^64 - 1
+					copy(data[56:64], bytes.Repeat([]byte{0xff}, 8))
+					return data
+				}(),
+			},
+			want: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := tt.definition.Match(tt.log)
+			result, err := tt.definition.Match(tt.log, DefaultMaxDynamicValueLength)
 			assert.NilError(t, err)
 			assert.Equal(t, tt.want, result)
 		})
@@ -2215,10 +2804,10 @@ func TestWithEVM(t *testing.T) {
 
 			equal := cmp.DeepEqual(encoded, doubleEncoded)
 			assert.Check(t, equal, "did not survive roundtrip: %v", tt.name)
-			match, err := etd.Match(vLog)
+			match, err := etd.Match(vLog, DefaultMaxDynamicValueLength)
 			assert.NilError(t, err, "error when matching: %v. err: %v", tt.name, err)
 			assert.Equal(t, match, tt.match, "did not match expectation: %v\nlog data:\t%v\nmatch data:\t%v", tt.name, vLog, etd)
-			match, err = decoded.Match(vLog)
+			match, err = decoded.Match(vLog, DefaultMaxDynamicValueLength)
 			assert.NilError(t, err, "error when matching from decoded: %v. err: %v", tt.name, err)
 			assert.Equal(t, match, tt.match, "did not match expectation after roundtrip: %v", tt.name)
 		})
@@ -2232,3 +2821,8 @@ func Align(val []byte) []byte {
 	copy(x[len(x)-len(val):], val)
 	return x
 }
+
+// int256Word encodes the value as a 32 byte two's complement word.
+func int256Word(v int64) []byte {
+	return math.U256Bytes(big.NewInt(v))
+}
@@ -0,0 +1,811 @@
+package shutterservice
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The event trigger DSL describes an event trigger definition in terms of the Solidity signature
+// of the event it triggers on, e.g.,
+//
+//	Transfer(address indexed from, address indexed to, uint256 value) where to == 0xabc.. and value >= 1e18
+//
+// The signature determines the topic and data offsets of the arguments and whether they are
+// dynamic. It also implies a condition on the first topic, the event id. Conditions in the where
+// clause combine comparisons of arguments with literals using `and`, `or`, `not` and parentheses.
+// Comparisons are
+//   - `==` and `!=` with a literal, and `in [..]` with a list of literals,
+//   - `<`, `<=`, `>`, `>=`, `between .. and ..` for integers, interpreted according to their type,
+//   - `hasall` and `hasany` with a bit mask for unsigned integers, and
+//   - `prefix` and `contains` with a literal for non-indexed bytes and strings.
+//
+// Literals are decimal numbers (possibly negative or with an exponent, e.g., 1e18), hex values
+// (0x..), Go-style quoted strings and the booleans true and false. Indexed bytes and strings are
+// compared by the hash of the literal, as only their hash is part of the log.
+//
+// Instead of argument names, topics and data words can be referenced directly as topic[i],
+// word[i] (the static value of the i-th data word) and bytes[i] (the dynamic value whose offset is
+// in the i-th data word). They are treated as uint256 and bytes, respectively. A source without a
+// signature consists of a where clause only.
+
+var (
+	uint256Type, _ = abi.NewType("uint256", "", nil)
+	bytesType, _   = abi.NewType("bytes", "", nil)
+)
+
+// CompileEventTrigger compiles the description of an event trigger in the event trigger DSL to a
+// valid definition for logs emitted by the contract.
+//
+// Conjunctions of comparisons result in version 2 definitions, all other conditions in version 3
+// definitions.
+func CompileEventTrigger(contract common.Address, source string) (*EventTriggerDefinition, error) {
+	p, err := newDSLParser(source)
+	if err != nil {
+		return nil, err
+	}
+	var conjuncts []*PredicateNode
+	if p.peek().kind == tokenIdent && p.peek().text != "where" {
+		event, err := p.parseSignature()
+		if err != nil {
+			return nil, err
+		}
+		conjuncts = append(conjuncts, topicEqLeaf(0, event.ID))
+	}
+	if p.accept("where") {
+		condition, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if condition.Kind == NodeAnd {
+			conjuncts = append(conjuncts, condition.Children...)
+		} else {
+			conjuncts = append(conjuncts, condition)
+		}
+	}
+	if err := p.expectEOF(); err != nil {
+		return nil, err
+	}
+	if len(conjuncts) == 0 {
+		return nil, fmt.Errorf("event trigger must have a signature or a where clause")
+	}
+
+	definition := &EventTriggerDefinition{Contract: contract}
+	if allLeaves(conjuncts) {
+		definition.LogPredicates = []LogPredicate{}
+		for _, conjunct := range conjuncts {
+			definition.LogPredicates = append(definition.LogPredicates, *conjunct.Leaf)
+		}
+	} else {
+		definition.Condition = And(conjuncts...)
+	}
+	if err := definition.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid event trigger: %w", err)
+	}
+	return definition, nil
+}
+
+// ParseEventSignature parses a Solidity event signature, e.g.,
+// `Transfer(address indexed from, address indexed to, uint256 value)`.
+func ParseEventSignature(signature string) (*abi.Event, error) {
+	p, err := newDSLParser(signature)
+	if err != nil {
+		return nil, err
+	}
+	event, err := p.parseSignature()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectEOF(); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func topicEqLeaf(topic uint64, value common.Hash) *PredicateNode {
+	return Leaf(LogPredicate{
+		LogValueRef:    LogValueRef{Offset: topic},
+		ValuePredicate: ValuePredicate{Op: BytesEq, IntArgs: []*big.Int{}, ByteArgs: [][]byte{value.Bytes()}},
+	})
+}
+
+func allLeaves(nodes []*PredicateNode) bool {
+	for _, node := range nodes {
+		if node.Kind != NodeLeaf {
+			return false
+		}
+	}
+	return true
+}
+
+// dslArg is a value of a log that can be referenced in the DSL, either an argument of the event
+// or a raw topic or data word.
+type dslArg struct {
+	Name string
+	Ref  LogValueRef
+	Type abi.Type
+	// Hashed is set for indexed dynamic arguments, of which only the hash is part of the log.
+	Hashed bool
+}
+
+// eventArgs determines the log value references of the arguments of the event, by topic for
+// indexed arguments and by data word for the others.
+func eventArgs(event *abi.Event) ([]dslArg, error) {
+	args := []dslArg{}
+	topic := uint64(1)
+	word := uint64(0)
+	for _, input := range event.Inputs {
+		arg := dslArg{Name: input.Name, Type: input.Type}
+		if input.Indexed {
+			if topic > 3 {
+				return nil, fmt.Errorf("event must not have more than 3 indexed arguments")
+			}
+			arg.Ref = LogValueRef{Offset: topic}
+			arg.Hashed = isDynamicType(input.Type)
+			topic++
+		} else {
+			arg.Ref = LogValueRef{Offset: 4 + word, Dynamic: isDynamicType(input.Type)}
+			word += headWords(input.Type)
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// isDynamicType checks if values of the type are encoded in the tail of the ABI encoding.
+func isDynamicType(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy:
+		return true
+	case abi.ArrayTy:
+		return isDynamicType(*t.Elem)
+	}
+	return false
+}
+
+// headWords returns the number of words values of the type occupy in the head of the ABI encoding.
+func headWords(t abi.Type) uint64 {
+	if t.T == abi.ArrayTy && !isDynamicType(t) {
+		return uint64(t.Size) * headWords(*t.Elem) //nolint:gosec // G115: array sizes are not negative
+	}
+	return 1
+}
+
+type dslTokenKind int
+
+const (
+	tokenEOF dslTokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenHex
+	tokenString
+	tokenPunct
+)
+
+type dslToken struct {
+	kind dslTokenKind
+	text string
+	pos  int
+}
+
+func (t dslToken) String() string {
+	if t.kind == tokenEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q at position %d", t.text, t.pos)
+}
+
+func lexDSL(source string) ([]dslToken, error) {
+	tokens := []dslToken{}
+	for pos := 0; pos < len(source); {
+		c := source[pos]
+		start := pos
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+			continue
+		case c == '_' || c == '$' || unicode.IsLetter(rune(c)):
+			pos = scanWhile(source, pos, isIdentChar)
+			tokens = append(tokens, dslToken{kind: tokenIdent, text: source[start:pos], pos: start})
+		case strings.HasPrefix(source[pos:], "0x"):
+			pos = scanWhile(source, pos+2, isHexChar)
+			tokens = append(tokens, dslToken{kind: tokenHex, text: source[start:pos], pos: start})
+		case isDigit(c) || (c == '-' && pos+1 < len(source) && isDigit(source[pos+1])):
+			pos = scanWhile(source, pos+1, isNumberChar)
+			tokens = append(tokens, dslToken{kind: tokenNumber, text: source[start:pos], pos: start})
+		case c == '"':
+			token, err := lexString(source, pos)
+			if err != nil {
+				return nil, err
+			}
+			pos += len(token.text)
+			tokens = append(tokens, token)
+		default:
+			text := punctAt(source, pos)
+			if text == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, pos)
+			}
+			pos += len(text)
+			tokens = append(tokens, dslToken{kind: tokenPunct, text: text, pos: start})
+		}
+	}
+	return append(tokens, dslToken{kind: tokenEOF, pos: len(source)}), nil
+}
+
+func lexString(source string, pos int) (dslToken, error) {
+	for end := pos + 1; end < len(source); end++ {
+		switch source[end] {
+		case '\\':
+			end++
+		case '"':
+			return dslToken{kind: tokenString, text: source[pos : end+1], pos: pos}, nil
+		}
+	}
+	return dslToken{}, fmt.Errorf("unterminated string at position %d", pos)
+}
+
+func punctAt(source string, pos int) string {
+	for _, punct := range []string{"==", "!=", "<=", ">=", "<", ">", "(", ")", "[", "]", ","} {
+		if strings.HasPrefix(source[pos:], punct) {
+			return punct
+		}
+	}
+	return ""
+}
+
+func scanWhile(source string, pos int, f func(byte) bool) int {
+	for pos < len(source) && f(source[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || unicode.IsLetter(rune(c))
+}
+
+func isHexChar(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func isNumberChar(c byte) bool {
+	return isDigit(c) || c == '.' || c == 'e' || c == 'E'
+}
+
+type dslParser struct {
+	tokens []dslToken
+	pos    int
+	args   map[string]dslArg
+}
+
+func newDSLParser(source string) (*dslParser, error) {
+	tokens, err := lexDSL(source)
+	if err != nil {
+		return nil, err
+	}
+	return &dslParser{tokens: tokens, args: map[string]dslArg{}}, nil
+}
+
+func (p *dslParser) peek() dslToken {
+	return p.tokens[p.pos]
+}
+
+func (p *dslParser) next() dslToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+// accept consumes the next token if it is the given keyword or punctuation.
+func (p *dslParser) accept(text string) bool {
+	token := p.peek()
+	if (token.kind == tokenIdent || token.kind == tokenPunct) && token.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *dslParser) expect(text string) error {
+	if !p.accept(text) {
+		return fmt.Errorf("expected %q, got %s", text, p.peek())
+	}
+	return nil
+}
+
+func (p *dslParser) expectEOF() error {
+	if token := p.peek(); token.kind != tokenEOF {
+		return fmt.Errorf("unexpected %s", token)
+	}
+	return nil
+}
+
+func (p *dslParser) expectIdent() (string, error) {
+	token := p.next()
+	if token.kind != tokenIdent {
+		return "", fmt.Errorf("expected identifier, got %s", token)
+	}
+	return token.text, nil
+}
+
+// parseSignature parses an event signature and makes its named arguments available to the where
+// clause.
+func (p *dslParser) parseSignature() (*abi.Event, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	inputs := abi.Arguments{}
+	for !p.accept(")") {
+		if len(inputs) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		input, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, input)
+	}
+	event := abi.NewEvent(name, name, false, inputs)
+	args, err := eventArgs(&event)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		if arg.Name == "" {
+			continue
+		}
+		if _, ok := p.args[arg.Name]; ok {
+			return nil, fmt.Errorf("duplicate argument name %q", arg.Name)
+		}
+		p.args[arg.Name] = arg
+	}
+	return &event, nil
+}
+
+func (p *dslParser) parseArgument() (abi.Argument, error) {
+	typeName, err := p.expectIdent()
+	if err != nil {
+		return abi.Argument{}, err
+	}
+	if typeName == "tuple" {
+		return abi.Argument{}, fmt.Errorf("tuple arguments are not supported")
+	}
+	for p.accept("[") {
+		size := ""
+		if p.peek().kind == tokenNumber {
+			size = p.next().text
+		}
+		if err := p.expect("]"); err != nil {
+			return abi.Argument{}, err
+		}
+		typeName += "[" + size + "]"
+	}
+	t, err := abi.NewType(typeName, "", nil)
+	if err != nil {
+		return abi.Argument{}, fmt.Errorf("invalid type %q: %w", typeName, err)
+	}
+	argument := abi.Argument{Type: t, Indexed: p.accept("indexed")}
+	if token := p.peek(); token.kind == tokenIdent {
+		argument.Name = p.next().text
+	}
+	return argument, nil
+}
+
+func (p *dslParser) parseOr() (*PredicateNode, error) {
+	return p.parseChain("or", p.parseAnd, Or)
+}
+
+func (p *dslParser) parseAnd() (*PredicateNode, error) {
+	return p.parseChain("and", p.parseUnary, And)
+}
+
+// parseChain parses operands separated by the keyword and combines them if there is more than one.
+func (p *dslParser) parseChain(
+	keyword string,
+	parseOperand func() (*PredicateNode, error),
+	combine func(...*PredicateNode) *PredicateNode,
+) (*PredicateNode, error) {
+	operands := []*PredicateNode{}
+	for len(operands) == 0 || p.accept(keyword) {
+		operand, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return combine(operands...), nil
+}
+
+func (p *dslParser) parseUnary() (*PredicateNode, error) {
+	if p.accept("not") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(operand), nil
+	}
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return node, p.expect(")")
+	}
+	return p.parseComparison()
+}
+
+func (p *dslParser) parseComparison() (*PredicateNode, error) {
+	arg, err := p.parseArgRef()
+	if err != nil {
+		return nil, err
+	}
+	operator := p.next()
+	if operator.kind != tokenIdent && operator.kind != tokenPunct {
+		return nil, fmt.Errorf("expected comparison operator, got %s", operator)
+	}
+	var literals []dslToken
+	switch operator.text {
+	case "in":
+		literals, err = p.parseList()
+	case "between":
+		literals, err = p.parseRange()
+	default:
+		literals = []dslToken{p.next()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	node, err := arg.compare(operator.text, literals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid comparison %s of %s: %w", operator, arg.describe(), err)
+	}
+	return node, nil
+}
+
+// parseArgRef parses a reference to a named argument or a raw topic or data word.
+func (p *dslParser) parseArgRef() (dslArg, error) {
+	token := p.peek()
+	name, err := p.expectIdent()
+	if err != nil {
+		return dslArg{}, err
+	}
+	if !p.accept("[") {
+		arg, ok := p.args[name]
+		if !ok {
+			return dslArg{}, fmt.Errorf("unknown argument %s", token)
+		}
+		return arg, nil
+	}
+	index := p.next()
+	i, err := strconv.ParseUint(index.text, 10, 32)
+	if index.kind != tokenNumber || err != nil {
+		return dslArg{}, fmt.Errorf("expected index, got %s", index)
+	}
+	if err := p.expect("]"); err != nil {
+		return dslArg{}, err
+	}
+	switch name {
+	case "topic":
+		if i > 3 {
+			return dslArg{}, fmt.Errorf("topic index must be less than 4, got %d", i)
+		}
+		return dslArg{Ref: LogValueRef{Offset: i}, Type: uint256Type}, nil
+	case "word":
+		return dslArg{Ref: LogValueRef{Offset: 4 + i}, Type: uint256Type}, nil
+	case "bytes":
+		return dslArg{Ref: LogValueRef{Offset: 4 + i, Dynamic: true}, Type: bytesType}, nil
+	}
+	return dslArg{}, fmt.Errorf("unknown log value %s, expected topic, word or bytes", token)
+}
+
+func (p *dslParser) parseList() ([]dslToken, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	literals := []dslToken{}
+	for !p.accept("]") {
+		if len(literals) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		literals = append(literals, p.next())
+	}
+	return literals, nil
+}
+
+func (p *dslParser) parseRange() ([]dslToken, error) {
+	lower := p.next()
+	if err := p.expect("and"); err != nil {
+		return nil, err
+	}
+	return []dslToken{lower, p.next()}, nil
+}
+
+func (a dslArg) describe() string {
+	if a.Name != "" {
+		return fmt.Sprintf("argument %q of type %s", a.Name, a.Type)
+	}
+	return fmt.Sprintf("%s of type %s", a.rawName(), a.Type)
+}
+
+// rawName returns the name of the referenced topic or data word in the DSL.
+func (a dslArg) rawName() string {
+	switch {
+	case a.Ref.IsTopic():
+		return fmt.Sprintf("topic[%d]", a.Ref.Offset)
+	case a.Ref.Dynamic:
+		return fmt.Sprintf("bytes[%d]", a.Ref.Offset-4)
+	default:
+		return fmt.Sprintf("word[%d]", a.Ref.Offset-4)
+	}
+}
+
+func (a dslArg) isWord() bool {
+	return !a.Ref.Dynamic && !a.Hashed
+}
+
+func (a dslArg) leaf(op Op, intArgs []*big.Int, byteArgs [][]byte) *PredicateNode {
+	return Leaf(LogPredicate{
+		LogValueRef:    a.Ref,
+		ValuePredicate: ValuePredicate{Op: op, IntArgs: intArgs, ByteArgs: byteArgs},
+	})
+}
+
+// compare compiles a comparison of the argument with the literals.
+func (a dslArg) compare(operator string, literals []dslToken) (*PredicateNode, error) {
+	if a.Type.T == abi.SliceTy || a.Type.T == abi.ArrayTy {
+		return nil, fmt.Errorf("comparisons of %s arguments are not supported", a.Type)
+	}
+	switch operator {
+	case "==", "!=":
+		value, err := a.bytesValue(literals[0])
+		if err != nil {
+			return nil, err
+		}
+		node := a.leaf(BytesEq, []*big.Int{}, [][]byte{value})
+		if operator == "!=" {
+			node = Not(node)
+		}
+		return node, nil
+	case "in":
+		return a.compareIn(literals)
+	case "<", "<=", ">", ">=", "between":
+		return a.compareOrder(operator, literals)
+	case "hasall", "hasany":
+		if !a.isWord() || a.Type.T != abi.UintTy {
+			return nil, fmt.Errorf("bit masks require an unsigned integer")
+		}
+		mask, err := a.intValue(literals[0])
+		if err != nil {
+			return nil, err
+		}
+		op := BitMaskAll
+		if operator == "hasany" {
+			op = BitMaskAny
+		}
+		return a.leaf(op, []*big.Int{mask}, [][]byte{}), nil
+	case "prefix", "contains":
+		if !a.Ref.Dynamic {
+			return nil, fmt.Errorf("%s requires a non-indexed bytes or string argument", operator)
+		}
+		value, err := a.bytesValue(literals[0])
+		if err != nil {
+			return nil, err
+		}
+		op := BytesPrefix
+		if operator == "contains" {
+			op = BytesContains
+		}
+		return a.leaf(op, []*big.Int{}, [][]byte{value}), nil
+	}
+	return nil, fmt.Errorf("unknown operator")
+}
+
+func (a dslArg) compareIn(literals []dslToken) (*PredicateNode, error) {
+	if len(literals) == 0 {
+		return nil, fmt.Errorf("set must not be empty")
+	}
+	set := []byte{}
+	elementLength := -1
+	for _, literal := range literals {
+		value, err := a.bytesValue(literal)
+		if err != nil {
+			return nil, err
+		}
+		if elementLength != -1 && len(value) != elementLength {
+			return nil, fmt.Errorf("set elements must have the same length")
+		}
+		elementLength = len(value)
+		set = append(set, value...)
+	}
+	return a.leaf(BytesIn, []*big.Int{big.NewInt(int64(elementLength))}, [][]byte{set}), nil
+}
+
+func (a dslArg) compareOrder(operator string, literals []dslToken) (*PredicateNode, error) {
+	if !a.isWord() || (a.Type.T != abi.UintTy && a.Type.T != abi.IntTy) {
+		return nil, fmt.Errorf("ordering requires an integer")
+	}
+	values := []*big.Int{}
+	for _, literal := range literals {
+		value, err := a.intValue(literal)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if a.Type.T == abi.UintTy {
+		ops := map[string]Op{"<": UintLt, "<=": UintLte, ">": UintGt, ">=": UintGte, "between": UintInRange}
+		return a.leaf(ops[operator], values, [][]byte{}), nil
+	}
+	// Signed comparisons only support < and >, so the others are expressed by negating them.
+	lt := a.leaf(IntLt, values[:1], [][]byte{})
+	gt := a.leaf(IntGt, values[len(values)-1:], [][]byte{})
+	switch operator {
+	case "<":
+		return lt, nil
+	case ">":
+		return gt, nil
+	case "<=":
+		return Not(gt), nil
+	case ">=":
+		return Not(lt), nil
+	}
+	return And(Not(lt), Not(gt)), nil
+}
+
+// intValue converts an integer literal to a value of the argument's type.
+func (a dslArg) intValue(literal dslToken) (*big.Int, error) {
+	n, err := parseInt(literal)
+	if err != nil {
+		return nil, err
+	}
+	size := uint(a.Type.Size) //nolint:gosec // G115: type sizes are not negative
+	if a.Type.T == abi.IntTy {
+		limit := new(big.Int).Lsh(big.NewInt(1), size-1)
+		if n.Cmp(new(big.Int).Neg(limit)) < 0 || n.Cmp(limit) >= 0 {
+			return nil, fmt.Errorf("%s exceeds the range of %s", literal.text, a.Type)
+		}
+		return n, nil
+	}
+	if n.Sign() < 0 || n.BitLen() > int(size) {
+		return nil, fmt.Errorf("%s exceeds the range of %s", literal.text, a.Type)
+	}
+	return n, nil
+}
+
+// bytesValue converts a literal to the bytes the argument is compared with: a word for static
+// arguments, the hash for indexed dynamic ones, and the raw bytes for the other dynamic ones.
+func (a dslArg) bytesValue(literal dslToken) ([]byte, error) {
+	if !a.isWord() {
+		value, err := parseBytes(literal)
+		if err != nil {
+			return nil, err
+		}
+		if a.Hashed {
+			return crypto.Keccak256(value), nil
+		}
+		return value, nil
+	}
+	switch a.Type.T {
+	case abi.UintTy, abi.IntTy:
+		n, err := a.intValue(literal)
+		if err != nil {
+			return nil, err
+		}
+		return math.U256Bytes(new(big.Int).Set(n)), nil
+	case abi.BoolTy:
+		if literal.text != "true" && literal.text != "false" {
+			return nil, fmt.Errorf("expected true or false, got %s", literal)
+		}
+		value := make([]byte, Word)
+		if literal.text == "true" {
+			value[Word-1] = 1
+		}
+		return value, nil
+	case abi.AddressTy, abi.FixedBytesTy:
+		value, err := parseHex(literal)
+		if err != nil {
+			return nil, err
+		}
+		if a.Type.T == abi.AddressTy {
+			if len(value) != common.AddressLength {
+				return nil, fmt.Errorf("expected a 20 byte address, got %s", literal)
+			}
+			return common.LeftPadBytes(value, Word), nil
+		}
+		if len(value) > a.Type.Size {
+			return nil, fmt.Errorf("%s exceeds %d bytes", literal.text, a.Type.Size)
+		}
+		return common.RightPadBytes(value, Word), nil
+	}
+	return nil, fmt.Errorf("comparisons of %s arguments are not supported", a.Type)
+}
+
+// parseInt parses a decimal or hex integer literal. Decimal literals may have a fractional part
+// and an exponent as long as they denote an integer, e.g., 1.5e18.
+func parseInt(literal dslToken) (*big.Int, error) {
+	if literal.kind == tokenHex {
+		n, ok := new(big.Int).SetString(literal.text[2:], 16)
+		if !ok {
+			return nil, fmt.Errorf("invalid hex number %s", literal)
+		}
+		return n, nil
+	}
+	if literal.kind != tokenNumber {
+		return nil, fmt.Errorf("expected number, got %s", literal)
+	}
+	mantissa, exponent, _ := strings.Cut(strings.ToLower(literal.text), "e")
+	integer, fraction, _ := strings.Cut(mantissa, ".")
+	exp := uint64(0)
+	if exponent != "" {
+		var err error
+		if exp, err = strconv.ParseUint(exponent, 10, 8); err != nil {
+			return nil, fmt.Errorf("invalid exponent in %s", literal)
+		}
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if uint64(len(fraction)) > exp {
+		return nil, fmt.Errorf("%s is not an integer", literal)
+	}
+	n, ok := new(big.Int).SetString(integer+fraction, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %s", literal)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), new(big.Int).SetUint64(exp-uint64(len(fraction))), nil)
+	return n.Mul(n, scale), nil
+}
+
+func parseHex(literal dslToken) ([]byte, error) {
+	if literal.kind != tokenHex {
+		return nil, fmt.Errorf("expected hex value, got %s", literal)
+	}
+	value, err := hex.DecodeString(literal.text[2:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex value %s: %w", literal, err)
+	}
+	return value, nil
+}
+
+// parseBytes parses a hex or string literal.
+func parseBytes(literal dslToken) ([]byte, error) {
+	if literal.kind != tokenString {
+		return parseHex(literal)
+	}
+	value, err := strconv.Unquote(literal.text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid string %s: %w", literal, err)
+	}
+	return []byte(value), nil
+}
+
+// isPrintable checks if the value can be shown as a string literal.
+func isPrintable(value []byte) bool {
+	return len(value) > 0 && bytes.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsPrint(r) || r == unicode.ReplacementChar
+	}) == -1
+}
@@ -0,0 +1,283 @@
+package shutterservice
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/assert"
+)
+
+const transferSignature = "Transfer(address indexed from, address indexed to, uint256 value)"
+
+var (
+	transferEventID = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	dslAddress      = common.HexToAddress("0xabcdefabcdefabcdefabcdefabcdefabcdefabcd")
+)
+
+func dslPredicate(offset uint64, dynamic bool, op Op, intArgs []*big.Int, byteArgs ...[]byte) LogPredicate {
+	if byteArgs == nil {
+		byteArgs = [][]byte{}
+	}
+	if intArgs == nil {
+		intArgs = []*big.Int{}
+	}
+	return LogPredicate{
+		LogValueRef:    LogValueRef{Offset: offset, Dynamic: dynamic},
+		ValuePredicate: ValuePredicate{Op: op, IntArgs: intArgs, ByteArgs: byteArgs},
+	}
+}
+
+func eventIDPredicate(signature string) LogPredicate {
+	return dslPredicate(0, false, BytesEq, nil, crypto.Keccak256([]byte(signature)))
+}
+
+func TestCompileEventTriggerPredicates(t *testing.T) {
+	oneEther, _ := new(big.Int).SetString("1000000000000000000", 10)
+	tests := []struct {
+		name   string
+		source string
+		want   []LogPredicate
+	}{
+		{
+			name:   "signature only",
+			source: transferSignature,
+			want:   []LogPredicate{eventIDPredicate("Transfer(address,address,uint256)")},
+		},
+		{
+			name:   "transfer to address with minimum value",
+			source: transferSignature + " where to == 0xabcdefabcdefabcdefabcdefabcdefabcdefabcd and value >= 1e18",
+			want: []LogPredicate{
+				eventIDPredicate("Transfer(address,address,uint256)"),
+				dslPredicate(2, false, BytesEq, nil, common.LeftPadBytes(dslAddress.Bytes(), Word)),
+				dslPredicate(4, false, UintGte, []*big.Int{oneEther}),
+			},
+		},
+		{
+			name:   "unsigned comparisons",
+			source: "E(uint8 a, uint256 b) where a < 0xff and b between 1.5e3 and 2000 and b hasany 0x3",
+			want: []LogPredicate{
+				eventIDPredicate("E(uint8,uint256)"),
+				dslPredicate(4, false, UintLt, []*big.Int{big.NewInt(255)}),
+				dslPredicate(5, false, UintInRange, []*big.Int{big.NewInt(1500), big.NewInt(2000)}),
+				dslPredicate(5, false, BitMaskAny, []*big.Int{big.NewInt(3)}),
+			},
+		},
+		{
+			name:   "signed comparisons",
+			source: "E(int256 indexed a) where a < -5 and a > -10",
+			want: []LogPredicate{
+				eventIDPredicate("E(int256)"),
+				dslPredicate(1, false, IntLt, []*big.Int{big.NewInt(-5)}),
+				dslPredicate(1, false, IntGt, []*big.Int{big.NewInt(-10)}),
+			},
+		},
+		{
+			name:   "dynamic values",
+			source: `E(string s, bytes b) where s prefix "ipfs://" and b contains 0xdead and s == ""`,
+			want: []LogPredicate{
+				eventIDPredicate("E(string,bytes)"),
+				dslPredicate(4, true, BytesPrefix, nil, []byte("ipfs://")),
+				dslPredicate(5, true, BytesContains, nil, []byte{0xde, 0xad}),
+				dslPredicate(4, true, BytesEq, nil, []byte{}),
+			},
+		},
+		{
+			name:   "indexed dynamic values are hashed",
+			source: `E(string indexed name, bool flag, bytes4 selector) where name == "shutter" and flag == true and selector == 0xa9059cbb`,
+			want: []LogPredicate{
+				eventIDPredicate("E(string,bool,bytes4)"),
+				dslPredicate(1, false, BytesEq, nil, crypto.Keccak256([]byte("shutter"))),
+				dslPredicate(4, false, BytesEq, nil, common.LeftPadBytes([]byte{1}, Word)),
+				dslPredicate(5, false, BytesEq, nil, common.RightPadBytes([]byte{0xa9, 0x05, 0x9c, 0xbb}, Word)),
+			},
+		},
+		{
+			name:   "static arrays occupy multiple data words",
+			source: "E(uint256[2] pair, bytes[] list, uint256 last) where last == 1",
+			want: []LogPredicate{
+				eventIDPredicate("E(uint256[2],bytes[],uint256)"),
+				dslPredicate(7, false, BytesEq, nil, common.LeftPadBytes([]byte{1}, Word)),
+			},
+		},
+		{
+			name:   "set membership",
+			source: "E(uint16 indexed a) where a in [1, 0x2]",
+			want: []LogPredicate{
+				eventIDPredicate("E(uint16)"),
+				dslPredicate(1, false, BytesIn, []*big.Int{big.NewInt(Word)},
+					append(common.LeftPadBytes([]byte{1}, Word), common.LeftPadBytes([]byte{2}, Word)...)),
+			},
+		},
+		{
+			name:   "raw references without signature",
+			source: "where topic[1] == 5 and word[2] > 7 and bytes[0] prefix 0x01",
+			want: []LogPredicate{
+				dslPredicate(1, false, BytesEq, nil, common.LeftPadBytes([]byte{5}, Word)),
+				dslPredicate(6, false, UintGt, []*big.Int{big.NewInt(7)}),
+				dslPredicate(4, true, BytesPrefix, nil, []byte{0x01}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definition, err := CompileEventTrigger(dslAddress, tt.source)
+			assert.NilError(t, err)
+			assert.Equal(t, definition.Contract, dslAddress)
+			assert.Assert(t, definition.Condition == nil)
+			want := &EventTriggerDefinition{Contract: dslAddress, LogPredicates: tt.want}
+			assert.Assert(t, bytes.Equal(definition.MarshalBytes(), want.MarshalBytes()))
+		})
+	}
+}
+
+func TestCompileEventTriggerTree(t *testing.T) {
+	definition, err := CompileEventTrigger(dslAddress,
+		transferSignature+" where (from == 0xabcdefabcdefabcdefabcdefabcdefabcdefabcd or to != 0xabcdefabcdefabcdefabcdefabcdefabcdefabcd)"+
+			" and not value <= 10")
+	assert.NilError(t, err)
+	assert.Equal(t, definition.MarshalBytes()[0], byte(VersionPredicateTree))
+
+	address := common.LeftPadBytes(dslAddress.Bytes(), Word)
+	want := And(
+		Leaf(eventIDPredicate("Transfer(address,address,uint256)")),
+		Or(
+			Leaf(dslPredicate(1, false, BytesEq, nil, address)),
+			Not(Leaf(dslPredicate(2, false, BytesEq, nil, address))),
+		),
+		Not(Leaf(dslPredicate(4, false, UintLte, []*big.Int{big.NewInt(10)}))),
+	)
+	wantDefinition := &EventTriggerDefinition{Contract: dslAddress, Condition: want}
+	assert.Assert(t, bytes.Equal(definition.MarshalBytes(), wantDefinition.MarshalBytes()))
+
+	query, err := definition.ToFilterQuery()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, query.Topics[0], []common.Hash{transferEventID})
+}
+
+func TestCompileEventTriggerSignedRanges(t *testing.T) {
+	definition, err := CompileEventTrigger(dslAddress, "where word[0] > 1 or word[0] < 0")
+	assert.NilError(t, err)
+	assert.Equal(t, definition.Condition.Children[0].Kind, NodeOr)
+
+	definition, err = CompileEventTrigger(dslAddress, "E(int8 a) where a between -3 and 3")
+	assert.NilError(t, err)
+	want := And(
+		Leaf(eventIDPredicate("E(int8)")),
+		Not(Leaf(dslPredicate(4, false, IntLt, []*big.Int{big.NewInt(-3)}))),
+		Not(Leaf(dslPredicate(4, false, IntGt, []*big.Int{big.NewInt(3)}))),
+	)
+	wantDefinition := &EventTriggerDefinition{Contract: dslAddress, Condition: want}
+	assert.Assert(t, bytes.Equal(definition.MarshalBytes(), wantDefinition.MarshalBytes()))
+}
+
+func TestCompileEventTriggerErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		errMsg string
+	}{
+		{"", "event trigger must have a signature or a where clause"},
+		{transferSignature + " where amount == 1", `unknown argument "amount"`},
+		{"E(foo a)", `invalid type "foo"`},
+		{"E(tuple a)", "tuple arguments are not supported"},
+		{"E(uint256 indexed a, uint256 indexed b, uint256 indexed c, uint256 indexed d)", "not have more than 3 indexed"},
+		{"E(uint256 a, uint256 a)", `duplicate argument name "a"`},
+		{"E(uint8 a) where a < 256", "256 exceeds the range of uint8"},
+		{"E(uint8 a) where a < -1", "-1 exceeds the range of uint8"},
+		{"E(int8 a) where a > 128", "128 exceeds the range of int8"},
+		{"E(uint256 a) where a == 1.5", "is not an integer"},
+		{"E(uint256 a) where a == 0xzz", `invalid hex number "0x"`},
+		{"E(address a) where a == 0x1234", "expected a 20 byte address"},
+		{"E(bytes2 a) where a == 0x123456", "exceeds 2 bytes"},
+		{"E(bool a) where a == 1", "expected true or false"},
+		{"E(int256 a) where a hasall 1", "bit masks require an unsigned integer"},
+		{"E(string indexed a) where a prefix \"x\"", "prefix requires a non-indexed bytes or string argument"},
+		{"E(bytes a) where a < 1", "ordering requires an integer"},
+		{"E(bytes a) where a in [0x01, 0x0203]", "set elements must have the same length"},
+		{"E(bytes a) where a in []", "set must not be empty"},
+		{"E(uint256[] a) where a == 1", "comparisons of uint256[] arguments are not supported"},
+		{"E(uint256 a) where a ~ 1", `unexpected character '~'`},
+		{`E(string a) where a == "open`, "unterminated string"},
+		{"E(uint256 a) where (a == 1", `expected ")"`},
+		{"E(uint256 a) where a == 1 a", `unexpected "a"`},
+		{"where topic[4] == 1", "topic index must be less than 4"},
+		{"where data[0] == 1", "unknown log value"},
+		{"E(uint256 a) where a like 1", "unknown operator"},
+		{"E(bytes a) where a == \"\" or a prefix \"\"", "invalid event trigger"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			_, err := CompileEventTrigger(dslAddress, tt.source)
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestParseEventSignature(t *testing.T) {
+	event, err := ParseEventSignature(transferSignature)
+	assert.NilError(t, err)
+	assert.Equal(t, event.ID, transferEventID)
+	assert.Equal(t, formatSignature(event), transferSignature)
+
+	_, err = ParseEventSignature(transferSignature + " where value > 1")
+	assert.ErrorContains(t, err, `unexpected "where"`)
+}
+
+func TestExplainEventTriggerRoundTrip(t *testing.T) {
+	sources := []string{
+		transferSignature,
+		transferSignature + " where to == " + dslAddress.Hex() + " and value >= 1000000000000000000",
+		transferSignature + " where (from == " + dslAddress.Hex() + " or value < 5) and not value between 1 and 2",
+		`E(int8 indexed a, string s, bytes b, bytes4 sel, bool f) where a <= -3 and a >= -100 and s prefix "ipfs://"` +
+			` and b contains 0xdead and sel in [0xa9059cbb, 0x23b872dd] and f != true`,
+		`E(string indexed s, uint256 flags) where flags hasall 0x3 and not (flags hasany 0x4 or flags == 7)`,
+		"where topic[1] == 0x0000000000000000000000000000000000000000000000000000000000000005 and bytes[1] prefix 0x01",
+	}
+	for _, source := range sources {
+		t.Run(source, func(t *testing.T) {
+			definition, err := CompileEventTrigger(dslAddress, source)
+			assert.NilError(t, err)
+			events := []*abi.Event{}
+			if !strings.HasPrefix(source, "where") {
+				event, err := ParseEventSignature(source[:strings.Index(source, ")")+1])
+				assert.NilError(t, err)
+				events = append(events, event)
+			}
+
+			explained := ExplainEventTrigger(definition, events)
+			lines := strings.Split(explained, "\n")
+			assert.Equal(t, lines[0], "contract "+dslAddress.Hex())
+			assert.Equal(t, lines[1], source)
+
+			recompiled, err := CompileEventTrigger(dslAddress, lines[1])
+			assert.NilError(t, err)
+			assert.Assert(t, bytes.Equal(recompiled.MarshalBytes(), definition.MarshalBytes()))
+		})
+	}
+}
+
+func TestExplainEventTriggerRaw(t *testing.T) {
+	definition, err := CompileEventTrigger(dslAddress, transferSignature+" where value > 5")
+	assert.NilError(t, err)
+	explained := ExplainEventTrigger(definition, nil)
+	assert.Equal(t, explained, "contract "+dslAddress.Hex()+"\nwhere topic[0] == "+transferEventID.Hex()+" and word[0] > 5\n")
+
+	multi := EventTriggerDefinition{
+		MultiEvent: multiEvent(CombineSequence, 1, 10, LogCondition{Contract: dslAddress, Condition: definition.Condition}),
+	}
+	multi.MultiEvent.Conditions[0].Condition = And(
+		Leaf(definition.LogPredicates[0]),
+		Leaf(definition.LogPredicates[1]),
+	)
+	event, err := ParseEventSignature(transferSignature)
+	assert.NilError(t, err)
+	assert.Equal(t, ExplainEventTrigger(&multi, []*abi.Event{event}),
+		"the following conditions in sequence within 10 blocks\n"+
+			"0: contract "+dslAddress.Hex()+"\n"+transferSignature+" where value > 5\n")
+}
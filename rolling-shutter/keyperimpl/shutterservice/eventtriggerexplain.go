@@ -0,0 +1,272 @@
+package shutterservice
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ExplainEventTrigger describes the definition in the event trigger DSL, see CompileEventTrigger.
+//
+// Conditions requiring the id of one of the given events in the first topic are shown with the
+// event's signature and argument names, all others with raw references to topics and data
+// words. Predicates that the DSL can't express, e.g., signed comparisons of raw words, are shown
+// with the operator closest to their meaning, so the result may not compile back to the same
+// definition.
+func ExplainEventTrigger(definition *EventTriggerDefinition, events []*abi.Event) string {
+	if definition.MultiEvent == nil {
+		return fmt.Sprintf("contract %s\n%s\n", definition.Contract.Hex(),
+			explainCondition(definition.LogPredicates, definition.Condition, events))
+	}
+	m := definition.MultiEvent
+	var b strings.Builder
+	switch m.Combinator {
+	case CombineAll:
+		fmt.Fprintf(&b, "all of the following conditions, each matching at least %d times", m.Count)
+	case CombineSequence:
+		b.WriteString("the following conditions in sequence")
+	}
+	if m.Window != 0 {
+		fmt.Fprintf(&b, " within %d blocks", m.Window)
+	}
+	b.WriteString("\n")
+	for i, condition := range m.Conditions {
+		fmt.Fprintf(&b, "%d: contract %s\n%s\n", i, condition.Contract.Hex(), explainCondition(nil, condition.Condition, events))
+	}
+	return b.String()
+}
+
+// explainCondition describes the conjunction of the log predicates, or the predicate tree.
+func explainCondition(logPredicates []LogPredicate, condition *PredicateNode, events []*abi.Event) string {
+	conjuncts := []*PredicateNode{}
+	for _, logPredicate := range logPredicates {
+		conjuncts = append(conjuncts, Leaf(logPredicate))
+	}
+	switch {
+	case condition != nil && condition.Kind == NodeAnd:
+		conjuncts = append(conjuncts, condition.Children...)
+	case condition != nil:
+		conjuncts = append(conjuncts, condition)
+	}
+
+	e := explainer{args: map[LogValueRef]dslArg{}}
+	signature := ""
+	for i, conjunct := range conjuncts {
+		if event := eventOf(conjunct, events); event != nil {
+			signature = formatSignature(event)
+			e.addEventArgs(event)
+			conjuncts = append(conjuncts[:i:i], conjuncts[i+1:]...)
+			break
+		}
+	}
+
+	explained := []string{}
+	for _, conjunct := range conjuncts {
+		explained = append(explained, e.explain(conjunct, precedenceAnd))
+	}
+	switch {
+	case len(explained) == 0:
+		return signature
+	case signature == "":
+		return "where " + strings.Join(explained, " and ")
+	default:
+		return signature + " where " + strings.Join(explained, " and ")
+	}
+}
+
+// eventOf returns the event whose id the node requires in the first topic.
+func eventOf(node *PredicateNode, events []*abi.Event) *abi.Event {
+	if node.Kind != NodeLeaf || node.Leaf.LogValueRef != (LogValueRef{}) || node.Leaf.ValuePredicate.Op != BytesEq {
+		return nil
+	}
+	for _, event := range events {
+		if bytes.Equal(node.Leaf.ValuePredicate.ByteArgs[0], event.ID.Bytes()) {
+			return event
+		}
+	}
+	return nil
+}
+
+func formatSignature(event *abi.Event) string {
+	inputs := []string{}
+	for _, input := range event.Inputs {
+		parts := []string{input.Type.String()}
+		if input.Indexed {
+			parts = append(parts, "indexed")
+		}
+		if input.Name != "" {
+			parts = append(parts, input.Name)
+		}
+		inputs = append(inputs, strings.Join(parts, " "))
+	}
+	return fmt.Sprintf("%s(%s)", event.RawName, strings.Join(inputs, ", "))
+}
+
+const (
+	precedenceOr = iota
+	precedenceAnd
+	precedenceNot
+)
+
+type explainer struct {
+	args map[LogValueRef]dslArg
+}
+
+func (e explainer) addEventArgs(event *abi.Event) {
+	args, err := eventArgs(event)
+	if err != nil {
+		return
+	}
+	for _, arg := range args {
+		if arg.Name != "" {
+			e.args[arg.Ref] = arg
+		}
+	}
+}
+
+// arg returns the named argument referenced by ref, or the raw topic or data word.
+func (e explainer) arg(ref LogValueRef) dslArg {
+	if arg, ok := e.args[ref]; ok {
+		return arg
+	}
+	if ref.Dynamic {
+		return dslArg{Ref: ref, Type: bytesType}
+	}
+	return dslArg{Ref: ref, Type: uint256Type}
+}
+
+// explain describes the tree rooted at the node, in parentheses if it is part of an expression
+// binding more tightly.
+func (e explainer) explain(node *PredicateNode, precedence int) string {
+	var s string
+	var own int
+	switch node.Kind {
+	case NodeLeaf:
+		return e.explainLeaf(node.Leaf)
+	case NodeAnd:
+		s, own = e.explainChildren(node.Children, " and ", precedenceAnd), precedenceAnd
+	case NodeOr:
+		s, own = e.explainChildren(node.Children, " or ", precedenceOr), precedenceOr
+	case NodeNot:
+		child := node.Children[0]
+		if child.Kind == NodeLeaf {
+			if negated := e.explainNegatedLeaf(child.Leaf); negated != "" {
+				return negated
+			}
+		}
+		return "not " + e.explain(child, precedenceNot)
+	}
+	if own < precedence {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func (e explainer) explainChildren(children []*PredicateNode, separator string, precedence int) string {
+	explained := []string{}
+	for _, child := range children {
+		explained = append(explained, e.explain(child, precedence+1))
+	}
+	return strings.Join(explained, separator)
+}
+
+// explainNegatedLeaf describes negated predicates that have an operator of their own in the DSL,
+// or returns an empty string.
+func (e explainer) explainNegatedLeaf(p *LogPredicate) string {
+	arg := e.arg(p.LogValueRef)
+	name := e.name(arg)
+	args := p.ValuePredicate.IntArgs
+	switch {
+	case p.ValuePredicate.Op == BytesEq:
+		return fmt.Sprintf("%s != %s", name, e.value(arg, p.ValuePredicate.ByteArgs[0]))
+	case p.ValuePredicate.Op == IntGt && arg.Type.T == abi.IntTy:
+		return fmt.Sprintf("%s <= %s", name, args[0])
+	case p.ValuePredicate.Op == IntLt && arg.Type.T == abi.IntTy:
+		return fmt.Sprintf("%s >= %s", name, args[0])
+	}
+	return ""
+}
+
+var comparisonOperators = map[Op]string{
+	UintLt:  "<",
+	UintLte: "<=",
+	UintEq:  "==",
+	UintGt:  ">",
+	UintGte: ">=",
+	IntLt:   "<",
+	IntGt:   ">",
+}
+
+func (e explainer) explainLeaf(p *LogPredicate) string {
+	arg := e.arg(p.LogValueRef)
+	name := e.name(arg)
+	predicate := p.ValuePredicate
+	if operator, ok := comparisonOperators[predicate.Op]; ok {
+		return fmt.Sprintf("%s %s %s", name, operator, predicate.IntArgs[0])
+	}
+	if predicate.Op == UintInRange {
+		return fmt.Sprintf("%s between %s and %s", name, predicate.IntArgs[0], predicate.IntArgs[1])
+	}
+	if predicate.Op == BitMaskAll || predicate.Op == BitMaskAny {
+		operator := map[Op]string{BitMaskAll: "hasall", BitMaskAny: "hasany"}[predicate.Op]
+		return fmt.Sprintf("%s %s %s", name, operator, hexutil.EncodeBig(predicate.IntArgs[0]))
+	}
+	if predicate.Op == BytesIn {
+		elementLength := int(predicate.IntArgs[0].Int64())
+		set := predicate.ByteArgs[0]
+		elements := []string{}
+		for i := 0; i+elementLength <= len(set); i += elementLength {
+			elements = append(elements, e.value(arg, set[i:i+elementLength]))
+		}
+		return fmt.Sprintf("%s in [%s]", name, strings.Join(elements, ", "))
+	}
+	operator := map[Op]string{BytesEq: "==", BytesPrefix: "prefix", BytesContains: "contains"}[predicate.Op]
+	return fmt.Sprintf("%s %s %s", name, operator, e.value(arg, predicate.ByteArgs[0]))
+}
+
+func (e explainer) name(arg dslArg) string {
+	if arg.Name != "" {
+		return arg.Name
+	}
+	return arg.rawName()
+}
+
+// value describes the value of the argument as a literal of its type where possible, and as hex
+// value otherwise.
+func (e explainer) value(arg dslArg, value []byte) string {
+	if !arg.isWord() {
+		if arg.Type.T == abi.StringTy && !arg.Hashed && isPrintable(value) {
+			return strconv.Quote(string(value))
+		}
+		return hexutil.Encode(value)
+	}
+	if len(value) != Word || arg.Name == "" {
+		return hexutil.Encode(value)
+	}
+	n := new(big.Int).SetBytes(value)
+	switch arg.Type.T {
+	case abi.UintTy:
+		return n.String()
+	case abi.IntTy:
+		return toSigned(value).String()
+	case abi.BoolTy:
+		if n.Cmp(big.NewInt(1)) <= 0 {
+			return strconv.FormatBool(n.Sign() == 1)
+		}
+	case abi.AddressTy:
+		if n.BitLen() <= 8*common.AddressLength {
+			return common.BytesToAddress(value).Hex()
+		}
+	case abi.FixedBytesTy:
+		if bytes.Equal(value[arg.Type.Size:], make([]byte, Word-arg.Type.Size)) {
+			return hexutil.Encode(value[:arg.Type.Size])
+		}
+	}
+	return hexutil.Encode(value)
+}
@@ -0,0 +1,240 @@
+package shutterservice
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// MaxLogConditions is the maximum number of log conditions of a multi event trigger.
+	MaxLogConditions = 8
+	// MaxEventCount is the maximum number of times each condition of a multi event trigger can be
+	// required to match.
+	MaxEventCount = 64
+)
+
+// Combinator enumerates the ways the log conditions of a multi event trigger are combined.
+type Combinator uint64
+
+const (
+	// CombineAll requires each condition to match at least Count times, in any order.
+	CombineAll Combinator = iota
+	// CombineSequence requires the conditions to match in the order they are listed, each one in a
+	// log after the log matching the previous condition.
+	CombineSequence
+)
+
+func (c Combinator) Validate() error {
+	switch c {
+	case CombineAll, CombineSequence:
+		return nil
+	default:
+		return fmt.Errorf("invalid combinator: %d", c)
+	}
+}
+
+// MultiEventCondition is the condition of a version 4 event trigger definition. In contrast to
+// the other versions, it is not satisfied by a single log, but by a set of logs, possibly
+// emitted by different contracts and in different blocks.
+//
+// If Window is not zero, all logs of the set must be emitted within Window blocks, i.e., the
+// block number of the last one must not exceed the one of the first one by more than Window.
+type MultiEventCondition struct {
+	Combinator Combinator
+	Count      uint64
+	Window     uint64
+	Conditions []LogCondition
+}
+
+// LogCondition is a condition on a single log of a multi event trigger.
+type LogCondition struct {
+	Contract  common.Address
+	Condition *PredicateNode
+}
+
+// EventMatch is a log matching one of the conditions of a multi event trigger.
+type EventMatch struct {
+	Condition   uint64
+	BlockNumber uint64
+	TxIndex     uint64
+	LogIndex    uint64
+}
+
+// Validate checks if the multi event condition is valid.
+//
+// It is valid if
+//   - the combinator is valid,
+//   - there are between 1 and MaxLogConditions conditions, each with a valid predicate tree, and
+//   - Count is between 1 and MaxEventCount, and 1 for sequences.
+func (m *MultiEventCondition) Validate() error {
+	if err := m.Combinator.Validate(); err != nil {
+		return err
+	}
+	if len(m.Conditions) == 0 || len(m.Conditions) > MaxLogConditions {
+		return fmt.Errorf("number of log conditions must be between 1 and %d, got %d", MaxLogConditions, len(m.Conditions))
+	}
+	if m.Count == 0 || m.Count > MaxEventCount {
+		return fmt.Errorf("count must be between 1 and %d, got %d", MaxEventCount, m.Count)
+	}
+	if m.Combinator == CombineSequence && m.Count != 1 {
+		return fmt.Errorf("count must be 1 for sequences, got %d", m.Count)
+	}
+	for i, condition := range m.Conditions {
+		if err := condition.Condition.Validate(); err != nil {
+			return fmt.Errorf("invalid log condition %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (c *LogCondition) definition() *EventTriggerDefinition {
+	return &EventTriggerDefinition{Contract: c.Contract, Condition: c.Condition}
+}
+
+// ToFilterQuery creates a filter query for the logs that may match the condition, see
+// EventTriggerDefinition.ToFilterQuery.
+func (c *LogCondition) ToFilterQuery() (ethereum.FilterQuery, error) {
+	return c.definition().ToFilterQuery()
+}
+
+// Match checks if the log matches the condition, see EventTriggerDefinition.Match.
+//
+// This may panic if Validate does not pass.
+func (c *LogCondition) Match(log *types.Log, maxDynamicLength uint64) (bool, error) {
+	return c.definition().Match(log, maxDynamicLength)
+}
+
+// Satisfied checks if the given matches satisfy the multi event condition.
+//
+// This may panic if Validate does not pass.
+func (m *MultiEventCondition) Satisfied(matches []EventMatch) bool {
+	matches = sortMatches(matches)
+	switch m.Combinator {
+	case CombineAll:
+		return m.satisfiedAll(matches)
+	case CombineSequence:
+		return m.satisfiedSequence(matches)
+	}
+	return false
+}
+
+// sortMatches returns a copy of the matches sorted by the position of their logs in the chain.
+// Matches of the same log are sorted by descending condition, so that in a sequence the log
+// can't match a condition and the one following it.
+func sortMatches(matches []EventMatch) []EventMatch {
+	sorted := make([]EventMatch, len(matches))
+	copy(sorted, matches)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return matchesBefore(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// matchesBefore defines the order of sortMatches.
+func matchesBefore(a, b EventMatch) bool {
+	if a.BlockNumber != b.BlockNumber {
+		return a.BlockNumber < b.BlockNumber
+	}
+	if a.TxIndex != b.TxIndex {
+		return a.TxIndex < b.TxIndex
+	}
+	if a.LogIndex != b.LogIndex {
+		return a.LogIndex < b.LogIndex
+	}
+	return a.Condition > b.Condition
+}
+
+// satisfiedAll checks if there is a window in which each condition matched Count times.
+func (m *MultiEventCondition) satisfiedAll(matches []EventMatch) bool {
+	counts := make([]uint64, len(m.Conditions))
+	start := 0
+	for _, match := range matches {
+		if match.Condition >= uint64(len(m.Conditions)) {
+			continue
+		}
+		counts[match.Condition]++
+		for m.Window != 0 && matches[start].BlockNumber+m.Window < match.BlockNumber {
+			if matches[start].Condition < uint64(len(m.Conditions)) {
+				counts[matches[start].Condition]--
+			}
+			start++
+		}
+		if m.allCounted(counts) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MultiEventCondition) allCounted(counts []uint64) bool {
+	for _, count := range counts {
+		if count < m.Count {
+			return false
+		}
+	}
+	return true
+}
+
+// satisfiedSequence checks if the conditions matched in order within the window. For each
+// condition, it tracks the latest block in which a sequence of matches of all conditions up to
+// and including it started, as later starts leave more room for the following conditions.
+func (m *MultiEventCondition) satisfiedSequence(matches []EventMatch) bool {
+	starts := make([]*uint64, len(m.Conditions))
+	for _, match := range matches {
+		i := match.Condition
+		if i >= uint64(len(m.Conditions)) {
+			continue
+		}
+		var start uint64
+		if i == 0 {
+			start = match.BlockNumber
+		} else {
+			if starts[i-1] == nil {
+				continue
+			}
+			start = *starts[i-1]
+			if m.Window != 0 && start+m.Window < match.BlockNumber {
+				continue
+			}
+		}
+		if starts[i] == nil || *starts[i] < start {
+			starts[i] = &start
+		}
+	}
+	return starts[len(starts)-1] != nil
+}
+
+// Relevant checks if a new match can contribute to satisfying the condition given the existing
+// ones. It is used to bound the number of matches that have to be stored for conditions without
+// a window: for them, only the first Count matches of each condition, or the first match
+// continuing a sequence, are relevant. With a window, any match is.
+func (m *MultiEventCondition) Relevant(existing []EventMatch, match EventMatch) bool {
+	if match.Condition >= uint64(len(m.Conditions)) {
+		return false
+	}
+	if m.Window != 0 {
+		return true
+	}
+	counts := make([]uint64, len(m.Conditions))
+	for _, e := range existing {
+		if e.Condition < uint64(len(m.Conditions)) {
+			counts[e.Condition]++
+		}
+	}
+	switch m.Combinator {
+	case CombineAll:
+		return counts[match.Condition] < m.Count
+	case CombineSequence:
+		// Matches are only stored if they continue the sequence, so each stored match is preceded
+		// by a match of the previous condition.
+		if counts[match.Condition] != 0 {
+			return false
+		}
+		return match.Condition == 0 || counts[match.Condition-1] != 0
+	}
+	return false
+}
@@ -0,0 +1,367 @@
+package shutterservice
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/assert"
+)
+
+var otherContract = common.HexToAddress("0x9999999999999999999999999999999999999999")
+
+func transferCondition(contract common.Address) LogCondition {
+	return LogCondition{Contract: contract, Condition: topicEq(0, transferTopic)}
+}
+
+func multiEvent(combinator Combinator, count, window uint64, conditions ...LogCondition) *MultiEventCondition {
+	return &MultiEventCondition{Combinator: combinator, Count: count, Window: window, Conditions: conditions}
+}
+
+func TestMultiEventConditionValidate(t *testing.T) {
+	tooMany := []LogCondition{}
+	for i := 0; i < MaxLogConditions+1; i++ {
+		tooMany = append(tooMany, transferCondition(treeContract))
+	}
+
+	tests := []struct {
+		name       string
+		definition EventTriggerDefinition
+		errMsg     string
+	}{
+		{
+			name: "all of two contracts",
+			definition: EventTriggerDefinition{
+				MultiEvent: multiEvent(CombineAll, 1, 0, transferCondition(treeContract), transferCondition(otherContract)),
+			},
+		},
+		{
+			name:       "event occurring N times",
+			definition: EventTriggerDefinition{MultiEvent: multiEvent(CombineAll, MaxEventCount, 0, transferCondition(treeContract))},
+		},
+		{
+			name: "sequence within window",
+			definition: EventTriggerDefinition{
+				MultiEvent: multiEvent(CombineSequence, 1, 10, transferCondition(treeContract), transferCondition(otherContract)),
+			},
+		},
+		{
+			name:       "invalid combinator",
+			definition: EventTriggerDefinition{MultiEvent: multiEvent(2, 1, 0, transferCondition(treeContract))},
+			errMsg:     "invalid combinator: 2",
+		},
+		{
+			name:       "no conditions",
+			definition: EventTriggerDefinition{MultiEvent: multiEvent(CombineAll, 1, 0)},
+			errMsg:     "number of log conditions must be between 1 and 8, got 0",
+		},
+		{
+			name:       "too many conditions",
+			definition: EventTriggerDefinition{MultiEvent: multiEvent(CombineAll, 1, 0, tooMany...)},
+			errMsg:     "number of log conditions must be between 1 and 8, got 9",
+		},
+		{
+			name:       "zero count",
+			definition: EventTriggerDefinition{MultiEvent: multiEvent(CombineAll, 0, 0, transferCondition(treeContract))},
+			errMsg:     "count must be between 1 and 64, got 0",
+		},
+		{
+			name:       "too large count",
+			definition: EventTriggerDefinition{MultiEvent: multiEvent(CombineAll, MaxEventCount+1, 0, transferCondition(treeContract))},
+			errMsg:     "count must be between 1 and 64, got 65",
+		},
+		{
+			name: "sequence with count",
+			definition: EventTriggerDefinition{
+				MultiEvent: multiEvent(CombineSequence, 2, 0, transferCondition(treeContract), transferCondition(otherContract)),
+			},
+			errMsg: "count must be 1 for sequences, got 2",
+		},
+		{
+			name: "condition without predicate tree",
+			definition: EventTriggerDefinition{
+				MultiEvent: multiEvent(CombineAll, 1, 0, LogCondition{Contract: treeContract}),
+			},
+			errMsg: "invalid log condition 0: predicate node must not be nil",
+		},
+		{
+			name: "contract besides multi event condition",
+			definition: EventTriggerDefinition{
+				Contract:   treeContract,
+				MultiEvent: multiEvent(CombineAll, 1, 0, transferCondition(treeContract)),
+			},
+			errMsg: "multi event definition must only have a multi event condition",
+		},
+		{
+			name: "predicate tree besides multi event condition",
+			definition: EventTriggerDefinition{
+				Condition:  topicEq(0, transferTopic),
+				MultiEvent: multiEvent(CombineAll, 1, 0, transferCondition(treeContract)),
+			},
+			errMsg: "multi event definition must only have a multi event condition",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.definition.Validate()
+			if tt.errMsg == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestMultiEventMarshalUnmarshal(t *testing.T) {
+	definition := EventTriggerDefinition{
+		MultiEvent: multiEvent(CombineSequence, 1, 100,
+			LogCondition{
+				Contract:  treeContract,
+				Condition: And(topicEq(0, transferTopic), Or(topicEq(2, recipientA), topicEq(2, recipientB))),
+			},
+			LogCondition{Contract: otherContract, Condition: Not(amountEq(0))},
+		),
+	}
+	marshaled := definition.MarshalBytes()
+	assert.Equal(t, marshaled[0], byte(VersionMultiEvent))
+
+	var unmarshaled EventTriggerDefinition
+	assert.NilError(t, unmarshaled.UnmarshalBytes(marshaled))
+	assert.Assert(t, unmarshaled.Condition == nil)
+	assert.Equal(t, unmarshaled.MultiEvent.Combinator, CombineSequence)
+	assert.Equal(t, unmarshaled.MultiEvent.Count, uint64(1))
+	assert.Equal(t, unmarshaled.MultiEvent.Window, uint64(100))
+	assert.Equal(t, len(unmarshaled.MultiEvent.Conditions), 2)
+	assert.Equal(t, unmarshaled.MultiEvent.Conditions[1].Contract, otherContract)
+	assert.Assert(t, bytes.Equal(unmarshaled.MarshalBytes(), marshaled))
+
+	// multi event definitions don't have a contract
+	var withContract EventTriggerDefinition
+	assert.NilError(t, withContract.UnmarshalBytes(marshaled))
+	withContract.Contract = treeContract
+	assert.ErrorContains(t, withContract.Validate(), "must only have a multi event condition")
+
+	// there is neither a single filter query, nor a single log matching the definition
+	_, err := unmarshaled.ToFilterQuery()
+	assert.ErrorContains(t, err, "one filter query per log condition")
+	_, err = unmarshaled.Match(transferLog(recipientA, 1), DefaultMaxDynamicValueLength)
+	assert.ErrorContains(t, err, "can't be matched against a single log")
+}
+
+func TestMultiEventUnmarshalErrors(t *testing.T) {
+	invalid := EventTriggerDefinition{MultiEvent: multiEvent(CombineAll, 0, 0, transferCondition(treeContract))}
+	tooDeep := EventTriggerDefinition{
+		MultiEvent: multiEvent(CombineAll, 1, 0, LogCondition{Contract: treeContract, Condition: deepTree(MaxPredicateTreeDepth + 1)}),
+	}
+
+	for _, data := range [][]byte{
+		{VersionMultiEvent},
+		{VersionMultiEvent, 0xff, 0xff},
+		invalid.MarshalBytes(),
+		tooDeep.MarshalBytes(),
+	} {
+		var definition EventTriggerDefinition
+		assert.Assert(t, definition.UnmarshalBytes(data) != nil)
+	}
+}
+
+func TestLogConditionMatch(t *testing.T) {
+	condition := LogCondition{Contract: treeContract, Condition: Or(topicEq(2, recipientA), topicEq(2, recipientB))}
+	query, err := condition.ToFilterQuery()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, query.Addresses, []common.Address{treeContract})
+	assert.DeepEqual(t, query.Topics, [][]common.Hash{{}, {}, {recipientA, recipientB}})
+
+	match, err := condition.Match(transferLog(recipientB, 1), DefaultMaxDynamicValueLength)
+	assert.NilError(t, err)
+	assert.Assert(t, match)
+	otherLog := &types.Log{Address: otherContract, Topics: []common.Hash{transferTopic, {}, recipientA}}
+	match, err = condition.Match(otherLog, DefaultMaxDynamicValueLength)
+	assert.NilError(t, err)
+	assert.Assert(t, !match)
+}
+
+func eventMatch(condition, blockNumber, logIndex uint64) EventMatch {
+	return EventMatch{Condition: condition, BlockNumber: blockNumber, LogIndex: logIndex}
+}
+
+func TestMultiEventConditionSatisfied(t *testing.T) {
+	a, b := transferCondition(treeContract), transferCondition(otherContract)
+	tests := []struct {
+		name      string
+		condition *MultiEventCondition
+		matches   []EventMatch
+		want      bool
+	}{
+		{
+			name:      "all matched",
+			condition: multiEvent(CombineAll, 1, 0, a, b),
+			matches:   []EventMatch{eventMatch(1, 10, 0), eventMatch(0, 20, 0)},
+			want:      true,
+		},
+		{
+			name:      "one missing",
+			condition: multiEvent(CombineAll, 1, 0, a, b),
+			matches:   []EventMatch{eventMatch(1, 10, 0), eventMatch(1, 20, 0)},
+			want:      false,
+		},
+		{
+			name:      "same log matching all conditions",
+			condition: multiEvent(CombineAll, 1, 0, a, b),
+			matches:   []EventMatch{eventMatch(0, 10, 0), eventMatch(1, 10, 0)},
+			want:      true,
+		},
+		{
+			name:      "count reached",
+			condition: multiEvent(CombineAll, 3, 0, a),
+			matches:   []EventMatch{eventMatch(0, 10, 0), eventMatch(0, 10, 1), eventMatch(0, 30, 0)},
+			want:      true,
+		},
+		{
+			name:      "count not reached",
+			condition: multiEvent(CombineAll, 3, 0, a),
+			matches:   []EventMatch{eventMatch(0, 10, 0), eventMatch(0, 30, 0)},
+			want:      false,
+		},
+		{
+			name:      "count reached within window",
+			condition: multiEvent(CombineAll, 3, 10, a),
+			matches:   []EventMatch{eventMatch(0, 1, 0), eventMatch(0, 15, 0), eventMatch(0, 20, 0), eventMatch(0, 25, 0)},
+			want:      true,
+		},
+		{
+			name:      "count not reached within window",
+			condition: multiEvent(CombineAll, 3, 10, a),
+			matches:   []EventMatch{eventMatch(0, 1, 0), eventMatch(0, 10, 0), eventMatch(0, 20, 0), eventMatch(0, 30, 0)},
+			want:      false,
+		},
+		{
+			name:      "all matched at window boundary",
+			condition: multiEvent(CombineAll, 1, 10, a, b),
+			matches:   []EventMatch{eventMatch(0, 10, 0), eventMatch(1, 20, 0)},
+			want:      true,
+		},
+		{
+			name:      "sequence in order",
+			condition: multiEvent(CombineSequence, 1, 0, a, b),
+			matches:   []EventMatch{eventMatch(0, 10, 0), eventMatch(1, 20, 0)},
+			want:      true,
+		},
+		{
+			name:      "sequence in same block",
+			condition: multiEvent(CombineSequence, 1, 0, a, b),
+			matches:   []EventMatch{eventMatch(1, 10, 2), eventMatch(0, 10, 1)},
+			want:      true,
+		},
+		{
+			name:      "sequence out of order",
+			condition: multiEvent(CombineSequence, 1, 0, a, b),
+			matches:   []EventMatch{eventMatch(1, 10, 0), eventMatch(0, 20, 0)},
+			want:      false,
+		},
+		{
+			name:      "sequence from a single log",
+			condition: multiEvent(CombineSequence, 1, 0, a, b),
+			matches:   []EventMatch{eventMatch(0, 10, 0), eventMatch(1, 10, 0)},
+			want:      false,
+		},
+		{
+			name:      "sequence outside window",
+			condition: multiEvent(CombineSequence, 1, 10, a, b),
+			matches:   []EventMatch{eventMatch(0, 10, 0), eventMatch(1, 21, 0)},
+			want:      false,
+		},
+		{
+			name:      "sequence restarted within window",
+			condition: multiEvent(CombineSequence, 1, 10, a, b, a),
+			matches: []EventMatch{
+				eventMatch(0, 10, 0), eventMatch(1, 15, 0), eventMatch(0, 15, 1), eventMatch(1, 22, 0), eventMatch(2, 24, 0),
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NilError(t, tt.condition.Validate())
+			assert.Equal(t, tt.condition.Satisfied(tt.matches), tt.want)
+		})
+	}
+}
+
+func TestMultiEventConditionRelevant(t *testing.T) {
+	a, b := transferCondition(treeContract), transferCondition(otherContract)
+	tests := []struct {
+		name      string
+		condition *MultiEventCondition
+		existing  []EventMatch
+		match     EventMatch
+		want      bool
+	}{
+		{
+			name:      "count not reached",
+			condition: multiEvent(CombineAll, 2, 0, a, b),
+			existing:  []EventMatch{eventMatch(0, 10, 0)},
+			match:     eventMatch(0, 20, 0),
+			want:      true,
+		},
+		{
+			name:      "count reached",
+			condition: multiEvent(CombineAll, 2, 0, a, b),
+			existing:  []EventMatch{eventMatch(0, 10, 0), eventMatch(0, 11, 0)},
+			match:     eventMatch(0, 20, 0),
+			want:      false,
+		},
+		{
+			name:      "count reached with window",
+			condition: multiEvent(CombineAll, 2, 5, a, b),
+			existing:  []EventMatch{eventMatch(0, 10, 0), eventMatch(0, 11, 0)},
+			match:     eventMatch(0, 20, 0),
+			want:      true,
+		},
+		{
+			name:      "first step of sequence",
+			condition: multiEvent(CombineSequence, 1, 0, a, b),
+			existing:  []EventMatch{},
+			match:     eventMatch(0, 10, 0),
+			want:      true,
+		},
+		{
+			name:      "second step of sequence before first",
+			condition: multiEvent(CombineSequence, 1, 0, a, b),
+			existing:  []EventMatch{},
+			match:     eventMatch(1, 10, 0),
+			want:      false,
+		},
+		{
+			name:      "repeated first step of sequence",
+			condition: multiEvent(CombineSequence, 1, 0, a, b),
+			existing:  []EventMatch{eventMatch(0, 10, 0)},
+			match:     eventMatch(0, 20, 0),
+			want:      false,
+		},
+		{
+			name:      "second step of sequence",
+			condition: multiEvent(CombineSequence, 1, 0, a, b),
+			existing:  []EventMatch{eventMatch(0, 10, 0)},
+			match:     eventMatch(1, 20, 0),
+			want:      true,
+		},
+		{
+			name:      "unknown condition",
+			condition: multiEvent(CombineAll, 1, 5, a, b),
+			existing:  []EventMatch{},
+			match:     eventMatch(2, 20, 0),
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.condition.Relevant(tt.existing, tt.match), tt.want)
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package shutterservice
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// MaxPredicateTreeDepth is the maximum number of nodes on a path from the root of a predicate
+	// tree to one of its leaves.
+	MaxPredicateTreeDepth = 8
+	// MaxPredicateTreeSize is the maximum number of nodes in a predicate tree.
+	MaxPredicateTreeSize = 64
+)
+
+// NodeKind enumerates the kinds of nodes in a predicate tree.
+type NodeKind uint64
+
+const (
+	// NodeLeaf nodes hold a single log predicate.
+	NodeLeaf NodeKind = iota
+	// NodeAnd nodes match if all of their children match.
+	NodeAnd
+	// NodeOr nodes match if at least one of their children matches.
+	NodeOr
+	// NodeNot nodes match if their only child does not match.
+	NodeNot
+)
+
+func (k NodeKind) Validate() error {
+	switch k {
+	case NodeLeaf, NodeAnd, NodeOr, NodeNot:
+		return nil
+	default:
+		return fmt.Errorf("invalid node kind: %d", k)
+	}
+}
+
+// PredicateNode is a node of the predicate tree of a version 3 event trigger definition. Leaves
+// hold a log predicate, inner nodes combine the results of their children.
+type PredicateNode struct {
+	Kind     NodeKind
+	Leaf     *LogPredicate
+	Children []*PredicateNode
+}
+
+// Leaf creates a leaf node holding the given log predicate.
+func Leaf(logPredicate LogPredicate) *PredicateNode {
+	return &PredicateNode{Kind: NodeLeaf, Leaf: &logPredicate}
+}
+
+// And creates a node matching if all of the given nodes match.
+func And(children ...*PredicateNode) *PredicateNode {
+	return &PredicateNode{Kind: NodeAnd, Children: children}
+}
+
+// Or creates a node matching if at least one of the given nodes matches.
+func Or(children ...*PredicateNode) *PredicateNode {
+	return &PredicateNode{Kind: NodeOr, Children: children}
+}
+
+// Not creates a node matching if the given node does not match.
+func Not(child *PredicateNode) *PredicateNode {
+	return &PredicateNode{Kind: NodeNot, Children: []*PredicateNode{child}}
+}
+
+// Validate checks if the tree rooted at the node is valid.
+//
+// A tree is valid if
+//   - its depth and size are within MaxPredicateTreeDepth and MaxPredicateTreeSize,
+//   - leaves hold a valid log predicate and have no children,
+//   - AND and OR nodes have at least one child, NOT nodes have exactly one, and
+//   - BytesEq predicates on topics compare against 32-byte values.
+func (n *PredicateNode) Validate() error {
+	size := 0
+	return n.validate(1, &size)
+}
+
+func (n *PredicateNode) validate(depth int, size *int) error {
+	if n == nil {
+		return fmt.Errorf("predicate node must not be nil")
+	}
+	if depth > MaxPredicateTreeDepth {
+		return fmt.Errorf("predicate tree exceeds maximum depth of %d", MaxPredicateTreeDepth)
+	}
+	*size++
+	if *size > MaxPredicateTreeSize {
+		return fmt.Errorf("predicate tree exceeds maximum size of %d nodes", MaxPredicateTreeSize)
+	}
+	if err := n.Kind.Validate(); err != nil {
+		return err
+	}
+
+	if n.Kind == NodeLeaf {
+		return n.validateLeaf()
+	}
+
+	if n.Leaf != nil {
+		return fmt.Errorf("node of kind %d must not hold a log predicate", n.Kind)
+	}
+	if n.Kind == NodeNot && len(n.Children) != 1 {
+		return fmt.Errorf("NOT node must have exactly one child, got %d", len(n.Children))
+	}
+	if len(n.Children) == 0 {
+		return fmt.Errorf("node of kind %d must have at least one child", n.Kind)
+	}
+	for i, child := range n.Children {
+		if err := child.validate(depth+1, size); err != nil {
+			return fmt.Errorf("invalid child %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (n *PredicateNode) validateLeaf() error {
+	if n.Leaf == nil {
+		return fmt.Errorf("leaf node must hold a log predicate")
+	}
+	if len(n.Children) != 0 {
+		return fmt.Errorf("leaf node must not have children, got %d", len(n.Children))
+	}
+	if err := n.Leaf.Validate(); err != nil {
+		return err
+	}
+	ref, predicate := n.Leaf.LogValueRef, n.Leaf.ValuePredicate
+	if ref.IsTopic() && predicate.Op == BytesEq && len(predicate.ByteArgs[0]) != Word {
+		return fmt.Errorf("BytesEq predicate for topic %d must have a 32-byte value, got %d bytes",
+			ref.Offset, len(predicate.ByteArgs[0]))
+	}
+	return nil
+}
+
+// Match evaluates the tree rooted at the node for the given log, see
+// EventTriggerDefinition.Match.
+//
+// This may panic if Validate does not pass.
+func (n *PredicateNode) Match(log *types.Log, maxDynamicLength uint64) (bool, error) {
+	switch n.Kind {
+	case NodeLeaf:
+		return n.Leaf.Match(log, maxDynamicLength)
+	case NodeAnd:
+		for _, child := range n.Children {
+			match, err := child.Match(log, maxDynamicLength)
+			if err != nil || !match {
+				return false, err
+			}
+		}
+		return true, nil
+	case NodeOr:
+		for _, child := range n.Children {
+			match, err := child.Match(log, maxDynamicLength)
+			if err != nil || match {
+				return match, err
+			}
+		}
+		return false, nil
+	case NodeNot:
+		match, err := n.Children[0].Match(log, maxDynamicLength)
+		if err != nil {
+			return false, err
+		}
+		return !match, nil
+	}
+	return false, fmt.Errorf("unknown node kind %d", n.Kind)
+}
+
+// topicConstraints maps topic indices to the set of values the topic must have for a tree to
+// match. Topics that are not in the map are unconstrained.
+type topicConstraints map[uint64]map[common.Hash]struct{}
+
+// topicConstraints derives the constraints on the log topics that are implied by the tree rooted
+// at the node. They are necessary, but not sufficient for the tree to match:
+//   - BytesEq predicates on topics constrain the topic to their argument,
+//   - BytesIn predicates on topics constrain the topic to their set if its elements are 32 bytes long,
+//   - AND nodes intersect the constraints of their children,
+//   - OR nodes unite the constraints on topics that are constrained by all of their children, and
+//   - NOT nodes and all other predicates don't impose any constraints.
+func (n *PredicateNode) topicConstraints() topicConstraints {
+	constraints := topicConstraints{}
+	switch n.Kind {
+	case NodeLeaf:
+		if values := n.Leaf.topicValues(); values != nil {
+			constraints[n.Leaf.LogValueRef.Offset] = values
+		}
+	case NodeAnd:
+		for _, child := range n.Children {
+			constraints.intersect(child.topicConstraints())
+		}
+	case NodeOr:
+		constraints = n.Children[0].topicConstraints()
+		for _, child := range n.Children[1:] {
+			constraints.unite(child.topicConstraints())
+		}
+	case NodeNot:
+		// negations can't be expressed in topic filters
+	}
+	return constraints
+}
+
+// intersect restricts the constraints to the values allowed by the other constraints as well.
+func (c topicConstraints) intersect(other topicConstraints) {
+	for index, values := range other {
+		existing, ok := c[index]
+		if !ok {
+			c[index] = values
+			continue
+		}
+		intersection := map[common.Hash]struct{}{}
+		for value := range values {
+			if _, ok := existing[value]; ok {
+				intersection[value] = struct{}{}
+			}
+		}
+		// An empty topic list would match any topic, so in the unsatisfiable case we keep the
+		// looser constraint and leave it to Match to reject the logs.
+		if len(intersection) > 0 {
+			c[index] = intersection
+		}
+	}
+}
+
+// unite relaxes the constraints to allow the values allowed by the other constraints as well.
+// Topics unconstrained by either of them become unconstrained.
+func (c topicConstraints) unite(other topicConstraints) {
+	for index, values := range c {
+		otherValues, ok := other[index]
+		if !ok {
+			delete(c, index)
+			continue
+		}
+		for value := range otherValues {
+			values[value] = struct{}{}
+		}
+	}
+}
+
+// topics converts the constraints into the topics field of a filter query.
+func (c topicConstraints) topics() [][]common.Hash {
+	topics := [][]common.Hash{}
+	for index, values := range c {
+		for uint64(len(topics)) <= index {
+			topics = append(topics, []common.Hash{})
+		}
+		for value := range values {
+			topics[index] = append(topics[index], value)
+		}
+		sort.Slice(topics[index], func(i, j int) bool {
+			return bytes.Compare(topics[index][i][:], topics[index][j][:]) < 0
+		})
+	}
+	return topics
+}
+
+// EncodeRLP encodes the node as a list starting with its kind, followed by the log predicate for
+// leaves and the children for all other nodes.
+func (n *PredicateNode) EncodeRLP(w io.Writer) error {
+	elements := []interface{}{uint64(n.Kind)}
+	if n.Kind == NodeLeaf {
+		elements = append(elements, n.Leaf)
+	} else {
+		for _, child := range n.Children {
+			elements = append(elements, child)
+		}
+	}
+	return rlp.Encode(w, elements)
+}
+
+func (n *PredicateNode) DecodeRLP(s *rlp.Stream) error {
+	size := 0
+	return n.decodeRLP(s, 1, &size)
+}
+
+// decodeRLP decodes the node and its children, enforcing the depth and size limits while doing so
+// to not recurse arbitrarily deep on malicious input.
+func (n *PredicateNode) decodeRLP(s *rlp.Stream, depth int, size *int) error {
+	if depth > MaxPredicateTreeDepth {
+		return fmt.Errorf("predicate tree exceeds maximum depth of %d", MaxPredicateTreeDepth)
+	}
+	*size++
+	if *size > MaxPredicateTreeSize {
+		return fmt.Errorf("predicate tree exceeds maximum size of %d nodes", MaxPredicateTreeSize)
+	}
+
+	if _, err := s.List(); err != nil {
+		return fmt.Errorf("failed to decode PredicateNode: %w", err)
+	}
+	kindInt, err := s.Uint64()
+	if err != nil {
+		return fmt.Errorf("failed to read kind of PredicateNode: %w", err)
+	}
+	kind := NodeKind(kindInt)
+	if err := kind.Validate(); err != nil {
+		return err
+	}
+
+	var leaf *LogPredicate
+	children := []*PredicateNode{}
+	if kind == NodeLeaf {
+		leaf = new(LogPredicate)
+		if err := s.Decode(leaf); err != nil {
+			return fmt.Errorf("failed to decode log predicate of leaf: %w", err)
+		}
+	} else {
+		for {
+			if _, _, err := s.Kind(); errors.Is(err, rlp.EOL) {
+				break
+			}
+			child := new(PredicateNode)
+			if err := child.decodeRLP(s, depth+1, size); err != nil {
+				return err
+			}
+			children = append(children, child)
+		}
+	}
+
+	if err := s.ListEnd(); err != nil {
+		return fmt.Errorf("failed to decode PredicateNode: %w", err)
+	}
+
+	n.Kind = kind
+	n.Leaf = leaf
+	n.Children = children
+	return nil
+}
+
+// topicValues returns the values a topic must have for the log predicate to match, or nil if the
+// predicate doesn't constrain a topic to a set of values.
+func (p *LogPredicate) topicValues() map[common.Hash]struct{} {
+	if !p.LogValueRef.IsTopic() {
+		return nil
+	}
+	predicate := p.ValuePredicate
+	switch {
+	case predicate.Op == BytesEq:
+		return map[common.Hash]struct{}{common.BytesToHash(predicate.ByteArgs[0]): {}}
+	case predicate.Op == BytesIn && predicate.IntArgs[0].Cmp(big.NewInt(Word)) == 0:
+		values := map[common.Hash]struct{}{}
+		for i := 0; i < len(predicate.ByteArgs[0]); i += Word {
+			values[common.BytesToHash(predicate.ByteArgs[0][i:i+Word])] = struct{}{}
+		}
+		return values
+	default:
+		return nil
+	}
+}
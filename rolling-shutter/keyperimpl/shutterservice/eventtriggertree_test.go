@@ -0,0 +1,380 @@
+package shutterservice
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"gotest.tools/assert"
+)
+
+var (
+	treeContract  = common.HexToAddress("0x1234567890123456789012345678901234567890")
+	transferTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	recipientA    = common.HexToHash("0x000000000000000000000000aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	recipientB    = common.HexToHash("0x000000000000000000000000bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+	recipientC    = common.HexToHash("0x000000000000000000000000cccccccccccccccccccccccccccccccccccccccc")
+)
+
+func topicEq(index uint64, value common.Hash) *PredicateNode {
+	return Leaf(LogPredicate{
+		LogValueRef: LogValueRef{Offset: index},
+		ValuePredicate: ValuePredicate{
+			Op:       BytesEq,
+			IntArgs:  []*big.Int{},
+			ByteArgs: [][]byte{value.Bytes()},
+		},
+	})
+}
+
+func amountEq(amount int64) *PredicateNode {
+	return Leaf(LogPredicate{
+		LogValueRef: LogValueRef{Offset: 4},
+		ValuePredicate: ValuePredicate{
+			Op:       UintEq,
+			IntArgs:  []*big.Int{big.NewInt(amount)},
+			ByteArgs: [][]byte{},
+		},
+	})
+}
+
+func transferLog(recipient common.Hash, amount int64) *types.Log {
+	return &types.Log{
+		Address: treeContract,
+		Topics:  []common.Hash{transferTopic, common.Hash{}, recipient},
+		Data:    common.BigToHash(big.NewInt(amount)).Bytes(),
+	}
+}
+
+// deepTree creates a chain of NOT nodes with the given number of nodes in total.
+func deepTree(depth int) *PredicateNode {
+	node := amountEq(0)
+	for i := 1; i < depth; i++ {
+		node = Not(node)
+	}
+	return node
+}
+
+func wideTree(size int) *PredicateNode {
+	children := []*PredicateNode{}
+	for i := 1; i < size; i++ {
+		children = append(children, amountEq(int64(i)))
+	}
+	return Or(children...)
+}
+
+func TestPredicateNodeValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    *PredicateNode
+		wantErr bool
+	}{
+		{
+			name: "leaf",
+			node: amountEq(0),
+		},
+		{
+			name: "nested groups",
+			node: And(topicEq(0, transferTopic), Or(topicEq(2, recipientA), Not(amountEq(0)))),
+		},
+		{
+			name: "maximum depth",
+			node: deepTree(MaxPredicateTreeDepth),
+		},
+		{
+			name: "maximum size",
+			node: wideTree(MaxPredicateTreeSize),
+		},
+		{
+			name:    "too deep",
+			node:    deepTree(MaxPredicateTreeDepth + 1),
+			wantErr: true,
+		},
+		{
+			name:    "too large",
+			node:    wideTree(MaxPredicateTreeSize + 1),
+			wantErr: true,
+		},
+		{
+			name:    "invalid kind",
+			node:    &PredicateNode{Kind: 4, Children: []*PredicateNode{amountEq(0)}},
+			wantErr: true,
+		},
+		{
+			name:    "leaf without predicate",
+			node:    &PredicateNode{Kind: NodeLeaf},
+			wantErr: true,
+		},
+		{
+			name: "leaf with children",
+			node: &PredicateNode{
+				Kind:     NodeLeaf,
+				Leaf:     amountEq(0).Leaf,
+				Children: []*PredicateNode{amountEq(0)},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "inner node with predicate",
+			node:    &PredicateNode{Kind: NodeAnd, Leaf: amountEq(0).Leaf, Children: []*PredicateNode{amountEq(0)}},
+			wantErr: true,
+		},
+		{
+			name:    "empty AND",
+			node:    And(),
+			wantErr: true,
+		},
+		{
+			name:    "empty OR",
+			node:    Or(),
+			wantErr: true,
+		},
+		{
+			name:    "NOT with two children",
+			node:    &PredicateNode{Kind: NodeNot, Children: []*PredicateNode{amountEq(0), amountEq(1)}},
+			wantErr: true,
+		},
+		{
+			name:    "nil child",
+			node:    And(amountEq(0), nil),
+			wantErr: true,
+		},
+		{
+			name: "invalid log predicate",
+			node: Leaf(LogPredicate{
+				LogValueRef:    LogValueRef{Offset: 4},
+				ValuePredicate: ValuePredicate{Op: UintEq, IntArgs: []*big.Int{}, ByteArgs: [][]byte{}},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "topic BytesEq with short value",
+			node: Leaf(LogPredicate{
+				LogValueRef:    LogValueRef{Offset: 1},
+				ValuePredicate: ValuePredicate{Op: BytesEq, IntArgs: []*big.Int{}, ByteArgs: [][]byte{{0x01}}},
+			}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.node.Validate()
+			if tt.wantErr {
+				assert.Assert(t, err != nil)
+			} else {
+				assert.NilError(t, err)
+			}
+		})
+	}
+}
+
+func TestPredicateTreeMatch(t *testing.T) {
+	toAOrB := And(topicEq(0, transferTopic), Or(topicEq(2, recipientA), topicEq(2, recipientB)))
+	nonZero := And(topicEq(0, transferTopic), Not(amountEq(0)))
+	notPrefixed := Not(Leaf(LogPredicate{
+		LogValueRef:    LogValueRef{Offset: 4, Dynamic: true},
+		ValuePredicate: ValuePredicate{Op: BytesPrefix, IntArgs: []*big.Int{}, ByteArgs: [][]byte{{0xaa}}},
+	}))
+
+	tests := []struct {
+		name      string
+		condition *PredicateNode
+		log       *types.Log
+		want      bool
+	}{
+		{"OR first alternative", toAOrB, transferLog(recipientA, 1), true},
+		{"OR second alternative", toAOrB, transferLog(recipientB, 1), true},
+		{"OR no alternative", toAOrB, transferLog(recipientC, 1), false},
+		{"NOT matching", nonZero, transferLog(recipientA, 1), true},
+		{"NOT not matching", nonZero, transferLog(recipientA, 0), false},
+		{
+			name:      "nested groups",
+			condition: Or(And(topicEq(2, recipientA), Not(amountEq(0))), And(topicEq(2, recipientB), amountEq(5))),
+			log:       transferLog(recipientB, 5),
+			want:      true,
+		},
+		{
+			name:      "NOT on malformed dynamic value",
+			condition: notPrefixed,
+			log:       &types.Log{Address: treeContract, Data: make([]byte, Word-1)},
+			want:      false,
+		},
+		{
+			name:      "different contract",
+			condition: toAOrB,
+			log: &types.Log{
+				Address: common.HexToAddress("0x9999999999999999999999999999999999999999"),
+				Topics:  []common.Hash{transferTopic, {}, recipientA},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definition := EventTriggerDefinition{Contract: treeContract, Condition: tt.condition}
+			assert.NilError(t, definition.Validate())
+			match, err := definition.Match(tt.log, DefaultMaxDynamicValueLength)
+			assert.NilError(t, err)
+			assert.Equal(t, match, tt.want)
+		})
+	}
+}
+
+func TestPredicateTreeToFilterQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition *PredicateNode
+		want      [][]common.Hash
+	}{
+		{
+			name:      "single topic",
+			condition: topicEq(0, transferTopic),
+			want:      [][]common.Hash{{transferTopic}},
+		},
+		{
+			name:      "OR over topic alternatives",
+			condition: And(topicEq(0, transferTopic), Or(topicEq(2, recipientB), topicEq(2, recipientA))),
+			want:      [][]common.Hash{{transferTopic}, {}, {recipientA, recipientB}},
+		},
+		{
+			name:      "OR with an unconstrained alternative",
+			condition: And(topicEq(0, transferTopic), Or(topicEq(2, recipientA), amountEq(1))),
+			want:      [][]common.Hash{{transferTopic}},
+		},
+		{
+			name:      "OR over different topics",
+			condition: Or(topicEq(1, recipientA), topicEq(2, recipientA)),
+			want:      [][]common.Hash{},
+		},
+		{
+			name: "AND of ORs",
+			condition: And(
+				Or(topicEq(2, recipientA), topicEq(2, recipientB)),
+				Or(topicEq(2, recipientB), topicEq(2, recipientC)),
+			),
+			want: [][]common.Hash{{}, {}, {recipientB}},
+		},
+		{
+			name:      "unsatisfiable AND",
+			condition: And(topicEq(2, recipientA), topicEq(2, recipientB)),
+			want:      [][]common.Hash{{}, {}, {recipientA}},
+		},
+		{
+			name: "BytesIn over topic alternatives",
+			condition: Leaf(LogPredicate{
+				LogValueRef: LogValueRef{Offset: 2},
+				ValuePredicate: ValuePredicate{
+					Op:       BytesIn,
+					IntArgs:  []*big.Int{big.NewInt(Word)},
+					ByteArgs: [][]byte{append(recipientB.Bytes(), recipientA.Bytes()...)},
+				},
+			}),
+			want: [][]common.Hash{{}, {}, {recipientA, recipientB}},
+		},
+		{
+			name:      "NOT",
+			condition: And(topicEq(0, transferTopic), Not(topicEq(2, recipientA))),
+			want:      [][]common.Hash{{transferTopic}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definition := EventTriggerDefinition{Contract: treeContract, Condition: tt.condition}
+			assert.NilError(t, definition.Validate())
+			query, err := definition.ToFilterQuery()
+			assert.NilError(t, err)
+			assert.DeepEqual(t, query, ethereum.FilterQuery{
+				Addresses: []common.Address{treeContract},
+				Topics:    tt.want,
+			})
+		})
+	}
+}
+
+func TestPredicateTreeMarshalUnmarshal(t *testing.T) {
+	definition := EventTriggerDefinition{
+		Contract: treeContract,
+		Condition: And(
+			topicEq(0, transferTopic),
+			Or(topicEq(2, recipientA), topicEq(2, recipientB)),
+			Not(amountEq(0)),
+		),
+	}
+	marshaled := definition.MarshalBytes()
+	assert.Equal(t, marshaled[0], byte(VersionPredicateTree))
+
+	var unmarshaled EventTriggerDefinition
+	assert.NilError(t, unmarshaled.UnmarshalBytes(marshaled))
+	assert.Equal(t, unmarshaled.Contract, definition.Contract)
+	assert.Equal(t, len(unmarshaled.LogPredicates), 0)
+	assert.Assert(t, bytes.Equal(unmarshaled.MarshalBytes(), marshaled))
+
+	for _, log := range []*types.Log{transferLog(recipientA, 1), transferLog(recipientB, 0), transferLog(recipientC, 1)} {
+		want, err := definition.Match(log, DefaultMaxDynamicValueLength)
+		assert.NilError(t, err)
+		got, err := unmarshaled.Match(log, DefaultMaxDynamicValueLength)
+		assert.NilError(t, err)
+		assert.Equal(t, got, want)
+	}
+}
+
+func TestPredicateTreeKeepsVersion2Encoding(t *testing.T) {
+	definition := EventTriggerDefinition{
+		Contract:      treeContract,
+		LogPredicates: []LogPredicate{*topicEq(0, transferTopic).Leaf},
+	}
+	encoded, err := rlp.EncodeToBytes(struct {
+		Contract      common.Address
+		LogPredicates []LogPredicate
+	}{definition.Contract, definition.LogPredicates})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, definition.MarshalBytes(), append([]byte{Version}, encoded...))
+
+	var unmarshaled EventTriggerDefinition
+	assert.NilError(t, unmarshaled.UnmarshalBytes(definition.MarshalBytes()))
+	assert.Assert(t, unmarshaled.Condition == nil)
+	assert.Equal(t, len(unmarshaled.LogPredicates), 1)
+}
+
+func TestPredicateTreeUnmarshalErrors(t *testing.T) {
+	encode := func(condition *PredicateNode) []byte {
+		definition := EventTriggerDefinition{Contract: treeContract, Condition: condition}
+		return definition.MarshalBytes()
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"version only", []byte{VersionPredicateTree}},
+		{"invalid RLP", []byte{VersionPredicateTree, 0xff, 0xff}},
+		{"too deep", encode(deepTree(MaxPredicateTreeDepth + 1))},
+		{"too large", encode(wideTree(MaxPredicateTreeSize + 1))},
+		{"invalid kind", encode(&PredicateNode{Kind: 7, Children: []*PredicateNode{amountEq(0)}})},
+		{"empty OR", encode(Or())},
+		{"NOT with two children", encode(&PredicateNode{Kind: NodeNot, Children: []*PredicateNode{amountEq(0), amountEq(1)}})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var definition EventTriggerDefinition
+			err := definition.UnmarshalBytes(tt.data)
+			assert.Assert(t, err != nil)
+		})
+	}
+}
+
+func TestPredicateTreeValidateRejectsLogPredicates(t *testing.T) {
+	definition := EventTriggerDefinition{
+		Contract:      treeContract,
+		LogPredicates: []LogPredicate{*amountEq(0).Leaf},
+		Condition:     amountEq(0),
+	}
+	assert.ErrorContains(t, definition.Validate(), "must not have log predicates")
+}
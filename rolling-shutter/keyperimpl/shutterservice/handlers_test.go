@@ -15,6 +15,7 @@ import (
 	corekeyperdatabase "github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/database"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/shutterservice/database"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/shutterservice/serviceztypes"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testsetup"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
@@ -59,7 +60,7 @@ func TestHandleDecryptionKeySharesThresholdNotReached(t *testing.T) {
 		IdentityPreimages: identityPreimages,
 	}
 
-	signature, err := decryptionData.ComputeSignature(privateKey)
+	signature, err := decryptionData.ComputeSignature(ctx, signer.NewLocal(privateKey))
 	assert.NilError(t, err)
 
 	keys := testsetup.InitializeEon(ctx, t, dbpool, config, keyperIndex)
@@ -121,7 +122,7 @@ func TestHandleDecryptionKeySharesThresholdReached(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Equal(t, len(msgs), 0)
 
-	keyper2Signature, err := decryptionData.ComputeSignature(keyper2PrivateKey)
+	keyper2Signature, err := decryptionData.ComputeSignature(ctx, signer.NewLocal(keyper2PrivateKey))
 	assert.NilError(t, err)
 
 	// now thrsehold will be reached after this message causing to send out
@@ -220,7 +221,7 @@ func generateDecryptionMessage(t *testing.T, keyperConfigIndex, keyperIndex uint
 		IdentityPreimages: identityPreimages,
 	}
 
-	signature, err := decryptionData.ComputeSignature(pvtKey)
+	signature, err := decryptionData.ComputeSignature(context.Background(), signer.NewLocal(pvtKey))
 	assert.NilError(t, err)
 	// threshold is two, so no outgoing message after first input
 	shares := []*p2pmsg.KeyShare{}
@@ -286,9 +287,9 @@ func TestValidateAndHandleDecryptionKey(t *testing.T) {
 		IdentityPreimages: identityPreimages,
 	}
 
-	keyper1Signature, err := decryptionData.ComputeSignature(keyper1PrivateKey)
+	keyper1Signature, err := decryptionData.ComputeSignature(ctx, signer.NewLocal(keyper1PrivateKey))
 	assert.NilError(t, err)
-	keyper2Signature, err := decryptionData.ComputeSignature(keyper2PrivateKey)
+	keyper2Signature, err := decryptionData.ComputeSignature(ctx, signer.NewLocal(keyper2PrivateKey))
 	assert.NilError(t, err)
 
 	keyperIndex := uint64(1)
@@ -374,9 +375,9 @@ func TestInValidateDecryptionKey(t *testing.T) {
 		IdentityPreimages: identityPreimages,
 	}
 
-	keyper1Signature, err := decryptionData.ComputeSignature(keyper1PrivateKey)
+	keyper1Signature, err := decryptionData.ComputeSignature(ctx, signer.NewLocal(keyper1PrivateKey))
 	assert.NilError(t, err)
-	keyper2Signature, err := decryptionData.ComputeSignature(keyper2PrivateKey)
+	keyper2Signature, err := decryptionData.ComputeSignature(ctx, signer.NewLocal(keyper2PrivateKey))
 	assert.NilError(t, err)
 
 	keyperIndex := uint64(1)
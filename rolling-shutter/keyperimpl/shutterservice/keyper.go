@@ -6,8 +6,8 @@ import (
 	"log/slog"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	gethLog "github.com/ethereum/go-ethereum/log"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -23,16 +23,20 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync"
 	syncevent "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/event"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/db"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/failover"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 )
 
 var ErrParseKeyperSet = errors.New("cannot parse KeyperSet")
 
 type Keyper struct {
-	core   *keyper.KeyperCore
-	config *Config
-	dbpool *pgxpool.Pool
+	core      *keyper.KeyperCore
+	config    *Config
+	dbpool    *pgxpool.Pool
+	signer    signer.Signer
+	ethClient *failover.Client
 
 	chainSyncClient     *chainsync.Client
 	registrySyncer      *RegistrySyncer
@@ -70,6 +74,14 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to connect to database")
 	}
+	kpr.signer, err = signer.New(kpr.config.Chain.Node)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize signer")
+	}
+	kpr.ethClient, err = failover.Dial(ctx, kpr.config.Chain.Node.EthereumURLs())
+	if err != nil {
+		return errors.Wrap(err, "failed to dial ethereum node")
+	}
 	messageSender, err := p2p.New(kpr.config.P2P)
 	if err != nil {
 		return errors.Wrap(err, "failed to initialize p2p messaging")
@@ -77,7 +89,7 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 
 	messageSender.AddMessageHandler(&DecryptionKeySharesHandler{kpr.dbpool})
 	messageSender.AddMessageHandler(&DecryptionKeysHandler{kpr.dbpool})
-	messagingMiddleware := NewMessagingMiddleware(messageSender, kpr.dbpool, kpr.config)
+	messagingMiddleware := NewMessagingMiddleware(messageSender, kpr.dbpool, kpr.config, kpr.signer)
 
 	kpr.core, err = NewKeyper(kpr, messagingMiddleware)
 	if err != nil {
@@ -85,27 +97,24 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 	}
 	kpr.chainSyncClient, err = chainsync.NewClient(
 		ctx,
-		chainsync.WithClientURL(kpr.config.Chain.Node.EthereumURL),
+		chainsync.WithClient(kpr.ethClient),
 		chainsync.WithKeyperSetManager(kpr.config.Chain.Contracts.KeyperSetManager),
 		chainsync.WithKeyBroadcastContract(kpr.config.Chain.Contracts.KeyBroadcastContract),
 		chainsync.WithSyncNewBlock(kpr.channelNewBlock),
 		chainsync.WithSyncNewKeyperSet(kpr.channelNewKeyperSet),
-		chainsync.WithPrivateKey(kpr.config.Chain.Node.PrivateKey.Key),
+		chainsync.WithSigner(kpr.signer),
 		chainsync.WithLogger(gethLog.NewLogger(slog.Default().Handler())),
 	)
 	if err != nil {
 		return err
 	}
 
-	eonKeyPublisherClient, err := ethclient.DialContext(ctx, kpr.config.Chain.Node.EthereumURL)
-	if err != nil {
-		return errors.Wrapf(err, "failed to dial ethereum node at %s", kpr.config.Chain.Node.EthereumURL)
-	}
 	kpr.eonKeyPublisher, err = eonkeypublisher.NewEonKeyPublisher(
 		kpr.dbpool,
-		eonKeyPublisherClient,
+		kpr.ethClient,
 		kpr.config.Chain.Contracts.KeyperSetManager,
-		kpr.config.Chain.Node.PrivateKey.Key,
+		kpr.signer,
+		kpr.core.Keyring(),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to initialize eon key publisher")
@@ -128,7 +137,7 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 		CheckInterval: time.Duration(kpr.config.Chain.SyncMonitorCheckInterval) * time.Second,
 	}
 	runner.Go(func() error { return kpr.processInputs(ctx) })
-	return runner.StartService(kpr.core, kpr.chainSyncClient, kpr.eonKeyPublisher, kpr.syncMonitor)
+	return runner.StartService(kpr.ethClient, kpr.core, kpr.chainSyncClient, kpr.eonKeyPublisher, kpr.syncMonitor)
 }
 
 func NewKeyper(kpr *Keyper, messagingMiddleware *MessagingMiddleware) (*keyper.KeyperCore, error) {
@@ -143,30 +152,41 @@ func NewKeyper(kpr *Keyper, messagingMiddleware *MessagingMiddleware) (*keyper.K
 			Ethereum:             kpr.config.Chain.Node,
 			Shuttermint:          kpr.config.Shuttermint,
 			Metrics:              kpr.config.Metrics,
+			Secrets:              kpr.config.Secrets,
+			Retention:            kpr.config.Retention,
+			PeerRecords:          kpr.config.PeerRecords,
+			KeyperStatus:         kpr.config.KeyperStatus,
 			MaxNumKeysPerMessage: kpr.config.MaxNumKeysPerMessage,
 		},
 		kpr.decryptionTriggerChannel,
 		keyper.WithDBPool(kpr.dbpool),
+		keyper.WithSigner(kpr.signer),
+		keyper.WithBlockSyncClient(kpr.ethClient),
 		keyper.NoBroadcastEonPublicKey(),
 		keyper.WithEonPublicKeyHandler(kpr.channelNewEonPublicKey),
 		keyper.WithMessaging(messagingMiddleware),
+		keyper.WithPruneFunc(kpr.prune),
 	)
 }
 
+// prune deletes the decryption signatures of pruned eons.
+func (kpr *Keyper) prune(ctx context.Context, tx pgx.Tx, cutoff keyper.RetentionCutoff) (map[string]int64, error) {
+	tag, err := database.New(tx).DeleteDecryptionSignaturesBeforeEon(ctx, cutoff.Eon)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete decryption signatures")
+	}
+	return map[string]int64{"decryption_signatures": tag.RowsAffected()}, nil
+}
+
 // initRegistrySycer initializes the registry syncer if the keyper is known to be a member of a
 // keyper set. Otherwise, the syncer will only be initialized once such a keyper set is observed to
 // be added, as only then we will know which eon(s) we are responsible for.
 func (kpr *Keyper) initRegistrySyncer(ctx context.Context) error {
-	client, err := ethclient.DialContext(ctx, kpr.config.Chain.Node.EthereumURL)
-	if err != nil {
-		return errors.Wrap(err, "failed to dial Ethereum execution node")
-	}
-
 	log.Info().
 		Str("contract-address", kpr.config.Chain.Contracts.KeyperSetManager.Hex()).
 		Msg("initializing registry syncer")
 
-	contract, err := registryBindings.NewShutterregistry(kpr.config.Chain.Contracts.ShutterRegistry, client)
+	contract, err := registryBindings.NewShutterregistry(kpr.config.Chain.Contracts.ShutterRegistry, kpr.ethClient)
 	if err != nil {
 		return err
 	}
@@ -174,13 +194,13 @@ func (kpr *Keyper) initRegistrySyncer(ctx context.Context) error {
 	kpr.registrySyncer = &RegistrySyncer{
 		Contract:             contract,
 		DBPool:               kpr.dbpool,
-		ExecutionClient:      client,
+		ExecutionClient:      kpr.ethClient,
 		SyncStartBlockNumber: kpr.config.Chain.SyncStartBlockNumber,
 	}
 
 	// Perform an initial sync now because it might take some time and doing so during regular
 	// slot processing might hold up things
-	latestHeader, err := client.HeaderByNumber(ctx, nil)
+	latestHeader, err := kpr.ethClient.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return errors.Wrap(err, "failed to get latest block header")
 	}
@@ -194,13 +214,9 @@ func (kpr *Keyper) initRegistrySyncer(ctx context.Context) error {
 
 // initMultiEventSyncer initializes the multi event syncer and all its event processors.
 func (kpr *Keyper) initMultiEventSyncer(ctx context.Context) error {
-	triggerRegistryClient, err := ethclient.DialContext(ctx, kpr.config.Chain.Node.EthereumURL)
-	if err != nil {
-		return fmt.Errorf("failed to dial Ethereum execution node: %w", err)
-	}
 	eventTriggerRegistryContract, err := triggerRegistryV1Bindings.NewShuttereventtriggerregistryv1(
 		kpr.config.Chain.Contracts.ShutterEventTriggerRegistry,
-		triggerRegistryClient,
+		kpr.ethClient,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create ShutterRegistry contract instance: %w", err)
@@ -210,24 +226,17 @@ func (kpr *Keyper) initMultiEventSyncer(ctx context.Context) error {
 		kpr.dbpool,
 	)
 
-	triggerClient, err := ethclient.DialContext(ctx, kpr.config.Chain.Node.EthereumURL)
-	if err != nil {
-		return fmt.Errorf("failed to dial Ethereum execution node: %w", err)
-	}
-	triggerProcessor := NewTriggerProcessor(triggerClient, kpr.dbpool)
+	triggerProcessor := NewTriggerProcessor(kpr.ethClient, kpr.dbpool)
+	triggerProcessor.MaxDynamicValueLength = kpr.config.MaxDynamicValueLength
 
 	processors := []EventProcessor{
 		eventTriggerRegisteredProcessor,
 		triggerProcessor,
 	}
 
-	multiEventSyncerClient, err := ethclient.DialContext(ctx, kpr.config.Chain.Node.EthereumURL)
-	if err != nil {
-		return fmt.Errorf("failed to dial Ethereum node at %s: %w", kpr.config.Chain.Node.EthereumURL, err)
-	}
 	kpr.multiEventSyncer, err = NewMultiEventSyncer(
 		kpr.dbpool,
-		multiEventSyncerClient,
+		kpr.ethClient,
 		kpr.config.Chain.SyncStartBlockNumber,
 		processors,
 	)
@@ -238,7 +247,7 @@ func (kpr *Keyper) initMultiEventSyncer(ctx context.Context) error {
 	// Perform an initial sync now because it might take some time and doing so during regular
 	// slot processing might hold up things
 	log.Info().Msg("performing initial sync of multi event syncer")
-	latestHeader, err := multiEventSyncerClient.HeaderByNumber(ctx, nil)
+	latestHeader, err := kpr.ethClient.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to get latest block header: %w", err)
 	}
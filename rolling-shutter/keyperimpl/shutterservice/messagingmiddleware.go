@@ -15,6 +15,7 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/identitypreimage"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 )
@@ -23,6 +24,7 @@ type MessagingMiddleware struct {
 	config    *Config
 	messaging p2p.Messaging
 	dbpool    *pgxpool.Pool
+	signer    signer.Signer
 }
 
 type WrappedMessageHandler struct {
@@ -56,8 +58,13 @@ func (h *WrappedMessageHandler) HandleMessage(ctx context.Context, msg p2pmsg.Me
 	return replacedMsgs, nil
 }
 
-func NewMessagingMiddleware(messaging p2p.Messaging, dbpool *pgxpool.Pool, config *Config) *MessagingMiddleware {
-	return &MessagingMiddleware{messaging: messaging, dbpool: dbpool, config: config}
+func NewMessagingMiddleware(
+	messaging p2p.Messaging,
+	dbpool *pgxpool.Pool,
+	config *Config,
+	s signer.Signer,
+) *MessagingMiddleware {
+	return &MessagingMiddleware{messaging: messaging, dbpool: dbpool, config: config, signer: s}
 }
 
 func (i *MessagingMiddleware) SendMessage(ctx context.Context, msg p2pmsg.Message, opts ...retry.Option) error {
@@ -75,6 +82,14 @@ func (i *MessagingMiddleware) AddValidator(ctx p2p.ValidatorFunc, protos ...p2pm
 	i.messaging.AddValidator(ctx, protos...)
 }
 
+func (i *MessagingMiddleware) AddPeerScore(fn p2p.PeerScoreFunc) {
+	i.messaging.AddPeerScore(fn)
+}
+
+func (i *MessagingMiddleware) SetBackfillProvider(provider p2p.BackfillProvider) {
+	i.messaging.SetBackfillProvider(provider)
+}
+
 func (i *MessagingMiddleware) AddMessageHandler(mhs ...p2p.MessageHandler) {
 	for _, mh := range mhs {
 		wmh := &WrappedMessageHandler{handler: mh, middleware: i}
@@ -118,7 +133,7 @@ func (i *MessagingMiddleware) interceptDecryptionKeyShares(
 	if err != nil {
 		return nil, err
 	}
-	signature, err := decryptionSignatureData.ComputeSignature(i.config.Chain.Node.PrivateKey.Key)
+	signature, err := decryptionSignatureData.ComputeSignature(ctx, i.signer)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to compute decryption signature")
 	}
@@ -6,7 +6,6 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
@@ -14,6 +13,8 @@ import (
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/shutterservice/database"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley"
+	syncclient "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/failover"
 )
 
 const (
@@ -24,7 +25,7 @@ const (
 type MultiEventSyncer struct {
 	Processors           map[string]EventProcessor
 	DBPool               *pgxpool.Pool
-	ExecutionClient      *ethclient.Client
+	ExecutionClient      syncclient.Client
 	SyncStartBlockNumber uint64
 	AssumedReorgDepth    int
 	MaxRequestBlockRange uint64
@@ -37,7 +38,7 @@ type SyncStatus struct {
 
 func NewMultiEventSyncer(
 	dbPool *pgxpool.Pool,
-	executionClient *ethclient.Client,
+	executionClient syncclient.Client,
 	syncStartBlockNumber uint64,
 	processors []EventProcessor,
 ) (*MultiEventSyncer, error) {
@@ -103,6 +104,9 @@ func (s *MultiEventSyncer) Sync(ctx context.Context, header *types.Header) error
 }
 
 func (s *MultiEventSyncer) syncRange(ctx context.Context, start, end uint64) (int, error) {
+	// The header and the events must be fetched from the same node. Otherwise, they might belong
+	// to different forks or the events might be missing if one node lags behind.
+	ctx = failover.Pin(ctx, s.ExecutionClient)
 	header, err := s.ExecutionClient.HeaderByNumber(ctx, new(big.Int).SetUint64(end))
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to get execution block header")
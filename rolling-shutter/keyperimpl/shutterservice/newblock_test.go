@@ -558,7 +558,7 @@ func TestFiredTriggersProducesOrderedShares(t *testing.T) {
 	msg, err := keyShareHandler.ConstructDecryptionKeyShares(ctx, triggerEon, triggers[0].IdentityPreimages)
 	assert.NilError(t, err)
 
-	validator := epochkghandler.NewDecryptionKeyShareHandler(config, dbpool)
+	validator := epochkghandler.NewDecryptionKeyShareHandler(config, dbpool, nil)
 	res, err := validator.ValidateMessage(ctx, msg)
 	assert.Equal(t, res, pubsub.ValidationAccept)
 	assert.NilError(t, err)
@@ -1,13 +1,14 @@
 package serviceztypes
 
 import (
-	"crypto/ecdsa"
+	"context"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/identitypreimage"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 )
 
 type IdentityPreimage struct {
@@ -44,12 +45,12 @@ func NewDecryptionSignatureData(
 	}, nil
 }
 
-func (d *DecryptionSignatureData) ComputeSignature(key *ecdsa.PrivateKey) ([]byte, error) {
+func (d *DecryptionSignatureData) ComputeSignature(ctx context.Context, s signer.Signer) ([]byte, error) {
 	h, err := d.HashTreeRoot()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to compute hash tree root of decryption signature data")
 	}
-	return crypto.Sign(h[:], key)
+	return s.SignHash(ctx, h[:])
 }
 
 func (d *DecryptionSignatureData) CheckSignature(signature []byte, address common.Address) (bool, error) {
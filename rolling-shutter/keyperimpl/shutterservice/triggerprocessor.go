@@ -4,21 +4,30 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/shutterservice/database"
+	syncclient "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
 )
 
 // TriggerProcessor implements the EventProcessor interface for processing trigger events.
 type TriggerProcessor struct {
-	ExecutionClient *ethclient.Client
+	ExecutionClient syncclient.Client
 	DBPool          *pgxpool.Pool
+	// AssumedReorgDepth is the number of blocks partial matches of multi event triggers are kept
+	// after they have left the window or the trigger has expired or fired, so that they are still
+	// available if the chain is rolled back.
+	AssumedReorgDepth int
+	// MaxDynamicValueLength is the maximum length in bytes of dynamic values read from logs when
+	// matching them against triggers. Logs with longer values don't match.
+	MaxDynamicValueLength uint64
 }
 
 type TriggerEvent struct {
@@ -26,13 +35,32 @@ type TriggerEvent struct {
 	Log                         types.Log
 }
 
+// MultiEventMatch is a log matching one of the conditions of a multi event trigger.
+type MultiEventMatch struct {
+	EventTriggerRegisteredEvent database.EventTriggerRegisteredEvent
+	Trigger                     *MultiEventCondition
+	ConditionIndex              uint64
+	Log                         types.Log
+}
+
+func (m *MultiEventMatch) eventMatch() EventMatch {
+	return EventMatch{
+		Condition:   m.ConditionIndex,
+		BlockNumber: m.Log.BlockNumber,
+		TxIndex:     uint64(m.Log.TxIndex),
+		LogIndex:    uint64(m.Log.Index),
+	}
+}
+
 func NewTriggerProcessor(
-	executionClient *ethclient.Client,
+	executionClient syncclient.Client,
 	dbPool *pgxpool.Pool,
 ) *TriggerProcessor {
 	return &TriggerProcessor{
-		ExecutionClient: executionClient,
-		DBPool:          dbPool,
+		ExecutionClient:       executionClient,
+		DBPool:                dbPool,
+		AssumedReorgDepth:     DefaultAssumedReorgDepth,
+		MaxDynamicValueLength: DefaultMaxDynamicValueLength,
 	}
 }
 
@@ -69,6 +97,14 @@ func (tp *TriggerProcessor) FetchEvents(ctx context.Context, start, end uint64)
 			triggerLog.Error().Err(err).Msg("ignoring invalid trigger definition in database")
 			continue
 		}
+		if trigger.MultiEvent != nil {
+			matches, err := tp.fetchMultiEventMatches(ctx, triggerRegisteredEvent, trigger.MultiEvent, start, end, triggerLog)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, matches...)
+			continue
+		}
 
 		filterQuery, err := trigger.ToFilterQuery()
 		if err != nil {
@@ -90,7 +126,7 @@ func (tp *TriggerProcessor) FetchEvents(ctx context.Context, start, end uint64)
 			if eventLog.BlockNumber > uint64(triggerRegisteredEvent.ExpirationBlockNumber) {
 				continue
 			}
-			match, err := trigger.Match(&eventLog)
+			match, err := trigger.Match(&eventLog, tp.MaxDynamicValueLength)
 			if err != nil {
 				triggerLog.Error().Err(err).Msg("failed to match trigger with event log")
 				continue
@@ -111,35 +147,205 @@ func (tp *TriggerProcessor) FetchEvents(ctx context.Context, start, end uint64)
 	return events, nil
 }
 
+// fetchMultiEventMatches fetches the logs matching any of the conditions of a multi event trigger.
+func (tp *TriggerProcessor) fetchMultiEventMatches(
+	ctx context.Context,
+	triggerRegisteredEvent database.EventTriggerRegisteredEvent,
+	trigger *MultiEventCondition,
+	start, end uint64,
+	triggerLog zerolog.Logger,
+) ([]Event, error) {
+	var events []Event
+	for i, condition := range trigger.Conditions {
+		filterQuery, err := condition.ToFilterQuery()
+		if err != nil {
+			// This is not supposed to happen as only valid triggers are inserted into the database.
+			triggerLog.Error().Err(err).Int("condition", i).Msg("failed to create filter query for trigger condition")
+			continue
+		}
+		filterQuery.FromBlock = new(big.Int).SetUint64(start)
+		filterQuery.ToBlock = new(big.Int).SetUint64(end)
+
+		logs, err := tp.ExecutionClient.FilterLogs(ctx, filterQuery)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to filter logs for condition %d of event trigger", i)
+		}
+		for _, eventLog := range logs {
+			if eventLog.BlockNumber > uint64(triggerRegisteredEvent.ExpirationBlockNumber) {
+				continue
+			}
+			match, err := condition.Match(&eventLog, tp.MaxDynamicValueLength)
+			if err != nil {
+				triggerLog.Error().Err(err).Int("condition", i).Msg("failed to match trigger condition with event log")
+				continue
+			}
+			if !match {
+				continue
+			}
+			events = append(events, &MultiEventMatch{
+				EventTriggerRegisteredEvent: triggerRegisteredEvent,
+				Trigger:                     trigger,
+				ConditionIndex:              uint64(i),
+				Log:                         eventLog,
+			})
+		}
+	}
+	return events, nil
+}
+
 func (tp *TriggerProcessor) ProcessEvents(ctx context.Context, tx pgx.Tx, events []Event) error {
 	queries := database.New(tx)
+	multiEventMatches := make(map[string][]*MultiEventMatch)
+	multiEventTriggers := []string{}
 	for _, untypedEvent := range events {
-		event := untypedEvent.(*TriggerEvent)
-		err := queries.InsertFiredTrigger(ctx, database.InsertFiredTriggerParams{
-			Eon:            event.EventTriggerRegisteredEvent.Eon,
-			Identity:       event.EventTriggerRegisteredEvent.Identity,
-			IdentityPrefix: event.EventTriggerRegisteredEvent.IdentityPrefix,
-			Sender:         event.EventTriggerRegisteredEvent.Sender,
-			BlockNumber:    int64(event.Log.BlockNumber),
-			BlockHash:      event.Log.BlockHash[:],
-			TxIndex:        int64(event.Log.TxIndex),
-			LogIndex:       int64(event.Log.Index),
+		switch event := untypedEvent.(type) {
+		case *TriggerEvent:
+			if err := insertFiredTrigger(ctx, queries, event.EventTriggerRegisteredEvent, event.Log); err != nil {
+				return err
+			}
+		case *MultiEventMatch:
+			key := fmt.Sprintf("%d-%x", event.EventTriggerRegisteredEvent.Eon, event.EventTriggerRegisteredEvent.Identity)
+			if _, ok := multiEventMatches[key]; !ok {
+				multiEventTriggers = append(multiEventTriggers, key)
+			}
+			multiEventMatches[key] = append(multiEventMatches[key], event)
+		default:
+			return errors.Errorf("unexpected event type %T", untypedEvent)
+		}
+	}
+
+	for _, key := range multiEventTriggers {
+		if err := tp.processMultiEventMatches(ctx, queries, multiEventMatches[key]); err != nil {
+			return err
+		}
+	}
+	return tp.deleteInactivePartialMatches(ctx, queries)
+}
+
+// deleteInactivePartialMatches deletes the partial matches of multi event triggers that have
+// expired or fired more than AssumedReorgDepth blocks before the block synced so far. Those can't
+// contribute to the trigger anymore, even if the chain is rolled back.
+func (tp *TriggerProcessor) deleteInactivePartialMatches(ctx context.Context, queries *database.Queries) error {
+	status, err := queries.GetMultiEventSyncStatus(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get sync status")
+	}
+	blockNumber := status.BlockNumber - int64(tp.AssumedReorgDepth)
+	if blockNumber <= 0 {
+		return nil
+	}
+	err = queries.DeleteEventTriggerPartialMatchesOfInactiveTriggers(ctx, blockNumber)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete partial matches of inactive event triggers")
+	}
+	return nil
+}
+
+// processMultiEventMatches stores the new matches of a multi event trigger as partial matches and
+// fires the trigger once they satisfy its condition. The matches must belong to the same trigger.
+func (tp *TriggerProcessor) processMultiEventMatches(
+	ctx context.Context,
+	queries *database.Queries,
+	matches []*MultiEventMatch,
+) error {
+	triggerRegisteredEvent := matches[0].EventTriggerRegisteredEvent
+	trigger := matches[0].Trigger
+
+	rows, err := queries.GetEventTriggerPartialMatches(ctx, database.GetEventTriggerPartialMatchesParams{
+		Eon:      triggerRegisteredEvent.Eon,
+		Identity: triggerRegisteredEvent.Identity,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get partial matches of event trigger")
+	}
+	existing := make([]EventMatch, 0, len(rows)+len(matches))
+	for _, row := range rows {
+		existing = append(existing, EventMatch{
+			Condition:   uint64(row.ConditionIndex),
+			BlockNumber: uint64(row.BlockNumber),
+			TxIndex:     uint64(row.TxIndex),
+			LogIndex:    uint64(row.LogIndex),
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matchesBefore(matches[i].eventMatch(), matches[j].eventMatch())
+	})
+	for _, match := range matches {
+		eventMatch := match.eventMatch()
+		if !trigger.Relevant(existing, eventMatch) {
+			continue
+		}
+		err := queries.InsertEventTriggerPartialMatch(ctx, database.InsertEventTriggerPartialMatchParams{
+			Eon:            triggerRegisteredEvent.Eon,
+			Identity:       triggerRegisteredEvent.Identity,
+			ConditionIndex: int64(match.ConditionIndex),
+			BlockNumber:    int64(match.Log.BlockNumber),
+			BlockHash:      match.Log.BlockHash[:],
+			TxIndex:        int64(match.Log.TxIndex),
+			LogIndex:       int64(match.Log.Index),
 		})
 		if err != nil {
-			return fmt.Errorf("failed to insert fired trigger: %w", err)
+			return errors.Wrap(err, "failed to insert partial match of event trigger")
+		}
+		existing = append(existing, eventMatch)
+		if trigger.Satisfied(existing) {
+			return insertFiredTrigger(ctx, queries, triggerRegisteredEvent, match.Log)
 		}
-		log.Info().
-			Int64("trigger-registered-block-number", event.EventTriggerRegisteredEvent.BlockNumber).
-			Hex("trigger-registered-block-hash", event.EventTriggerRegisteredEvent.BlockHash).
-			Int64("trigger-registered-tx-index", event.EventTriggerRegisteredEvent.TxIndex).
-			Int64("trigger-registered-log-index", event.EventTriggerRegisteredEvent.LogIndex).
-			Uint64("event-block-number", event.Log.BlockNumber).
-			Hex("event-block-hash", event.Log.BlockHash.Bytes()).
-			Uint("event-tx-index", event.Log.TxIndex).
-			Uint("event-log-index", event.Log.Index).
-			Msg("processed fired trigger event")
 	}
 
+	// Matches that have left the window can't contribute to the trigger anymore.
+	if trigger.Window == 0 || len(existing) == 0 {
+		return nil
+	}
+	latest := existing[len(existing)-1].BlockNumber
+	depth := uint64(tp.AssumedReorgDepth) //nolint:gosec // G115
+	if latest > trigger.Window && latest-trigger.Window > depth {
+		err := queries.DeleteEventTriggerPartialMatchesBeforeBlockNumber(ctx,
+			database.DeleteEventTriggerPartialMatchesBeforeBlockNumberParams{
+				Eon:         triggerRegisteredEvent.Eon,
+				Identity:    triggerRegisteredEvent.Identity,
+				BlockNumber: int64(latest - trigger.Window - depth),
+			})
+		if err != nil {
+			return errors.Wrap(err, "failed to delete outdated partial matches of event trigger")
+		}
+	}
+	return nil
+}
+
+func insertFiredTrigger(
+	ctx context.Context,
+	queries *database.Queries,
+	triggerRegisteredEvent database.EventTriggerRegisteredEvent,
+	eventLog types.Log,
+) error {
+	err := queries.InsertFiredTrigger(ctx, database.InsertFiredTriggerParams{
+		Eon:            triggerRegisteredEvent.Eon,
+		Identity:       triggerRegisteredEvent.Identity,
+		IdentityPrefix: triggerRegisteredEvent.IdentityPrefix,
+		Sender:         triggerRegisteredEvent.Sender,
+		BlockNumber:    int64(eventLog.BlockNumber),
+		BlockHash:      eventLog.BlockHash[:],
+		TxIndex:        int64(eventLog.TxIndex),
+		LogIndex:       int64(eventLog.Index),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert fired trigger: %w", err)
+	}
+	log.Info().
+		Int64("trigger-registered-block-number", triggerRegisteredEvent.BlockNumber).
+		Hex("trigger-registered-block-hash", triggerRegisteredEvent.BlockHash).
+		Int64("trigger-registered-tx-index", triggerRegisteredEvent.TxIndex).
+		Int64("trigger-registered-log-index", triggerRegisteredEvent.LogIndex).
+		Uint64("event-block-number", eventLog.BlockNumber).
+		Hex("event-block-hash", eventLog.BlockHash.Bytes()).
+		Uint("event-tx-index", eventLog.TxIndex).
+		Uint("event-log-index", eventLog.Index).
+		Msg("processed fired trigger event")
 	return nil
 }
 
@@ -149,5 +355,9 @@ func (tp *TriggerProcessor) RollbackEvents(ctx context.Context, tx pgx.Tx, toBlo
 	if err != nil {
 		return fmt.Errorf("failed to delete fired triggers from block number: %w", err)
 	}
+	err = queries.DeleteEventTriggerPartialMatchesFromBlockNumber(ctx, toBlock+1)
+	if err != nil {
+		return fmt.Errorf("failed to delete partial matches from block number: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,223 @@
+package shutterservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v4"
+	"gotest.tools/assert"
+
+	servicedatabase "github.com/shutter-network/rolling-shutter/rolling-shutter/keyperimpl/shutterservice/database"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testsetup"
+)
+
+func registerMultiEventTrigger(
+	ctx context.Context,
+	t *testing.T,
+	db *servicedatabase.Queries,
+	condition *MultiEventCondition,
+) servicedatabase.EventTriggerRegisteredEvent {
+	t.Helper()
+	definition := EventTriggerDefinition{MultiEvent: condition}
+	assert.NilError(t, definition.Validate())
+	_, err := db.InsertEventTriggerRegisteredEvent(ctx, servicedatabase.InsertEventTriggerRegisteredEventParams{
+		BlockNumber:           1,
+		BlockHash:             []byte{0x01},
+		Eon:                   1,
+		IdentityPrefix:        b32(0x11),
+		Sender:                "0x0000000000000000000000000000000000000011",
+		Definition:            definition.MarshalBytes(),
+		ExpirationBlockNumber: 10_000,
+		Identity:              b32(0x01),
+	})
+	assert.NilError(t, err)
+	triggers, err := db.GetActiveEventTriggerRegisteredEvents(ctx, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, len(triggers), 1)
+	return triggers[0]
+}
+
+func newMultiEventMatch(
+	trigger servicedatabase.EventTriggerRegisteredEvent,
+	condition *MultiEventCondition,
+	conditionIndex uint64,
+	blockNumber uint64,
+) Event {
+	return &MultiEventMatch{
+		EventTriggerRegisteredEvent: trigger,
+		Trigger:                     condition,
+		ConditionIndex:              conditionIndex,
+		Log: types.Log{
+			BlockNumber: blockNumber,
+			BlockHash:   common.BigToHash(common.Big1),
+		},
+	}
+}
+
+func processEvents(ctx context.Context, t *testing.T, tp *TriggerProcessor, events ...Event) {
+	t.Helper()
+	err := tp.DBPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return tp.ProcessEvents(ctx, tx, events)
+	})
+	assert.NilError(t, err)
+}
+
+func numActiveTriggers(ctx context.Context, t *testing.T, db *servicedatabase.Queries) int {
+	t.Helper()
+	triggers, err := db.GetActiveEventTriggerRegisteredEvents(ctx, 0)
+	assert.NilError(t, err)
+	return len(triggers)
+}
+
+func numPartialMatches(
+	ctx context.Context,
+	t *testing.T,
+	db *servicedatabase.Queries,
+	trigger servicedatabase.EventTriggerRegisteredEvent,
+) int {
+	t.Helper()
+	matches, err := db.GetEventTriggerPartialMatches(ctx, servicedatabase.GetEventTriggerPartialMatchesParams{
+		Eon:      trigger.Eon,
+		Identity: trigger.Identity,
+	})
+	assert.NilError(t, err)
+	return len(matches)
+}
+
+func TestProcessMultiEventMatches(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dbpool, dbclose := testsetup.NewTestDBPool(ctx, t, servicedatabase.Definition)
+	t.Cleanup(dbclose)
+	db := servicedatabase.New(dbpool)
+	tp := NewTriggerProcessor(nil, dbpool)
+
+	condition := multiEvent(CombineSequence, 1, 0, transferCondition(treeContract), transferCondition(otherContract))
+	trigger := registerMultiEventTrigger(ctx, t, db, condition)
+
+	// the second step before the first one is not stored
+	processEvents(ctx, t, tp, newMultiEventMatch(trigger, condition, 1, 5), newMultiEventMatch(trigger, condition, 0, 10))
+	assert.Equal(t, numPartialMatches(ctx, t, db, trigger), 1)
+	assert.Equal(t, numActiveTriggers(ctx, t, db), 1)
+
+	processEvents(ctx, t, tp, newMultiEventMatch(trigger, condition, 1, 20))
+	assert.Equal(t, numActiveTriggers(ctx, t, db), 0)
+	fired, err := db.GetUndecryptedFiredTriggers(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(fired), 1)
+	assert.Equal(t, fired[0].BlockNumber, int64(20))
+
+	// after a reorg, the first step is still known
+	err = dbpool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return tp.RollbackEvents(ctx, tx, 15)
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, numActiveTriggers(ctx, t, db), 1)
+	assert.Equal(t, numPartialMatches(ctx, t, db, trigger), 1)
+
+	processEvents(ctx, t, tp, newMultiEventMatch(trigger, condition, 1, 21))
+	fired, err = db.GetUndecryptedFiredTriggers(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(fired), 1)
+	assert.Equal(t, fired[0].BlockNumber, int64(21))
+}
+
+func TestProcessMultiEventMatchesPrunesWindow(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dbpool, dbclose := testsetup.NewTestDBPool(ctx, t, servicedatabase.Definition)
+	t.Cleanup(dbclose)
+	db := servicedatabase.New(dbpool)
+	tp := NewTriggerProcessor(nil, dbpool)
+	tp.AssumedReorgDepth = 2
+
+	condition := multiEvent(CombineAll, 3, 5, transferCondition(treeContract))
+	trigger := registerMultiEventTrigger(ctx, t, db, condition)
+
+	processEvents(ctx, t, tp, newMultiEventMatch(trigger, condition, 0, 10), newMultiEventMatch(trigger, condition, 0, 12))
+	assert.Equal(t, numPartialMatches(ctx, t, db, trigger), 2)
+
+	// matches more than window + reorg depth blocks old are removed
+	processEvents(ctx, t, tp, newMultiEventMatch(trigger, condition, 0, 18))
+	assert.Equal(t, numPartialMatches(ctx, t, db, trigger), 2)
+	assert.Equal(t, numActiveTriggers(ctx, t, db), 1)
+
+	processEvents(ctx, t, tp, newMultiEventMatch(trigger, condition, 0, 20), newMultiEventMatch(trigger, condition, 0, 21))
+	assert.Equal(t, numActiveTriggers(ctx, t, db), 0)
+}
+
+func TestProcessEventsPrunesPartialMatchesOfInactiveTriggers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dbpool, dbclose := testsetup.NewTestDBPool(ctx, t, servicedatabase.Definition)
+	t.Cleanup(dbclose)
+	db := servicedatabase.New(dbpool)
+	tp := NewTriggerProcessor(nil, dbpool)
+	tp.AssumedReorgDepth = 2
+
+	setSyncedBlock := func(blockNumber int64) {
+		t.Helper()
+		err := db.SetMultiEventSyncStatus(ctx, servicedatabase.SetMultiEventSyncStatusParams{
+			BlockNumber: blockNumber,
+			BlockHash:   []byte{0x01},
+		})
+		assert.NilError(t, err)
+	}
+
+	condition := multiEvent(CombineSequence, 1, 0, transferCondition(treeContract), transferCondition(otherContract))
+	fired := registerMultiEventTrigger(ctx, t, db, condition)
+	_, err := db.InsertEventTriggerRegisteredEvent(ctx, servicedatabase.InsertEventTriggerRegisteredEventParams{
+		BlockNumber:           1,
+		BlockHash:             []byte{0x01},
+		Eon:                   1,
+		IdentityPrefix:        b32(0x12),
+		Sender:                "0x0000000000000000000000000000000000000011",
+		Definition:            fired.Definition,
+		ExpirationBlockNumber: 30,
+		Identity:              b32(0x02),
+	})
+	assert.NilError(t, err)
+	triggers, err := db.GetActiveEventTriggerRegisteredEvents(ctx, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, len(triggers), 2)
+	expiring := triggers[0]
+	if expiring.ExpirationBlockNumber != 30 {
+		expiring = triggers[1]
+	}
+
+	processEvents(ctx, t, tp,
+		newMultiEventMatch(fired, condition, 0, 5),
+		newMultiEventMatch(expiring, condition, 0, 5),
+		newMultiEventMatch(fired, condition, 1, 20),
+	)
+	assert.Equal(t, numPartialMatches(ctx, t, db, fired), 2)
+	assert.Equal(t, numPartialMatches(ctx, t, db, expiring), 1)
+
+	// partial matches are kept until the trigger has fired more than the reorg depth ago
+	setSyncedBlock(22)
+	processEvents(ctx, t, tp)
+	assert.Equal(t, numPartialMatches(ctx, t, db, fired), 2)
+	setSyncedBlock(23)
+	processEvents(ctx, t, tp)
+	assert.Equal(t, numPartialMatches(ctx, t, db, fired), 0)
+	assert.Equal(t, numPartialMatches(ctx, t, db, expiring), 1)
+
+	// or has expired more than the reorg depth ago
+	setSyncedBlock(32)
+	processEvents(ctx, t, tp)
+	assert.Equal(t, numPartialMatches(ctx, t, db, expiring), 1)
+	setSyncedBlock(33)
+	processEvents(ctx, t, tp)
+	assert.Equal(t, numPartialMatches(ctx, t, db, expiring), 0)
+}
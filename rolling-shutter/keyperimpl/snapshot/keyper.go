@@ -71,6 +71,10 @@ func (kpr *Keyper) Start(ctx context.Context, runner service.Runner) error {
 			Ethereum:             kpr.config.Ethereum,
 			Shuttermint:          kpr.config.Shuttermint,
 			Metrics:              kpr.config.Metrics,
+			Secrets:              kpr.config.Secrets,
+			Retention:            kpr.config.Retention,
+			PeerRecords:          kpr.config.PeerRecords,
+			KeyperStatus:         kpr.config.KeyperStatus,
 			MaxNumKeysPerMessage: kpr.config.MaxNumKeysPerMessage,
 		},
 		decrTrigChan,
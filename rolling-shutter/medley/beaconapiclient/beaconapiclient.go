@@ -7,50 +7,168 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	encodeableurl "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/url"
 )
 
+const defaultTimeout = 10 * time.Second
+
+var errNotFound = errors.New("not found")
+
+type Option func(*Client) error
+
+// WithFallbackURLs adds beacon API endpoints that are used if the primary one fails.
+func WithFallbackURLs(rawURLs ...string) Option {
+	return func(c *Client) error {
+		for _, rawURL := range rawURLs {
+			parsedURL, err := url.Parse(rawURL)
+			if err != nil {
+				return err
+			}
+			c.urls = append(c.urls, parsedURL)
+		}
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of a single request to a beacon API endpoint.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return errors.New("timeout must be positive")
+		}
+		c.c.Timeout = timeout
+		return nil
+	}
+}
+
+// WithStore persists the cached proposer duties and validator pubkeys in the given store.
+func WithStore(store Store) Option {
+	return func(c *Client) error {
+		c.cache.store = store
+		return nil
+	}
+}
+
+// Client talks to one or more beacon API endpoints. Requests are sent to the endpoint that has
+// answered the last request and are retried with the other endpoints if it fails. Proposer duties
+// and validator pubkeys are cached. Cached proposer duties are only used once the client has been
+// started, as they are checked against the head events of the beacon node.
 type Client struct {
-	c   *http.Client
-	url *url.URL
+	c     *http.Client
+	urls  []*url.URL
+	cache *cache
+
+	mux sync.Mutex
+	// preferred is the index of the endpoint that has answered the last request
+	preferred int
 }
 
-func New(rawURL string) (*Client, error) {
+func New(rawURL string, options ...Option) (*Client, error) {
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{
-		c:   &http.Client{},
-		url: parsedURL,
-	}, nil
+	c := &Client{
+		c:     &http.Client{Timeout: defaultTimeout},
+		urls:  []*url.URL{parsedURL},
+		cache: newCache(),
+	}
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
-func (c *Client) GetBeaconNodeVersion(ctx context.Context) (string, error) {
-	path := c.url.JoinPath("/eth/v1/node/version")
-	req, err := http.NewRequestWithContext(ctx, "GET", path.String(), http.NoBody)
+// isRetryable checks if a request that failed with the given status code should be retried with
+// another endpoint.
+func isRetryable(statusCode int) bool {
+	return statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests
+}
+
+// get sends a GET request to the endpoints, starting with the preferred one, until one of them
+// answers. The JSON response body is decoded into result. errNotFound is returned if the endpoint
+// answers with 404.
+func (c *Client) get(ctx context.Context, path func(base *url.URL) *url.URL, result any) error {
+	c.mux.Lock()
+	preferred := c.preferred
+	c.mux.Unlock()
+
+	var err error
+	for i := 0; i < len(c.urls); i++ {
+		index := (preferred + i) % len(c.urls)
+		var retryable bool
+		retryable, err = c.getFrom(ctx, path(c.urls[index]), result)
+		if err == nil || errors.Is(err, errNotFound) {
+			if index != preferred {
+				log.Info().
+					Str("endpoint", encodeableurl.Redact(c.urls[index].String())).
+					Msg("switching beacon API endpoint")
+				c.mux.Lock()
+				c.preferred = index
+				c.mux.Unlock()
+			}
+			return err
+		}
+		if !retryable || ctx.Err() != nil {
+			return err
+		}
+		if len(c.urls) > 1 {
+			log.Warn().
+				Err(err).
+				Str("endpoint", encodeableurl.Redact(c.urls[index].String())).
+				Msg("beacon API request failed, trying next endpoint")
+		}
+	}
+	return err
+}
+
+func (c *Client) getFrom(ctx context.Context, u *url.URL, result any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
 	if err != nil {
-		return "", err
+		return false, err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	res, err := c.c.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to get beacon node version from consensus node: %w", err)
+		return true, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return false, errNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		return isRetryable(res.StatusCode), errors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return true, errors.Wrap(err, "failed to read consensus client response body")
 	}
-	body, err := io.ReadAll(resp.Body)
+	err = json.Unmarshal(body, result)
 	if err != nil {
-		return "", err
+		return true, errors.Wrap(err, "failed to unmarshal consensus client response body")
 	}
+	return false, nil
+}
+
+func (c *Client) GetBeaconNodeVersion(ctx context.Context) (string, error) {
 	var result struct {
 		Data struct {
 			Version string `json:"version"`
 		} `json:"data"`
 	}
-	err = json.Unmarshal(body, &result)
+	err := c.get(ctx, func(base *url.URL) *url.URL {
+		return base.JoinPath("/eth/v1/node/version")
+	}, &result)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to get beacon node version from consensus node: %w", err)
 	}
 	return result.Data.Version, nil
 }
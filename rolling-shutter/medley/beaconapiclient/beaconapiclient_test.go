@@ -0,0 +1,474 @@
+package beaconapiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+)
+
+const numValidators = 100
+
+// fakeBeaconNode serves proposer duties and validators and counts the requests it receives.
+type fakeBeaconNode struct {
+	*httptest.Server
+
+	mux                 sync.Mutex
+	status              int
+	delay               time.Duration
+	executionOptimistic bool
+	dependentRoot       string
+	numRequests         int
+
+	heads chan HeadEvent
+}
+
+func newFakeBeaconNode(t *testing.T) *fakeBeaconNode {
+	t.Helper()
+	n := &fakeBeaconNode{status: http.StatusOK, heads: make(chan HeadEvent)}
+	n.Server = httptest.NewServer(http.HandlerFunc(n.serve))
+	t.Cleanup(n.Close)
+	return n
+}
+
+func (n *fakeBeaconNode) setStatus(status int) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	n.status = status
+}
+
+func (n *fakeBeaconNode) setDependentRoot(dependentRoot string) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	n.dependentRoot = dependentRoot
+}
+
+func (n *fakeBeaconNode) requests() int {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	return n.numRequests
+}
+
+func (n *fakeBeaconNode) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/eth/v1/events" {
+		n.serveHeadEvents(w, r)
+		return
+	}
+	n.mux.Lock()
+	n.numRequests++
+	status := n.status
+	delay := n.delay
+	executionOptimistic := n.executionOptimistic
+	dependentRoot := n.dependentRoot
+	n.mux.Unlock()
+
+	time.Sleep(delay)
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+
+	var response any
+	switch {
+	case r.URL.Path == "/eth/v1/node/version":
+		response = map[string]any{"data": map[string]string{"version": "fake"}}
+	case strings.HasPrefix(r.URL.Path, "/eth/v1/validator/duties/proposer/"):
+		epoch, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/eth/v1/validator/duties/proposer/"), 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		duties := []ProposerDuty{}
+		for slot := epoch * 16; slot < (epoch+1)*16; slot++ {
+			duties = append(duties, ProposerDuty{Pubkey: pubkeyHex(slot), ValidatorIndex: slot, Slot: slot})
+		}
+		response = GetProposerDutiesResponse{
+			ExecutionOptimistic: executionOptimistic,
+			DependentRoot:       dependentRoot,
+			Data:                duties,
+		}
+	case strings.TrimSuffix(r.URL.Path, "/") == "/eth/v1/beacon/states/head/validators":
+		ids := r.URL.Query()["id"]
+		if len(ids) > maxIndicesPerRequest {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data := []ValidatorData{}
+		for _, id := range ids {
+			index, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if index < numValidators {
+				data = append(data, ValidatorData{Index: index, Validator: Validator{PubkeyHex: pubkeyHex(index)}})
+			}
+		}
+		response = GetValidatorByIndexResponse{Data: data}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+// serveHeadEvents streams the head events sent to the heads channel. The subscription is not
+// counted as request.
+func (n *fakeBeaconNode) serveHeadEvents(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("topics") != "head" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	flusher := w.(http.Flusher)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case head := <-n.heads:
+			data, err := json.Marshal(head)
+			if err != nil {
+				return
+			}
+			_, _ = fmt.Fprintf(w, "event: head\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+// startClient starts the client, subscribing it to the head events of the beacon node.
+func startClient(t *testing.T, c *Client) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	group, cleanup := service.RunBackground(ctx, c)
+	t.Cleanup(func() {
+		cancel()
+		_ = group.Wait()
+		cleanup()
+	})
+}
+
+// sendHead sends a head event to the clients subscribed to the beacon node and waits until the
+// given client has received it.
+func (n *fakeBeaconNode) sendHead(t *testing.T, c *Client, head HeadEvent) {
+	t.Helper()
+	select {
+	case n.heads <- head:
+	case <-time.After(5 * time.Second):
+		t.Fatal("client did not subscribe to head events")
+	}
+	for i := 0; i < 500; i++ {
+		if received := c.cache.getHead(); received != nil && *received == head {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("client did not receive head event")
+}
+
+func pubkeyHex(index uint64) string {
+	return fmt.Sprintf("0x%096x", index)
+}
+
+// memoryStore is a Store keeping everything in memory, surviving the client it is used by.
+type memoryStore struct {
+	duties  map[uint64]*GetProposerDutiesResponse
+	pubkeys map[int64]string
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		duties:  make(map[uint64]*GetProposerDutiesResponse),
+		pubkeys: make(map[int64]string),
+	}
+}
+
+func (s *memoryStore) GetProposerDuties(_ context.Context, epoch uint64) (*GetProposerDutiesResponse, error) {
+	return s.duties[epoch], nil
+}
+
+func (s *memoryStore) PutProposerDuties(_ context.Context, epoch uint64, duties *GetProposerDutiesResponse) error {
+	s.duties[epoch] = duties
+	return nil
+}
+
+func (s *memoryStore) GetValidatorPubkeys(_ context.Context, validatorIndices []int64) (map[int64]string, error) {
+	pubkeys := make(map[int64]string)
+	for _, index := range validatorIndices {
+		if pubkey, ok := s.pubkeys[index]; ok {
+			pubkeys[index] = pubkey
+		}
+	}
+	return pubkeys, nil
+}
+
+func (s *memoryStore) PutValidatorPubkeys(_ context.Context, pubkeys map[int64]string) error {
+	for index, pubkey := range pubkeys {
+		s.pubkeys[index] = pubkey
+	}
+	return nil
+}
+
+func indexRange(start, end int64) []int64 {
+	indices := []int64{}
+	for i := start; i < end; i++ {
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+func TestFailover(t *testing.T) {
+	ctx := context.Background()
+	primary := newFakeBeaconNode(t)
+	fallback := newFakeBeaconNode(t)
+	c, err := New(primary.URL, WithFallbackURLs(fallback.URL))
+	assert.NilError(t, err)
+
+	primary.setStatus(http.StatusServiceUnavailable)
+	version, err := c.GetBeaconNodeVersion(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, version, "fake")
+	assert.Equal(t, primary.requests(), 1)
+	assert.Equal(t, fallback.requests(), 1)
+
+	// the fallback answered, so it is asked first from now on
+	primary.setStatus(http.StatusOK)
+	_, err = c.GetBeaconNodeVersion(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, primary.requests(), 1)
+	assert.Equal(t, fallback.requests(), 2)
+
+	// client errors are not retried with another endpoint
+	fallback.setStatus(http.StatusBadRequest)
+	_, err = c.GetBeaconNodeVersion(ctx)
+	assert.ErrorContains(t, err, "unexpected status code 400")
+	assert.Equal(t, primary.requests(), 1)
+	assert.Equal(t, fallback.requests(), 3)
+
+	// all endpoints down
+	primary.setStatus(http.StatusInternalServerError)
+	fallback.setStatus(http.StatusInternalServerError)
+	_, err = c.GetBeaconNodeVersion(ctx)
+	assert.ErrorContains(t, err, "unexpected status code 500")
+}
+
+func TestTimeout(t *testing.T) {
+	ctx := context.Background()
+	slow := newFakeBeaconNode(t)
+	slow.delay = 200 * time.Millisecond
+	fallback := newFakeBeaconNode(t)
+	c, err := New(slow.URL, WithFallbackURLs(fallback.URL), WithTimeout(20*time.Millisecond))
+	assert.NilError(t, err)
+
+	_, err = c.GetBeaconNodeVersion(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, fallback.requests(), 1)
+
+	_, err = New(slow.URL, WithTimeout(0))
+	assert.ErrorContains(t, err, "timeout must be positive")
+}
+
+func TestNotFound(t *testing.T) {
+	ctx := context.Background()
+	node := newFakeBeaconNode(t)
+	fallback := newFakeBeaconNode(t)
+	c, err := New(node.URL, WithFallbackURLs(fallback.URL))
+	assert.NilError(t, err)
+
+	node.setStatus(http.StatusNotFound)
+	duties, err := c.GetProposerDutiesByEpoch(ctx, 1)
+	assert.NilError(t, err)
+	assert.Assert(t, duties == nil)
+	validators, err := c.GetValidatorByIndices(ctx, "head", []int64{1})
+	assert.NilError(t, err)
+	assert.Assert(t, validators == nil)
+	pubkeys, err := c.GetValidatorPubkeys(ctx, []int64{1})
+	assert.NilError(t, err)
+	assert.Equal(t, len(pubkeys), 0)
+	assert.Equal(t, fallback.requests(), 0)
+}
+
+func TestProposerDutiesCache(t *testing.T) {
+	ctx := context.Background()
+	node := newFakeBeaconNode(t)
+	c, err := New(node.URL)
+	assert.NilError(t, err)
+	startClient(t, c)
+
+	node.setDependentRoot("0x01")
+	node.sendHead(t, c, HeadEvent{Slot: 49, Block: "0x31", CurrentDutyDependentRoot: "0x01"})
+	duties, err := c.GetProposerDutiesByEpoch(ctx, 3)
+	assert.NilError(t, err)
+	duty, err := duties.GetDutyForSlot(50)
+	assert.NilError(t, err)
+	assert.Equal(t, duty.ValidatorIndex, uint64(50))
+	assert.Equal(t, node.requests(), 1)
+
+	// duties of the current epoch are served from the cache while the head depends on the same
+	// block
+	cached, err := c.GetProposerDutiesByEpoch(ctx, 3)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cached, duties)
+	assert.Equal(t, node.requests(), 1)
+	node.sendHead(t, c, HeadEvent{Slot: 50, Block: "0x32", CurrentDutyDependentRoot: "0x01"})
+	_, err = c.GetProposerDutiesByEpoch(ctx, 3)
+	assert.NilError(t, err)
+	assert.Equal(t, node.requests(), 1)
+
+	// duties of the next epoch depend on the head block
+	node.setDependentRoot("0x32")
+	_, err = c.GetProposerDutiesByEpoch(ctx, 4)
+	assert.NilError(t, err)
+	_, err = c.GetProposerDutiesByEpoch(ctx, 4)
+	assert.NilError(t, err)
+	assert.Equal(t, node.requests(), 2)
+	node.setDependentRoot("0x33")
+	node.sendHead(t, c, HeadEvent{Slot: 51, Block: "0x33", CurrentDutyDependentRoot: "0x01"})
+	duties, err = c.GetProposerDutiesByEpoch(ctx, 4)
+	assert.NilError(t, err)
+	assert.Equal(t, duties.DependentRoot, "0x33")
+	assert.Equal(t, node.requests(), 3)
+
+	// in the next epoch, the duties of the previous one are still checked
+	node.sendHead(t, c, HeadEvent{Slot: 64, Block: "0x40", PreviousDutyDependentRoot: "0x01", CurrentDutyDependentRoot: "0x33"})
+	_, err = c.GetProposerDutiesByEpoch(ctx, 3)
+	assert.NilError(t, err)
+	_, err = c.GetProposerDutiesByEpoch(ctx, 4)
+	assert.NilError(t, err)
+	assert.Equal(t, node.requests(), 3)
+
+	// old epochs are evicted
+	node.sendHead(t, c, HeadEvent{Slot: 1000, Block: "0xff"})
+	for epoch := uint64(5); epoch < 5+maxCachedEpochs; epoch++ {
+		_, err = c.GetProposerDutiesByEpoch(ctx, epoch)
+		assert.NilError(t, err)
+	}
+	assert.Equal(t, node.requests(), 3+maxCachedEpochs)
+	_, err = c.GetProposerDutiesByEpoch(ctx, 3)
+	assert.NilError(t, err)
+	assert.Equal(t, node.requests(), 4+maxCachedEpochs)
+}
+
+func TestProposerDutiesReorg(t *testing.T) {
+	ctx := context.Background()
+	node := newFakeBeaconNode(t)
+	store := newMemoryStore()
+	c, err := New(node.URL, WithStore(store))
+	assert.NilError(t, err)
+	startClient(t, c)
+
+	node.setDependentRoot("0x01")
+	node.sendHead(t, c, HeadEvent{Slot: 48, Block: "0x30", CurrentDutyDependentRoot: "0x01"})
+	duties, err := c.GetProposerDutiesByEpoch(ctx, 3)
+	assert.NilError(t, err)
+	assert.Equal(t, duties.DependentRoot, "0x01")
+
+	// the block the duties depend on is reorged
+	node.setDependentRoot("0x02")
+	node.sendHead(t, c, HeadEvent{Slot: 48, Block: "0x30b", CurrentDutyDependentRoot: "0x02"})
+	duties, err = c.GetProposerDutiesByEpoch(ctx, 3)
+	assert.NilError(t, err)
+	assert.Equal(t, duties.DependentRoot, "0x02")
+	assert.Equal(t, store.duties[3].DependentRoot, "0x02")
+	_, err = c.GetProposerDutiesByEpoch(ctx, 3)
+	assert.NilError(t, err)
+	assert.Equal(t, node.requests(), 2)
+}
+
+func TestProposerDutiesNotCachedWithoutHead(t *testing.T) {
+	ctx := context.Background()
+	node := newFakeBeaconNode(t)
+	c, err := New(node.URL)
+	assert.NilError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = c.GetProposerDutiesByEpoch(ctx, 3)
+		assert.NilError(t, err)
+	}
+	assert.Equal(t, node.requests(), 2)
+}
+
+func TestProposerDutiesNotCachedIfOptimistic(t *testing.T) {
+	ctx := context.Background()
+	node := newFakeBeaconNode(t)
+	node.executionOptimistic = true
+	c, err := New(node.URL)
+	assert.NilError(t, err)
+	startClient(t, c)
+	node.sendHead(t, c, HeadEvent{Slot: 49, Block: "0x31"})
+
+	for i := 0; i < 2; i++ {
+		_, err = c.GetProposerDutiesByEpoch(ctx, 3)
+		assert.NilError(t, err)
+	}
+	assert.Equal(t, node.requests(), 2)
+}
+
+func TestValidatorPubkeysCache(t *testing.T) {
+	ctx := context.Background()
+	node := newFakeBeaconNode(t)
+	c, err := New(node.URL)
+	assert.NilError(t, err)
+
+	// unknown validators are omitted, requests are chunked
+	pubkeys, err := c.GetValidatorPubkeys(ctx, indexRange(50, 150))
+	assert.NilError(t, err)
+	assert.Equal(t, len(pubkeys), 50)
+	assert.Equal(t, pubkeys[99], pubkeyHex(99))
+	assert.Equal(t, node.requests(), 2)
+
+	// only the missing pubkeys are fetched
+	pubkeys, err = c.GetValidatorPubkeys(ctx, indexRange(0, 100))
+	assert.NilError(t, err)
+	assert.Equal(t, len(pubkeys), 100)
+	assert.Equal(t, pubkeys[0], pubkeyHex(0))
+	assert.Equal(t, node.requests(), 3)
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	node := newFakeBeaconNode(t)
+	store := newMemoryStore()
+	c, err := New(node.URL, WithStore(store))
+	assert.NilError(t, err)
+
+	node.setDependentRoot("0x01")
+	_, err = c.GetProposerDutiesByEpoch(ctx, 3)
+	assert.NilError(t, err)
+	_, err = c.GetValidatorPubkeys(ctx, indexRange(0, 10))
+	assert.NilError(t, err)
+	assert.Equal(t, node.requests(), 2)
+	assert.Equal(t, len(store.duties[3].Data), 16)
+	assert.Equal(t, len(store.pubkeys), 10)
+
+	// a new client with the same store doesn't have to ask the beacon node again, once the head
+	// confirms that the stored duties are still valid
+	c, err = New(node.URL, WithStore(store))
+	assert.NilError(t, err)
+	startClient(t, c)
+	node.sendHead(t, c, HeadEvent{Slot: 49, Block: "0x31", CurrentDutyDependentRoot: "0x01"})
+	duties, err := c.GetProposerDutiesByEpoch(ctx, 3)
+	assert.NilError(t, err)
+	assert.Equal(t, len(duties.Data), 16)
+	pubkeys, err := c.GetValidatorPubkeys(ctx, indexRange(0, 10))
+	assert.NilError(t, err)
+	assert.Equal(t, len(pubkeys), 10)
+	assert.Equal(t, node.requests(), 2)
+}
@@ -0,0 +1,188 @@
+package beaconapiclient
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxCachedEpochs is the number of epochs the proposer duties are kept in memory for.
+const maxCachedEpochs = 4
+
+// Store persists proposer duties and validator pubkeys, so that they don't have to be fetched
+// again after a restart.
+type Store interface {
+	// GetProposerDuties returns the stored proposer duties of the epoch, or nil if there are none.
+	GetProposerDuties(ctx context.Context, epoch uint64) (*GetProposerDutiesResponse, error)
+	// PutProposerDuties stores the proposer duties of the epoch, replacing those stored before.
+	PutProposerDuties(ctx context.Context, epoch uint64, duties *GetProposerDutiesResponse) error
+	// GetValidatorPubkeys returns the stored hex encoded pubkeys of those of the given validators
+	// that are known.
+	GetValidatorPubkeys(ctx context.Context, validatorIndices []int64) (map[int64]string, error)
+	PutValidatorPubkeys(ctx context.Context, pubkeys map[int64]string) error
+}
+
+// cache keeps the proposer duties of the latest epochs and the pubkeys of all validators looked up
+// so far in memory, backed by an optional store. Failing to access the store is not fatal, the
+// data will just be fetched from the beacon node.
+//
+// Proposer duties change if the block they depend on is reorged, so they are only served while
+// their dependent root matches the latest head of the beacon node (see Client.Start).
+type cache struct {
+	store Store
+
+	mux     sync.Mutex
+	duties  map[uint64]*GetProposerDutiesResponse
+	pubkeys map[int64]string
+	// head is the latest head event of the beacon node, or nil if it is not known
+	head *HeadEvent
+}
+
+func newCache() *cache {
+	return &cache{
+		duties:  make(map[uint64]*GetProposerDutiesResponse),
+		pubkeys: make(map[int64]string),
+	}
+}
+
+func (c *cache) setHead(head *HeadEvent) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.head = head
+}
+
+func (c *cache) getHead() *HeadEvent {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.head
+}
+
+// getProposerDuties returns the cached proposer duties of the epoch. It returns nil if there are
+// none or if they are not known to be valid at the latest head.
+func (c *cache) getProposerDuties(ctx context.Context, epoch uint64) *GetProposerDutiesResponse {
+	c.mux.Lock()
+	head := c.head
+	duties, ok := c.duties[epoch]
+	c.mux.Unlock()
+	if head == nil {
+		return nil
+	}
+	if ok {
+		if !duties.validAt(head) {
+			return nil
+		}
+		return duties
+	}
+	if c.store == nil {
+		return nil
+	}
+
+	duties, err := c.store.GetProposerDuties(ctx, epoch)
+	if err != nil {
+		log.Warn().Err(err).Uint64("epoch", epoch).Msg("failed to load proposer duties from store")
+		return nil
+	}
+	if duties == nil || !duties.validAt(head) {
+		return nil
+	}
+	c.putProposerDutiesInMemory(epoch, duties)
+	return duties
+}
+
+// putProposerDuties caches the proposer duties of the epoch. They are only written to the store if
+// they differ from the ones cached before.
+func (c *cache) putProposerDuties(ctx context.Context, epoch uint64, duties *GetProposerDutiesResponse) {
+	previous := c.putProposerDutiesInMemory(epoch, duties)
+	if previous != nil {
+		if previous.DependentRoot == duties.DependentRoot {
+			return
+		}
+		log.Info().
+			Uint64("epoch", epoch).
+			Str("previous-dependent-root", previous.DependentRoot).
+			Str("dependent-root", duties.DependentRoot).
+			Msg("proposer duties changed due to reorg")
+	}
+	if c.store == nil {
+		return
+	}
+	err := c.store.PutProposerDuties(ctx, epoch, duties)
+	if err != nil {
+		log.Warn().Err(err).Uint64("epoch", epoch).Msg("failed to store proposer duties")
+	}
+}
+
+// putProposerDutiesInMemory caches the proposer duties of the epoch in memory and returns the ones
+// cached before, if any.
+func (c *cache) putProposerDutiesInMemory(epoch uint64, duties *GetProposerDutiesResponse) *GetProposerDutiesResponse {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	previous := c.duties[epoch]
+	c.duties[epoch] = duties
+	for len(c.duties) > maxCachedEpochs {
+		oldest := epoch
+		for e := range c.duties {
+			if e < oldest {
+				oldest = e
+			}
+		}
+		delete(c.duties, oldest)
+	}
+	return previous
+}
+
+// getValidatorPubkeys returns the cached pubkeys of the given validators and the indices of the
+// validators not in the cache.
+func (c *cache) getValidatorPubkeys(ctx context.Context, validatorIndices []int64) (map[int64]string, []int64) {
+	pubkeys := make(map[int64]string)
+	missing := []int64{}
+	c.mux.Lock()
+	for _, index := range validatorIndices {
+		if pubkey, ok := c.pubkeys[index]; ok {
+			pubkeys[index] = pubkey
+		} else {
+			missing = append(missing, index)
+		}
+	}
+	c.mux.Unlock()
+	if len(missing) == 0 || c.store == nil {
+		return pubkeys, missing
+	}
+
+	stored, err := c.store.GetValidatorPubkeys(ctx, missing)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load validator pubkeys from store")
+		return pubkeys, missing
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	stillMissing := []int64{}
+	for _, index := range missing {
+		if pubkey, ok := stored[index]; ok {
+			pubkeys[index] = pubkey
+			c.pubkeys[index] = pubkey
+		} else {
+			stillMissing = append(stillMissing, index)
+		}
+	}
+	return pubkeys, stillMissing
+}
+
+func (c *cache) putValidatorPubkeys(ctx context.Context, pubkeys map[int64]string) {
+	if len(pubkeys) == 0 {
+		return
+	}
+	c.mux.Lock()
+	for index, pubkey := range pubkeys {
+		c.pubkeys[index] = pubkey
+	}
+	c.mux.Unlock()
+	if c.store == nil {
+		return
+	}
+	err := c.store.PutValidatorPubkeys(ctx, pubkeys)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to store validator pubkeys")
+	}
+}
@@ -0,0 +1,60 @@
+package beaconapiclient
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var _ configuration.Config = &Config{}
+
+func NewConfig() *Config {
+	c := &Config{}
+	c.Init()
+	return c
+}
+
+type Config struct {
+	FallbackURLs   []string `comment:"beacon API endpoints used if the primary one fails, in order of preference"`
+	RequestTimeout uint64   `comment:"timeout of beacon API requests in seconds"`
+}
+
+func (c *Config) Init() {
+	c.FallbackURLs = []string{}
+}
+
+func (c *Config) Name() string {
+	return "beaconapi"
+}
+
+func (c *Config) Validate() error {
+	if c.RequestTimeout == 0 {
+		return errors.New("request timeout must be positive")
+	}
+	return nil
+}
+
+func (c *Config) SetDefaultValues() error {
+	c.FallbackURLs = []string{}
+	c.RequestTimeout = uint64(defaultTimeout / time.Second)
+	return nil
+}
+
+func (c *Config) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c *Config) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
+
+// Options returns the client options corresponding to the config.
+func (c *Config) Options() []Option {
+	return []Option{
+		WithFallbackURLs(c.FallbackURLs...),
+		WithTimeout(time.Duration(c.RequestTimeout) * time.Second), //nolint:gosec // G115
+	}
+}
@@ -2,10 +2,8 @@ package beaconapiclient
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 
 	"github.com/pkg/errors"
 )
@@ -23,40 +21,61 @@ type ProposerDuty struct {
 	Slot           uint64 `json:"slot,string"`
 }
 
+// GetProposerDutiesByEpoch fetches the proposer duties of the given epoch. It returns nil if the
+// beacon node doesn't know them. Duties that don't depend on an optimistically imported block are
+// cached and served from the cache while the block they depend on is part of the chain according
+// to the head events of the beacon node. Without head events, the duties are fetched on every
+// call.
 func (c *Client) GetProposerDutiesByEpoch(
 	ctx context.Context,
 	epoch uint64,
 ) (*GetProposerDutiesResponse, error) {
-	path := c.url.JoinPath("/eth/v1/validator/duties/proposer/", fmt.Sprint(epoch))
-	req, err := http.NewRequestWithContext(ctx, "GET", path.String(), http.NoBody)
-	if err != nil {
-		return nil, err
-	}
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get proposer duties for epoch %d from consensus node", epoch)
+	if duties := c.cache.getProposerDuties(ctx, epoch); duties != nil {
+		return duties, nil
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode == http.StatusNotFound {
+	response := new(GetProposerDutiesResponse)
+	err := c.get(ctx, func(base *url.URL) *url.URL {
+		return base.JoinPath("/eth/v1/validator/duties/proposer/", fmt.Sprint(epoch))
+	}, response)
+	if errors.Is(err, errNotFound) {
 		return nil, nil
 	}
-	if res.StatusCode != http.StatusOK {
+	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get proposer duties for epoch %d from consensus node", epoch)
 	}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to read consensus client response body")
+	if !response.ExecutionOptimistic {
+		c.cache.putProposerDuties(ctx, epoch, response)
 	}
+	return response, nil
+}
 
-	response := new(GetProposerDutiesResponse)
-	err = json.Unmarshal(body, response)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to unmarshal consensus client response body")
+// validAt checks if the duties are still valid at the given head, i.e., if the block they depend
+// on, the last one before the epoch, has not been reorged. The duties of the next epoch depend on
+// the head block itself and the ones of the current and the previous epoch on the dependent roots
+// of the head event. The duties of older epochs are assumed not to change anymore.
+func (r *GetProposerDutiesResponse) validAt(head *HeadEvent) bool {
+	if len(r.Data) == 0 {
+		return false
+	}
+	epochStart := r.Data[0].Slot
+	for _, duty := range r.Data {
+		epochStart = min(epochStart, duty.Slot)
+	}
+	slotsPerEpoch := uint64(len(r.Data))
+	switch {
+	case head.Slot+slotsPerEpoch < epochStart:
+		return false
+	case head.Slot < epochStart:
+		return r.DependentRoot == head.Block
+	case head.Slot < epochStart+slotsPerEpoch:
+		return r.DependentRoot == head.CurrentDutyDependentRoot
+	case head.Slot < epochStart+2*slotsPerEpoch:
+		return r.DependentRoot == head.PreviousDutyDependentRoot
+	default:
+		return true
 	}
-
-	return response, nil
 }
 
 func (r *GetProposerDutiesResponse) GetDutyForSlot(slot uint64) (ProposerDuty, error) {
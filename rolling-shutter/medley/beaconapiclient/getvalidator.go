@@ -3,11 +3,9 @@ package beaconapiclient
 import (
 	"context"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/pkg/errors"
 	blst "github.com/supranational/blst/bindings/go"
@@ -37,55 +35,73 @@ type Validator struct {
 	WithdrawalEpoch            uint64 `json:"withdrawal_epoch,string"`
 }
 
+// maxIndicesPerRequest is the maximum number of validators that are requested at once.
+const maxIndicesPerRequest = 64
+
+// GetValidatorByIndices fetches the given validators at the given state. It returns nil if the
+// beacon node doesn't know the state.
 func (c *Client) GetValidatorByIndices(
 	ctx context.Context,
 	stateID string,
 	validatorIndices []int64,
 ) (*GetValidatorByIndexResponse, error) {
-	path := c.url.JoinPath("/eth/v1/beacon/states/", stateID, "/validators/")
-	query := url.Values{}
-	for _, index := range validatorIndices {
-		query.Add("id", fmt.Sprint(index))
-	}
-	path.RawQuery = query.Encode()
-
-	req, err := http.NewRequestWithContext(ctx, "GET", path.String(), http.NoBody)
-	if err != nil {
-		return nil, err
+	response := new(GetValidatorByIndexResponse)
+	err := c.get(ctx, func(base *url.URL) *url.URL {
+		path := base.JoinPath("/eth/v1/beacon/states/", stateID, "/validators/")
+		query := url.Values{}
+		for _, index := range validatorIndices {
+			query.Add("id", fmt.Sprint(index))
+		}
+		path.RawQuery = query.Encode()
+		return path
+	}, response)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
 	}
-	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get validator by index from consensus node")
 	}
-	defer res.Body.Close()
+	return response, nil
+}
 
-	if res.StatusCode == http.StatusNotFound {
-		return nil, nil
-	}
-	if res.StatusCode != http.StatusOK {
-		return nil, errors.Wrap(err, "failed to get validator by index from consensus node")
-	}
+// GetValidatorPubkeys returns the hex encoded pubkeys of the given validators. Validators unknown
+// to the beacon node are omitted. As pubkeys never change, they are cached indefinitely and only
+// the missing ones are fetched from the head state, in chunks of at most maxIndicesPerRequest.
+func (c *Client) GetValidatorPubkeys(ctx context.Context, validatorIndices []int64) (map[int64]string, error) {
+	pubkeys, missing := c.cache.getValidatorPubkeys(ctx, validatorIndices)
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to read consensus client response body")
+	fetched := make(map[int64]string)
+	for i := 0; i < len(missing); i += maxIndicesPerRequest {
+		end := i + maxIndicesPerRequest
+		if end > len(missing) {
+			end = len(missing)
+		}
+		response, err := c.GetValidatorByIndices(ctx, "head", missing[i:end])
+		if err != nil {
+			return nil, err
+		}
+		if response == nil {
+			continue
+		}
+		for _, data := range response.Data {
+			fetched[int64(data.Index)] = data.Validator.PubkeyHex //nolint:gosec // G115
+		}
 	}
+	c.cache.putValidatorPubkeys(ctx, fetched)
 
-	response := new(GetValidatorByIndexResponse)
-	err = json.Unmarshal(body, response)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to unmarshal consensus client response body")
+	for index, pubkey := range fetched {
+		pubkeys[index] = pubkey
 	}
-
-	return response, nil
+	return pubkeys, nil
 }
 
 func (v *Validator) GetPubkey() (*blst.P1Affine, error) {
-	pubkeyHex := v.PubkeyHex
-	if pubkeyHex[:2] == "0x" {
-		pubkeyHex = pubkeyHex[2:]
-	}
+	return ParsePubkey(v.PubkeyHex)
+}
 
+// ParsePubkey decodes a hex encoded, compressed validator pubkey.
+func ParsePubkey(pubkeyHex string) (*blst.P1Affine, error) {
+	pubkeyHex = strings.TrimPrefix(pubkeyHex, "0x")
 	pubkeyBytes, err := hex.DecodeString(pubkeyHex)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to hex decode validator pubkey")
@@ -0,0 +1,106 @@
+package beaconapiclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	encodeableurl "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/url"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+)
+
+const (
+	// headEventsRetryInterval is the time to wait before subscribing to head events again after
+	// the subscription failed.
+	headEventsRetryInterval = 5 * time.Second
+	// headEventsIdleTimeout is the time after which the subscription to head events is considered
+	// broken if no data has been received.
+	headEventsIdleTimeout = 2 * time.Minute
+)
+
+// HeadEvent is emitted by the beacon node whenever its head changes.
+type HeadEvent struct {
+	Slot                      uint64 `json:"slot,string"`
+	Block                     string `json:"block"`
+	PreviousDutyDependentRoot string `json:"previous_duty_dependent_root"`
+	CurrentDutyDependentRoot  string `json:"current_duty_dependent_root"`
+	ExecutionOptimistic       bool   `json:"execution_optimistic"`
+}
+
+// Start subscribes to the head events of the beacon node, which are used to check if cached
+// proposer duties are still valid. If the subscription fails, it is retried with the next
+// endpoint.
+func (c *Client) Start(ctx context.Context, runner service.Runner) error { //nolint:unparam
+	runner.Go(func() error {
+		for attempt := 0; ; attempt++ {
+			c.mux.Lock()
+			index := (c.preferred + attempt) % len(c.urls)
+			c.mux.Unlock()
+			err := c.subscribeHeadEvents(ctx, index)
+			c.cache.setHead(nil)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Warn().
+				Err(err).
+				Str("endpoint", encodeableurl.Redact(c.urls[index].String())).
+				Msg("beacon API head event subscription failed")
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(headEventsRetryInterval):
+			}
+		}
+	})
+	return nil
+}
+
+// subscribeHeadEvents reads the head events of the endpoint with the given index until the
+// connection fails.
+func (c *Client) subscribeHeadEvents(ctx context.Context, index int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	u := c.urls[index].JoinPath("/eth/v1/events")
+	u.RawQuery = "topics=head"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	// The stream is long-lived, so the request timeout does not apply. Instead, the
+	// connection is dropped if it has been idle for too long.
+	res, err := (&http.Client{Transport: c.c.Transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	idle := time.AfterFunc(headEventsIdleTimeout, cancel)
+	defer idle.Stop()
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		idle.Reset(headEventsIdleTimeout)
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		head := new(HeadEvent)
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), head); err != nil {
+			return errors.Wrap(err, "failed to unmarshal head event")
+		}
+		c.cache.setHead(head)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("head event stream closed")
+}
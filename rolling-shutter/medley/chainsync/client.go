@@ -2,7 +2,6 @@ package chainsync
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -18,6 +17,7 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/number"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/logger"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 )
 
 var noopLogger = &logger.NoopLogger{}
@@ -30,7 +30,7 @@ type Client struct {
 
 	options *options
 	chainID *big.Int
-	privKey *ecdsa.PrivateKey
+	signer  signer.Signer
 
 	KeyperSetManager *bindings.KeyperSetManager
 	KeyBroadcast     *bindings.KeyBroadcastContract
@@ -118,18 +118,14 @@ func (s *Client) BroadcastEonKey(ctx context.Context, eon uint64, eonPubKey []by
 	// if we have a different key, error
 	// don't do a transaction
 	// s.KeyBroadcast.GetEonKey(eon)
-	if s.privKey == nil {
+	if s.signer == nil {
 		return nil, errors.New("can't broadcast eon public-key, client does not have a signer set")
 	}
 	chainID, err := s.ChainID(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "retrieve chain id")
 	}
-	opts, err := bind.NewKeyedTransactorWithChainID(s.privKey, chainID)
-	if err != nil {
-		return nil, errors.Wrap(err, "construct signer transaction opts")
-	}
-	opts.Context = ctx
+	opts := signer.NewTransactor(ctx, s.signer, chainID)
 	return s.KeyBroadcast.BroadcastEonKey(opts, eon, eonPubKey)
 }
 
@@ -5,7 +5,6 @@ import (
 	"crypto/ecdsa"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/pkg/errors"
 	"github.com/shutter-network/shop-contracts/bindings"
@@ -15,7 +14,9 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/event"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/syncer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/number"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/failover"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 )
 
 type Option func(*options) error
@@ -23,12 +24,12 @@ type Option func(*options) error
 type options struct {
 	keyperSetManagerAddress     *common.Address
 	keyBroadcastContractAddress *common.Address
-	clientURL                   string
+	clientURLs                  []string
 	client                      syncclient.Client
 	logger                      log.Logger
 	runner                      service.Runner
 	syncStart                   *number.BlockNumber
-	privKey                     *ecdsa.PrivateKey
+	signer                      signer.Signer
 
 	handlerShutterState event.ShutterStateHandler
 	handlerKeyperSet    event.KeyperSetHandler
@@ -37,11 +38,11 @@ type options struct {
 }
 
 func (o *options) verify() error {
-	if o.clientURL != "" && o.client != nil {
+	if len(o.clientURLs) > 0 && o.client != nil {
 		// TODO: error message
 		return errors.New("can't use client and client url")
 	}
-	if o.clientURL == "" && o.client == nil {
+	if len(o.clientURLs) == 0 && o.client == nil {
 		// TODO: error message
 		return errors.New("have to provide either url or client")
 	}
@@ -59,11 +60,15 @@ func (o *options) apply(ctx context.Context, c *Client) error {
 		client syncclient.Client
 		err    error
 	)
-	if o.clientURL != "" {
-		o.client, err = ethclient.DialContext(ctx, o.clientURL)
+	if len(o.clientURLs) > 0 {
+		var failoverClient *failover.Client
+		failoverClient, err = failover.Dial(ctx, o.clientURLs)
 		if err != nil {
 			return err
 		}
+		o.client = failoverClient
+		// the client is owned by us, so we need to run its health checks
+		c.services = append(c.services, failoverClient)
 	}
 	client = o.client
 	c.log = o.logger
@@ -132,7 +137,7 @@ func (o *options) apply(ctx context.Context, c *Client) error {
 	if o.handlerBlock != nil {
 		c.services = append(c.services, c.uhsync)
 	}
-	c.privKey = o.privKey
+	c.signer = o.signer
 	return nil
 }
 
@@ -140,7 +145,7 @@ func defaultOptions() *options {
 	return &options{
 		keyperSetManagerAddress:     &predeploy.KeyperSetManagerAddr,
 		keyBroadcastContractAddress: &predeploy.KeyBroadcastContractAddr,
-		clientURL:                   "",
+		clientURLs:                  nil,
 		client:                      nil,
 		logger:                      noopLogger,
 		runner:                      nil,
@@ -180,8 +185,14 @@ func WithKeyperSetManager(address common.Address) Option {
 }
 
 func WithClientURL(url string) Option {
+	return WithClientURLs(url)
+}
+
+// WithClientURLs connects to multiple JSON RPC endpoints of the same chain, failing over between
+// them if one becomes unavailable, see failover.Client.
+func WithClientURLs(urls ...string) Option {
 	return func(o *options) error {
-		o.clientURL = url
+		o.clientURLs = urls
 		return nil
 	}
 }
@@ -201,8 +212,12 @@ func WithClient(client syncclient.Client) Option {
 }
 
 func WithPrivateKey(key *ecdsa.PrivateKey) Option {
+	return WithSigner(signer.NewLocal(key))
+}
+
+func WithSigner(s signer.Signer) Option {
 	return func(o *options) error {
-		o.privKey = key
+		o.signer = s
 		return nil
 	}
 }
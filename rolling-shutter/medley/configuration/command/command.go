@@ -15,8 +15,9 @@ import (
 )
 
 type (
-	Option                                   func(*commandBuilderConfig)
-	ConfigurableFunc[T configuration.Config] func(cfg T) error
+	Option                                       func(*commandBuilderConfig)
+	ConfigurableFunc[T configuration.Config]     func(cfg T) error
+	ConfigurableArgsFunc[T configuration.Config] func(cfg T, args []string) error
 )
 
 // CommandBuilder is a factory for easily generating a
@@ -184,6 +185,23 @@ func (cb *CommandBuilder[T]) AddFunctionSubcommand(
 	})
 }
 
+// AddFunctionWithArgsSubcommand is like AddFunctionSubcommand, but additionally passes the
+// positional arguments to the function.
+func (cb *CommandBuilder[T]) AddFunctionWithArgsSubcommand(
+	fnc ConfigurableArgsFunc[T],
+	use, short string,
+	args cobra.PositionalArgs,
+) {
+	cb.cobraCommand.AddCommand(&cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  args,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cb.WrapFuncParseConfig(func(cfg T) error { return fnc(cfg, args) })(cmd, args)
+		},
+	})
+}
+
 func (cb *CommandBuilder[T]) WrapFuncParseConfig(fnc ConfigurableFunc[T]) CobraRunE {
 	return func(cmd *cobra.Command, args []string) error {
 		cfg := NewConfigForFunc(fnc)
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/keys"
 )
 
@@ -17,25 +20,44 @@ func NewEthnodeConfig() *EthnodeConfig {
 }
 
 type EthnodeConfig struct {
-	PrivateKey    *keys.ECDSAPrivate `shconfig:",required"`
-	DeploymentDir string             `                     comment:"Contract source directory"`
-	EthereumURL   string             `                     comment:"The layer 1 JSON RPC endpoint"`
+	PrivateKey           *keys.ECDSAPrivate `comment:"The private key to sign with, required unless a remote signer is configured"`
+	RemoteSigner         *RemoteSignerConfig
+	DeploymentDir        string   `comment:"Contract source directory"`
+	EthereumURL          string   `comment:"The layer 1 JSON RPC endpoint"`
+	FallbackEthereumURLs []string `comment:"Optional, JSON RPC endpoints of the same chain used if EthereumURL fails or lags"`
 }
 
 func (c *EthnodeConfig) Init() {
 	c.PrivateKey = &keys.ECDSAPrivate{}
+	c.RemoteSigner = &RemoteSignerConfig{}
 }
 
 func (c *EthnodeConfig) Name() string {
 	return "ethnode"
 }
 
+// Validate checks that exactly one of a private key and a remote signer is configured. It is not
+// called automatically, see Config.Validate.
 func (c *EthnodeConfig) Validate() error {
-	return nil
+	return ValidateSigner(c.PrivateKey, c.RemoteSigner)
+}
+
+// Address returns the address of the configured key.
+func (c *EthnodeConfig) Address() common.Address {
+	if c.RemoteSigner.Enabled() {
+		return c.RemoteSigner.Address
+	}
+	return c.PrivateKey.EthereumAddress()
+}
+
+// EthereumURLs returns the primary JSON RPC endpoint followed by the fallback endpoints.
+func (c *EthnodeConfig) EthereumURLs() []string {
+	return append([]string{c.EthereumURL}, c.FallbackEthereumURLs...)
 }
 
 func (c *EthnodeConfig) SetDefaultValues() error {
 	c.EthereumURL = "http://127.0.0.1:8545/"
+	c.FallbackEthereumURLs = []string{}
 	c.DeploymentDir = "./deployments/localhost/"
 	return nil
 }
@@ -54,5 +76,68 @@ func (c *EthnodeConfig) SetExampleValues() error {
 }
 
 func (c EthnodeConfig) TOMLWriteHeader(w io.Writer) (int, error) {
-	return fmt.Fprintf(w, "# Ethereum address: %s\n", c.PrivateKey.EthereumAddress())
+	return fmt.Fprintf(w, "# Ethereum address: %s\n", c.Address())
+}
+
+// ValidateSigner checks that exactly one of the given private key and remote signer is configured.
+func ValidateSigner(privateKey *keys.ECDSAPrivate, remoteSigner *RemoteSignerConfig) error {
+	hasPrivateKey := privateKey != nil && privateKey.Key != nil
+	if remoteSigner.Enabled() {
+		if hasPrivateKey {
+			return errors.New("PrivateKey must not be set if a remote signer is configured")
+		}
+		return remoteSigner.Validate()
+	}
+	if !hasPrivateKey {
+		return errors.New("PrivateKey is required unless a remote signer is configured")
+	}
+	return nil
+}
+
+var _ Config = &RemoteSignerConfig{}
+
+// RemoteSignerConfig configures a remote signer, so that the private key does not have to be part
+// of the config. See signer.RemoteSigner for the protocol. The signer is disabled if URL is empty.
+type RemoteSignerConfig struct {
+	URL     string         `comment:"URL of the remote signer, leave empty to sign with PrivateKey"`
+	Address common.Address `comment:"address of the key the remote signer signs with"`
+	Timeout uint64         `comment:"timeout of signing requests in seconds"`
+}
+
+func (c *RemoteSignerConfig) Init() {}
+
+func (c *RemoteSignerConfig) Name() string {
+	return "remotesigner"
+}
+
+func (c *RemoteSignerConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.Address == (common.Address{}) {
+		return errors.New("Address of the remote signer is not set")
+	}
+	if c.Timeout == 0 {
+		return errors.New("Timeout of the remote signer must be positive")
+	}
+	return nil
+}
+
+func (c *RemoteSignerConfig) SetDefaultValues() error {
+	c.URL = ""
+	c.Timeout = 10
+	return nil
+}
+
+func (c *RemoteSignerConfig) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c RemoteSignerConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
+
+// Enabled reports if a remote signer is configured.
+func (c *RemoteSignerConfig) Enabled() bool {
+	return c != nil && c.URL != ""
 }
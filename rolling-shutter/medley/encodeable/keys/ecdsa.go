@@ -60,6 +60,11 @@ func (k *ECDSAPrivate) Equal(b *ECDSAPrivate) bool {
 }
 
 func (k *ECDSAPrivate) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		// an empty value means that no key is set, see MarshalText
+		k.Key = nil
+		return nil
+	}
 	dec, err := hex.DecodeHex(b)
 	if err != nil {
 		return err
@@ -73,6 +78,9 @@ func (k *ECDSAPrivate) UnmarshalText(b []byte) error {
 }
 
 func (k *ECDSAPrivate) MarshalText() ([]byte, error) {
+	if k.Key == nil {
+		return []byte{}, nil
+	}
 	return hex.EncodeHex(k.Bytes()), nil
 }
 
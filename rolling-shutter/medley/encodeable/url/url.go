@@ -24,3 +24,12 @@ func (u *URL) UnmarshalText(text []byte) error {
 	u.URL = u1
 	return nil
 }
+
+// Redact strips the user info, path and query from the URL as they often contain API keys.
+func Redact(rawURL string) string {
+	u, err := gourl.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "<invalid url>"
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -13,11 +13,11 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 
+	syncclient "github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/db"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
@@ -86,7 +86,7 @@ type EventSyncUpdate struct {
 
 // EventSyncer watches the blockchain for events of given types and yields them in order.
 type EventSyncer struct {
-	Client         *ethclient.Client
+	Client         syncclient.Client
 	FinalityOffset uint64
 
 	Events       []*EventType
@@ -100,7 +100,7 @@ type EventSyncer struct {
 // New creates a new event syncer. It will look for events starting at a certain block number and
 // log index. The types of events to filter for are specified as a set of EventTypes. The finality
 // offset is the number of blocks we trail behind the current block to be safe from reorgs.
-func New(client *ethclient.Client, finalityOffset uint64, events []*EventType, fromBlock uint64, fromLogIndex uint64) *EventSyncer {
+func New(client syncclient.Client, finalityOffset uint64, events []*EventType, fromBlock uint64, fromLogIndex uint64) *EventSyncer {
 	return &EventSyncer{
 		Client:         client,
 		FinalityOffset: finalityOffset,
@@ -0,0 +1,283 @@
+// Package failover implements an Ethereum JSON RPC client that is backed by multiple endpoints of
+// the same chain. It continuously checks the health of the endpoints and sends requests to a
+// healthy one, failing over to the others if the endpoint in use becomes unavailable or lags
+// behind.
+package failover
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/url"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+)
+
+var _ client.Client = &Client{}
+
+var ErrNoEndpoints = errors.New("no endpoints given")
+
+// Endpoint is a single JSON RPC endpoint. Name identifies the endpoint in logs and metrics, so it
+// must not contain any secrets.
+type Endpoint struct {
+	Name   string
+	Client client.Client
+}
+
+type endpoint struct {
+	Endpoint
+
+	healthy bool
+	head    uint64
+	latency time.Duration
+	err     error
+}
+
+// Client is an Ethereum JSON RPC client which sends its requests to one of multiple healthy
+// endpoints. Endpoints are preferred in the order they are given in, i.e., the client
+// uses the first healthy endpoint and fails over to the next one if it becomes unhealthy.
+type Client struct {
+	opts      *options
+	endpoints []*endpoint
+
+	mux      sync.Mutex
+	selected *endpoint
+	// switched is closed and replaced whenever another endpoint is selected
+	switched  chan struct{}
+	closeOnce sync.Once
+}
+
+// Dial connects to all of the given URLs and performs an initial health check. Endpoints that
+// cannot be dialed are logged and skipped, an error is only returned if none of them can be used.
+func Dial(ctx context.Context, urls []string, opts ...Option) (*Client, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	endpoints := []Endpoint{}
+	names := make(map[string]bool)
+	var dialErr error
+	for i, u := range urls {
+		name := url.Redact(u)
+		if names[name] {
+			// several endpoints of the same host, e.g., with different API keys
+			name = fmt.Sprintf("%s#%d", name, i)
+		}
+		names[name] = true
+		c, err := ethclient.DialContext(ctx, u)
+		if err != nil {
+			log.Warn().Err(err).Str("endpoint", name).Msg("failed to dial ethereum node")
+			dialErr = errors.Wrapf(err, "failed to dial ethereum node at %s", name)
+			continue
+		}
+		endpoints = append(endpoints, Endpoint{Name: name, Client: c})
+	}
+	if len(endpoints) == 0 {
+		return nil, dialErr
+	}
+	c, err := New(endpoints, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.CheckHealth(ctx)
+	return c, nil
+}
+
+// New creates a client for the given endpoints. Until the first health check, all endpoints are
+// considered healthy.
+func New(endpoints []Endpoint, opts ...Option) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	o := defaultOptions()
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	c := &Client{
+		opts:     o,
+		switched: make(chan struct{}),
+	}
+	for _, e := range endpoints {
+		c.endpoints = append(c.endpoints, &endpoint{
+			Endpoint: e,
+			healthy:  true,
+		})
+	}
+	c.selected = c.endpoints[0]
+	c.reportSelection()
+	return c, nil
+}
+
+// Start periodically checks the health of the endpoints until the context is canceled.
+func (c *Client) Start(ctx context.Context, runner service.Runner) error { //nolint:unparam
+	runner.Go(func() error {
+		ticker := time.NewTicker(c.opts.healthCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+				c.CheckHealth(ctx)
+			}
+		}
+	})
+	return nil
+}
+
+// Close closes the connections to all endpoints.
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		for _, ep := range c.endpoints {
+			ep.Client.Close()
+		}
+	})
+}
+
+// Selected returns the name of the endpoint currently in use.
+func (c *Client) Selected() string {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.selected.Name
+}
+
+type healthCheckResult struct {
+	head    uint64
+	latency time.Duration
+	err     error
+}
+
+// CheckHealth queries the latest block number of all endpoints concurrently. Endpoints that fail
+// to respond, respond slower than the configured maximum latency or lag more than the configured
+// number of blocks behind the highest head are considered unhealthy. If the endpoint in use is
+// unhealthy, the client fails over to the first healthy one.
+func (c *Client) CheckHealth(ctx context.Context) {
+	results := make([]healthCheckResult, len(c.endpoints))
+	wg := sync.WaitGroup{}
+	for i, ep := range c.endpoints {
+		wg.Add(1)
+		go func(i int, ep *endpoint) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, c.opts.maxLatency)
+			defer cancel()
+			start := time.Now()
+			head, err := ep.Client.BlockNumber(checkCtx)
+			results[i] = healthCheckResult{head: head, latency: time.Since(start), err: err}
+		}(i, ep)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	var maxHead uint64
+	for _, res := range results {
+		if res.err == nil && res.head > maxHead {
+			maxHead = res.head
+		}
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for i, ep := range c.endpoints {
+		res := results[i]
+		ep.latency = res.latency
+		ep.err = res.err
+		if res.err == nil {
+			ep.head = res.head
+		}
+		healthy := res.err == nil && res.head+c.opts.maxBlockLag >= maxHead
+		if healthy != ep.healthy {
+			logEvent := log.Info()
+			if !healthy {
+				logEvent = log.Warn().Err(res.err)
+			}
+			logEvent.
+				Str("endpoint", ep.Name).
+				Bool("healthy", healthy).
+				Uint64("head", ep.head).
+				Uint64("max-head", maxHead).
+				Dur("latency", res.latency).
+				Msg("ethereum node health changed")
+		}
+		ep.healthy = healthy
+		reportHealth(ep)
+	}
+	c.selectLocked()
+}
+
+// markFailed marks the endpoint as unhealthy after a failed request, so that subsequent requests
+// are sent to another endpoint until the next health check.
+func (c *Client) markFailed(ep *endpoint, err error) {
+	metricsEndpointErrors.WithLabelValues(ep.Name).Inc()
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if ep.healthy {
+		log.Warn().Err(err).Str("endpoint", ep.Name).Msg("request to ethereum node failed, marking it unhealthy")
+	}
+	ep.healthy = false
+	ep.err = err
+	reportHealth(ep)
+	c.selectLocked()
+}
+
+// selectLocked selects the first healthy endpoint. If none is healthy, the current selection is
+// kept.
+func (c *Client) selectLocked() {
+	for _, ep := range c.endpoints {
+		if !ep.healthy {
+			continue
+		}
+		if ep != c.selected {
+			log.Info().
+				Str("previous", c.selected.Name).
+				Str("endpoint", ep.Name).
+				Msg("switching ethereum node")
+			metricsFailovers.Inc()
+			c.selected = ep
+			close(c.switched)
+			c.switched = make(chan struct{})
+			c.reportSelection()
+		}
+		return
+	}
+}
+
+// current returns the selected endpoint and a channel that is closed when another endpoint gets
+// selected.
+func (c *Client) current() (*endpoint, <-chan struct{}) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.selected, c.switched
+}
+
+// candidates returns the endpoints to try a request with in order, i.e., the selected one followed
+// by the other healthy ones.
+func (c *Client) candidates() []*endpoint {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	candidates := []*endpoint{c.selected}
+	for _, ep := range c.endpoints {
+		if ep != c.selected && ep.healthy {
+			candidates = append(candidates, ep)
+		}
+	}
+	return candidates
+}
+
+func (c *Client) reportSelection() {
+	for _, ep := range c.endpoints {
+		v := 0.0
+		if ep == c.selected {
+			v = 1
+		}
+		metricsEndpointSelected.WithLabelValues(ep.Name).Set(v)
+	}
+}
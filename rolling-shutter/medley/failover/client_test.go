@@ -0,0 +1,150 @@
+package failover
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
+)
+
+func newTestClient(t *testing.T, n int, opts ...Option) (*Client, []*simulated.Backend) {
+	t.Helper()
+	backends := []*simulated.Backend{}
+	endpoints := []Endpoint{}
+	for i := 0; i < n; i++ {
+		backend := simulated.NewBackend(types.GenesisAlloc{})
+		t.Cleanup(func() { _ = backend.Close() })
+		cl, ok := backend.Client().(client.Client)
+		assert.Assert(t, ok)
+		backends = append(backends, backend)
+		endpoints = append(endpoints, Endpoint{Name: string(rune('a' + i)), Client: cl})
+	}
+	c, err := New(endpoints, opts...)
+	assert.NilError(t, err)
+	return c, backends
+}
+
+func commit(backend *simulated.Backend, n int) {
+	for i := 0; i < n; i++ {
+		backend.Commit()
+	}
+}
+
+func TestFailoverOnEndpointError(t *testing.T) {
+	ctx := context.Background()
+	c, backends := newTestClient(t, 2)
+	commit(backends[1], 2)
+
+	assert.Equal(t, c.Selected(), "a")
+	assert.NilError(t, backends[0].Close())
+	blockNumber, err := c.BlockNumber(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, blockNumber, uint64(2))
+	assert.Equal(t, c.Selected(), "b")
+}
+
+func TestNoFailoverOnRequestError(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t, 2)
+
+	// the endpoint answers with an error, so there is no reason to ask another one
+	_, err := c.TransactionReceipt(ctx, common.Hash{1})
+	assert.Assert(t, err != nil)
+	assert.Equal(t, c.Selected(), "a")
+}
+
+func TestPin(t *testing.T) {
+	ctx := context.Background()
+	c, backends := newTestClient(t, 2)
+	commit(backends[1], 2)
+
+	// requests in the pinned context stick to a, even if it fails
+	pinned := Pin(ctx, c)
+	blockNumber, err := c.BlockNumber(pinned)
+	assert.NilError(t, err)
+	assert.Equal(t, blockNumber, uint64(0))
+	assert.NilError(t, backends[0].Close())
+	_, err = c.BlockNumber(pinned)
+	assert.Assert(t, err != nil)
+	assert.Equal(t, c.Selected(), "b")
+
+	// other requests fail over to b, as does the next pinned context
+	blockNumber, err = c.BlockNumber(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, blockNumber, uint64(2))
+	blockNumber, err = c.BlockNumber(Pin(ctx, c))
+	assert.NilError(t, err)
+	assert.Equal(t, blockNumber, uint64(2))
+}
+
+func TestHealthCheck(t *testing.T) {
+	ctx := context.Background()
+	c, backends := newTestClient(t, 3, WithMaxBlockLag(2))
+
+	// a lags too far behind, b is the first healthy endpoint
+	commit(backends[1], 5)
+	commit(backends[2], 5)
+	c.CheckHealth(ctx)
+	assert.Equal(t, c.Selected(), "b")
+
+	// a has caught up and is preferred again
+	commit(backends[0], 3)
+	c.CheckHealth(ctx)
+	assert.Equal(t, c.Selected(), "a")
+
+	// b and c are down, a is the only endpoint left
+	assert.NilError(t, backends[1].Close())
+	assert.NilError(t, backends[2].Close())
+	commit(backends[0], 10)
+	c.CheckHealth(ctx)
+	assert.Equal(t, c.Selected(), "a")
+
+	// if all endpoints are down, the selection is kept
+	assert.NilError(t, backends[0].Close())
+	c.CheckHealth(ctx)
+	assert.Equal(t, c.Selected(), "a")
+	_, err := c.BlockNumber(ctx)
+	assert.Assert(t, err != nil)
+}
+
+func TestSubscriptionFailover(t *testing.T) {
+	ctx := context.Background()
+	c, backends := newTestClient(t, 2, WithResubscribeInterval(10*time.Millisecond))
+
+	heads := make(chan *types.Header, 10)
+	sub, err := c.SubscribeNewHead(ctx, heads)
+	assert.NilError(t, err)
+	defer sub.Unsubscribe()
+
+	receiveHead := func(backend *simulated.Backend) {
+		t.Helper()
+		hash := backend.Commit()
+		for {
+			select {
+			case head := <-heads:
+				if head.Hash() == hash {
+					return
+				}
+			case err := <-sub.Err():
+				t.Fatalf("subscription failed: %v", err)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timeout waiting for head %s", hash.Hex())
+			}
+		}
+	}
+
+	receiveHead(backends[0])
+
+	// the subscription moves to b once a stops being selected
+	assert.NilError(t, backends[0].Close())
+	c.CheckHealth(ctx)
+	assert.Equal(t, c.Selected(), "b")
+	receiveHead(backends[1])
+	receiveHead(backends[1])
+}
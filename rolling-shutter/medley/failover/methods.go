@@ -0,0 +1,262 @@
+package failover
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
+)
+
+// isEndpointError checks if the error is caused by the endpoint, e.g., because it is unreachable,
+// and not by the request itself. Only in the former case it makes sense to retry the request with
+// another endpoint.
+func isEndpointError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	// the endpoint has answered with a JSON RPC error, e.g., because a call has reverted
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// pinKey is the context key of the endpoint a client is pinned to, see Pin.
+type pinKey struct {
+	client *Client
+}
+
+// Pin returns a context in which the requests of the given client are only sent to the endpoint
+// currently in use. If it fails, the requests fail instead of being retried with another endpoint.
+// This makes sure that related requests, e.g., for the header and the logs of a block range, are
+// answered from the same view of the chain. Clients other than failover clients are not affected.
+func Pin(ctx context.Context, cl client.Client) context.Context {
+	c, ok := cl.(*Client)
+	if !ok {
+		return ctx
+	}
+	ep, _ := c.current()
+	return context.WithValue(ctx, pinKey{client: c}, ep)
+}
+
+// call sends the request to the selected endpoint. If the endpoint fails, it is marked unhealthy
+// and the request is retried with the other healthy endpoints, unless the client is pinned to the
+// endpoint in the context.
+func call[T any](ctx context.Context, c *Client, f func(client.Client) (T, error)) (T, error) {
+	var (
+		res T
+		err error
+	)
+	candidates := c.candidates()
+	if ep, ok := ctx.Value(pinKey{client: c}).(*endpoint); ok {
+		candidates = []*endpoint{ep}
+	}
+	for _, ep := range candidates {
+		res, err = f(ep.Client)
+		if err == nil || !isEndpointError(ctx, err) {
+			return res, err
+		}
+		c.markFailed(ep, err)
+	}
+	return res, err
+}
+
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	return call(ctx, c, func(cl client.Client) (*big.Int, error) {
+		return cl.ChainID(ctx)
+	})
+}
+
+func (c *Client) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return call(ctx, c, func(cl client.Client) (*types.Block, error) {
+		return cl.BlockByHash(ctx, hash)
+	})
+}
+
+func (c *Client) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return call(ctx, c, func(cl client.Client) (*types.Block, error) {
+		return cl.BlockByNumber(ctx, number)
+	})
+}
+
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	return call(ctx, c, func(cl client.Client) (uint64, error) {
+		return cl.BlockNumber(ctx)
+	})
+}
+
+func (c *Client) PeerCount(ctx context.Context) (uint64, error) {
+	return call(ctx, c, func(cl client.Client) (uint64, error) {
+		return cl.PeerCount(ctx)
+	})
+}
+
+func (c *Client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return call(ctx, c, func(cl client.Client) (*types.Header, error) {
+		return cl.HeaderByHash(ctx, hash)
+	})
+}
+
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return call(ctx, c, func(cl client.Client) (*types.Header, error) {
+		return cl.HeaderByNumber(ctx, number)
+	})
+}
+
+func (c *Client) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	type result struct {
+		tx        *types.Transaction
+		isPending bool
+	}
+	res, err := call(ctx, c, func(cl client.Client) (result, error) {
+		tx, isPending, err := cl.TransactionByHash(ctx, hash)
+		return result{tx: tx, isPending: isPending}, err
+	})
+	return res.tx, res.isPending, err
+}
+
+func (c *Client) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
+	return call(ctx, c, func(cl client.Client) (common.Address, error) {
+		return cl.TransactionSender(ctx, tx, block, index)
+	})
+}
+
+func (c *Client) TransactionCount(ctx context.Context, blockHash common.Hash) (uint, error) {
+	return call(ctx, c, func(cl client.Client) (uint, error) {
+		return cl.TransactionCount(ctx, blockHash)
+	})
+}
+
+func (c *Client) TransactionInBlock(ctx context.Context, blockHash common.Hash, index uint) (*types.Transaction, error) {
+	return call(ctx, c, func(cl client.Client) (*types.Transaction, error) {
+		return cl.TransactionInBlock(ctx, blockHash, index)
+	})
+}
+
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return call(ctx, c, func(cl client.Client) (*types.Receipt, error) {
+		return cl.TransactionReceipt(ctx, txHash)
+	})
+}
+
+func (c *Client) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) {
+	return call(ctx, c, func(cl client.Client) (*ethereum.SyncProgress, error) {
+		return cl.SyncProgress(ctx)
+	})
+}
+
+func (c *Client) NetworkID(ctx context.Context) (*big.Int, error) {
+	return call(ctx, c, func(cl client.Client) (*big.Int, error) {
+		return cl.NetworkID(ctx)
+	})
+}
+
+func (c *Client) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return call(ctx, c, func(cl client.Client) (*big.Int, error) {
+		return cl.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *Client) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, c, func(cl client.Client) ([]byte, error) {
+		return cl.StorageAt(ctx, account, key, blockNumber)
+	})
+}
+
+func (c *Client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, c, func(cl client.Client) ([]byte, error) {
+		return cl.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *Client) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return call(ctx, c, func(cl client.Client) (uint64, error) {
+		return cl.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *Client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return call(ctx, c, func(cl client.Client) ([]types.Log, error) {
+		return cl.FilterLogs(ctx, q)
+	})
+}
+
+func (c *Client) PendingBalanceAt(ctx context.Context, account common.Address) (*big.Int, error) {
+	return call(ctx, c, func(cl client.Client) (*big.Int, error) {
+		return cl.PendingBalanceAt(ctx, account)
+	})
+}
+
+func (c *Client) PendingStorageAt(ctx context.Context, account common.Address, key common.Hash) ([]byte, error) {
+	return call(ctx, c, func(cl client.Client) ([]byte, error) {
+		return cl.PendingStorageAt(ctx, account, key)
+	})
+}
+
+func (c *Client) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return call(ctx, c, func(cl client.Client) ([]byte, error) {
+		return cl.PendingCodeAt(ctx, account)
+	})
+}
+
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return call(ctx, c, func(cl client.Client) (uint64, error) {
+		return cl.PendingNonceAt(ctx, account)
+	})
+}
+
+func (c *Client) PendingTransactionCount(ctx context.Context) (uint, error) {
+	return call(ctx, c, func(cl client.Client) (uint, error) {
+		return cl.PendingTransactionCount(ctx)
+	})
+}
+
+func (c *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, c, func(cl client.Client) ([]byte, error) {
+		return cl.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+func (c *Client) CallContractAtHash(ctx context.Context, msg ethereum.CallMsg, blockHash common.Hash) ([]byte, error) {
+	return call(ctx, c, func(cl client.Client) ([]byte, error) {
+		return cl.CallContractAtHash(ctx, msg, blockHash)
+	})
+}
+
+func (c *Client) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	return call(ctx, c, func(cl client.Client) ([]byte, error) {
+		return cl.PendingCallContract(ctx, msg)
+	})
+}
+
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return call(ctx, c, func(cl client.Client) (*big.Int, error) {
+		return cl.SuggestGasPrice(ctx)
+	})
+}
+
+func (c *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return call(ctx, c, func(cl client.Client) (*big.Int, error) {
+		return cl.SuggestGasTipCap(ctx)
+	})
+}
+
+func (c *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return call(ctx, c, func(cl client.Client) (uint64, error) {
+		return cl.EstimateGas(ctx, msg)
+	})
+}
+
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	_, err := call(ctx, c, func(cl client.Client) (struct{}, error) {
+		return struct{}{}, cl.SendTransaction(ctx, tx)
+	})
+	return err
+}
@@ -0,0 +1,83 @@
+package failover
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var metricsEndpointSelected = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "ethrpc",
+		Name:      "endpoint_selected",
+		Help:      "Whether the Ethereum JSON RPC endpoint is the one currently in use (1) or not (0)",
+	},
+	[]string{"endpoint"},
+)
+
+var metricsEndpointHealthy = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "ethrpc",
+		Name:      "endpoint_healthy",
+		Help:      "Whether the Ethereum JSON RPC endpoint is considered healthy (1) or not (0)",
+	},
+	[]string{"endpoint"},
+)
+
+var metricsEndpointHead = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "ethrpc",
+		Name:      "endpoint_head_block_number",
+		Help:      "Latest block number reported by the Ethereum JSON RPC endpoint",
+	},
+	[]string{"endpoint"},
+)
+
+var metricsEndpointLatency = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "ethrpc",
+		Name:      "endpoint_latency_seconds",
+		Help:      "Response time of the Ethereum JSON RPC endpoint in the latest health check",
+	},
+	[]string{"endpoint"},
+)
+
+var metricsEndpointErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "ethrpc",
+		Name:      "endpoint_errors_total",
+		Help:      "Number of requests to the Ethereum JSON RPC endpoint that failed due to the endpoint",
+	},
+	[]string{"endpoint"},
+)
+
+var metricsFailovers = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "ethrpc",
+		Name:      "failovers_total",
+		Help:      "Number of times another Ethereum JSON RPC endpoint has been selected",
+	},
+)
+
+func InitMetrics() {
+	prometheus.MustRegister(metricsEndpointSelected)
+	prometheus.MustRegister(metricsEndpointHealthy)
+	prometheus.MustRegister(metricsEndpointHead)
+	prometheus.MustRegister(metricsEndpointLatency)
+	prometheus.MustRegister(metricsEndpointErrors)
+	prometheus.MustRegister(metricsFailovers)
+}
+
+func reportHealth(ep *endpoint) {
+	healthy := 0.0
+	if ep.healthy {
+		healthy = 1
+	}
+	metricsEndpointHealthy.WithLabelValues(ep.Name).Set(healthy)
+	metricsEndpointHead.WithLabelValues(ep.Name).Set(float64(ep.head))
+	metricsEndpointLatency.WithLabelValues(ep.Name).Set(ep.latency.Seconds())
+}
@@ -0,0 +1,76 @@
+package failover
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultMaxLatency          = 5 * time.Second
+	defaultMaxBlockLag         = 3
+	defaultResubscribeInterval = 2 * time.Second
+)
+
+type Option func(*options) error
+
+type options struct {
+	healthCheckInterval time.Duration
+	maxLatency          time.Duration
+	maxBlockLag         uint64
+	resubscribeInterval time.Duration
+}
+
+func defaultOptions() *options {
+	return &options{
+		healthCheckInterval: defaultHealthCheckInterval,
+		maxLatency:          defaultMaxLatency,
+		maxBlockLag:         defaultMaxBlockLag,
+		resubscribeInterval: defaultResubscribeInterval,
+	}
+}
+
+// WithHealthCheckInterval sets the interval in which the health of the endpoints is checked.
+func WithHealthCheckInterval(interval time.Duration) Option {
+	return func(o *options) error {
+		if interval <= 0 {
+			return errors.New("health check interval must be positive")
+		}
+		o.healthCheckInterval = interval
+		return nil
+	}
+}
+
+// WithMaxLatency sets the time after which an endpoint not answering a health check is considered
+// unhealthy.
+func WithMaxLatency(latency time.Duration) Option {
+	return func(o *options) error {
+		if latency <= 0 {
+			return errors.New("max latency must be positive")
+		}
+		o.maxLatency = latency
+		return nil
+	}
+}
+
+// WithMaxBlockLag sets the number of blocks an endpoint may lag behind the endpoint with the
+// highest head before it is considered unhealthy.
+func WithMaxBlockLag(blocks uint64) Option {
+	return func(o *options) error {
+		o.maxBlockLag = blocks
+		return nil
+	}
+}
+
+// WithResubscribeInterval sets the time to wait before retrying to resubscribe after a
+// subscription has failed.
+func WithResubscribeInterval(interval time.Duration) Option {
+	return func(o *options) error {
+		if interval <= 0 {
+			return errors.New("resubscribe interval must be positive")
+		}
+		o.resubscribeInterval = interval
+		return nil
+	}
+}
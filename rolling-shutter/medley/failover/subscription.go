@@ -0,0 +1,78 @@
+package failover
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/chainsync/client"
+)
+
+type subscribeFunc func(ctx context.Context, cl client.Client) (ethereum.Subscription, error)
+
+// subscribe pins a subscription to the selected endpoint. If the subscription fails or another
+// endpoint gets selected, it is transparently moved to the newly selected endpoint. Events
+// emitted while switching endpoints may be missed. Only the initial subscription error is
+// returned, later ones are logged and retried.
+func (c *Client) subscribe(ctx context.Context, subscribe subscribeFunc) (ethereum.Subscription, error) {
+	ep, switched := c.current()
+	sub, err := subscribe(ctx, ep.Client)
+	if err != nil {
+		if isEndpointError(ctx, err) {
+			c.markFailed(ep, err)
+		}
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for {
+			select {
+			case <-quit:
+				sub.Unsubscribe()
+				return nil
+			case err := <-sub.Err():
+				log.Warn().Err(err).Str("endpoint", ep.Name).Msg("subscription to ethereum node failed")
+				c.markFailed(ep, err)
+			case <-switched:
+				log.Info().Str("endpoint", ep.Name).Msg("moving subscription away from ethereum node")
+			}
+			sub.Unsubscribe()
+
+			for {
+				ep, switched = c.current()
+				sub, err = c.resubscribe(subscribe, ep)
+				if err == nil {
+					break
+				}
+				log.Warn().Err(err).Str("endpoint", ep.Name).Msg("failed to resubscribe to ethereum node")
+				c.markFailed(ep, err)
+				select {
+				case <-quit:
+					return nil
+				case <-time.After(c.opts.resubscribeInterval):
+				}
+			}
+		}
+	}), nil
+}
+
+func (c *Client) resubscribe(subscribe subscribeFunc, ep *endpoint) (ethereum.Subscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.maxLatency)
+	defer cancel()
+	return subscribe(ctx, ep.Client)
+}
+
+func (c *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return c.subscribe(ctx, func(ctx context.Context, cl client.Client) (ethereum.Subscription, error) {
+		return cl.SubscribeNewHead(ctx, ch)
+	})
+}
+
+func (c *Client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return c.subscribe(ctx, func(ctx context.Context, cl client.Client) (ethereum.Subscription, error) {
+		return cl.SubscribeFilterLogs(ctx, q, ch)
+	})
+}
@@ -0,0 +1,146 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+// maxResponseSize limits the size of responses read from the remote signer.
+const maxResponseSize = 1 << 16
+
+// eth1SignPath is the path of Web3Signer's eth1 signing endpoint, relative to the configured URL.
+const eth1SignPath = "/api/v1/eth1/sign"
+
+// signHashPath is the path of the endpoint signing digests, relative to the configured URL.
+const signHashPath = "/api/v1/sign-hash"
+
+// RemoteSigner signs with a key held by a remote signer, e.g. Web3Signer. Data is signed with
+// Web3Signer's eth1 signing endpoint
+//
+//	POST <url>/api/v1/eth1/sign/<address> with body {"data": "0x<data>"}
+//
+// which hashes the data with keccak256 and signs the hash. This is used for transactions and to
+// verify the signer. Shutter messages are signed over SHA3-256 and SSZ digests instead, which
+// Web3Signer cannot produce. They are signed with
+//
+//	POST <url>/api/v1/sign-hash/<address> with body {"hash": "0x<digest>"}
+//
+// which must sign the 32 byte digest as is. Signatures are expected as hex string, either as plain
+// text or as JSON string. Every signature is checked to be created by the configured address.
+type RemoteSigner struct {
+	client  *http.Client
+	url     *url.URL
+	address common.Address
+}
+
+var _ Signer = &RemoteSigner{}
+
+func NewRemote(config *configuration.RemoteSignerConfig) (*RemoteSigner, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	parsedURL, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid remote signer URL")
+	}
+	return &RemoteSigner{
+		client:  &http.Client{Timeout: time.Duration(config.Timeout) * time.Second}, //nolint:gosec // G115
+		url:     parsedURL,
+		address: config.Address,
+	}, nil
+}
+
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+type eth1SignRequest struct {
+	Data string `json:"data"`
+}
+
+type signHashRequest struct {
+	Hash string `json:"hash"`
+}
+
+func (s *RemoteSigner) SignData(ctx context.Context, data []byte) ([]byte, error) {
+	return s.sign(ctx, eth1SignPath, eth1SignRequest{Data: hexutil.Encode(data)}, ethcrypto.Keccak256(data))
+}
+
+func (s *RemoteSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, errors.Errorf("hash must be 32 bytes, got %d", len(hash))
+	}
+	return s.sign(ctx, signHashPath, signHashRequest{Hash: hexutil.Encode(hash)}, hash)
+}
+
+// sign posts the request to the endpoint at the given path and checks that the returned signature
+// signs the given hash.
+func (s *RemoteSigner) sign(ctx context.Context, path string, request any, hash []byte) ([]byte, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := s.url.JoinPath(path, s.address.Hex())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request signature from remote signer")
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response of remote signer")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer responded with status code %d: %s",
+			resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	signature, err := decodeSignature(respBody)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSignature(hash, signature, s.address); err != nil {
+		return nil, errors.Wrap(err, "remote signer returned an unexpected signature")
+	}
+	return signature, nil
+}
+
+// decodeSignature decodes a signature returned as plain text or as JSON string. V may be given as
+// 27 or 28 and is normalized to 0 or 1.
+func decodeSignature(body []byte) ([]byte, error) {
+	text := strings.TrimSpace(string(body))
+	var s string
+	if err := json.Unmarshal([]byte(text), &s); err == nil {
+		text = s
+	}
+	signature, err := hexutil.Decode(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "remote signer returned a malformed signature")
+	}
+	if len(signature) != 65 {
+		return nil, errors.Errorf("remote signer returned a signature of %d bytes, expected 65", len(signature))
+	}
+	if signature[64] >= 27 {
+		signature[64] -= 27
+	}
+	return signature, nil
+}
@@ -0,0 +1,151 @@
+// Package signer provides an abstraction over the ECDSA key a node signs messages and
+// transactions with. The key can either be held locally or by a remote signer.
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+// Signer signs with a secp256k1 key. Signatures are in the format produced by go-ethereum's
+// crypto.Sign, i.e. [R || S || V] with V being 0 or 1.
+type Signer interface {
+	Address() common.Address
+	// SignData signs the keccak256 hash of the given data.
+	SignData(ctx context.Context, data []byte) ([]byte, error)
+	// SignHash signs the given 32 byte digest.
+	SignHash(ctx context.Context, hash []byte) ([]byte, error)
+}
+
+// New creates the signer configured in the given config.
+func New(config *configuration.EthnodeConfig) (Signer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.RemoteSigner.Enabled() {
+		return NewRemote(config.RemoteSigner)
+	}
+	return NewLocal(config.PrivateKey.Key), nil
+}
+
+// LocalSigner signs with a private key held in memory.
+type LocalSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+var _ Signer = &LocalSigner{}
+
+func NewLocal(key *ecdsa.PrivateKey) *LocalSigner {
+	return &LocalSigner{key: key}
+}
+
+func (s *LocalSigner) Address() common.Address {
+	return ethcrypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *LocalSigner) SignData(_ context.Context, data []byte) ([]byte, error) {
+	return ethcrypto.Sign(ethcrypto.Keccak256(data), s.key)
+}
+
+func (s *LocalSigner) SignHash(_ context.Context, hash []byte) ([]byte, error) {
+	return ethcrypto.Sign(hash, s.key)
+}
+
+// Verify signs random data and a random digest and checks that the signatures have been created
+// by the signer's address. This can be used to check that a remote signer is available and
+// configured correctly.
+func Verify(ctx context.Context, s Signer) error {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return err
+	}
+	signature, err := s.SignData(ctx, data)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign test data")
+	}
+	if err := checkSignature(ethcrypto.Keccak256(data), signature, s.Address()); err != nil {
+		return err
+	}
+	signature, err = s.SignHash(ctx, data)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign test digest")
+	}
+	return checkSignature(data, signature, s.Address())
+}
+
+func checkSignature(hash []byte, signature []byte, address common.Address) error {
+	pubkey, err := ethcrypto.SigToPub(hash, signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	signer := ethcrypto.PubkeyToAddress(*pubkey)
+	if signer != address {
+		return errors.Errorf("signature has been created by %s instead of %s", signer, address)
+	}
+	return nil
+}
+
+// NewTransactor creates transaction options signing transactions with the given signer.
+func NewTransactor(ctx context.Context, s Signer, chainID *big.Int) *bind.TransactOpts {
+	txSigner := types.LatestSignerForChainID(chainID)
+	return &bind.TransactOpts{
+		From: s.Address(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != s.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			preimage, err := signingPreimage(txSigner, tx)
+			if err != nil {
+				return nil, err
+			}
+			signature, err := s.SignData(ctx, preimage)
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(txSigner, signature)
+		},
+		Context: ctx,
+	}
+}
+
+// signingPreimage returns the data whose keccak256 hash is signed in the given transaction, so
+// that remote signers can hash it themselves.
+func signingPreimage(txSigner types.Signer, tx *types.Transaction) ([]byte, error) {
+	chainID := txSigner.ChainID()
+	var fields []any
+	switch tx.Type() {
+	case types.LegacyTxType:
+		fields = []any{tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), chainID, uint(0), uint(0)}
+	case types.AccessListTxType:
+		fields = []any{chainID, tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), tx.AccessList()}
+	case types.DynamicFeeTxType:
+		fields = []any{
+			chainID, tx.Nonce(), tx.GasTipCap(), tx.GasFeeCap(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), tx.AccessList(),
+		}
+	default:
+		return nil, errors.Errorf("cannot sign transactions of type %d", tx.Type())
+	}
+	preimage, err := rlp.EncodeToBytes(fields)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Type() != types.LegacyTxType {
+		preimage = append([]byte{tx.Type()}, preimage...)
+	}
+	// guard against encoding the transaction differently than the signer
+	if ethcrypto.Keccak256Hash(preimage) != txSigner.Hash(tx) {
+		return nil, errors.Errorf("failed to encode transaction of type %d for signing", tx.Type())
+	}
+	return preimage, nil
+}
@@ -0,0 +1,221 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+// newFakeRemoteSigner starts a server implementing the signing endpoints of the remote signer
+// with the given key. Like Web3Signer, the eth1 endpoint signs the keccak256 hash of the data.
+func newFakeRemoteSigner(t *testing.T, key *ecdsa.PrivateKey) *httptest.Server {
+	t.Helper()
+	address := ethcrypto.PubkeyToAddress(key.PublicKey)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var hash []byte
+		var err error
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == eth1SignPath+"/"+address.Hex():
+			req := eth1SignRequest{}
+			if err = json.NewDecoder(r.Body).Decode(&req); err == nil {
+				var data []byte
+				data, err = hexutil.Decode(req.Data)
+				hash = ethcrypto.Keccak256(data)
+			}
+		case r.Method == http.MethodPost && r.URL.Path == signHashPath+"/"+address.Hex():
+			req := signHashRequest{}
+			if err = json.NewDecoder(r.Body).Decode(&req); err == nil {
+				hash, err = hexutil.Decode(req.Hash)
+			}
+		default:
+			http.Error(w, "no such key", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		signature, err := ethcrypto.Sign(hash, key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		signature[64] += 27
+		_, _ = w.Write([]byte(hexutil.Encode(signature)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	return key
+}
+
+func newTestRemoteSigner(t *testing.T, url string, address common.Address) *RemoteSigner {
+	t.Helper()
+	s, err := NewRemote(&configuration.RemoteSignerConfig{URL: url, Address: address, Timeout: 5})
+	assert.NilError(t, err)
+	return s
+}
+
+func TestRemoteSigner(t *testing.T) {
+	ctx := context.Background()
+	key := newTestKey(t)
+	server := newFakeRemoteSigner(t, key)
+	remote := newTestRemoteSigner(t, server.URL, ethcrypto.PubkeyToAddress(key.PublicKey))
+	local := NewLocal(key)
+	assert.Equal(t, remote.Address(), local.Address())
+
+	hash := ethcrypto.Keccak256([]byte("message"))
+	remoteSignature, err := remote.SignHash(ctx, hash)
+	assert.NilError(t, err)
+	localSignature, err := local.SignHash(ctx, hash)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, remoteSignature, localSignature)
+
+	data := []byte("message")
+	remoteSignature, err = remote.SignData(ctx, data)
+	assert.NilError(t, err)
+	localSignature, err = local.SignData(ctx, data)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, remoteSignature, localSignature)
+	assert.NilError(t, checkSignature(hash, remoteSignature, remote.Address()))
+
+	assert.NilError(t, Verify(ctx, remote))
+	assert.NilError(t, Verify(ctx, local))
+
+	_, err = remote.SignHash(ctx, []byte("too short"))
+	assert.ErrorContains(t, err, "must be 32 bytes")
+}
+
+func TestRemoteSignerWrongKey(t *testing.T) {
+	ctx := context.Background()
+	key := newTestKey(t)
+	server := newFakeRemoteSigner(t, key)
+
+	// the signer does not know the key
+	other := newTestKey(t)
+	remote := newTestRemoteSigner(t, server.URL, ethcrypto.PubkeyToAddress(other.PublicKey))
+	_, err := remote.SignHash(ctx, ethcrypto.Keccak256([]byte("message")))
+	assert.ErrorContains(t, err, "status code 404")
+
+	// the signer signs with a different key than the configured one
+	lying := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = strings.Replace(r.URL.Path,
+			ethcrypto.PubkeyToAddress(other.PublicKey).Hex(), ethcrypto.PubkeyToAddress(key.PublicKey).Hex(), 1)
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(lying.Close)
+	remote = newTestRemoteSigner(t, lying.URL, ethcrypto.PubkeyToAddress(other.PublicKey))
+	err = Verify(ctx, remote)
+	assert.ErrorContains(t, err, "unexpected signature")
+}
+
+func TestDecodeSignature(t *testing.T) {
+	raw := make([]byte, 65)
+	raw[64] = 28
+	for _, body := range []string{hexutil.Encode(raw), `"` + hexutil.Encode(raw) + `"`, hexutil.Encode(raw) + "\n"} {
+		signature, err := decodeSignature([]byte(body))
+		assert.NilError(t, err)
+		assert.Equal(t, signature[64], byte(1))
+	}
+	_, err := decodeSignature([]byte("0x1234"))
+	assert.ErrorContains(t, err, "expected 65")
+	_, err = decodeSignature([]byte("not hex"))
+	assert.ErrorContains(t, err, "malformed")
+}
+
+func TestNewFromConfig(t *testing.T) {
+	key := newTestKey(t)
+	config := configuration.NewEthnodeConfig()
+	_, err := New(config)
+	assert.ErrorContains(t, err, "PrivateKey is required")
+
+	config.PrivateKey.Key = key
+	s, err := New(config)
+	assert.NilError(t, err)
+	_, ok := s.(*LocalSigner)
+	assert.Assert(t, ok)
+	assert.Equal(t, config.Address(), ethcrypto.PubkeyToAddress(key.PublicKey))
+
+	config.RemoteSigner.URL = "http://localhost:9000"
+	config.RemoteSigner.Address = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	config.RemoteSigner.Timeout = 1
+	_, err = New(config)
+	assert.ErrorContains(t, err, "must not be set")
+
+	config.PrivateKey.Key = nil
+	s, err = New(config)
+	assert.NilError(t, err)
+	_, ok = s.(*RemoteSigner)
+	assert.Assert(t, ok)
+	assert.Equal(t, config.Address(), config.RemoteSigner.Address)
+}
+
+func TestTransactor(t *testing.T) {
+	ctx := context.Background()
+	key := newTestKey(t)
+	server := newFakeRemoteSigner(t, key)
+	remote := newTestRemoteSigner(t, server.URL, ethcrypto.PubkeyToAddress(key.PublicKey))
+	chainID := big.NewInt(100)
+
+	opts := NewTransactor(ctx, remote, chainID)
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	txs := []*types.Transaction{
+		types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     1,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(2),
+			Gas:       21000,
+			Value:     big.NewInt(3),
+		}),
+		types.NewTx(&types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      2,
+			GasPrice:   big.NewInt(1),
+			Gas:        21000,
+			To:         &to,
+			Data:       []byte{1, 2, 3},
+			AccessList: types.AccessList{{Address: to, StorageKeys: []common.Hash{{1}}}},
+		}),
+		types.NewTx(&types.LegacyTx{
+			Nonce:    3,
+			GasPrice: big.NewInt(1),
+			Gas:      21000,
+			To:       &to,
+			Value:    big.NewInt(4),
+		}),
+	}
+	for _, tx := range txs {
+		signedTx, err := opts.Signer(remote.Address(), tx)
+		assert.NilError(t, err)
+		sender, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+		assert.NilError(t, err)
+		assert.Equal(t, sender, remote.Address())
+	}
+
+	// blob transactions are not supported
+	_, err := opts.Signer(remote.Address(), types.NewTx(&types.BlobTx{}))
+	assert.ErrorContains(t, err, "cannot sign transactions of type")
+
+	_, err = opts.Signer(common.Address{}, txs[0])
+	assert.ErrorIs(t, err, bind.ErrNotAuthorized)
+}
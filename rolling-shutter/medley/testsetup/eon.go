@@ -77,7 +77,7 @@ func InitializeEon(
 		PublicKey:       eonKeys.EonPublicKey(),
 		PublicKeyShares: publicKeyShares,
 	}
-	dkgResultEncoded, err := shdb.EncodePureDKGResult(&dkgResult)
+	dkgResultEncoded, err := database.EncodePureDKGResult(nil, &dkgResult)
 	assert.NilError(tb, err)
 
 	err = keyperDB.InsertBatchConfig(ctx, database.InsertBatchConfigParams{
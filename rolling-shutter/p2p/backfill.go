@@ -0,0 +1,236 @@
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+const (
+	// BackfillProtocolID is the libp2p protocol used to request decryption keys and key shares
+	// that have been missed on the gossip topics.
+	BackfillProtocolID = protocol.ID("/shutter/backfill/0.1.0")
+
+	// backfillTimeout bounds the time to answer a single backfill request.
+	backfillTimeout = 10 * time.Second
+	// backfillMaxMessageSize is the maximum size of backfill requests and responses in bytes.
+	backfillMaxMessageSize = 4 << 20
+	// backfillMaxPeers is the number of peers asked until one of them returns messages.
+	backfillMaxPeers = 3
+)
+
+var ErrNoBackfillPeers = errors.New("no connected peer supports the backfill protocol")
+
+// BackfillProvider answers backfill requests of other peers with the messages they have missed.
+type BackfillProvider func(context.Context, *p2pmsg.BackfillRequest) ([]p2pmsg.Message, error)
+
+// SetBackfillProvider enables answering backfill requests of other peers. It has to be called
+// before the messaging service is started.
+func (m *P2PMessaging) SetBackfillProvider(provider BackfillProvider) {
+	if m.P2P == nil {
+		return
+	}
+	m.P2P.setBackfillProvider(provider)
+}
+
+// Backfill requests the messages specified by req from connected peers. The messages returned
+// are validated and handled like messages received via gossip, with the responding peer
+// considered the forwarder of the message. It returns the number of accepted messages.
+func (m *P2PMessaging) Backfill(ctx context.Context, req *p2pmsg.BackfillRequest) (int, error) {
+	if m.P2P == nil {
+		return 0, ErrNoBackfillPeers
+	}
+	peers := m.P2P.backfillPeers()
+	if len(peers) == 0 {
+		return 0, ErrNoBackfillPeers
+	}
+	if len(peers) > backfillMaxPeers {
+		peers = peers[:backfillMaxPeers]
+	}
+	for _, peerID := range peers {
+		resp, err := m.P2P.requestBackfill(ctx, peerID, req)
+		if err != nil {
+			log.Info().Err(err).Str("peer-id", peerID.String()).Msg("backfill request failed")
+			continue
+		}
+		if resp.Error != "" {
+			log.Info().Str("peer-id", peerID.String()).Str("error", resp.Error).Msg("backfill request refused")
+			continue
+		}
+		accepted := m.handleBackfilled(ctx, peerID, resp.Messages)
+		metricsP2PBackfilledMessages.Add(float64(accepted))
+		if accepted > 0 {
+			return accepted, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *P2PMessaging) handleBackfilled(ctx context.Context, sender peer.ID, messages [][]byte) int {
+	accepted := 0
+	for _, data := range messages {
+		msg, _, err := p2pmsg.Unmarshal(data)
+		if err != nil {
+			log.Info().Err(err).Str("peer-id", sender.String()).Msg("received invalid backfilled message")
+			continue
+		}
+		topic := msg.Topic()
+		if _, ok := m.validatorRegistry[topic]; !ok {
+			continue
+		}
+		pubsubMsg := &pubsub.Message{
+			Message:      &pb.Message{Data: data, Topic: &topic},
+			ReceivedFrom: sender,
+		}
+		validate := m.P2P.backfillValidator(m.validatorRegistry.GetCombinedValidator(topic))
+		if validate(ctx, sender, pubsubMsg) != pubsub.ValidationAccept {
+			continue
+		}
+		if err := m.handle(ctx, pubsubMsg); err != nil {
+			log.Info().Err(err).Str("topic", topic).Msg("failed to handle backfilled message")
+			continue
+		}
+		accepted++
+	}
+	return accepted
+}
+
+func (p *P2PNode) setBackfillProvider(provider BackfillProvider) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.config.BackfillProvider = provider
+}
+
+// backfillValidator wraps the validator of backfilled messages, so that they are captured if
+// enabled.
+func (p *P2PNode) backfillValidator(validator pubsub.ValidatorEx) pubsub.ValidatorEx {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.capture == nil {
+		return validator
+	}
+	return p.capture.wrapValidator(validator, true)
+}
+
+// backfillPeers returns the connected peers that support the backfill protocol.
+func (p *P2PNode) backfillPeers() []peer.ID {
+	p.mux.Lock()
+	h := p.host
+	p.mux.Unlock()
+	if h == nil {
+		return nil
+	}
+	peers := []peer.ID{}
+	for _, peerID := range h.Network().Peers() {
+		protocols, err := h.Peerstore().SupportsProtocols(peerID, BackfillProtocolID)
+		if err != nil || len(protocols) == 0 {
+			continue
+		}
+		peers = append(peers, peerID)
+	}
+	return peers
+}
+
+func (p *P2PNode) requestBackfill(
+	ctx context.Context,
+	peerID peer.ID,
+	req *p2pmsg.BackfillRequest,
+) (*p2pmsg.BackfillResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, backfillTimeout)
+	defer cancel()
+
+	stream, err := p.host.NewStream(ctx, peerID, BackfillProtocolID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open stream")
+	}
+	defer stream.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+
+	if _, err := protodelim.MarshalTo(stream, req); err != nil {
+		_ = stream.Reset()
+		return nil, errors.Wrap(err, "failed to send backfill request")
+	}
+	if err := stream.CloseWrite(); err != nil {
+		_ = stream.Reset()
+		return nil, errors.Wrap(err, "failed to close stream for writing")
+	}
+	resp := &p2pmsg.BackfillResponse{}
+	err = protodelim.UnmarshalOptions{MaxSize: backfillMaxMessageSize}.UnmarshalFrom(bufio.NewReader(stream), resp)
+	if err != nil {
+		_ = stream.Reset()
+		return nil, errors.Wrap(err, "failed to read backfill response")
+	}
+	return resp, nil
+}
+
+func (p *P2PNode) handleBackfillStream(ctx context.Context, provider BackfillProvider) network.StreamHandler {
+	return func(stream network.Stream) {
+		defer stream.Close()
+		ctx, cancel := context.WithTimeout(ctx, backfillTimeout)
+		defer cancel()
+		_ = stream.SetDeadline(time.Now().Add(backfillTimeout))
+
+		remote := stream.Conn().RemotePeer()
+		req := &p2pmsg.BackfillRequest{}
+		err := protodelim.UnmarshalOptions{MaxSize: backfillMaxMessageSize}.UnmarshalFrom(bufio.NewReader(stream), req)
+		if err != nil {
+			log.Info().Err(err).Str("peer-id", remote.String()).Msg("received invalid backfill request")
+			_ = stream.Reset()
+			return
+		}
+		resp := answerBackfillRequest(ctx, provider, req)
+		log.Debug().
+			Str("peer-id", remote.String()).
+			Uint64("eon", req.Eon).
+			Int("num-messages", len(resp.Messages)).
+			Str("error", resp.Error).
+			Msg("answered backfill request")
+		if _, err := protodelim.MarshalTo(stream, resp); err != nil {
+			log.Info().Err(err).Str("peer-id", remote.String()).Msg("failed to send backfill response")
+			_ = stream.Reset()
+		}
+	}
+}
+
+func answerBackfillRequest(
+	ctx context.Context,
+	provider BackfillProvider,
+	req *p2pmsg.BackfillRequest,
+) *p2pmsg.BackfillResponse {
+	resp := &p2pmsg.BackfillResponse{}
+	msgs, err := provider(ctx, req)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+	size := proto.Size(resp)
+	for _, msg := range msgs {
+		data, err := p2pmsg.Marshal(msg, nil)
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Messages = nil
+			return resp
+		}
+		// leave some room for the framing of each message
+		size += len(data) + 16
+		if size > backfillMaxMessageSize {
+			break
+		}
+		resp.Messages = append(resp.Messages, data)
+	}
+	return resp
+}
@@ -0,0 +1,123 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/address"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+type backfillTestHandler struct {
+	sources  chan MessageSource
+	received chan p2pmsg.Message
+}
+
+func (*backfillTestHandler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.DecryptionKeyShares{}}
+}
+
+func (h *backfillTestHandler) ValidateMessage(ctx context.Context, msg p2pmsg.Message) (pubsub.ValidationResult, error) {
+	source, _ := MessageSourceFromContext(ctx)
+	h.sources <- source
+	if msg.(*p2pmsg.DecryptionKeyShares).KeyperIndex != 0 {
+		return pubsub.ValidationReject, nil
+	}
+	return pubsub.ValidationAccept, nil
+}
+
+func (h *backfillTestHandler) HandleMessage(_ context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	h.received <- msg
+	return nil, nil
+}
+
+func newBackfillTestConfig(t *testing.T, port int) (*Config, *address.P2PAddress) {
+	t.Helper()
+	cfg := NewConfig()
+	assert.NilError(t, configuration.SetExampleValuesRecursive(cfg))
+	listenAddr := &address.P2PAddress{}
+	assert.NilError(t, encodeable.FromString(listenAddr, fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", port)))
+	cfg.ListenAddresses = []*address.P2PAddress{listenAddr}
+	pid, err := cfg.P2PKey.PeerID()
+	assert.NilError(t, err)
+	externalAddr := &address.P2PAddress{}
+	assert.NilError(t, encodeable.FromString(externalAddr, fmt.Sprintf("/ip4/127.0.0.1/tcp/%d/p2p/%s", port, pid)))
+	return cfg, externalAddr
+}
+
+func TestBackfillIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	providerConfig, providerAddr := newBackfillTestConfig(t, 2100)
+	requesterConfig, _ := newBackfillTestConfig(t, 2101)
+	providerConfig.CustomBootstrapAddresses = []*address.P2PAddress{providerAddr}
+	requesterConfig.CustomBootstrapAddresses = []*address.P2PAddress{providerAddr}
+
+	var requests []*p2pmsg.BackfillRequest
+	provider, err := New(providerConfig)
+	assert.NilError(t, err)
+	provider.SetBackfillProvider(func(_ context.Context, req *p2pmsg.BackfillRequest) ([]p2pmsg.Message, error) {
+		requests = append(requests, req)
+		return []p2pmsg.Message{
+			&p2pmsg.DecryptionKeyShares{InstanceId: req.InstanceId, Eon: req.Eon, KeyperIndex: 0},
+			&p2pmsg.DecryptionKeyShares{InstanceId: req.InstanceId, Eon: req.Eon, KeyperIndex: 1},
+		}, nil
+	})
+
+	requester, err := New(requesterConfig)
+	assert.NilError(t, err)
+	handler := &backfillTestHandler{
+		sources:  make(chan MessageSource, 2),
+		received: make(chan p2pmsg.Message, 2),
+	}
+	requester.AddMessageHandler(handler)
+
+	req := &p2pmsg.BackfillRequest{
+		InstanceId: 5,
+		Eon:        3,
+		KeyShares:  true,
+		Range: &p2pmsg.BackfillRequest_Slots{
+			Slots: &p2pmsg.SlotRange{Start: 10, End: 12},
+		},
+	}
+	testFn := func(ctx context.Context, _ service.Runner) error {
+		for len(requester.P2P.backfillPeers()) == 0 {
+			select {
+			case <-ctx.Done():
+				t.Fatalf("waiting for backfill peer: %s", ctx.Err())
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+		numAccepted, err := requester.Backfill(ctx, req)
+		assert.NilError(t, err)
+		assert.Equal(t, numAccepted, 1)
+		return ErrTestComplete
+	}
+
+	err = service.Run(ctx, provider, requester, service.Function{Func: testFn})
+	assert.Error(t, err, ErrTestComplete.Error())
+
+	assert.Equal(t, len(requests), 1)
+	assert.Equal(t, requests[0].GetSlots().End, uint64(12))
+	providerID, err := providerConfig.P2PKey.PeerID()
+	assert.NilError(t, err)
+	for i := 0; i < 2; i++ {
+		source := <-handler.sources
+		assert.Equal(t, source.Forwarder, providerID.ID)
+		assert.Equal(t, source.Origin.String(), "")
+	}
+	msg := <-handler.received
+	assert.Equal(t, msg.(*p2pmsg.DecryptionKeyShares).Eon, uint64(3))
+}
@@ -0,0 +1,212 @@
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/encoding/protodelim"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// captureMaxRecordSize is the maximum size of a single record read from a capture file.
+const captureMaxRecordSize = 4 << 20
+
+var errCaptureClosed = errors.New("capture file is closed")
+
+// CaptureConfig configures writing every received gossip message to a capture file, e.g. for
+// replaying it later with `rolling-shutter p2p replay`.
+type CaptureConfig struct {
+	Enabled     bool
+	Path        string `comment:"File the messages are written to. Rotated files get the suffixes .1, .2, ..."`
+	MaxFileSize int64  `comment:"Size in MiB after which the capture file is rotated"`
+	MaxFiles    int    `comment:"Number of rotated capture files to keep"`
+}
+
+func (c *CaptureConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Path == "" {
+		return errors.New("capture path must not be empty")
+	}
+	if c.MaxFileSize <= 0 {
+		return errors.New("capture max file size must be positive")
+	}
+	if c.MaxFiles < 0 {
+		return errors.New("capture max files must not be negative")
+	}
+	return nil
+}
+
+// captureWriter writes captured messages to a file, rotating it once it exceeds the maximum
+// size.
+type captureWriter struct {
+	mux      sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+func newCaptureWriter(config CaptureConfig) (*captureWriter, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	w := &captureWriter{
+		path:     config.Path,
+		maxSize:  config.MaxFileSize << 20,
+		maxFiles: config.MaxFiles,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *captureWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open capture file %s", w.path)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return errors.Wrapf(err, "failed to stat capture file %s", w.path)
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+func (w *captureWriter) rotatedPath(n int) string {
+	return fmt.Sprintf("%s.%d", w.path, n)
+}
+
+// rotate moves the current file to path.1, path.1 to path.2 and so on, dropping the files
+// exceeding the maximum number of files kept.
+func (w *captureWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return errors.Wrap(err, "failed to close capture file")
+	}
+	w.file = nil
+	if w.maxFiles == 0 {
+		if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "failed to remove capture file")
+		}
+		return w.open()
+	}
+	if err := os.Remove(w.rotatedPath(w.maxFiles)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove oldest capture file")
+	}
+	for n := w.maxFiles - 1; n >= 1; n-- {
+		if err := os.Rename(w.rotatedPath(n), w.rotatedPath(n+1)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "failed to rotate capture file")
+		}
+	}
+	if err := os.Rename(w.path, w.rotatedPath(1)); err != nil {
+		return errors.Wrap(err, "failed to rotate capture file")
+	}
+	return w.open()
+}
+
+func (w *captureWriter) Write(record *p2pmsg.CapturedMessage) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if w.file == nil {
+		return errCaptureClosed
+	}
+	buf := &bytes.Buffer{}
+	if _, err := protodelim.MarshalTo(buf, record); err != nil {
+		return errors.Wrap(err, "failed to marshal captured message")
+	}
+	if w.size > 0 && w.size+int64(buf.Len()) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := w.file.Write(buf.Bytes())
+	w.size += int64(n)
+	if err != nil {
+		return errors.Wrap(err, "failed to write captured message")
+	}
+	return nil
+}
+
+func (w *captureWriter) Close() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// wrapValidator returns a validator recording every message validated by validator together
+// with the validation result.
+func (w *captureWriter) wrapValidator(validator pubsub.ValidatorEx, backfilled bool) pubsub.ValidatorEx {
+	return func(ctx context.Context, sender peer.ID, message *pubsub.Message) pubsub.ValidationResult {
+		arrivalTime := time.Now()
+		res := validator(ctx, sender, message)
+		record := &p2pmsg.CapturedMessage{
+			Envelope:         message.GetData(),
+			Topic:            message.GetTopic(),
+			Sender:           sender.String(),
+			ArrivalTime:      arrivalTime.UnixNano(),
+			ValidationResult: validationResultString(res),
+			Backfilled:       backfilled,
+		}
+		if origin := message.GetFrom(); origin != "" {
+			record.Origin = origin.String()
+		}
+		if err := w.Write(record); err != nil && err != errCaptureClosed {
+			log.Warn().Err(err).Str("topic", message.GetTopic()).Msg("failed to capture message")
+		}
+		return res
+	}
+}
+
+func validationResultString(res pubsub.ValidationResult) string {
+	switch res {
+	case pubsub.ValidationAccept:
+		return "accept"
+	case pubsub.ValidationReject:
+		return "reject"
+	case pubsub.ValidationIgnore:
+		return "ignore"
+	default:
+		return fmt.Sprintf("unknown(%d)", res)
+	}
+}
+
+// ReadCapture calls fn for each message stored in a capture file, in the order they have been
+// received.
+func ReadCapture(r io.Reader, fn func(*p2pmsg.CapturedMessage) error) error {
+	reader := bufio.NewReader(r)
+	for {
+		record := &p2pmsg.CapturedMessage{}
+		err := protodelim.UnmarshalOptions{MaxSize: captureMaxRecordSize}.UnmarshalFrom(reader, record)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read captured message")
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
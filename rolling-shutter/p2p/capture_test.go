@@ -0,0 +1,139 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+func readCaptureFile(t *testing.T, path string) []*p2pmsg.CapturedMessage {
+	t.Helper()
+	file, err := os.Open(path)
+	assert.NilError(t, err)
+	defer file.Close()
+	records := []*p2pmsg.CapturedMessage{}
+	err = ReadCapture(file, func(record *p2pmsg.CapturedMessage) error {
+		records = append(records, record)
+		return nil
+	})
+	assert.NilError(t, err)
+	return records
+}
+
+func TestCaptureWriterRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture")
+	w, err := newCaptureWriter(CaptureConfig{Enabled: true, Path: path, MaxFileSize: 1, MaxFiles: 2})
+	assert.NilError(t, err)
+	// records of slightly more than 400KiB, so that two of them fit into a file
+	envelope := bytes.Repeat([]byte{1}, 400<<10)
+	for i := 0; i < 7; i++ {
+		assert.NilError(t, w.Write(&p2pmsg.CapturedMessage{Envelope: envelope, ArrivalTime: int64(i)}))
+	}
+	assert.NilError(t, w.Close())
+	assert.Equal(t, w.Write(&p2pmsg.CapturedMessage{}), errCaptureClosed)
+
+	// files are path.2 (records 2, 3), path.1 (records 4, 5) and path (record 6)
+	for n, want := range map[string][]int64{path + ".2": {2, 3}, path + ".1": {4, 5}, path: {6}} {
+		records := readCaptureFile(t, n)
+		assert.Equal(t, len(records), len(want))
+		for i, record := range records {
+			assert.Equal(t, record.ArrivalTime, want[i])
+		}
+	}
+	_, err = os.Stat(path + ".3")
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestCaptureValidator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture")
+	w, err := newCaptureWriter(CaptureConfig{Enabled: true, Path: path, MaxFileSize: 1})
+	assert.NilError(t, err)
+
+	sender, origin := peer.ID("sender"), peer.ID("origin")
+	topic := "topic"
+	validator := w.wrapValidator(func(context.Context, peer.ID, *pubsub.Message) pubsub.ValidationResult {
+		return pubsub.ValidationIgnore
+	}, true)
+	res := validator(context.Background(), sender, &pubsub.Message{
+		Message: &pb.Message{Data: []byte("data"), Topic: &topic, From: []byte(origin)},
+	})
+	assert.Equal(t, res, pubsub.ValidationIgnore)
+	assert.NilError(t, w.Close())
+
+	records := readCaptureFile(t, path)
+	assert.Equal(t, len(records), 1)
+	assert.DeepEqual(t, records[0].Envelope, []byte("data"))
+	assert.Equal(t, records[0].Topic, topic)
+	assert.Equal(t, records[0].Sender, sender.String())
+	assert.Equal(t, records[0].Origin, origin.String())
+	assert.Equal(t, records[0].ValidationResult, "ignore")
+	assert.Assert(t, records[0].Backfilled)
+	assert.Assert(t, records[0].ArrivalTime > 0)
+}
+
+type replayTestHandler struct {
+	handled []p2pmsg.Message
+}
+
+func (*replayTestHandler) MessagePrototypes() []p2pmsg.Message {
+	return []p2pmsg.Message{&p2pmsg.DecryptionKeyShares{}}
+}
+
+func (*replayTestHandler) ValidateMessage(_ context.Context, msg p2pmsg.Message) (pubsub.ValidationResult, error) {
+	if msg.(*p2pmsg.DecryptionKeyShares).KeyperIndex != 0 {
+		return pubsub.ValidationReject, nil
+	}
+	return pubsub.ValidationAccept, nil
+}
+
+func (h *replayTestHandler) HandleMessage(_ context.Context, msg p2pmsg.Message) ([]p2pmsg.Message, error) {
+	h.handled = append(h.handled, msg)
+	// output messages are dropped
+	return []p2pmsg.Message{&p2pmsg.DecryptionKeys{}}, nil
+}
+
+func TestReplay(t *testing.T) {
+	sender, err := peer.Decode("QmdfBeR6odD1pRKendUjWejhMd9wybivDq5RjixhRhiERg")
+	assert.NilError(t, err)
+	path := filepath.Join(t.TempDir(), "capture")
+	w, err := newCaptureWriter(CaptureConfig{Enabled: true, Path: path, MaxFileSize: 1})
+	assert.NilError(t, err)
+
+	messages := []p2pmsg.Message{
+		&p2pmsg.DecryptionKeyShares{Eon: 1, KeyperIndex: 0},
+		&p2pmsg.DecryptionKeyShares{Eon: 2, KeyperIndex: 1},
+		&p2pmsg.DecryptionKeys{Eon: 3},
+	}
+	for _, msg := range messages {
+		data, err := p2pmsg.Marshal(msg, nil)
+		assert.NilError(t, err)
+		assert.NilError(t, w.Write(&p2pmsg.CapturedMessage{
+			Envelope:         data,
+			Topic:            msg.Topic(),
+			Sender:           sender.String(),
+			ValidationResult: "accept",
+		}))
+	}
+	assert.NilError(t, w.Close())
+	file, err := os.Open(path)
+	assert.NilError(t, err)
+	defer file.Close()
+
+	messaging := NewReplayMessaging()
+	handler := &replayTestHandler{}
+	messaging.AddMessageHandler(handler)
+	stats, err := messaging.Replay(context.Background(), file)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, stats, ReplayStats{Total: 3, Accepted: 1, Rejected: 1, Skipped: 1, Diverged: 1})
+	assert.Equal(t, len(handler.handled), 1)
+	assert.Equal(t, handler.handled[0].(*p2pmsg.DecryptionKeyShares).Eon, uint64(1))
+}
@@ -26,6 +26,10 @@ func init() {
 	}
 	defaultListenAddrs = []*address.P2PAddress{}
 	for _, add := range cfg.ListenAddrs {
+		// browser transports are opt-in
+		if isBrowserTransportAddr(add) {
+			continue
+		}
 		defaultListenAddrs = append(defaultListenAddrs, &address.P2PAddress{Multiaddr: add})
 	}
 }
@@ -39,17 +43,24 @@ func NewConfig() *Config {
 func (c *Config) Init() {
 	c.P2PKey = &keys.Libp2pPrivate{}
 	c.AdvertiseAddresses = []*address.P2PAddress{}
+	c.GossipSub = NewGossipSubConfig()
+	c.RateLimits = NewRateLimitsConfig()
+	c.WebSocket = NewWebSocketConfig()
 }
 
 type Config struct {
-	P2PKey                   *keys.Libp2pPrivate `shconfig:",required"`
-	ListenAddresses          []*address.P2PAddress
+	P2PKey                   *keys.Libp2pPrivate   `shconfig:",required"`
+	ListenAddresses          []*address.P2PAddress `comment:"WebSocket and WebTransport listen addresses are only used by access nodes"`
 	AdvertiseAddresses       []*address.P2PAddress `comment:"Optional, addresses to be advertised to other peers instead of auto-detected ones."` //nolint:lll
 	CustomBootstrapAddresses []*address.P2PAddress `comment:"Overwrite p2p boostrap nodes"`
 	Environment              env.Environment
 	DiscoveryNamespace       string                  `shconfig:",required" comment:"Must be unique for each instance id."`
 	IsAccessNode             bool                    `comment:"Optional, to be set to true if running an access node"`
 	FloodSubDiscovery        FloodsubDiscoveryConfig `shconfig:"required"`
+	Capture                  CaptureConfig
+	GossipSub                *GossipSubConfig
+	RateLimits               *RateLimitsConfig
+	WebSocket                *WebSocketConfig
 }
 
 func (c *Config) Name() string {
@@ -57,6 +68,22 @@ func (c *Config) Name() string {
 }
 
 func (c *Config) Validate() error {
+	if err := c.Capture.Validate(); err != nil {
+		return err
+	}
+	if c.GossipSub != nil {
+		if err := c.GossipSub.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.RateLimits != nil {
+		if err := c.RateLimits.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.WebSocket != nil {
+		return c.WebSocket.Validate()
+	}
 	return nil
 }
 
@@ -65,6 +92,8 @@ func (c *Config) SetDefaultValues() error {
 	c.Environment = env.EnvironmentProduction
 	c.FloodSubDiscovery.Interval = 10
 	c.FloodSubDiscovery.Topics = []string{}
+	c.Capture.MaxFileSize = 100
+	c.Capture.MaxFiles = 5
 	return nil
 }
 
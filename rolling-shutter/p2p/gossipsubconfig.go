@@ -0,0 +1,280 @@
+package p2p
+
+import (
+	"io"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var (
+	_ configuration.Config = &GossipSubConfig{}
+	_ configuration.Config = &TopicScoreConfig{}
+)
+
+func NewGossipSubConfig() *GossipSubConfig {
+	c := &GossipSubConfig{}
+	c.Init()
+	return c
+}
+
+// GossipSubConfig holds the gossipsub router and peer scoring parameters.
+type GossipSubConfig struct {
+	HeartbeatIntervalMillis uint64
+	HistoryLength           int
+	HistoryGossip           int
+
+	D             int `comment:"Target mesh degree. Bootstrap nodes always use zero for all mesh degrees."`
+	Dlo           int
+	Dhi           int
+	Dout          int
+	Dscore        int
+	Dlazy         int
+	AccessNodeDhi int `comment:"Replaces Dhi on access nodes"`
+
+	GossipThreshold             float64
+	PublishThreshold            float64
+	GraylistThreshold           float64
+	AcceptPXThreshold           float64
+	OpportunisticGraftThreshold float64
+
+	BootstrapPeerScore          float64 `comment:"Application specific score of bootstrap peers, allowing them to reach AcceptPXThreshold"`
+	TopicScoreCap               float64
+	IPColocationFactorWeight    float64
+	IPColocationFactorThreshold int
+	BehaviourPenaltyWeight      float64
+	BehaviourPenaltyThreshold   float64
+	BehaviourPenaltyDecay       float64
+
+	DecryptionKeysTopic      *TopicScoreConfig
+	DecryptionKeySharesTopic *TopicScoreConfig
+}
+
+func NewTopicScoreConfig() *TopicScoreConfig {
+	c := &TopicScoreConfig{}
+	c.Init()
+	return c
+}
+
+// TopicScoreConfig holds the peer scoring parameters of a single topic.
+type TopicScoreConfig struct {
+	TopicWeight                            float64
+	TimeInMeshWeight                       float64
+	TimeInMeshQuantumSeconds               uint64
+	TimeInMeshCap                          float64
+	FirstMessageDeliveriesWeight           float64
+	FirstMessageDeliveriesDecay            float64
+	FirstMessageDeliveriesCap              float64
+	MeshMessageDeliveriesWeight            float64
+	MeshMessageDeliveriesDecay             float64
+	MeshMessageDeliveriesCap               float64
+	MeshMessageDeliveriesThreshold         float64
+	MeshMessageDeliveriesWindowMillis      uint64
+	MeshMessageDeliveriesActivationSeconds uint64
+	MeshFailurePenaltyWeight               float64
+	MeshFailurePenaltyDecay                float64
+	InvalidMessageDeliveriesWeight         float64
+	InvalidMessageDeliveriesDecay          float64
+}
+
+func (c *GossipSubConfig) Init() {
+	c.DecryptionKeysTopic = NewTopicScoreConfig()
+	c.DecryptionKeySharesTopic = NewTopicScoreConfig()
+}
+
+func (c *GossipSubConfig) Name() string {
+	return "gossipsub"
+}
+
+func (c *GossipSubConfig) Validate() error {
+	if c.HeartbeatIntervalMillis == 0 {
+		return errors.New("HeartbeatIntervalMillis must be positive")
+	}
+	if c.HistoryGossip <= 0 || c.HistoryGossip > c.HistoryLength {
+		return errors.Errorf("HistoryGossip (%d) must be positive and at most HistoryLength (%d)", c.HistoryGossip, c.HistoryLength)
+	}
+	if c.Dlo <= 0 || c.Dlo > c.D {
+		return errors.Errorf("Dlo (%d) must be positive and at most D (%d)", c.Dlo, c.D)
+	}
+	if c.D > c.Dhi {
+		return errors.Errorf("D (%d) must not exceed Dhi (%d)", c.D, c.Dhi)
+	}
+	if c.D > c.AccessNodeDhi {
+		return errors.Errorf("D (%d) must not exceed AccessNodeDhi (%d)", c.D, c.AccessNodeDhi)
+	}
+	if c.Dout < 0 || c.Dout >= c.Dlo || c.Dout > c.D/2 {
+		return errors.Errorf("Dout (%d) must be below Dlo (%d) and at most half of D (%d)", c.Dout, c.Dlo, c.D)
+	}
+	if c.Dscore < 0 || c.Dscore > c.Dhi {
+		return errors.Errorf("Dscore (%d) must not exceed Dhi (%d)", c.Dscore, c.Dhi)
+	}
+	if c.Dlazy < 0 {
+		return errors.New("Dlazy must not be negative")
+	}
+	if c.GossipThreshold > 0 {
+		return errors.New("GossipThreshold must not be positive")
+	}
+	if c.PublishThreshold > c.GossipThreshold {
+		return errors.New("PublishThreshold must not exceed GossipThreshold")
+	}
+	if c.GraylistThreshold > c.PublishThreshold {
+		return errors.New("GraylistThreshold must not exceed PublishThreshold")
+	}
+	if c.AcceptPXThreshold < 0 {
+		return errors.New("AcceptPXThreshold must not be negative")
+	}
+	if c.OpportunisticGraftThreshold < 0 {
+		return errors.New("OpportunisticGraftThreshold must not be negative")
+	}
+	if c.TopicScoreCap < 0 {
+		return errors.New("TopicScoreCap must not be negative")
+	}
+	if c.IPColocationFactorWeight > 0 || c.IPColocationFactorThreshold < 1 {
+		return errors.New("IPColocationFactorWeight must not be positive and IPColocationFactorThreshold must be positive")
+	}
+	if c.BehaviourPenaltyWeight > 0 || c.BehaviourPenaltyThreshold < 0 || !isDecay(c.BehaviourPenaltyDecay) {
+		return errors.New("invalid behaviour penalty parameters")
+	}
+	if err := c.DecryptionKeysTopic.Validate(); err != nil {
+		return errors.Wrap(err, "invalid DecryptionKeysTopic parameters")
+	}
+	if err := c.DecryptionKeySharesTopic.Validate(); err != nil {
+		return errors.Wrap(err, "invalid DecryptionKeySharesTopic parameters")
+	}
+	return nil
+}
+
+func isDecay(v float64) bool {
+	return v > 0 && v < 1
+}
+
+func (c *TopicScoreConfig) Init() {}
+
+func (c *TopicScoreConfig) Name() string {
+	return "topicscore"
+}
+
+func (c *TopicScoreConfig) Validate() error {
+	if c.TopicWeight < 0 {
+		return errors.New("TopicWeight must not be negative")
+	}
+	if c.TimeInMeshWeight < 0 || c.TimeInMeshQuantumSeconds == 0 || c.TimeInMeshCap <= 0 {
+		return errors.New("invalid time in mesh parameters")
+	}
+	if c.FirstMessageDeliveriesWeight < 0 || !isDecay(c.FirstMessageDeliveriesDecay) || c.FirstMessageDeliveriesCap <= 0 {
+		return errors.New("invalid first message deliveries parameters")
+	}
+	if c.MeshMessageDeliveriesWeight > 0 ||
+		!isDecay(c.MeshMessageDeliveriesDecay) ||
+		c.MeshMessageDeliveriesCap <= 0 ||
+		c.MeshMessageDeliveriesThreshold <= 0 ||
+		c.MeshMessageDeliveriesThreshold > c.MeshMessageDeliveriesCap ||
+		c.MeshMessageDeliveriesActivationSeconds == 0 {
+		return errors.New("invalid mesh message deliveries parameters")
+	}
+	if c.MeshFailurePenaltyWeight > 0 || !isDecay(c.MeshFailurePenaltyDecay) {
+		return errors.New("invalid mesh failure penalty parameters")
+	}
+	if c.InvalidMessageDeliveriesWeight > 0 || !isDecay(c.InvalidMessageDeliveriesDecay) {
+		return errors.New("invalid invalid message deliveries parameters")
+	}
+	return nil
+}
+
+func (c *GossipSubConfig) SetDefaultValues() error {
+	// modified defaults from ethereum consensus spec
+	// https://github.com/ethereum/consensus-specs/blob/5d80b1954a4b7a121aa36143d50b366727b66cbc/\
+	//   specs/phase0/p2p-interface.md#why-are-these-specific-gossip-parameters-chosen //nolint:lll
+	c.HeartbeatIntervalMillis = 700
+	c.HistoryLength = 6
+	c.HistoryGossip = pubsub.GossipSubHistoryGossip
+
+	c.D = pubsub.GossipSubD
+	c.Dlo = pubsub.GossipSubDlo
+	c.Dhi = pubsub.GossipSubDhi
+	c.Dout = pubsub.GossipSubDout
+	c.Dscore = pubsub.GossipSubDscore
+	c.Dlazy = pubsub.GossipSubDlazy
+	c.AccessNodeDhi = 30
+
+	c.GossipThreshold = -4000
+	c.PublishThreshold = -8000
+	c.GraylistThreshold = -16000
+	c.AcceptPXThreshold = 100
+	c.OpportunisticGraftThreshold = 5
+
+	c.BootstrapPeerScore = 200
+	c.TopicScoreCap = 32.72
+	c.IPColocationFactorWeight = -35.11
+	c.IPColocationFactorThreshold = 10
+	c.BehaviourPenaltyWeight = -15.92
+	c.BehaviourPenaltyThreshold = 6
+	c.BehaviourPenaltyDecay = 0.928
+	return nil
+}
+
+func (c *GossipSubConfig) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c GossipSubConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
+
+// SetDefaultValues sets parameters based on the attestation topic in the beacon chain network.
+// The formula uses the number of validators which we set to a fixed number which could be the
+// number of keypers.
+func (c *TopicScoreConfig) SetDefaultValues() error {
+	n := float64(200)
+	c.TopicWeight = 1
+	c.TimeInMeshWeight = 0.0324
+	c.TimeInMeshQuantumSeconds = 12
+	c.TimeInMeshCap = 300
+	c.FirstMessageDeliveriesWeight = 0.05
+	c.FirstMessageDeliveriesDecay = 0.631
+	c.FirstMessageDeliveriesCap = n / 755.712
+	c.MeshMessageDeliveriesWeight = -0.026
+	c.MeshMessageDeliveriesDecay = 0.631
+	c.MeshMessageDeliveriesCap = n / 94.464
+	c.MeshMessageDeliveriesThreshold = n / 377.856
+	c.MeshMessageDeliveriesWindowMillis = 200
+	c.MeshMessageDeliveriesActivationSeconds = 4 * 12
+	c.MeshFailurePenaltyWeight = -0.0026
+	c.MeshFailurePenaltyDecay = 0.631
+	c.InvalidMessageDeliveriesWeight = -99
+	c.InvalidMessageDeliveriesDecay = 0.9994
+	return nil
+}
+
+func (c *TopicScoreConfig) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c TopicScoreConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
+
+func (c *TopicScoreConfig) params() *pubsub.TopicScoreParams {
+	return &pubsub.TopicScoreParams{
+		TopicWeight:                     c.TopicWeight,
+		TimeInMeshWeight:                c.TimeInMeshWeight,
+		TimeInMeshQuantum:               time.Duration(c.TimeInMeshQuantumSeconds) * time.Second, //nolint:gosec // G115
+		TimeInMeshCap:                   c.TimeInMeshCap,
+		FirstMessageDeliveriesWeight:    c.FirstMessageDeliveriesWeight,
+		FirstMessageDeliveriesDecay:     c.FirstMessageDeliveriesDecay,
+		FirstMessageDeliveriesCap:       c.FirstMessageDeliveriesCap,
+		MeshMessageDeliveriesWeight:     c.MeshMessageDeliveriesWeight,
+		MeshMessageDeliveriesDecay:      c.MeshMessageDeliveriesDecay,
+		MeshMessageDeliveriesCap:        c.MeshMessageDeliveriesCap,
+		MeshMessageDeliveriesThreshold:  c.MeshMessageDeliveriesThreshold,
+		MeshMessageDeliveriesWindow:     time.Duration(c.MeshMessageDeliveriesWindowMillis) * time.Millisecond, //nolint:gosec // G115
+		MeshMessageDeliveriesActivation: time.Duration(c.MeshMessageDeliveriesActivationSeconds) * time.Second, //nolint:gosec // G115
+		MeshFailurePenaltyWeight:        c.MeshFailurePenaltyWeight,
+		MeshFailurePenaltyDecay:         c.MeshFailurePenaltyDecay,
+		InvalidMessageDeliveriesWeight:  c.InvalidMessageDeliveriesWeight,
+		InvalidMessageDeliveriesDecay:   c.InvalidMessageDeliveriesDecay,
+	}
+}
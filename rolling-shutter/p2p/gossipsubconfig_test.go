@@ -0,0 +1,89 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprtopics"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+func newDefaultGossipSubConfig(t *testing.T) *GossipSubConfig {
+	t.Helper()
+	config := NewGossipSubConfig()
+	assert.NilError(t, configuration.SetDefaultValuesRecursive(config, nil))
+	return config
+}
+
+func TestGossipSubConfigValidate(t *testing.T) {
+	assert.NilError(t, newDefaultGossipSubConfig(t).Validate())
+
+	testCases := []struct {
+		name   string
+		modify func(*GossipSubConfig)
+		err    string
+	}{
+		{"Dlo above D", func(c *GossipSubConfig) { c.Dlo = c.D + 1 }, "Dlo"},
+		{"D above Dhi", func(c *GossipSubConfig) { c.D = c.Dhi + 1 }, "Dhi"},
+		{"Dout not below Dlo", func(c *GossipSubConfig) { c.Dout = c.Dlo }, "Dout"},
+		{"zero heartbeat", func(c *GossipSubConfig) { c.HeartbeatIntervalMillis = 0 }, "HeartbeatIntervalMillis"},
+		{"gossip history too long", func(c *GossipSubConfig) { c.HistoryGossip = c.HistoryLength + 1 }, "HistoryGossip"},
+		{"publish above gossip threshold", func(c *GossipSubConfig) { c.PublishThreshold = 0 }, "PublishThreshold"},
+		{"positive graylist threshold", func(c *GossipSubConfig) { c.GraylistThreshold = 1 }, "GraylistThreshold"},
+		{
+			"invalid key share topic decay",
+			func(c *GossipSubConfig) { c.DecryptionKeySharesTopic.InvalidMessageDeliveriesDecay = 1 },
+			"DecryptionKeySharesTopic",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := newDefaultGossipSubConfig(t)
+			tc.modify(config)
+			assert.ErrorContains(t, config.Validate(), tc.err)
+		})
+	}
+
+	// a small testnet configuration
+	config := newDefaultGossipSubConfig(t)
+	config.D, config.Dlo, config.Dhi, config.Dout, config.Dscore = 2, 1, 4, 0, 1
+	assert.NilError(t, config.Validate())
+}
+
+func TestMakePubSubParams(t *testing.T) {
+	config := newDefaultGossipSubConfig(t)
+	config.HeartbeatIntervalMillis = 1000
+	config.D = 4
+	config.Dhi = 8
+	config.AccessNodeDhi = 20
+	config.GossipThreshold = -10
+
+	gossipSubParams, peerScoreParams, peerScoreThresholds := makePubSubParams(pubSubParamsOptions{config: config})
+	assert.Equal(t, gossipSubParams.HeartbeatInterval, time.Second)
+	assert.Equal(t, gossipSubParams.D, 4)
+	assert.Equal(t, gossipSubParams.Dhi, 8)
+	assert.Equal(t, peerScoreThresholds.GossipThreshold, float64(-10))
+	assert.Equal(t, peerScoreParams.TopicScoreCap, config.TopicScoreCap)
+
+	gossipSubParams, _, _ = makePubSubParams(pubSubParamsOptions{config: config, isAccessNode: true})
+	assert.Equal(t, gossipSubParams.Dhi, 20)
+
+	gossipSubParams, _, _ = makePubSubParams(pubSubParamsOptions{config: config, isBootstrapNode: true})
+	assert.Equal(t, gossipSubParams.D, 0)
+	assert.Equal(t, gossipSubParams.Dhi, 0)
+}
+
+func TestTopicScoreParams(t *testing.T) {
+	config := newDefaultGossipSubConfig(t)
+	config.DecryptionKeysTopic.TopicWeight = 0.5
+	config.DecryptionKeySharesTopic.MeshMessageDeliveriesWindowMillis = 500
+
+	assert.Equal(t, topicScoreParams(config, kprtopics.DecryptionKeys).TopicWeight, 0.5)
+	assert.Equal(t, topicScoreParams(config, kprtopics.DecryptionKeyShares).MeshMessageDeliveriesWindow, 500*time.Millisecond)
+	defaults := topicScoreParams(config, kprtopics.EonPublicKey)
+	assert.Equal(t, defaults.TopicWeight, float64(1))
+	assert.Equal(t, defaults.MeshMessageDeliveriesWindow, 200*time.Millisecond)
+	assert.Equal(t, defaults.MeshMessageDeliveriesActivation, 4*12*time.Second)
+}
@@ -15,6 +15,7 @@ import (
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/env"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/retry"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
@@ -27,8 +28,32 @@ type (
 	HandlerRegistry   map[protoreflect.FullName][]HandlerFunc
 	ValidatorFunc     func(context.Context, p2pmsg.Message) (pubsub.ValidationResult, error)
 	ValidatorRegistry map[string][]pubsub.ValidatorEx
+	// PeerScoreFunc returns a contribution to the application specific gossipsub score of a peer.
+	PeerScoreFunc func(peer.ID) float64
 )
 
+type messageSourceKey struct{}
+
+// MessageSource identifies the peers a gossip message has been received from.
+type MessageSource struct {
+	// Origin is the peer that published the message. It is authenticated by the message signature.
+	Origin peer.ID
+	// Forwarder is the peer that relayed the message to us.
+	Forwarder peer.ID
+}
+
+// MessageSourceFromContext returns the source of the message currently being validated. It is
+// only available in validator functions of messages received via gossip.
+func MessageSourceFromContext(ctx context.Context) (MessageSource, bool) {
+	source, ok := ctx.Value(messageSourceKey{}).(MessageSource)
+	return source, ok
+}
+
+// ContextWithMessageSource returns a copy of ctx carrying the source of a message.
+func ContextWithMessageSource(ctx context.Context, source MessageSource) context.Context {
+	return context.WithValue(ctx, messageSourceKey{}, source)
+}
+
 func (r *ValidatorRegistry) GetCombinedValidator(topic string) pubsub.ValidatorEx {
 	validate := func(ctx context.Context, sender peer.ID, message *pubsub.Message) pubsub.ValidationResult {
 		startTime := time.Now()
@@ -70,6 +95,8 @@ type Messaging interface {
 	SendMessage(context.Context, p2pmsg.Message, ...retry.Option) error
 	AddValidator(valFunc ValidatorFunc, protos ...p2pmsg.Message)
 	AddMessageHandler(mhs ...MessageHandler)
+	AddPeerScore(fn PeerScoreFunc)
+	SetBackfillProvider(provider BackfillProvider)
 }
 
 type MessageHandler interface {
@@ -83,11 +110,44 @@ func New(config *Config) (*P2PMessaging, error) {
 	if err != nil {
 		return nil, err
 	}
+	gossipSub := config.GossipSub
+	if gossipSub == nil {
+		gossipSub = NewGossipSubConfig()
+		if err := configuration.SetDefaultValuesRecursive(gossipSub, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := gossipSub.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid gossipsub config")
+	}
+	rateLimits := config.RateLimits
+	if rateLimits == nil {
+		rateLimits = NewRateLimitsConfig()
+		if err := configuration.SetDefaultValuesRecursive(rateLimits, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := rateLimits.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid rate limits config")
+	}
+	if err := config.Capture.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid capture config")
+	}
+
+	if config.WebSocket != nil {
+		if err := config.WebSocket.Validate(); err != nil {
+			return nil, errors.Wrap(err, "invalid websocket config")
+		}
+	}
 
 	listenAddresses := []multiaddr.Multiaddr{}
 	for _, addr := range config.ListenAddresses {
 		listenAddresses = append(listenAddresses, addr.Multiaddr)
 	}
+	listenAddresses, err = selectListenAddrs(listenAddresses, config.IsAccessNode, config.WebSocket)
+	if err != nil {
+		return nil, err
+	}
 	advertiseAddresses := []multiaddr.Multiaddr{}
 	for _, addr := range config.AdvertiseAddresses {
 		advertiseAddresses = append(advertiseAddresses, addr.Multiaddr)
@@ -100,6 +160,10 @@ func New(config *Config) (*P2PMessaging, error) {
 		DiscoveryNamespace: config.DiscoveryNamespace,
 		IsAccessNode:       config.IsAccessNode,
 		FloodsubDiscovery:  config.FloodSubDiscovery,
+		Capture:            config.Capture,
+		GossipSub:          gossipSub,
+		RateLimits:         rateLimits,
+		WebSocket:          config.WebSocket,
 	}
 
 	bootstrapAddresses := config.CustomBootstrapAddresses
@@ -196,6 +260,7 @@ func (m *P2PMessaging) addValidatorImpl(valFunc ValidatorFunc, messProto p2pmsg.
 			return invalidResultType
 		}
 
+		ctx = ContextWithMessageSource(ctx, MessageSource{Origin: message.GetFrom(), Forwarder: sender})
 		valid, err := valFunc(ctx, unmshl)
 		if err != nil {
 			handleError(err)
@@ -232,6 +297,15 @@ func (m *P2PMessaging) AddMessageHandler(mhs ...MessageHandler) {
 	}
 }
 
+// AddPeerScore adds the given function to the application specific score gossipsub assigns to
+// peers. It has to be called before the messaging service is started.
+func (m *P2PMessaging) AddPeerScore(fn PeerScoreFunc) {
+	if m.P2P == nil {
+		return
+	}
+	m.P2P.addPeerScore(fn)
+}
+
 // AddGossipTopic will subscribe to a specific topic on the
 // gossip p2p-messaging layer.
 // This is only necessary to call manually when we want to
@@ -381,6 +455,11 @@ func (m *P2PMessaging) SendMessage(
 	msg p2pmsg.Message,
 	retryOpts ...retry.Option,
 ) error {
+	if m.P2P == nil {
+		log.Debug().Str("message", msg.LogInfo()).Str("topic", msg.Topic()).
+			Msg("not connected to the network, dropping message")
+		return nil
+	}
 	var traceContext *p2pmsg.TraceContext
 	ctx, span, reportError := newSpanForPublish(ctx, m.P2P, traceContext, msg)
 	defer span.End()
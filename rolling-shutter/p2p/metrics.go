@@ -2,6 +2,7 @@ package p2p
 
 import (
 	mapset "github.com/deckarep/golang-set/v2"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/prometheus/client_golang/prometheus"
@@ -68,6 +69,45 @@ var metricsP2PPeerUserAgent = prometheus.NewGaugeVec(
 	[]string{"peer_id", "user_agent"},
 )
 
+var metricsP2PBackfilledMessages = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "p2p",
+		Name:      "backfilled_messages_total",
+		Help:      "Number of messages received via backfill requests and accepted.",
+	},
+)
+
+var metricsP2PRateLimitViolations = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "shutter",
+		Subsystem: "p2p",
+		Name:      "rate_limit_violations_total",
+		Help:      "Number of received messages exceeding the message size or per peer rate limits.",
+	},
+	[]string{"topic"},
+)
+
+var metricsP2PGossipSubParams = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "p2p",
+		Name:      "gossipsub_param",
+		Help:      "Effective gossipsub router and peer scoring parameters.",
+	},
+	[]string{"param"},
+)
+
+var metricsP2PTopicScoreParams = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "shutter",
+		Subsystem: "p2p",
+		Name:      "topic_score_param",
+		Help:      "Effective peer scoring parameters of a topic.",
+	},
+	[]string{"topic", "param"},
+)
+
 func collectPeerAddresses(p peer.AddrInfo) {
 	for _, multiAddr := range p.Addrs {
 		metricsP2PPeerTuples.WithLabelValues(p.ID.String(), multiAddr.String()).Set(1)
@@ -81,6 +121,65 @@ func init() {
 	prometheus.MustRegister(metricsP2PPeerConnectedness)
 	prometheus.MustRegister(metricsP2PPeerPing)
 	prometheus.MustRegister(metricsP2PPeerUserAgent)
+	prometheus.MustRegister(metricsP2PBackfilledMessages)
+	prometheus.MustRegister(metricsP2PRateLimitViolations)
+	prometheus.MustRegister(metricsP2PGossipSubParams)
+	prometheus.MustRegister(metricsP2PTopicScoreParams)
+}
+
+func reportGossipSubParams(
+	gossipSubParams *pubsub.GossipSubParams,
+	peerScoreParams *pubsub.PeerScoreParams,
+	peerScoreThresholds *pubsub.PeerScoreThresholds,
+) {
+	for param, value := range map[string]float64{
+		"heartbeat_interval_seconds":     gossipSubParams.HeartbeatInterval.Seconds(),
+		"history_length":                 float64(gossipSubParams.HistoryLength),
+		"history_gossip":                 float64(gossipSubParams.HistoryGossip),
+		"d":                              float64(gossipSubParams.D),
+		"d_lo":                           float64(gossipSubParams.Dlo),
+		"d_hi":                           float64(gossipSubParams.Dhi),
+		"d_out":                          float64(gossipSubParams.Dout),
+		"d_score":                        float64(gossipSubParams.Dscore),
+		"d_lazy":                         float64(gossipSubParams.Dlazy),
+		"gossip_threshold":               peerScoreThresholds.GossipThreshold,
+		"publish_threshold":              peerScoreThresholds.PublishThreshold,
+		"graylist_threshold":             peerScoreThresholds.GraylistThreshold,
+		"accept_px_threshold":            peerScoreThresholds.AcceptPXThreshold,
+		"opportunistic_graft_threshold":  peerScoreThresholds.OpportunisticGraftThreshold,
+		"topic_score_cap":                peerScoreParams.TopicScoreCap,
+		"ip_colocation_factor_weight":    peerScoreParams.IPColocationFactorWeight,
+		"ip_colocation_factor_threshold": float64(peerScoreParams.IPColocationFactorThreshold),
+		"behaviour_penalty_weight":       peerScoreParams.BehaviourPenaltyWeight,
+		"behaviour_penalty_threshold":    peerScoreParams.BehaviourPenaltyThreshold,
+		"behaviour_penalty_decay":        peerScoreParams.BehaviourPenaltyDecay,
+	} {
+		metricsP2PGossipSubParams.WithLabelValues(param).Set(value)
+	}
+}
+
+func reportTopicScoreParams(topic string, params *pubsub.TopicScoreParams) {
+	for param, value := range map[string]float64{
+		"topic_weight":                               params.TopicWeight,
+		"time_in_mesh_weight":                        params.TimeInMeshWeight,
+		"time_in_mesh_quantum_seconds":               params.TimeInMeshQuantum.Seconds(),
+		"time_in_mesh_cap":                           params.TimeInMeshCap,
+		"first_message_deliveries_weight":            params.FirstMessageDeliveriesWeight,
+		"first_message_deliveries_decay":             params.FirstMessageDeliveriesDecay,
+		"first_message_deliveries_cap":               params.FirstMessageDeliveriesCap,
+		"mesh_message_deliveries_weight":             params.MeshMessageDeliveriesWeight,
+		"mesh_message_deliveries_decay":              params.MeshMessageDeliveriesDecay,
+		"mesh_message_deliveries_cap":                params.MeshMessageDeliveriesCap,
+		"mesh_message_deliveries_threshold":          params.MeshMessageDeliveriesThreshold,
+		"mesh_message_deliveries_window_seconds":     params.MeshMessageDeliveriesWindow.Seconds(),
+		"mesh_message_deliveries_activation_seconds": params.MeshMessageDeliveriesActivation.Seconds(),
+		"mesh_failure_penalty_weight":                params.MeshFailurePenaltyWeight,
+		"mesh_failure_penalty_decay":                 params.MeshFailurePenaltyDecay,
+		"invalid_message_deliveries_weight":          params.InvalidMessageDeliveriesWeight,
+		"invalid_message_deliveries_decay":           params.InvalidMessageDeliveriesDecay,
+	} {
+		metricsP2PTopicScoreParams.WithLabelValues(topic, param).Set(value)
+	}
 }
 
 func updatePeersMetrics(h host.Host, peerIds mapset.Set[peer.ID]) {
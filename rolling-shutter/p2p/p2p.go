@@ -60,6 +60,8 @@ type P2PNode struct {
 	discovery   *routing.RoutingDiscovery
 	pubSub      *pubsub.PubSub
 	gossipRooms map[string]*gossipRoom
+	capture     *captureWriter
+	rateLimiter *rateLimiter
 
 	GossipMessages    chan *pubsub.Message
 	FloodSubDiscovery *floodsubpeerdiscovery.FloodsubPeerDiscovery
@@ -75,6 +77,12 @@ type p2pNodeConfig struct {
 	IsAccessNode       bool
 	DiscoveryNamespace string
 	FloodsubDiscovery  FloodsubDiscoveryConfig
+	PeerScores         []PeerScoreFunc
+	BackfillProvider   BackfillProvider
+	Capture            CaptureConfig
+	GossipSub          *GossipSubConfig
+	RateLimits         *RateLimitsConfig
+	WebSocket          *WebSocketConfig
 }
 
 type FloodsubDiscoveryConfig struct {
@@ -91,6 +99,10 @@ func NewP2PNode(config p2pNodeConfig) *P2PNode {
 		gossipRooms:    make(map[string]*gossipRoom),
 		GossipMessages: make(chan *pubsub.Message, messagesBufSize),
 	}
+	if config.RateLimits != nil {
+		p.rateLimiter = newRateLimiter(config.RateLimits)
+		p.config.PeerScores = append(p.config.PeerScores, p.rateLimiter.score)
+	}
 	return &p
 }
 
@@ -106,6 +118,14 @@ func (p *P2PNode) Run(
 	if err := p.init(ctx); err != nil {
 		return err
 	}
+	if p.config.Capture.Enabled {
+		capture, err := newCaptureWriter(p.config.Capture)
+		if err != nil {
+			return err
+		}
+		p.capture = capture
+		log.Info().Str("path", p.config.Capture.Path).Msg("capturing received gossip messages")
+	}
 
 	runner.Go(func() error {
 		<-ctx.Done()
@@ -117,12 +137,27 @@ func (p *P2PNode) Run(
 			log.Error().Err(err).Msg("error closing dht")
 		}
 		close(p.GossipMessages)
+		if p.capture != nil {
+			if err := p.capture.Close(); err != nil {
+				log.Error().Err(err).Msg("error closing capture file")
+			}
+		}
 		log.Debug().Msg("host closed")
 		return nil
 	})
 
+	if p.config.BackfillProvider != nil {
+		p.host.SetStreamHandler(BackfillProtocolID, p.handleBackfillStream(ctx, p.config.BackfillProvider))
+	}
+
 	for topicName := range topicValidators {
 		validator := topicValidators.GetCombinedValidator(topicName)
+		if p.rateLimiter != nil {
+			validator = p.rateLimiter.wrapValidator(topicName, validator)
+		}
+		if p.capture != nil {
+			validator = p.capture.wrapValidator(validator, false)
+		}
 		if err := p.pubSub.RegisterTopicValidator(topicName, validator); err != nil {
 			return err
 		}
@@ -176,6 +211,12 @@ func (p *P2PNode) Run(
 	return nil
 }
 
+func (p *P2PNode) addPeerScore(fn PeerScoreFunc) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.config.PeerScores = append(p.config.PeerScores, fn)
+}
+
 func (p *P2PNode) Publish(ctx context.Context, topic string, message []byte) error {
 	p.mux.Lock()
 	room, ok := p.gossipRooms[topic]
@@ -207,6 +248,9 @@ func (p *P2PNode) init(ctx context.Context) error {
 	p.discovery = discovery
 	p.pubSub = p2pPubSub
 	log.Info().Str("address", p.p2pAddress()).Msg("created libp2p host")
+	if p.config.IsAccessNode {
+		p.logBrowserAddrs()
+	}
 	return nil
 }
 
@@ -248,6 +292,11 @@ func createHost(
 		libp2p.EnableRelay(),
 		libp2p.Ping(true),
 	}
+	transports, err := transportOptions(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	options = append(options, transports...)
 
 	localNetworking := bool(config.Environment == env.EnvironmentLocal)
 	if !localNetworking {
@@ -263,7 +312,7 @@ func createHost(
 			// If advertise addresses are set, only advertise those
 			options = append(options,
 				libp2p.AddrsFactory(func(addrs []multiaddr.Multiaddr) []multiaddr.Multiaddr {
-					return config.AdvertiseAddrs
+					return withWebTransportCertHashes(config.AdvertiseAddrs, addrs)
 				}),
 			)
 		}
@@ -315,7 +364,10 @@ func createPubSub(
 		isBootstrapNode: config.IsBootstrapNode,
 		bootstrapPeers:  config.BootstrapPeers,
 		isAccessNode:    config.IsAccessNode,
+		peerScores:      config.PeerScores,
+		config:          config.GossipSub,
 	})
+	reportGossipSubParams(gossipSubParams, peerScoreParams, peerScoreThresholds)
 
 	pubsubOptions := []pubsub.Option{
 		pubsub.WithGossipSubParams(*gossipSubParams),
@@ -380,7 +432,9 @@ func (p *P2PNode) joinTopic(topicName string) error {
 	}
 
 	// set peer scoring parameters
-	err = topic.SetScoreParams(topicScoreParams())
+	scoreParams := topicScoreParams(p.config.GossipSub, topicName)
+	reportTopicScoreParams(topicName, scoreParams)
+	err = topic.SetScoreParams(scoreParams)
 	if err != nil {
 		return errors.Wrapf(err, "failed to set peer scoring parameters")
 	}
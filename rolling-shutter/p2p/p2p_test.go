@@ -12,6 +12,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"gotest.tools/assert"
 
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/address"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
@@ -40,7 +41,7 @@ func TestStartNetworkNodeIntegration(t *testing.T) { //nolint:funlen
 	firstPort := 2000
 	for i := 0; i < numBootstrappers; i++ {
 		cfg := NewConfig()
-		err := cfg.SetExampleValues()
+		err := configuration.SetExampleValuesRecursive(cfg)
 		assert.NilError(t, err)
 
 		port := firstPort + i
@@ -68,7 +69,7 @@ func TestStartNetworkNodeIntegration(t *testing.T) { //nolint:funlen
 
 	for i := 0; i < numPeers; i++ {
 		cfg := NewConfig()
-		err := cfg.SetExampleValues()
+		err := configuration.SetExampleValuesRecursive(cfg)
 		assert.NilError(t, err)
 
 		port := firstPort + numBootstrappers + i
@@ -5,25 +5,34 @@ import (
 
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprtopics"
 )
 
 type pubSubParamsOptions struct {
 	isBootstrapNode bool
 	isAccessNode    bool
 	bootstrapPeers  []peer.AddrInfo
+	peerScores      []PeerScoreFunc
+	config          *GossipSubConfig
 }
 
 func makePubSubParams(
 	options pubSubParamsOptions,
 ) (*pubsub.GossipSubParams, *pubsub.PeerScoreParams, *pubsub.PeerScoreThresholds) {
+	config := options.config
 	gsDefault := pubsub.DefaultGossipSubParams()
 	gossipSubParams := &gsDefault
 
-	// modified defaults from ethereum consensus spec
-	// https://github.com/ethereum/consensus-specs/blob/5d80b1954a4b7a121aa36143d50b366727b66cbc/\
-	//   specs/phase0/p2p-interface.md#why-are-these-specific-gossip-parameters-chosen //nolint:lll
-	gossipSubParams.HeartbeatInterval = 700 * time.Millisecond
-	gossipSubParams.HistoryLength = 6
+	gossipSubParams.HeartbeatInterval = time.Duration(config.HeartbeatIntervalMillis) * time.Millisecond //nolint:gosec // G115
+	gossipSubParams.HistoryLength = config.HistoryLength
+	gossipSubParams.HistoryGossip = config.HistoryGossip
+	gossipSubParams.D = config.D
+	gossipSubParams.Dlo = config.Dlo
+	gossipSubParams.Dhi = config.Dhi
+	gossipSubParams.Dout = config.Dout
+	gossipSubParams.Dscore = config.Dscore
+	gossipSubParams.Dlazy = config.Dlazy
 
 	// From the spec:
 	// to allow bootstrapping via PeerExchange (PX),
@@ -37,15 +46,15 @@ func makePubSubParams(
 	}
 
 	if options.isAccessNode {
-		gossipSubParams.Dhi = 30
+		gossipSubParams.Dhi = config.AccessNodeDhi
 	}
 
 	peerScoreThresholds := &pubsub.PeerScoreThresholds{
-		GossipThreshold:             -4000,
-		PublishThreshold:            -8000,
-		GraylistThreshold:           -16000,
-		AcceptPXThreshold:           100,
-		OpportunisticGraftThreshold: 5,
+		GossipThreshold:             config.GossipThreshold,
+		PublishThreshold:            config.PublishThreshold,
+		GraylistThreshold:           config.GraylistThreshold,
+		AcceptPXThreshold:           config.AcceptPXThreshold,
+		OpportunisticGraftThreshold: config.OpportunisticGraftThreshold,
 	}
 
 	bootstrapSet := make(map[peer.ID]bool, 0)
@@ -57,7 +66,7 @@ func makePubSubParams(
 	// Only the bootstrappers / highly trusted PX'ing nodes
 	// should reach the AcceptPXThreshold thus they need
 	// to be treated differently in the scoring function.
-	appSpecificScoringFn := func(p peer.ID) float64 {
+	bootstrapScoringFn := func(p peer.ID) float64 {
 		_, ok := bootstrapSet[p]
 		if !ok {
 			return 0.
@@ -71,23 +80,30 @@ func makePubSubParams(
 		// but don't overshoot and trust the bootstrap peers
 		// unconditionally - they should still be punishable
 		// for malicous behavior
-		return 200.
+		return config.BootstrapPeerScore
+	}
+	appSpecificScoringFn := func(p peer.ID) float64 {
+		score := bootstrapScoringFn(p)
+		for _, fn := range options.peerScores {
+			score += fn(p)
+		}
+		return score
 	}
 	peerScoreParams := &pubsub.PeerScoreParams{
 		// Topics score-map will be filled later while subscribing to topics.
 		Topics:        make(map[string]*pubsub.TopicScoreParams),
-		TopicScoreCap: 32.72,
+		TopicScoreCap: config.TopicScoreCap,
 
 		AppSpecificScore:  appSpecificScoringFn,
 		AppSpecificWeight: 1,
 
-		IPColocationFactorWeight:    -35.11,
-		IPColocationFactorThreshold: 10,
+		IPColocationFactorWeight:    config.IPColocationFactorWeight,
+		IPColocationFactorThreshold: config.IPColocationFactorThreshold,
 		IPColocationFactorWhitelist: nil,
 
-		BehaviourPenaltyWeight:    -15.92,
-		BehaviourPenaltyThreshold: 6,
-		BehaviourPenaltyDecay:     0.928,
+		BehaviourPenaltyWeight:    config.BehaviourPenaltyWeight,
+		BehaviourPenaltyThreshold: config.BehaviourPenaltyThreshold,
+		BehaviourPenaltyDecay:     config.BehaviourPenaltyDecay,
 
 		DecayInterval: 12 * time.Second,
 		DecayToZero:   0.01,
@@ -97,27 +113,17 @@ func makePubSubParams(
 	return gossipSubParams, peerScoreParams, peerScoreThresholds
 }
 
-func topicScoreParams() *pubsub.TopicScoreParams {
-	// Based on attestation topic in beacon chain network. The formula uses the number of
-	// validators which we set to a fixed number which could be the number of keypers.
-	n := float64(200)
-	return &pubsub.TopicScoreParams{
-		TopicWeight:                     1,
-		TimeInMeshWeight:                0.0324,
-		TimeInMeshQuantum:               12 * time.Second,
-		TimeInMeshCap:                   300,
-		FirstMessageDeliveriesWeight:    0.05,
-		FirstMessageDeliveriesDecay:     0.631,
-		FirstMessageDeliveriesCap:       n / 755.712,
-		MeshMessageDeliveriesWeight:     -0.026,
-		MeshMessageDeliveriesDecay:      0.631,
-		MeshMessageDeliveriesCap:        n / 94.464,
-		MeshMessageDeliveriesThreshold:  n / 377.856,
-		MeshMessageDeliveriesWindow:     200 * time.Millisecond,
-		MeshMessageDeliveriesActivation: 4 * 12 * time.Second,
-		MeshFailurePenaltyWeight:        -0.0026,
-		MeshFailurePenaltyDecay:         0.631,
-		InvalidMessageDeliveriesWeight:  -99,
-		InvalidMessageDeliveriesDecay:   0.9994,
+// topicScoreParams returns the score parameters of the given topic. The key and key share topics
+// are configurable, all other topics use the defaults.
+func topicScoreParams(config *GossipSubConfig, topic string) *pubsub.TopicScoreParams {
+	switch topic {
+	case kprtopics.DecryptionKeys:
+		return config.DecryptionKeysTopic.params()
+	case kprtopics.DecryptionKeyShares:
+		return config.DecryptionKeySharesTopic.params()
+	default:
+		defaults := NewTopicScoreConfig()
+		_ = defaults.SetDefaultValues()
+		return defaults.params()
 	}
 }
@@ -0,0 +1,152 @@
+package p2p
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprtopics"
+)
+
+// rateLimiterCleanupInterval is the interval in which state of idle peers is dropped.
+const rateLimiterCleanupInterval = 5 * time.Minute
+
+type peerLimits struct {
+	limiters   map[string]*rate.Limiter
+	violations float64
+	// lastViolation is the time violations has been decayed to
+	lastViolation time.Time
+	lastSeen      time.Time
+}
+
+// rateLimiter enforces the message size and per peer rate limits of incoming gossip messages and
+// bounds the number of messages validated concurrently.
+type rateLimiter struct {
+	config     *RateLimitsConfig
+	validation *semaphore.Weighted
+	now        func() time.Time
+
+	mux         sync.Mutex
+	peers       map[peer.ID]*peerLimits
+	lastCleanup time.Time
+}
+
+func newRateLimiter(config *RateLimitsConfig) *rateLimiter {
+	return &rateLimiter{
+		config:      config,
+		validation:  semaphore.NewWeighted(int64(config.MaxValidationConcurrency)),
+		now:         time.Now,
+		peers:       make(map[peer.ID]*peerLimits),
+		lastCleanup: time.Now(),
+	}
+}
+
+func (l *rateLimiter) topicLimits(topic string) *TopicLimitsConfig {
+	switch topic {
+	case kprtopics.DecryptionKeys:
+		return l.config.DecryptionKeysTopic
+	case kprtopics.DecryptionKeyShares:
+		return l.config.DecryptionKeySharesTopic
+	default:
+		return l.config.DefaultTopic
+	}
+}
+
+// decay returns the factor by which violations decay over the given duration.
+func (l *rateLimiter) decay(elapsed time.Duration) float64 {
+	halfLife := time.Duration(l.config.ViolationHalfLifeSeconds) * time.Second //nolint:gosec // G115
+	return math.Pow(0.5, elapsed.Seconds()/halfLife.Seconds())
+}
+
+// allow reports whether a message of the given size from the given peer is within the limits of
+// the topic. If not, a violation is recorded for the peer.
+func (l *rateLimiter) allow(topic string, sender peer.ID, size int) (pubsub.ValidationResult, bool) {
+	limits := l.topicLimits(topic)
+	now := l.now()
+
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.cleanup(now)
+	peerState, ok := l.peers[sender]
+	if !ok {
+		peerState = &peerLimits{limiters: make(map[string]*rate.Limiter)}
+		l.peers[sender] = peerState
+	}
+	peerState.lastSeen = now
+	limiter, ok := peerState.limiters[topic]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(limits.MessagesPerSecond), limits.Burst)
+		peerState.limiters[topic] = limiter
+	}
+
+	// oversized messages are rejected, so that gossipsub penalizes them as invalid deliveries
+	// too, excessive ones are only ignored as they might have been valid
+	result := pubsub.ValidationAccept
+	if size > limits.MaxMessageSize {
+		result = pubsub.ValidationReject
+	} else if !limiter.AllowN(now, 1) {
+		result = pubsub.ValidationIgnore
+	}
+	if result == pubsub.ValidationAccept {
+		return result, true
+	}
+	peerState.violations = peerState.violations*l.decay(now.Sub(peerState.lastViolation)) + 1
+	peerState.lastViolation = now
+	metricsP2PRateLimitViolations.WithLabelValues(topic).Inc()
+	return result, false
+}
+
+// cleanup drops the state of peers that have neither sent messages nor violated limits recently.
+func (l *rateLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < rateLimiterCleanupInterval {
+		return
+	}
+	l.lastCleanup = now
+	for peerID, peerState := range l.peers {
+		if now.Sub(peerState.lastSeen) < rateLimiterCleanupInterval {
+			continue
+		}
+		if peerState.violations*l.decay(now.Sub(peerState.lastViolation)) >= 0.01 {
+			continue
+		}
+		delete(l.peers, peerID)
+	}
+}
+
+// score is the application specific peer score contribution penalizing limit violations.
+func (l *rateLimiter) score(peerID peer.ID) float64 {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	peerState, ok := l.peers[peerID]
+	if !ok || peerState.violations == 0 {
+		return 0
+	}
+	violations := peerState.violations * l.decay(l.now().Sub(peerState.lastViolation))
+	return -l.config.ViolationPenalty * violations
+}
+
+// wrapValidator returns a validator enforcing the limits of the topic before calling validator.
+func (l *rateLimiter) wrapValidator(topic string, validator pubsub.ValidatorEx) pubsub.ValidatorEx {
+	return func(ctx context.Context, sender peer.ID, message *pubsub.Message) pubsub.ValidationResult {
+		if res, ok := l.allow(topic, sender, len(message.GetData())); !ok {
+			log.Debug().
+				Str("topic", topic).
+				Str("sender-id", sender.String()).
+				Int("size", len(message.GetData())).
+				Msg("peer exceeded message limits")
+			return res
+		}
+		if err := l.validation.Acquire(ctx, 1); err != nil {
+			return pubsub.ValidationIgnore
+		}
+		defer l.validation.Release(1)
+		return validator(ctx, sender, message)
+	}
+}
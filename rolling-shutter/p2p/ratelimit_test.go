@@ -0,0 +1,103 @@
+package p2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprtopics"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+func newTestRateLimiter(t *testing.T) (*rateLimiter, *time.Time) {
+	t.Helper()
+	config := NewRateLimitsConfig()
+	assert.NilError(t, configuration.SetDefaultValuesRecursive(config, nil))
+	config.MaxValidationConcurrency = 1
+	config.ViolationPenalty = 10
+	config.ViolationHalfLifeSeconds = 60
+	config.DecryptionKeySharesTopic.MaxMessageSize = 100
+	config.DecryptionKeySharesTopic.MessagesPerSecond = 1
+	config.DecryptionKeySharesTopic.Burst = 2
+	assert.NilError(t, config.Validate())
+
+	now := time.Unix(1000, 0)
+	limiter := newRateLimiter(config)
+	limiter.now = func() time.Time { return now }
+	limiter.lastCleanup = now
+	return limiter, &now
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	limiter, now := newTestRateLimiter(t)
+	topic := kprtopics.DecryptionKeyShares
+	alice, bob := peer.ID("alice"), peer.ID("bob")
+
+	res, ok := limiter.allow(topic, alice, 101)
+	assert.Assert(t, !ok)
+	assert.Equal(t, res, pubsub.ValidationReject)
+	assert.Equal(t, limiter.score(alice), float64(-10))
+
+	for i := 0; i < 2; i++ {
+		_, ok = limiter.allow(topic, alice, 100)
+		assert.Assert(t, ok)
+	}
+	res, ok = limiter.allow(topic, alice, 100)
+	assert.Assert(t, !ok)
+	assert.Equal(t, res, pubsub.ValidationIgnore)
+	assert.Equal(t, limiter.score(alice), float64(-20))
+
+	// limits are tracked per peer and topic
+	_, ok = limiter.allow(topic, bob, 100)
+	assert.Assert(t, ok)
+	_, ok = limiter.allow(kprtopics.DecryptionKeys, alice, 1000)
+	assert.Assert(t, ok)
+	assert.Equal(t, limiter.score(bob), float64(0))
+
+	// the budget refills and the penalty decays over time
+	*now = now.Add(time.Minute)
+	_, ok = limiter.allow(topic, alice, 100)
+	assert.Assert(t, ok)
+	assert.Equal(t, limiter.score(alice), float64(-10))
+
+	// idle peers without violations are dropped
+	*now = now.Add(rateLimiterCleanupInterval)
+	_, ok = limiter.allow(topic, alice, 100)
+	assert.Assert(t, ok)
+	_, known := limiter.peers[bob]
+	assert.Assert(t, !known)
+	_, known = limiter.peers[alice]
+	assert.Assert(t, known)
+}
+
+func TestRateLimiterValidationConcurrency(t *testing.T) {
+	limiter, _ := newTestRateLimiter(t)
+	topic := kprtopics.DecryptionKeys
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	validator := limiter.wrapValidator(topic, func(context.Context, peer.ID, *pubsub.Message) pubsub.ValidationResult {
+		blocked <- struct{}{}
+		<-release
+		return pubsub.ValidationAccept
+	})
+	message := &pubsub.Message{Message: &pb.Message{Data: []byte{1}, Topic: &topic}}
+
+	results := make(chan pubsub.ValidationResult)
+	go func() {
+		results <- validator(context.Background(), peer.ID("alice"), message)
+	}()
+	<-blocked
+
+	// the second validation waits for the first one and gives up when the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, validator(ctx, peer.ID("bob"), message), pubsub.ValidationIgnore)
+
+	close(release)
+	assert.Equal(t, <-results, pubsub.ValidationAccept)
+}
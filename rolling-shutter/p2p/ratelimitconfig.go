@@ -0,0 +1,129 @@
+package p2p
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+var (
+	_ configuration.Config = &RateLimitsConfig{}
+	_ configuration.Config = &TopicLimitsConfig{}
+)
+
+func NewRateLimitsConfig() *RateLimitsConfig {
+	c := &RateLimitsConfig{}
+	c.Init()
+	return c
+}
+
+// RateLimitsConfig holds the limits enforced on incoming gossip messages before they are
+// validated by the message handlers. Peers exceeding them are penalized via the application
+// specific peer score.
+type RateLimitsConfig struct {
+	MaxValidationConcurrency int     `comment:"Maximum number of messages validated by the handlers concurrently"`
+	ViolationPenalty         float64 `comment:"Peer score subtracted for every message exceeding a limit"`
+	ViolationHalfLifeSeconds uint64  `comment:"Time after which half of the penalty is forgiven"`
+
+	DefaultTopic             *TopicLimitsConfig `comment:"Limits of all topics not configured separately"`
+	DecryptionKeysTopic      *TopicLimitsConfig
+	DecryptionKeySharesTopic *TopicLimitsConfig
+}
+
+// TopicLimitsConfig holds the limits of a single topic.
+type TopicLimitsConfig struct {
+	MaxMessageSize    int     `comment:"Maximum size of an encoded message in bytes"`
+	MessagesPerSecond float64 `comment:"Number of messages a single peer may send per second on average"`
+	Burst             int     `comment:"Number of messages a single peer may send at once"`
+}
+
+func (c *RateLimitsConfig) Init() {
+	c.DefaultTopic = NewTopicLimitsConfig()
+	c.DecryptionKeysTopic = NewTopicLimitsConfig()
+	c.DecryptionKeySharesTopic = NewTopicLimitsConfig()
+}
+
+func (c *RateLimitsConfig) Name() string {
+	return "ratelimits"
+}
+
+func (c *RateLimitsConfig) Validate() error {
+	if c.MaxValidationConcurrency <= 0 {
+		return errors.New("MaxValidationConcurrency must be positive")
+	}
+	if c.ViolationPenalty < 0 {
+		return errors.New("ViolationPenalty must not be negative")
+	}
+	if c.ViolationHalfLifeSeconds == 0 {
+		return errors.New("ViolationHalfLifeSeconds must be positive")
+	}
+	if err := c.DefaultTopic.Validate(); err != nil {
+		return errors.Wrap(err, "invalid DefaultTopic limits")
+	}
+	if err := c.DecryptionKeysTopic.Validate(); err != nil {
+		return errors.Wrap(err, "invalid DecryptionKeysTopic limits")
+	}
+	if err := c.DecryptionKeySharesTopic.Validate(); err != nil {
+		return errors.Wrap(err, "invalid DecryptionKeySharesTopic limits")
+	}
+	return nil
+}
+
+func (c *RateLimitsConfig) SetDefaultValues() error {
+	c.MaxValidationConcurrency = 16
+	c.ViolationPenalty = 50
+	c.ViolationHalfLifeSeconds = 60
+	return nil
+}
+
+func (c *RateLimitsConfig) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c RateLimitsConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
+
+func NewTopicLimitsConfig() *TopicLimitsConfig {
+	c := &TopicLimitsConfig{}
+	c.Init()
+	return c
+}
+
+func (c *TopicLimitsConfig) Init() {}
+
+func (c *TopicLimitsConfig) Name() string {
+	return "topiclimits"
+}
+
+func (c *TopicLimitsConfig) Validate() error {
+	if c.MaxMessageSize <= 0 {
+		return errors.New("MaxMessageSize must be positive")
+	}
+	if c.MessagesPerSecond <= 0 {
+		return errors.New("MessagesPerSecond must be positive")
+	}
+	if c.Burst <= 0 {
+		return errors.New("Burst must be positive")
+	}
+	return nil
+}
+
+// SetDefaultValues sets limits that are generous enough for keyper sets with a few dozen
+// keypers releasing keys every slot.
+func (c *TopicLimitsConfig) SetDefaultValues() error {
+	c.MaxMessageSize = 1 << 20
+	c.MessagesPerSecond = 20
+	c.Burst = 200
+	return nil
+}
+
+func (c *TopicLimitsConfig) SetExampleValues() error {
+	return c.SetDefaultValues()
+}
+
+func (c TopicLimitsConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
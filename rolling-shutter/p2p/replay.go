@@ -0,0 +1,108 @@
+package p2p
+
+import (
+	"context"
+	"io"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
+)
+
+// ReplayStats summarizes the outcome of replaying a capture file.
+type ReplayStats struct {
+	Total    int
+	Accepted int
+	Rejected int
+	Ignored  int
+	// Skipped counts the messages on topics without registered validators.
+	Skipped int
+	// Diverged counts the messages whose validation result differs from the captured one.
+	Diverged int
+	// Failed counts the accepted messages the handlers returned an error for.
+	Failed int
+}
+
+// NewReplayMessaging returns a P2PMessaging that is not connected to any network. Handlers and
+// validators can be registered as usual and fed with captured messages using Replay. Messages
+// sent, e.g. the output messages of handlers, are dropped. It must not be started as a service.
+func NewReplayMessaging() *P2PMessaging {
+	return &P2PMessaging{
+		P2P:               nil,
+		gossipTopicNames:  make(map[string]struct{}),
+		handlerRegistry:   make(HandlerRegistry),
+		validatorRegistry: make(ValidatorRegistry),
+	}
+}
+
+// Replay reads a capture file and feeds its messages through the registered validators and, if
+// accepted, the registered handlers, in the same way messages received via gossip are processed.
+func (m *P2PMessaging) Replay(ctx context.Context, r io.Reader) (ReplayStats, error) {
+	stats := ReplayStats{}
+	err := ReadCapture(r, func(record *p2pmsg.CapturedMessage) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		stats.Total++
+		if _, ok := m.validatorRegistry[record.Topic]; !ok {
+			stats.Skipped++
+			return nil
+		}
+		msg, err := replayedPubsubMessage(record)
+		if err != nil {
+			return errors.Wrapf(err, "invalid captured message %d", stats.Total)
+		}
+		res := m.validatorRegistry.GetCombinedValidator(record.Topic)(ctx, msg.ReceivedFrom, msg)
+		logger := log.With().
+			Int("index", stats.Total).
+			Str("topic", record.Topic).
+			Str("sender-id", record.Sender).
+			Time("arrival-time", time.Unix(0, record.ArrivalTime)).
+			Logger()
+		if validationResultString(res) != record.ValidationResult {
+			stats.Diverged++
+			logger.Info().
+				Str("captured", record.ValidationResult).
+				Str("replayed", validationResultString(res)).
+				Msg("validation result diverges from capture")
+		}
+		switch res {
+		case pubsub.ValidationAccept:
+			stats.Accepted++
+		case pubsub.ValidationIgnore:
+			stats.Ignored++
+			return nil
+		default:
+			stats.Rejected++
+			return nil
+		}
+		if err := m.handle(ctx, msg); err != nil {
+			stats.Failed++
+			logger.Info().Err(err).Msg("failed to handle replayed message")
+		}
+		return nil
+	})
+	return stats, err
+}
+
+func replayedPubsubMessage(record *p2pmsg.CapturedMessage) (*pubsub.Message, error) {
+	sender, err := peer.Decode(record.Sender)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid sender")
+	}
+	topic := record.Topic
+	message := &pb.Message{Data: record.Envelope, Topic: &topic}
+	if record.Origin != "" {
+		origin, err := peer.Decode(record.Origin)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid origin")
+		}
+		message.From = []byte(origin)
+	}
+	return &pubsub.Message{Message: message, ReceivedFrom: sender}, nil
+}
@@ -0,0 +1,250 @@
+package p2p
+
+import (
+	"crypto/tls"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	quic "github.com/libp2p/go-libp2p/p2p/transport/quic"
+	"github.com/libp2p/go-libp2p/p2p/transport/tcp"
+	libp2pwebrtc "github.com/libp2p/go-libp2p/p2p/transport/webrtc"
+	ws "github.com/libp2p/go-libp2p/p2p/transport/websocket"
+	webtransport "github.com/libp2p/go-libp2p/p2p/transport/webtransport"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/configuration"
+)
+
+// certificateReloadInterval is the interval in which the WebSocket TLS certificate files are
+// checked for changes, so that renewed certificates are picked up without a restart.
+const certificateReloadInterval = time.Minute
+
+var _ configuration.Config = &WebSocketConfig{}
+
+func NewWebSocketConfig() *WebSocketConfig {
+	c := &WebSocketConfig{}
+	c.Init()
+	return c
+}
+
+// WebSocketConfig holds the certificate served by secure WebSocket listeners (/tls/ws or /wss
+// listen addresses). Browsers refuse to open insecure WebSockets from pages served via https, so
+// access nodes reachable by dapps need a certificate valid for the domain in the listen address.
+type WebSocketConfig struct {
+	TLSCertFile string `comment:"Optional, PEM encoded certificate chain for secure WebSocket listeners"`
+	TLSKeyFile  string `comment:"Optional, PEM encoded private key of the certificate"`
+}
+
+func (c *WebSocketConfig) Init() {}
+
+func (c *WebSocketConfig) Name() string {
+	return "websocket"
+}
+
+func (c *WebSocketConfig) Validate() error {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New("TLSCertFile and TLSKeyFile must be set together")
+	}
+	return nil
+}
+
+func (c *WebSocketConfig) SetDefaultValues() error {
+	return nil
+}
+
+func (c *WebSocketConfig) SetExampleValues() error {
+	return nil
+}
+
+func (c WebSocketConfig) TOMLWriteHeader(_ io.Writer) (int, error) {
+	return 0, nil
+}
+
+func (c *WebSocketConfig) hasCertificate() bool {
+	return c != nil && c.TLSCertFile != ""
+}
+
+// isBrowserTransportAddr checks if the address uses one of the transports meant for browser
+// peers, i.e. WebSocket or WebTransport.
+func isBrowserTransportAddr(addr multiaddr.Multiaddr) bool {
+	for _, code := range []int{multiaddr.P_WS, multiaddr.P_WSS, multiaddr.P_WEBTRANSPORT} {
+		if _, err := addr.ValueForProtocol(code); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func isSecureWebSocketAddr(addr multiaddr.Multiaddr) bool {
+	if _, err := addr.ValueForProtocol(multiaddr.P_WSS); err == nil {
+		return true
+	}
+	_, errTLS := addr.ValueForProtocol(multiaddr.P_TLS)
+	_, errWS := addr.ValueForProtocol(multiaddr.P_WS)
+	return errTLS == nil && errWS == nil
+}
+
+// selectListenAddrs returns the addresses to listen on. WebSocket and WebTransport listeners are
+// only opened by access nodes, other nodes drop them so that configs generated with the libp2p
+// default listen addresses keep working.
+func selectListenAddrs(
+	addrs []multiaddr.Multiaddr,
+	isAccessNode bool,
+	webSocket *WebSocketConfig,
+) ([]multiaddr.Multiaddr, error) {
+	selected := []multiaddr.Multiaddr{}
+	for _, addr := range addrs {
+		if !isBrowserTransportAddr(addr) {
+			selected = append(selected, addr)
+			continue
+		}
+		if !isAccessNode {
+			log.Warn().
+				Str("address", addr.String()).
+				Msg("ignoring listen address, WebSocket and WebTransport are only supported on access nodes")
+			continue
+		}
+		if isSecureWebSocketAddr(addr) && !webSocket.hasCertificate() {
+			return nil, errors.Errorf("listen address %s requires a WebSocket TLS certificate", addr)
+		}
+		selected = append(selected, addr)
+	}
+	return selected, nil
+}
+
+// transportOptions returns the libp2p transports of the node. On top of TCP, QUIC and
+// WebRTC, access nodes support WebSocket and WebTransport so that browsers can connect to them
+// directly.
+func transportOptions(config p2pNodeConfig) ([]libp2p.Option, error) {
+	options := []libp2p.Option{
+		libp2p.Transport(tcp.NewTCPTransport),
+		libp2p.Transport(quic.NewTransport),
+		libp2p.Transport(libp2pwebrtc.New),
+	}
+	if !config.IsAccessNode {
+		return options, nil
+	}
+
+	wsOptions := []interface{}{}
+	if config.WebSocket.hasCertificate() {
+		reloader, err := newCertificateReloader(config.WebSocket.TLSCertFile, config.WebSocket.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		wsOptions = append(wsOptions, ws.WithTLSConfig(&tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.getCertificate,
+		}))
+	}
+	// WebTransport generates and rotates the self-signed certificates browsers accept via the
+	// /certhash components of our addresses by itself
+	return append(options,
+		libp2p.Transport(ws.New, wsOptions...),
+		libp2p.Transport(webtransport.New),
+	), nil
+}
+
+// withWebTransportCertHashes replaces the /certhash components of the WebTransport addresses in
+// advertised with the ones of the listen addresses in addrs. The hashes change whenever the
+// certificates are rotated, so they cannot be configured statically.
+func withWebTransportCertHashes(advertised, addrs []multiaddr.Multiaddr) []multiaddr.Multiaddr {
+	var certHashes []multiaddr.Component
+	for _, addr := range addrs {
+		if _, err := addr.ValueForProtocol(multiaddr.P_WEBTRANSPORT); err != nil {
+			continue
+		}
+		multiaddr.ForEach(addr, func(c multiaddr.Component) bool {
+			if c.Protocol().Code == multiaddr.P_CERTHASH {
+				certHashes = append(certHashes, c)
+			}
+			return true
+		})
+		if len(certHashes) > 0 {
+			break
+		}
+	}
+
+	result := make([]multiaddr.Multiaddr, 0, len(advertised))
+	for _, addr := range advertised {
+		if _, err := addr.ValueForProtocol(multiaddr.P_WEBTRANSPORT); err != nil {
+			result = append(result, addr)
+			continue
+		}
+		withoutHashes := multiaddr.Multiaddr{}
+		multiaddr.ForEach(addr, func(c multiaddr.Component) bool {
+			if c.Protocol().Code != multiaddr.P_CERTHASH {
+				withoutHashes = append(withoutHashes, c)
+			}
+			return true
+		})
+		result = append(result, append(withoutHashes, certHashes...))
+	}
+	return result
+}
+
+// certificateReloader serves a TLS certificate from files, reloading it when they change.
+type certificateReloader struct {
+	certFile, keyFile string
+
+	mux         sync.Mutex
+	certificate *tls.Certificate
+	modTime     time.Time
+	lastCheck   time.Time
+}
+
+func newCertificateReloader(certFile, keyFile string) (*certificateReloader, error) {
+	r := &certificateReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(time.Now()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certificateReloader) reload(now time.Time) error {
+	r.lastCheck = now
+	info, err := os.Stat(r.certFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to stat WebSocket TLS certificate")
+	}
+	if r.certificate != nil && info.ModTime().Equal(r.modTime) {
+		return nil
+	}
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to load WebSocket TLS certificate")
+	}
+	r.certificate = &certificate
+	r.modTime = info.ModTime()
+	return nil
+}
+
+func (r *certificateReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	now := time.Now()
+	if now.Sub(r.lastCheck) >= certificateReloadInterval {
+		if err := r.reload(now); err != nil {
+			// keep serving the previous certificate, e.g. while it is being replaced
+			log.Warn().Err(err).Msg("failed to reload WebSocket TLS certificate")
+		}
+	}
+	return r.certificate, nil
+}
+
+// logBrowserAddrs logs the addresses browser peers can dial, including the current WebTransport
+// certificate hashes.
+func (p *P2PNode) logBrowserAddrs() {
+	for _, addr := range p.host.Addrs() {
+		if !isBrowserTransportAddr(addr) {
+			continue
+		}
+		log.Info().
+			Str("address", addr.Encapsulate(multiaddr.StringCast("/p2p/"+p.host.ID().String())).String()).
+			Msg("listening for browser peers")
+	}
+}
@@ -0,0 +1,106 @@
+package p2p
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ws "github.com/libp2p/go-libp2p/p2p/transport/websocket"
+	"github.com/multiformats/go-multiaddr"
+	"gotest.tools/assert"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/env"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/encodeable/keys"
+)
+
+func TestSelectListenAddrs(t *testing.T) {
+	tcp := multiaddr.StringCast("/ip4/0.0.0.0/tcp/23000")
+	webSocket := multiaddr.StringCast("/ip4/0.0.0.0/tcp/23001/ws")
+	secureWebSocket := multiaddr.StringCast("/dns4/keys.example.com/tcp/443/tls/ws")
+	webTransport := multiaddr.StringCast("/ip4/0.0.0.0/udp/23002/quic-v1/webtransport")
+	addrs := []multiaddr.Multiaddr{tcp, webSocket, webTransport}
+
+	selected, err := selectListenAddrs(addrs, false, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, selected, []multiaddr.Multiaddr{tcp})
+
+	selected, err = selectListenAddrs(addrs, true, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, selected, addrs)
+
+	_, err = selectListenAddrs([]multiaddr.Multiaddr{secureWebSocket}, true, &WebSocketConfig{})
+	assert.ErrorContains(t, err, "TLS certificate")
+	selected, err = selectListenAddrs(
+		[]multiaddr.Multiaddr{secureWebSocket},
+		true,
+		&WebSocketConfig{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"},
+	)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, selected, []multiaddr.Multiaddr{secureWebSocket})
+
+	for _, addr := range defaultListenAddrs {
+		assert.Assert(t, !isBrowserTransportAddr(addr.Multiaddr), addr.String())
+	}
+}
+
+func TestWithWebTransportCertHashes(t *testing.T) {
+	hash1 := "/certhash/uEiDDq4_xNyDorZBH3TlGazyJdOWSwvo4PUo5YHFMrvDE8g"
+	hash2 := "/certhash/uEiAkH5a4DPGKUuOBjYw0CgwjvcJCJMD2K_1aluKR_tpevQ"
+	addrs := []multiaddr.Multiaddr{
+		multiaddr.StringCast("/ip4/10.0.0.1/tcp/23000"),
+		multiaddr.StringCast("/ip4/10.0.0.1/udp/23002/quic-v1/webtransport" + hash1 + hash2),
+	}
+	advertised := []multiaddr.Multiaddr{
+		multiaddr.StringCast("/ip4/1.2.3.4/tcp/23000"),
+		multiaddr.StringCast("/ip4/1.2.3.4/udp/23002/quic-v1/webtransport"),
+		multiaddr.StringCast("/ip4/1.2.3.4/udp/23003/quic-v1/webtransport" + hash2),
+	}
+	assert.DeepEqual(t, withWebTransportCertHashes(advertised, addrs), []multiaddr.Multiaddr{
+		multiaddr.StringCast("/ip4/1.2.3.4/tcp/23000"),
+		multiaddr.StringCast("/ip4/1.2.3.4/udp/23002/quic-v1/webtransport" + hash1 + hash2),
+		multiaddr.StringCast("/ip4/1.2.3.4/udp/23003/quic-v1/webtransport" + hash1 + hash2),
+	})
+}
+
+func TestAccessNodeBrowserTransports(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	privKey, err := keys.GenerateLibp2pPrivate(rand.Reader)
+	assert.NilError(t, err)
+	p2pHost, hashTable, err := createHost(ctx, p2pNodeConfig{
+		ListenAddrs: []multiaddr.Multiaddr{
+			multiaddr.StringCast("/ip4/127.0.0.1/tcp/0/ws"),
+			multiaddr.StringCast("/ip4/127.0.0.1/udp/0/quic-v1/webtransport"),
+		},
+		PrivKey:      *privKey,
+		Environment:  env.EnvironmentLocal,
+		IsAccessNode: true,
+	})
+	assert.NilError(t, err)
+	defer hashTable.Close()
+	defer p2pHost.Close()
+
+	var webSocketAddr multiaddr.Multiaddr
+	hasCertHash := false
+	for _, addr := range p2pHost.Addrs() {
+		if _, err := addr.ValueForProtocol(multiaddr.P_WS); err == nil {
+			webSocketAddr = addr
+		}
+		if _, err := addr.ValueForProtocol(multiaddr.P_CERTHASH); err == nil {
+			hasCertHash = true
+		}
+	}
+	assert.Assert(t, webSocketAddr != nil)
+	assert.Assert(t, hasCertHash)
+
+	// a client only speaking WebSocket, like a browser, can connect
+	client, err := libp2p.New(libp2p.NoListenAddrs, libp2p.Transport(ws.New))
+	assert.NilError(t, err)
+	defer client.Close()
+	err = client.Connect(ctx, peer.AddrInfo{ID: p2pHost.ID(), Addrs: []multiaddr.Multiaddr{webSocketAddr}})
+	assert.NilError(t, err)
+}
@@ -1,18 +1,25 @@
 package p2pmsg
 
 import (
-	"crypto/ecdsa"
+	"context"
 	"encoding/binary"
 
 	"golang.org/x/crypto/sha3"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/identitypreimage"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 )
 
 var triggerHashPrefix = []byte{0x19, 't', 'r', 'i', 'g', 'g', 'e', 'r'}
 
+// NewSignedDecryptionTrigger creates a new decryption trigger and signs it with the given signer.
 func NewSignedDecryptionTrigger(
-	instanceID uint64, identityPreimage identitypreimage.IdentityPreimage, blockNumber uint64, txHash []byte, privKey *ecdsa.PrivateKey,
+	ctx context.Context,
+	instanceID uint64,
+	identityPreimage identitypreimage.IdentityPreimage,
+	blockNumber uint64,
+	txHash []byte,
+	s signer.Signer,
 ) (*DecryptionTrigger, error) {
 	trigger := &DecryptionTrigger{
 		InstanceId:       instanceID,
@@ -20,7 +27,7 @@ func NewSignedDecryptionTrigger(
 		BlockNumber:      blockNumber,
 		TransactionsHash: txHash,
 	}
-	err := Sign(trigger, privKey)
+	err := SignWithSigner(ctx, trigger, s)
 	if err != nil {
 		return nil, err
 	}
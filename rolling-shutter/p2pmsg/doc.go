@@ -1,3 +1,3 @@
 package p2pmsg
 
-//go:generate protoc gossip.proto --go_out=./
+//go:generate protoc gossip.proto backfill.proto capture.proto --go_out=./
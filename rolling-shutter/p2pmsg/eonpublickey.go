@@ -1,22 +1,25 @@
 package p2pmsg
 
 import (
-	"crypto/ecdsa"
+	"context"
 	"encoding/binary"
 
 	"golang.org/x/crypto/sha3"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 )
 
 var eonPubKeyHashPrefix = []byte{0x19, 'e', 'o', 'n', 'p', 'u', 'b'}
 
-// NewSignedEonPublicKey creates a new eon public key and signs it with the given private key.
+// NewSignedEonPublicKey creates a new eon public key and signs it with the given signer.
 func NewSignedEonPublicKey(
+	ctx context.Context,
 	instanceID uint64,
 	eonPublicKey []byte,
 	activationBlock uint64,
 	keyperConfigIndex uint64,
 	eon uint64,
-	privKey *ecdsa.PrivateKey,
+	s signer.Signer,
 ) (*EonPublicKey, error) {
 	candidate := &EonPublicKey{
 		InstanceId:        instanceID,
@@ -25,7 +28,7 @@ func NewSignedEonPublicKey(
 		KeyperConfigIndex: keyperConfigIndex,
 		Eon:               eon,
 	}
-	err := Sign(candidate, privKey)
+	err := SignWithSigner(ctx, candidate, s)
 	if err != nil {
 		return nil, err
 	}
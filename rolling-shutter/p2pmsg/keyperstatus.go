@@ -0,0 +1,81 @@
+package p2pmsg
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprtopics"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
+)
+
+// MaxKeyperStatusVersionLength is the maximum length of the software version in a keyper status.
+const MaxKeyperStatusVersionLength = 128
+
+var keyperStatusHashPrefix = []byte{0x19, 's', 't', 'a', 't', 'u', 's'}
+
+// NewSignedKeyperStatus sets the address of the signer in the given status and signs it.
+func NewSignedKeyperStatus(ctx context.Context, status *KeyperStatus, s signer.Signer) (*KeyperStatus, error) {
+	status.KeyperAddress = s.Address().Bytes()
+	err := SignWithSigner(ctx, status, s)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (s *KeyperStatus) SetSignature(signature []byte) {
+	s.Signature = signature
+}
+
+func (s *KeyperStatus) Hash() []byte {
+	hash := sha3.New256()
+	hash.Write(keyperStatusHashPrefix)
+	_ = binary.Write(hash, binary.BigEndian, s.InstanceId)
+	hash.Write(s.KeyperAddress)
+	_ = binary.Write(hash, binary.BigEndian, s.KeyperIndex)
+	_ = binary.Write(hash, binary.BigEndian, s.Eon)
+	_ = binary.Write(hash, binary.BigEndian, s.BlockNumber)
+	_ = binary.Write(hash, binary.BigEndian, s.Slot)
+	_ = binary.Write(hash, binary.BigEndian, s.ShuttermintHeight)
+	_ = binary.Write(hash, binary.BigEndian, s.Timestamp)
+	hash.Write([]byte(s.Version))
+	return hash.Sum(nil)
+}
+
+// GetKeyperAddressValue returns the keyper address as common.Address. It must only be called on
+// validated statuses.
+func (s *KeyperStatus) GetKeyperAddressValue() common.Address {
+	return common.BytesToAddress(s.KeyperAddress)
+}
+
+func (s *KeyperStatus) LogInfo() string {
+	return fmt.Sprintf(
+		"KeyperStatus{keyper=%s, eon=%d, index=%d, block=%d}",
+		common.BytesToAddress(s.KeyperAddress).Hex(),
+		s.Eon,
+		s.KeyperIndex,
+		s.BlockNumber,
+	)
+}
+
+func (*KeyperStatus) Topic() string {
+	return kprtopics.KeyperStatus
+}
+
+func (s *KeyperStatus) Validate() error {
+	if len(s.KeyperAddress) != common.AddressLength {
+		return errors.Errorf("keyper address has %d bytes, expected %d", len(s.KeyperAddress), common.AddressLength)
+	}
+	if s.ShuttermintHeight < 0 {
+		return errors.Errorf("negative shuttermint height %d", s.ShuttermintHeight)
+	}
+	if len(s.Version) > MaxKeyperStatusVersionLength {
+		return errors.Errorf("version has %d characters, at most %d allowed", len(s.Version), MaxKeyperStatusVersionLength)
+	}
+	return nil
+}
@@ -1,15 +1,19 @@
 package p2pmsg
 
 import (
+	"context"
 	"crypto/rand"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	p2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"gotest.tools/v3/assert"
 
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/identitypreimage"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/testkeygen"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/trace"
 )
@@ -87,7 +91,9 @@ func TestDecryptionTrigger(t *testing.T) {
 	privKey, err := ethcrypto.GenerateKey()
 	assert.NilError(t, err)
 
-	orig, err := NewSignedDecryptionTrigger(cfg.instanceID, cfg.identityPreimage, cfg.blockNumber, HashByteList(txs), privKey)
+	orig, err := NewSignedDecryptionTrigger(
+		context.Background(), cfg.instanceID, cfg.identityPreimage, cfg.blockNumber, HashByteList(txs), signer.NewLocal(privKey),
+	)
 	assert.NilError(t, err)
 	m, tc := marshalUnmarshalMessage(t, orig, nil)
 	assert.Assert(t, tc == nil)
@@ -122,7 +128,7 @@ func TestEonPublicKey(t *testing.T) {
 	eon := uint64(5)
 	keyperConfigIndex := uint64(6)
 	orig, err := NewSignedEonPublicKey(
-		cfg.instanceID, eonPublicKey, activationBlock, keyperConfigIndex, eon, privKey,
+		context.Background(), cfg.instanceID, eonPublicKey, activationBlock, keyperConfigIndex, eon, signer.NewLocal(privKey),
 	)
 	assert.NilError(t, err)
 
@@ -131,6 +137,82 @@ func TestEonPublicKey(t *testing.T) {
 	assert.DeepEqual(t, orig, m, cmpopts.IgnoreUnexported(EonPublicKey{}))
 }
 
+func TestKeyperPeerRecord(t *testing.T) {
+	cfg := defaultTestConfig(t)
+	privKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	p2pKey, _, err := p2pcrypto.GenerateEd25519Key(rand.Reader)
+	assert.NilError(t, err)
+	peerID, err := peer.IDFromPrivateKey(p2pKey)
+	assert.NilError(t, err)
+
+	orig, err := NewSignedKeyperPeerRecord(context.Background(), cfg.instanceID, peerID, 1000, signer.NewLocal(privKey))
+	assert.NilError(t, err)
+	assert.NilError(t, orig.Validate())
+
+	m, tc := marshalUnmarshalMessage(t, orig, nil)
+	assert.Assert(t, tc == nil)
+	assert.DeepEqual(t, orig, m, cmpopts.IgnoreUnexported(KeyperPeerRecord{}))
+
+	address := ethcrypto.PubkeyToAddress(privKey.PublicKey)
+	assert.Equal(t, m.GetKeyperAddressValue(), address)
+	decodedPeerID, err := m.GetPeerIDValue()
+	assert.NilError(t, err)
+	assert.Equal(t, decodedPeerID, peerID)
+	valid, err := VerifySignature(m, address)
+	assert.NilError(t, err)
+	assert.Assert(t, valid)
+
+	m.Timestamp++
+	valid, err = VerifySignature(m, address)
+	assert.NilError(t, err)
+	assert.Assert(t, !valid)
+
+	m.PeerId = "invalid"
+	assert.ErrorContains(t, m.Validate(), "invalid peer ID")
+	m.KeyperAddress = m.KeyperAddress[1:]
+	assert.ErrorContains(t, m.Validate(), "keyper address has 19 bytes")
+}
+
+func TestKeyperStatus(t *testing.T) {
+	cfg := defaultTestConfig(t)
+	privKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+
+	orig, err := NewSignedKeyperStatus(context.Background(), &KeyperStatus{
+		InstanceId:        cfg.instanceID,
+		KeyperIndex:       2,
+		Eon:               3,
+		BlockNumber:       1000,
+		Slot:              2000,
+		ShuttermintHeight: 500,
+		Version:           "v1.2.3",
+		Timestamp:         1000,
+	}, signer.NewLocal(privKey))
+	assert.NilError(t, err)
+	assert.NilError(t, orig.Validate())
+
+	m, tc := marshalUnmarshalMessage(t, orig, nil)
+	assert.Assert(t, tc == nil)
+	assert.DeepEqual(t, orig, m, cmpopts.IgnoreUnexported(KeyperStatus{}))
+
+	address := ethcrypto.PubkeyToAddress(privKey.PublicKey)
+	assert.Equal(t, m.GetKeyperAddressValue(), address)
+	valid, err := VerifySignature(m, address)
+	assert.NilError(t, err)
+	assert.Assert(t, valid)
+
+	m.BlockNumber++
+	valid, err = VerifySignature(m, address)
+	assert.NilError(t, err)
+	assert.Assert(t, !valid)
+
+	m.ShuttermintHeight = -1
+	assert.ErrorContains(t, m.Validate(), "negative shuttermint height")
+	m.KeyperAddress = m.KeyperAddress[1:]
+	assert.ErrorContains(t, m.Validate(), "keyper address has 19 bytes")
+}
+
 func TestTraceContext(t *testing.T) {
 	trace.SetEnabled()
 	defer trace.SetDisabled()
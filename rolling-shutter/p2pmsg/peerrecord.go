@@ -0,0 +1,86 @@
+package p2pmsg
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/keyper/kprtopics"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
+)
+
+var keyperPeerRecordHashPrefix = []byte{0x19, 'p', 'e', 'e', 'r', 'r', 'e', 'c'}
+
+// NewSignedKeyperPeerRecord creates a record binding the given peer ID to the address of the
+// signer and signs it.
+func NewSignedKeyperPeerRecord(
+	ctx context.Context,
+	instanceID uint64,
+	peerID peer.ID,
+	timestamp uint64,
+	s signer.Signer,
+) (*KeyperPeerRecord, error) {
+	record := &KeyperPeerRecord{
+		InstanceId:    instanceID,
+		KeyperAddress: s.Address().Bytes(),
+		PeerId:        peerID.String(),
+		Timestamp:     timestamp,
+	}
+	err := SignWithSigner(ctx, record, s)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (r *KeyperPeerRecord) SetSignature(s []byte) {
+	r.Signature = s
+}
+
+func (r *KeyperPeerRecord) Hash() []byte {
+	hash := sha3.New256()
+	hash.Write(keyperPeerRecordHashPrefix)
+	_ = binary.Write(hash, binary.BigEndian, r.InstanceId)
+	_ = binary.Write(hash, binary.BigEndian, r.Timestamp)
+	hash.Write(r.KeyperAddress)
+	hash.Write([]byte(r.PeerId))
+	return hash.Sum(nil)
+}
+
+// GetKeyperAddressValue returns the keyper address as common.Address. It must only be called on
+// validated records.
+func (r *KeyperPeerRecord) GetKeyperAddressValue() common.Address {
+	return common.BytesToAddress(r.KeyperAddress)
+}
+
+// GetPeerIDValue returns the decoded peer ID.
+func (r *KeyperPeerRecord) GetPeerIDValue() (peer.ID, error) {
+	return peer.Decode(r.PeerId)
+}
+
+func (r *KeyperPeerRecord) LogInfo() string {
+	return fmt.Sprintf(
+		"KeyperPeerRecord{keyper=%s, peer=%s}",
+		common.BytesToAddress(r.KeyperAddress).Hex(),
+		r.PeerId,
+	)
+}
+
+func (*KeyperPeerRecord) Topic() string {
+	return kprtopics.KeyperPeerRecord
+}
+
+func (r *KeyperPeerRecord) Validate() error {
+	if len(r.KeyperAddress) != common.AddressLength {
+		return errors.Errorf("keyper address has %d bytes, expected %d", len(r.KeyperAddress), common.AddressLength)
+	}
+	if _, err := r.GetPeerIDValue(); err != nil {
+		return errors.Wrap(err, "invalid peer ID")
+	}
+	return nil
+}
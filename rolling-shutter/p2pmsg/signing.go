@@ -1,10 +1,13 @@
 package p2pmsg
 
 import (
+	"context"
 	"crypto/ecdsa"
 
 	"github.com/ethereum/go-ethereum/common"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 )
 
 type Signable interface {
@@ -22,6 +25,16 @@ func Sign(s Signable, privKey *ecdsa.PrivateKey) error {
 	return nil
 }
 
+// SignWithSigner signs the message with the given signer.
+func SignWithSigner(ctx context.Context, s Signable, sgn signer.Signer) error {
+	signature, err := sgn.SignHash(ctx, s.Hash())
+	if err != nil {
+		return err
+	}
+	s.SetSignature(signature)
+	return nil
+}
+
 func RecoverAddress(s Signable) (common.Address, error) {
 	pubkey, err := ethcrypto.SigToPub(s.Hash(), s.GetSignature())
 	if err != nil {
@@ -0,0 +1,1051 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        v5.29.3
+// source: appstate.proto
+
+package shmsg
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// AppState is the persisted state of the shuttermint app. Maps of the app state are stored as
+// repeated fields sorted by their key, so that encoding the same state always results in the same
+// bytes. Addresses are stored as 20 byte values.
+type AppState struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ChainId         string                 `protobuf:"bytes,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	LastBlockHeight int64                  `protobuf:"varint,2,opt,name=last_block_height,json=lastBlockHeight,proto3" json:"last_block_height,omitempty"`
+	EonCounter      uint64                 `protobuf:"varint,3,opt,name=eon_counter,json=eonCounter,proto3" json:"eon_counter,omitempty"`
+	DevMode         bool                   `protobuf:"varint,4,opt,name=dev_mode,json=devMode,proto3" json:"dev_mode,omitempty"`
+	Configs         []*AppStateBatchConfig `protobuf:"bytes,5,rep,name=configs,proto3" json:"configs,omitempty"`
+	ConfigVoting    *AppStateConfigVoting  `protobuf:"bytes,6,opt,name=config_voting,json=configVoting,proto3" json:"config_voting,omitempty"`
+	DkgInstances    []*AppStateDKGInstance `protobuf:"bytes,7,rep,name=dkg_instances,json=dkgInstances,proto3" json:"dkg_instances,omitempty"`
+	Identities      []*AppStateIdentity    `protobuf:"bytes,8,rep,name=identities,proto3" json:"identities,omitempty"`
+	BlocksSeen      []*AppStateBlockSeen   `protobuf:"bytes,9,rep,name=blocks_seen,json=blocksSeen,proto3" json:"blocks_seen,omitempty"`
+	Validators      []*AppStateValidator   `protobuf:"bytes,10,rep,name=validators,proto3" json:"validators,omitempty"`
+	RandomNonces    []*AppStateNonces      `protobuf:"bytes,11,rep,name=random_nonces,json=randomNonces,proto3" json:"random_nonces,omitempty"`
+	ExpiringNonces  []*AppStateNonces      `protobuf:"bytes,12,rep,name=expiring_nonces,json=expiringNonces,proto3" json:"expiring_nonces,omitempty"`
+	Forks           []*AppStateFork        `protobuf:"bytes,13,rep,name=forks,proto3" json:"forks,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AppState) Reset() {
+	*x = AppState{}
+	mi := &file_appstate_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppState) ProtoMessage() {}
+
+func (x *AppState) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppState.ProtoReflect.Descriptor instead.
+func (*AppState) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AppState) GetChainId() string {
+	if x != nil {
+		return x.ChainId
+	}
+	return ""
+}
+
+func (x *AppState) GetLastBlockHeight() int64 {
+	if x != nil {
+		return x.LastBlockHeight
+	}
+	return 0
+}
+
+func (x *AppState) GetEonCounter() uint64 {
+	if x != nil {
+		return x.EonCounter
+	}
+	return 0
+}
+
+func (x *AppState) GetDevMode() bool {
+	if x != nil {
+		return x.DevMode
+	}
+	return false
+}
+
+func (x *AppState) GetConfigs() []*AppStateBatchConfig {
+	if x != nil {
+		return x.Configs
+	}
+	return nil
+}
+
+func (x *AppState) GetConfigVoting() *AppStateConfigVoting {
+	if x != nil {
+		return x.ConfigVoting
+	}
+	return nil
+}
+
+func (x *AppState) GetDkgInstances() []*AppStateDKGInstance {
+	if x != nil {
+		return x.DkgInstances
+	}
+	return nil
+}
+
+func (x *AppState) GetIdentities() []*AppStateIdentity {
+	if x != nil {
+		return x.Identities
+	}
+	return nil
+}
+
+func (x *AppState) GetBlocksSeen() []*AppStateBlockSeen {
+	if x != nil {
+		return x.BlocksSeen
+	}
+	return nil
+}
+
+func (x *AppState) GetValidators() []*AppStateValidator {
+	if x != nil {
+		return x.Validators
+	}
+	return nil
+}
+
+func (x *AppState) GetRandomNonces() []*AppStateNonces {
+	if x != nil {
+		return x.RandomNonces
+	}
+	return nil
+}
+
+func (x *AppState) GetExpiringNonces() []*AppStateNonces {
+	if x != nil {
+		return x.ExpiringNonces
+	}
+	return nil
+}
+
+func (x *AppState) GetForks() []*AppStateFork {
+	if x != nil {
+		return x.Forks
+	}
+	return nil
+}
+
+type AppStateBatchConfig struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Height                int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Keypers               [][]byte               `protobuf:"bytes,2,rep,name=keypers,proto3" json:"keypers,omitempty"`
+	ActivationBlockNumber uint64                 `protobuf:"varint,3,opt,name=activation_block_number,json=activationBlockNumber,proto3" json:"activation_block_number,omitempty"`
+	Threshold             uint64                 `protobuf:"varint,4,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	KeyperConfigIndex     uint64                 `protobuf:"varint,5,opt,name=keyper_config_index,json=keyperConfigIndex,proto3" json:"keyper_config_index,omitempty"`
+	Started               bool                   `protobuf:"varint,6,opt,name=started,proto3" json:"started,omitempty"`
+	ValidatorsUpdated     bool                   `protobuf:"varint,7,opt,name=validators_updated,json=validatorsUpdated,proto3" json:"validators_updated,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *AppStateBatchConfig) Reset() {
+	*x = AppStateBatchConfig{}
+	mi := &file_appstate_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStateBatchConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStateBatchConfig) ProtoMessage() {}
+
+func (x *AppStateBatchConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStateBatchConfig.ProtoReflect.Descriptor instead.
+func (*AppStateBatchConfig) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AppStateBatchConfig) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *AppStateBatchConfig) GetKeypers() [][]byte {
+	if x != nil {
+		return x.Keypers
+	}
+	return nil
+}
+
+func (x *AppStateBatchConfig) GetActivationBlockNumber() uint64 {
+	if x != nil {
+		return x.ActivationBlockNumber
+	}
+	return 0
+}
+
+func (x *AppStateBatchConfig) GetThreshold() uint64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *AppStateBatchConfig) GetKeyperConfigIndex() uint64 {
+	if x != nil {
+		return x.KeyperConfigIndex
+	}
+	return 0
+}
+
+func (x *AppStateBatchConfig) GetStarted() bool {
+	if x != nil {
+		return x.Started
+	}
+	return false
+}
+
+func (x *AppStateBatchConfig) GetValidatorsUpdated() bool {
+	if x != nil {
+		return x.ValidatorsUpdated
+	}
+	return false
+}
+
+// AppStateVote is the vote of a sender for the candidate with the given index.
+type AppStateVote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sender        []byte                 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Candidate     uint64                 `protobuf:"varint,2,opt,name=candidate,proto3" json:"candidate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppStateVote) Reset() {
+	*x = AppStateVote{}
+	mi := &file_appstate_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStateVote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStateVote) ProtoMessage() {}
+
+func (x *AppStateVote) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStateVote.ProtoReflect.Descriptor instead.
+func (*AppStateVote) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AppStateVote) GetSender() []byte {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *AppStateVote) GetCandidate() uint64 {
+	if x != nil {
+		return x.Candidate
+	}
+	return 0
+}
+
+type AppStateConfigVoting struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Candidates    []*AppStateBatchConfig `protobuf:"bytes,1,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	Votes         []*AppStateVote        `protobuf:"bytes,2,rep,name=votes,proto3" json:"votes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppStateConfigVoting) Reset() {
+	*x = AppStateConfigVoting{}
+	mi := &file_appstate_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStateConfigVoting) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStateConfigVoting) ProtoMessage() {}
+
+func (x *AppStateConfigVoting) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStateConfigVoting.ProtoReflect.Descriptor instead.
+func (*AppStateConfigVoting) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AppStateConfigVoting) GetCandidates() []*AppStateBatchConfig {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+func (x *AppStateConfigVoting) GetVotes() []*AppStateVote {
+	if x != nil {
+		return x.Votes
+	}
+	return nil
+}
+
+type AppStateSuccessVoting struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Candidates    []bool                 `protobuf:"varint,1,rep,packed,name=candidates,proto3" json:"candidates,omitempty"`
+	Votes         []*AppStateVote        `protobuf:"bytes,2,rep,name=votes,proto3" json:"votes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppStateSuccessVoting) Reset() {
+	*x = AppStateSuccessVoting{}
+	mi := &file_appstate_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStateSuccessVoting) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStateSuccessVoting) ProtoMessage() {}
+
+func (x *AppStateSuccessVoting) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStateSuccessVoting.ProtoReflect.Descriptor instead.
+func (*AppStateSuccessVoting) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AppStateSuccessVoting) GetCandidates() []bool {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+func (x *AppStateSuccessVoting) GetVotes() []*AppStateVote {
+	if x != nil {
+		return x.Votes
+	}
+	return nil
+}
+
+type AppStateSenderReceiver struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sender        []byte                 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Receiver      []byte                 `protobuf:"bytes,2,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppStateSenderReceiver) Reset() {
+	*x = AppStateSenderReceiver{}
+	mi := &file_appstate_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStateSenderReceiver) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStateSenderReceiver) ProtoMessage() {}
+
+func (x *AppStateSenderReceiver) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStateSenderReceiver.ProtoReflect.Descriptor instead.
+func (*AppStateSenderReceiver) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AppStateSenderReceiver) GetSender() []byte {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *AppStateSenderReceiver) GetReceiver() []byte {
+	if x != nil {
+		return x.Receiver
+	}
+	return nil
+}
+
+type AppStateDKGInstance struct {
+	state               protoimpl.MessageState    `protogen:"open.v1"`
+	Eon                 uint64                    `protobuf:"varint,1,opt,name=eon,proto3" json:"eon,omitempty"`
+	Config              *AppStateBatchConfig      `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	SuccessVoting       *AppStateSuccessVoting    `protobuf:"bytes,3,opt,name=success_voting,json=successVoting,proto3" json:"success_voting,omitempty"`
+	PolyEvalsSeen       []*AppStateSenderReceiver `protobuf:"bytes,4,rep,name=poly_evals_seen,json=polyEvalsSeen,proto3" json:"poly_evals_seen,omitempty"`
+	PolyCommitmentsSeen [][]byte                  `protobuf:"bytes,5,rep,name=poly_commitments_seen,json=polyCommitmentsSeen,proto3" json:"poly_commitments_seen,omitempty"`
+	AccusationsSeen     [][]byte                  `protobuf:"bytes,6,rep,name=accusations_seen,json=accusationsSeen,proto3" json:"accusations_seen,omitempty"`
+	ApologiesSeen       [][]byte                  `protobuf:"bytes,7,rep,name=apologies_seen,json=apologiesSeen,proto3" json:"apologies_seen,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *AppStateDKGInstance) Reset() {
+	*x = AppStateDKGInstance{}
+	mi := &file_appstate_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStateDKGInstance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStateDKGInstance) ProtoMessage() {}
+
+func (x *AppStateDKGInstance) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStateDKGInstance.ProtoReflect.Descriptor instead.
+func (*AppStateDKGInstance) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AppStateDKGInstance) GetEon() uint64 {
+	if x != nil {
+		return x.Eon
+	}
+	return 0
+}
+
+func (x *AppStateDKGInstance) GetConfig() *AppStateBatchConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *AppStateDKGInstance) GetSuccessVoting() *AppStateSuccessVoting {
+	if x != nil {
+		return x.SuccessVoting
+	}
+	return nil
+}
+
+func (x *AppStateDKGInstance) GetPolyEvalsSeen() []*AppStateSenderReceiver {
+	if x != nil {
+		return x.PolyEvalsSeen
+	}
+	return nil
+}
+
+func (x *AppStateDKGInstance) GetPolyCommitmentsSeen() [][]byte {
+	if x != nil {
+		return x.PolyCommitmentsSeen
+	}
+	return nil
+}
+
+func (x *AppStateDKGInstance) GetAccusationsSeen() [][]byte {
+	if x != nil {
+		return x.AccusationsSeen
+	}
+	return nil
+}
+
+func (x *AppStateDKGInstance) GetApologiesSeen() [][]byte {
+	if x != nil {
+		return x.ApologiesSeen
+	}
+	return nil
+}
+
+type AppStateIdentity struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Keyper             []byte                 `protobuf:"bytes,1,opt,name=keyper,proto3" json:"keyper,omitempty"`
+	ValidatorPublicKey []byte                 `protobuf:"bytes,2,opt,name=validator_public_key,json=validatorPublicKey,proto3" json:"validator_public_key,omitempty"` // 32 byte ed25519 public key
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *AppStateIdentity) Reset() {
+	*x = AppStateIdentity{}
+	mi := &file_appstate_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStateIdentity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStateIdentity) ProtoMessage() {}
+
+func (x *AppStateIdentity) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStateIdentity.ProtoReflect.Descriptor instead.
+func (*AppStateIdentity) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AppStateIdentity) GetKeyper() []byte {
+	if x != nil {
+		return x.Keyper
+	}
+	return nil
+}
+
+func (x *AppStateIdentity) GetValidatorPublicKey() []byte {
+	if x != nil {
+		return x.ValidatorPublicKey
+	}
+	return nil
+}
+
+type AppStateBlockSeen struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keyper        []byte                 `protobuf:"bytes,1,opt,name=keyper,proto3" json:"keyper,omitempty"`
+	BlockNumber   uint64                 `protobuf:"varint,2,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppStateBlockSeen) Reset() {
+	*x = AppStateBlockSeen{}
+	mi := &file_appstate_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStateBlockSeen) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStateBlockSeen) ProtoMessage() {}
+
+func (x *AppStateBlockSeen) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStateBlockSeen.ProtoReflect.Descriptor instead.
+func (*AppStateBlockSeen) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AppStateBlockSeen) GetKeyper() []byte {
+	if x != nil {
+		return x.Keyper
+	}
+	return nil
+}
+
+func (x *AppStateBlockSeen) GetBlockNumber() uint64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+type AppStateValidator struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PublicKey     []byte                 `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"` // 32 byte ed25519 public key
+	Power         int64                  `protobuf:"varint,2,opt,name=power,proto3" json:"power,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppStateValidator) Reset() {
+	*x = AppStateValidator{}
+	mi := &file_appstate_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStateValidator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStateValidator) ProtoMessage() {}
+
+func (x *AppStateValidator) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStateValidator.ProtoReflect.Descriptor instead.
+func (*AppStateValidator) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AppStateValidator) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *AppStateValidator) GetPower() int64 {
+	if x != nil {
+		return x.Power
+	}
+	return 0
+}
+
+type AppStateNonces struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sender        []byte                 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Nonces        []uint64               `protobuf:"varint,2,rep,packed,name=nonces,proto3" json:"nonces,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppStateNonces) Reset() {
+	*x = AppStateNonces{}
+	mi := &file_appstate_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStateNonces) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStateNonces) ProtoMessage() {}
+
+func (x *AppStateNonces) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStateNonces.ProtoReflect.Descriptor instead.
+func (*AppStateNonces) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AppStateNonces) GetSender() []byte {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *AppStateNonces) GetNonces() []uint64 {
+	if x != nil {
+		return x.Nonces
+	}
+	return nil
+}
+
+// AppStateFork stores the activation of the fork with the given name from the fork registry.
+type AppStateFork struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Enabled       bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Height        int64                  `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Eon           *uint64                `protobuf:"varint,4,opt,name=eon,proto3,oneof" json:"eon,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppStateFork) Reset() {
+	*x = AppStateFork{}
+	mi := &file_appstate_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStateFork) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStateFork) ProtoMessage() {}
+
+func (x *AppStateFork) ProtoReflect() protoreflect.Message {
+	mi := &file_appstate_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStateFork.ProtoReflect.Descriptor instead.
+func (*AppStateFork) Descriptor() ([]byte, []int) {
+	return file_appstate_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AppStateFork) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AppStateFork) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *AppStateFork) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *AppStateFork) GetEon() uint64 {
+	if x != nil && x.Eon != nil {
+		return *x.Eon
+	}
+	return 0
+}
+
+var File_appstate_proto protoreflect.FileDescriptor
+
+var file_appstate_proto_rawDesc = string([]byte{
+	0x0a, 0x0e, 0x61, 0x70, 0x70, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x05, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x22, 0x9b, 0x05, 0x0a, 0x08, 0x41, 0x70, 0x70, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x64, 0x12,
+	0x2a, 0x0a, 0x11, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x68, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x6c, 0x61, 0x73, 0x74,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x65,
+	0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0a, 0x65, 0x6f, 0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x19, 0x0a, 0x08,
+	0x64, 0x65, 0x76, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x64, 0x65, 0x76, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67,
+	0x2e, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73, 0x12, 0x40, 0x0a,
+	0x0d, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x41, 0x70, 0x70,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x56, 0x6f, 0x74, 0x69, 0x6e,
+	0x67, 0x52, 0x0c, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x56, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x12,
+	0x3f, 0x0a, 0x0d, 0x64, 0x6b, 0x67, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73,
+	0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x41,
+	0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x44, 0x4b, 0x47, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x52, 0x0c, 0x64, 0x6b, 0x67, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73,
+	0x12, 0x37, 0x0a, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x08,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x41, 0x70, 0x70,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x52, 0x0a, 0x69,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x0b, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x5f, 0x73, 0x65, 0x65, 0x6e, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18,
+	0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x65, 0x65, 0x6e, 0x52, 0x0a, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
+	0x53, 0x65, 0x65, 0x6e, 0x12, 0x38, 0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f,
+	0x72, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67,
+	0x2e, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74,
+	0x6f, 0x72, 0x52, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x3a,
+	0x0a, 0x0d, 0x72, 0x61, 0x6e, 0x64, 0x6f, 0x6d, 0x5f, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x73, 0x18,
+	0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x41, 0x70,
+	0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x73, 0x52, 0x0c, 0x72, 0x61,
+	0x6e, 0x64, 0x6f, 0x6d, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x3e, 0x0a, 0x0f, 0x65, 0x78,
+	0x70, 0x69, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x0c, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x41, 0x70, 0x70, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x73, 0x52, 0x0e, 0x65, 0x78, 0x70, 0x69,
+	0x72, 0x69, 0x6e, 0x67, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x05, 0x66, 0x6f,
+	0x72, 0x6b, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x6d, 0x73,
+	0x67, 0x2e, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x72, 0x6b, 0x52, 0x05,
+	0x66, 0x6f, 0x72, 0x6b, 0x73, 0x22, 0x96, 0x02, 0x0a, 0x13, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x16, 0x0a,
+	0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6b, 0x65, 0x79, 0x70, 0x65, 0x72, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x70, 0x65, 0x72, 0x73, 0x12,
+	0x36, 0x0a, 0x17, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x15, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73,
+	0x68, 0x6f, 0x6c, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x68, 0x72, 0x65,
+	0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x2e, 0x0a, 0x13, 0x6b, 0x65, 0x79, 0x70, 0x65, 0x72, 0x5f,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x11, 0x6b, 0x65, 0x79, 0x70, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12,
+	0x2d, 0x0a, 0x12, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x5f, 0x75, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x22, 0x44,
+	0x0a, 0x0c, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06,
+	0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64,
+	0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x63, 0x61, 0x6e, 0x64, 0x69,
+	0x64, 0x61, 0x74, 0x65, 0x22, 0x7d, 0x0a, 0x14, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x56, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x3a, 0x0a, 0x0a,
+	0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x0a, 0x63, 0x61,
+	0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x05, 0x76, 0x6f, 0x74, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e,
+	0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x52, 0x05, 0x76, 0x6f,
+	0x74, 0x65, 0x73, 0x22, 0x62, 0x0a, 0x15, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x53,
+	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x56, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x1e, 0x0a, 0x0a,
+	0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x08,
+	0x52, 0x0a, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x05,
+	0x76, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68,
+	0x6d, 0x73, 0x67, 0x2e, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x56, 0x6f, 0x74, 0x65,
+	0x52, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x73, 0x22, 0x4c, 0x0a, 0x16, 0x41, 0x70, 0x70, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65,
+	0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x63,
+	0x65, 0x69, 0x76, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x72, 0x65, 0x63,
+	0x65, 0x69, 0x76, 0x65, 0x72, 0x22, 0xed, 0x02, 0x0a, 0x13, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x44, 0x4b, 0x47, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x10, 0x0a,
+	0x03, 0x65, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x65, 0x6f, 0x6e, 0x12,
+	0x32, 0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x12, 0x43, 0x0a, 0x0e, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x76,
+	0x6f, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x73, 0x68,
+	0x6d, 0x73, 0x67, 0x2e, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x53, 0x75, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x56, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x52, 0x0d, 0x73, 0x75, 0x63, 0x63, 0x65,
+	0x73, 0x73, 0x56, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x45, 0x0a, 0x0f, 0x70, 0x6f, 0x6c, 0x79,
+	0x5f, 0x65, 0x76, 0x61, 0x6c, 0x73, 0x5f, 0x73, 0x65, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1d, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x72,
+	0x52, 0x0d, 0x70, 0x6f, 0x6c, 0x79, 0x45, 0x76, 0x61, 0x6c, 0x73, 0x53, 0x65, 0x65, 0x6e, 0x12,
+	0x32, 0x0a, 0x15, 0x70, 0x6f, 0x6c, 0x79, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65,
+	0x6e, 0x74, 0x73, 0x5f, 0x73, 0x65, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x13,
+	0x70, 0x6f, 0x6c, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x53,
+	0x65, 0x65, 0x6e, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x63, 0x63, 0x75, 0x73, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x5f, 0x73, 0x65, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0f, 0x61,
+	0x63, 0x63, 0x75, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x53, 0x65, 0x65, 0x6e, 0x12, 0x25,
+	0x0a, 0x0e, 0x61, 0x70, 0x6f, 0x6c, 0x6f, 0x67, 0x69, 0x65, 0x73, 0x5f, 0x73, 0x65, 0x65, 0x6e,
+	0x18, 0x07, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0d, 0x61, 0x70, 0x6f, 0x6c, 0x6f, 0x67, 0x69, 0x65,
+	0x73, 0x53, 0x65, 0x65, 0x6e, 0x22, 0x5c, 0x0a, 0x10, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x6b, 0x65, 0x79,
+	0x70, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6b, 0x65, 0x79, 0x70, 0x65,
+	0x72, 0x12, 0x30, 0x0a, 0x14, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x70,
+	0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x12, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63,
+	0x4b, 0x65, 0x79, 0x22, 0x4e, 0x0a, 0x11, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x65, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6b, 0x65, 0x79, 0x70,
+	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6b, 0x65, 0x79, 0x70, 0x65, 0x72,
+	0x12, 0x21, 0x0a, 0x0c, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x22, 0x48, 0x0a, 0x11, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x56,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x75, 0x62, 0x6c,
+	0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x75,
+	0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x77, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x22, 0x40, 0x0a,
+	0x0e, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x6f, 0x6e, 0x63, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x04, 0x52, 0x06, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x73, 0x22,
+	0x73, 0x0a, 0x0c, 0x41, 0x70, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x72, 0x6b, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x16, 0x0a,
+	0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x15, 0x0a, 0x03, 0x65, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x04, 0x48, 0x00, 0x52, 0x03, 0x65, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x06, 0x0a, 0x04,
+	0x5f, 0x65, 0x6f, 0x6e, 0x42, 0x0a, 0x5a, 0x08, 0x2e, 0x2f, 0x3b, 0x73, 0x68, 0x6d, 0x73, 0x67,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_appstate_proto_rawDescOnce sync.Once
+	file_appstate_proto_rawDescData []byte
+)
+
+func file_appstate_proto_rawDescGZIP() []byte {
+	file_appstate_proto_rawDescOnce.Do(func() {
+		file_appstate_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_appstate_proto_rawDesc), len(file_appstate_proto_rawDesc)))
+	})
+	return file_appstate_proto_rawDescData
+}
+
+var file_appstate_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_appstate_proto_goTypes = []any{
+	(*AppState)(nil),               // 0: shmsg.AppState
+	(*AppStateBatchConfig)(nil),    // 1: shmsg.AppStateBatchConfig
+	(*AppStateVote)(nil),           // 2: shmsg.AppStateVote
+	(*AppStateConfigVoting)(nil),   // 3: shmsg.AppStateConfigVoting
+	(*AppStateSuccessVoting)(nil),  // 4: shmsg.AppStateSuccessVoting
+	(*AppStateSenderReceiver)(nil), // 5: shmsg.AppStateSenderReceiver
+	(*AppStateDKGInstance)(nil),    // 6: shmsg.AppStateDKGInstance
+	(*AppStateIdentity)(nil),       // 7: shmsg.AppStateIdentity
+	(*AppStateBlockSeen)(nil),      // 8: shmsg.AppStateBlockSeen
+	(*AppStateValidator)(nil),      // 9: shmsg.AppStateValidator
+	(*AppStateNonces)(nil),         // 10: shmsg.AppStateNonces
+	(*AppStateFork)(nil),           // 11: shmsg.AppStateFork
+}
+var file_appstate_proto_depIdxs = []int32{
+	1,  // 0: shmsg.AppState.configs:type_name -> shmsg.AppStateBatchConfig
+	3,  // 1: shmsg.AppState.config_voting:type_name -> shmsg.AppStateConfigVoting
+	6,  // 2: shmsg.AppState.dkg_instances:type_name -> shmsg.AppStateDKGInstance
+	7,  // 3: shmsg.AppState.identities:type_name -> shmsg.AppStateIdentity
+	8,  // 4: shmsg.AppState.blocks_seen:type_name -> shmsg.AppStateBlockSeen
+	9,  // 5: shmsg.AppState.validators:type_name -> shmsg.AppStateValidator
+	10, // 6: shmsg.AppState.random_nonces:type_name -> shmsg.AppStateNonces
+	10, // 7: shmsg.AppState.expiring_nonces:type_name -> shmsg.AppStateNonces
+	11, // 8: shmsg.AppState.forks:type_name -> shmsg.AppStateFork
+	1,  // 9: shmsg.AppStateConfigVoting.candidates:type_name -> shmsg.AppStateBatchConfig
+	2,  // 10: shmsg.AppStateConfigVoting.votes:type_name -> shmsg.AppStateVote
+	2,  // 11: shmsg.AppStateSuccessVoting.votes:type_name -> shmsg.AppStateVote
+	1,  // 12: shmsg.AppStateDKGInstance.config:type_name -> shmsg.AppStateBatchConfig
+	4,  // 13: shmsg.AppStateDKGInstance.success_voting:type_name -> shmsg.AppStateSuccessVoting
+	5,  // 14: shmsg.AppStateDKGInstance.poly_evals_seen:type_name -> shmsg.AppStateSenderReceiver
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_appstate_proto_init() }
+func file_appstate_proto_init() {
+	if File_appstate_proto != nil {
+		return
+	}
+	file_appstate_proto_msgTypes[11].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_appstate_proto_rawDesc), len(file_appstate_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_appstate_proto_goTypes,
+		DependencyIndexes: file_appstate_proto_depIdxs,
+		MessageInfos:      file_appstate_proto_msgTypes,
+	}.Build()
+	File_appstate_proto = out.File
+	file_appstate_proto_goTypes = nil
+	file_appstate_proto_depIdxs = nil
+}
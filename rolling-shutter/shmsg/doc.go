@@ -3,3 +3,4 @@
 package shmsg
 
 //go:generate protoc shmsg.proto --go_out=./
+//go:generate protoc appstate.proto --go_out=./
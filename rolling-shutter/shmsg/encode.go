@@ -1,6 +1,7 @@
 package shmsg
 
 import (
+	"context"
 	"crypto/ecdsa"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -8,6 +9,8 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
 	"google.golang.org/protobuf/proto"
+
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 )
 
 // Instead of relying on protocol buffers we simply send a signature, followed by the marshaled message
@@ -18,6 +21,11 @@ var shmsgHashPrefix = []byte{0x19, 's', 'h', 'm', 's', 'g'}
 
 // SignMessage signs the given Message with the given private key.
 func SignMessage(msg proto.Message, privkey *ecdsa.PrivateKey) ([]byte, error) {
+	return SignMessageWithSigner(context.Background(), msg, signer.NewLocal(privkey))
+}
+
+// SignMessageWithSigner signs the given Message with the given signer.
+func SignMessageWithSigner(ctx context.Context, msg proto.Message, s signer.Signer) ([]byte, error) {
 	marshaled, err := proto.Marshal(msg)
 	if err != nil {
 		return nil, err
@@ -26,7 +34,7 @@ func SignMessage(msg proto.Message, privkey *ecdsa.PrivateKey) ([]byte, error) {
 	hash.Write(shmsgHashPrefix)
 	hash.Write(marshaled)
 	h := hash.Sum(nil)
-	signature, err := crypto.Sign(h, privkey)
+	signature, err := s.SignHash(ctx, h)
 	if err != nil {
 		return nil, err
 	}
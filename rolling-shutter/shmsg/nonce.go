@@ -0,0 +1,16 @@
+package shmsg
+
+// Nonces of MessageWithNonce bind a message to the shuttermint block height at which it has been
+// created. The height is stored in the upper 32 bits of the nonce, the lower 32 bits are chosen
+// randomly. This allows the shuttermint app to reject old messages and to forget about the nonces
+// of messages that are too old to be accepted anyway.
+
+// NewNonce creates a nonce for a message sent at the given block height.
+func NewNonce(height int64, random uint32) uint64 {
+	return uint64(height)<<32 | uint64(random) //nolint:gosec // G115
+}
+
+// NonceHeight returns the block height encoded in the given nonce.
+func NonceHeight(nonce uint64) int64 {
+	return int64(nonce >> 32)
+}
@@ -0,0 +1,15 @@
+package shmsg
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestNonceHeight(t *testing.T) {
+	for _, height := range []int64{0, 1, 123456, 1<<32 - 1} {
+		nonce := NewNonce(height, 0xffffffff)
+		assert.Equal(t, NonceHeight(nonce), height)
+	}
+	assert.Assert(t, NewNonce(5, 1) != NewNonce(5, 2))
+}
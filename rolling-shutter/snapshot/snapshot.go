@@ -13,6 +13,7 @@ import (
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/identitypreimage"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/metricsserver"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/service"
+	"github.com/shutter-network/rolling-shutter/rolling-shutter/medley/signer"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2p"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/p2pmsg"
 	"github.com/shutter-network/rolling-shutter/rolling-shutter/shdb"
@@ -30,6 +31,7 @@ type Snapshot struct {
 	dbpool        *pgxpool.Pool
 	db            *database.Queries
 	l1Client      *ethclient.Client
+	signer        signer.Signer
 	hubapi        *hubapi.HubAPI
 	jrpc          *snpjrpc.SnpJRPC
 	metricsServer *metricsserver.MetricsServer
@@ -59,6 +61,14 @@ func (snp *Snapshot) Start(ctx context.Context, runner service.Runner) error {
 		return err
 	}
 	snp.l1Client = l1Client
+	snp.signer, err = signer.New(snp.Config.Ethereum)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize signer")
+	}
+	err = signer.Verify(ctx, snp.signer)
+	if err != nil {
+		return errors.Wrap(err, "signer is not usable")
+	}
 
 	dbpool, err := pgxpool.Connect(ctx, snp.Config.DatabaseURL)
 	if err != nil {
@@ -137,11 +147,12 @@ func (snp *Snapshot) handleDecryptionKeyRequest(ctx context.Context, epochID []b
 		return err
 	}
 	trigMsg, err := p2pmsg.NewSignedDecryptionTrigger(
+		ctx,
 		snp.Config.InstanceID,
 		identityPreimage,
 		blockNumber,
 		zeroTXHash,
-		snp.Config.Ethereum.PrivateKey.Key,
+		snp.signer,
 	)
 	if err != nil {
 		return err
@@ -45,7 +45,7 @@ func printShutterApp(app *app.ShutterApp) {
 
 	fmt.Printf("ConfigVoting: %+v\n", app.ConfigVoting)
 
-	fmt.Printf("Gobpath: %s\n", app.Gobpath)
+	fmt.Printf("StatePath: %s\n", app.StatePath)
 	fmt.Printf("LastSaved: %s\n", app.LastSaved)
 	fmt.Printf("LastBlockHeight: %d\n", app.LastBlockHeight)
 